@@ -128,6 +128,11 @@ func (context Context) sendAPIGetRequest(relPath string, query map[string]string
 }
 
 func (context Context) sendAPIPostRequest(relPath string, body io.Reader, contentType string) (resp *http.Response, err error) {
+	return context.sendAPIPostRequestWithStatus(relPath, body, contentType, 201)
+}
+
+// sendAPIPostRequestWithStatus sends a POST request which is expected to result in the given status code.
+func (context Context) sendAPIPostRequestWithStatus(relPath string, body io.Reader, contentType string, status int) (resp *http.Response, err error) {
 
 	reqURL := url.URL{
 		Scheme: "http",
@@ -148,7 +153,7 @@ func (context Context) sendAPIPostRequest(relPath string, body io.Reader, conten
 		return nil, errors.Wrap(err, "HTTP client error")
 	}
 
-	if resp.StatusCode != 201 {
+	if resp.StatusCode != status {
 		errorResponse, err := getAPIErrorResponse(resp)
 		errorString := errorResponse.String()
 		if err == nil || errorString == "" {
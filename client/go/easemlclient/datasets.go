@@ -86,8 +86,9 @@ func (context Context) GetDatasetByID(id string) (result *types.Dataset, err err
 	return &respObject.Data, nil
 }
 
-// CreateDataset creates a new dataset given the provided parameters.
-func (context Context) CreateDataset(id, name, description, source, sourceAddress,accessKey string) (string, error) {
+// CreateDataset creates a new dataset given the provided parameters. The checksum is an optional sha256 digest
+// which the downloaded dataset file is verified against.
+func (context Context) CreateDataset(id, name, description, source, sourceAddress, accessKey, checksum string) (string, error) {
 
 	if id == "" {
 		panic("id argument cannot be empty")
@@ -113,6 +114,7 @@ func (context Context) CreateDataset(id, name, description, source, sourceAddres
 		Source:        source,
 		SourceAddress: sourceAddress,
 		AccessKey:	 accessKey,
+		Checksum:      checksum,
 	}
 
 	datasetBytes, err := json.Marshal(&dataset)
@@ -159,6 +161,7 @@ var ValidDatasetSources = []string{
 	types.DatasetDownload,
 	types.DatasetLocal,
 	types.DatasetGit,
+	types.DatasetS3,
 }
 
 // DatasetSourceValid checks if the provided dataset source is valid.
@@ -182,6 +185,8 @@ func DatasetSourceAddressRequired(source string) bool {
 		return true
 	case types.DatasetGit:
 		return true
+	case types.DatasetS3:
+		return true
 	default:
 		panic("unknown source")
 	}
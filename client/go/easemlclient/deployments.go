@@ -0,0 +1,155 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"github.com/ds3lab/easeml/client/go/easemlclient/types"
+
+	"github.com/pkg/errors"
+)
+
+// GetDeployments returns all deployments from the service.
+func (context Context) GetDeployments(user, status, task, modelName string) (result []types.Deployment, err error) {
+
+	result = []types.Deployment{}
+	nextCursor := ""
+
+	for {
+
+		query := map[string]string{}
+		if nextCursor != "" {
+			query["cursor"] = nextCursor
+		}
+		if user != "" {
+			query["user"] = user
+		}
+		if status != "" {
+			query["status"] = status
+		}
+		if task != "" {
+			query["task"] = task
+		}
+		if modelName != "" {
+			query["model"] = modelName
+		}
+		resp, err := context.sendAPIGetRequest("deployments", query)
+		if err != nil {
+			return nil, err
+		}
+
+		type getResponse struct {
+			Data     []types.Deployment       `json:"data"`
+			Metadata types.CollectionMetadata `json:"metadata"`
+			Links    map[string]string        `json:"links"`
+		}
+		respObject := getResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&respObject)
+		if err != nil {
+			return nil, errors.Wrap(err, "JSON decode error")
+		}
+		nextCursor = respObject.Metadata.NextPageCursor
+		result = append(result, respObject.Data...)
+
+		if nextCursor == "" || len(respObject.Data) == 0 {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// GetDeploymentByID returns a deployment given its ID.
+func (context Context) GetDeploymentByID(id string) (result *types.Deployment, err error) {
+
+	resp, err := context.sendAPIGetRequest(path.Join("deployments", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	type getDeploymentByIDResponse struct {
+		Data types.Deployment `json:"data"`
+	}
+	respObject := getDeploymentByIDResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&respObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON decode error")
+	}
+
+	return &respObject.Data, nil
+}
+
+// CreateDeployment deploys a completed task and returns the ID of the deployment.
+func (context Context) CreateDeployment(task string) (string, error) {
+
+	deployment := types.Deployment{Task: task}
+	deploymentBytes, err := json.Marshal(&deployment)
+	if err != nil {
+		return "", err
+	}
+	resp, err := context.sendAPIPostRequest("deployments", bytes.NewReader(deploymentBytes), "application/json")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Extract deployment ID if possible.
+	id := ""
+	location := resp.Header.Get("Location")
+	if location != "" {
+		id = path.Base(location)
+	}
+
+	return id, nil
+}
+
+// StopDeployment stops a deployment so that it doesn't accept prediction requests anymore.
+func (context Context) StopDeployment(id string) (err error) {
+	if id == "" {
+		panic("id argument cannot be empty")
+	}
+	updates := map[string]interface{}{"status": types.DeploymentStopped}
+	deploymentBytes, err := json.Marshal(&updates)
+	if err != nil {
+		return err
+	}
+	resp, err := context.sendAPIPatchRequest(path.Join("deployments", id), bytes.NewReader(deploymentBytes), "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// GetDeploymentLogs returns the log of a deployment.
+func (context Context) GetDeploymentLogs(id string) (string, error) {
+
+	resp, err := context.sendAPIGetRequest(path.Join("deployments", id, "logs"), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	logs, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "HTTP read error")
+	}
+	return string(logs), nil
+}
+
+// PredictWithDeployment sends samples to a deployment and returns the predictions. The samples are a tar
+// archive with an "input" directory, in which case a tar archive with an "output" directory is returned, or a
+// JSON object which maps sample names to objects of named tensors, in which case the predictions are returned
+// as a JSON object with a "data" field. The returned reader must be closed.
+func (context Context) PredictWithDeployment(id string, samples io.Reader, contentType string) (io.ReadCloser, error) {
+
+	resp, err := context.sendAPIPostRequestWithStatus(path.Join("deployments", id, "predict"), samples, contentType, 200)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -84,7 +84,7 @@ func (context Context) GetJobByID(id string) (result *types.Job, err error) {
 }
 
 // CreateJob creates a new job given the provided parameters.
-func (context Context) CreateJob(dataset, objective string, models []string, altObjectives []string, acceptNewModels bool, maxTasks uint64) (string, error) {
+func (context Context) CreateJob(dataset, objective string, models []string, altObjectives []string, acceptNewModels bool, maxTasks uint64, sampler string, samplerSeed int64) (string, error) {
 
 	// TODO: Perform checks.
 
@@ -95,6 +95,41 @@ func (context Context) CreateJob(dataset, objective string, models []string, alt
 		AltObjectives:   altObjectives,
 		AcceptNewModels: acceptNewModels,
 		MaxTasks:        maxTasks,
+		Sampler:         sampler,
+		SamplerSeed:     samplerSeed,
+	}
+
+	jobBytes, err := json.Marshal(&job)
+	if err != nil {
+		return "", err
+	}
+	resp, err := context.sendAPIPostRequest("jobs", bytes.NewReader(jobBytes), "application/json")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Extract job ID if possible.
+	id := ""
+	location := resp.Header.Get("Location")
+	if location != "" {
+		id = path.Base(location)
+	}
+
+	return id, nil
+}
+
+// CreatePredictionJob creates a job which applies the model of a completed task to a dataset that contains only
+// inputs. The task can also be given through a deployment of it. The predictions are stored as a new dataset
+// with the given ID, or an ID generated from the job ID if it is empty.
+func (context Context) CreatePredictionJob(dataset, task, deployment, predictions string) (string, error) {
+
+	job := types.Job{
+		Kind:        types.JobPredict,
+		Dataset:     dataset,
+		Task:        task,
+		Deployment:  deployment,
+		Predictions: predictions,
 	}
 
 	jobBytes, err := json.Marshal(&job)
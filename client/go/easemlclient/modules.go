@@ -92,8 +92,53 @@ func (context Context) GetModuleByID(id string) (result *types.Module, err error
 	return &respObject.Data, nil
 }
 
+// GetModuleCompatibility explains whether the module can be applied to the dataset.
+func (context Context) GetModuleCompatibility(moduleID, datasetID string) (result *types.ModuleCompatibility, err error) {
+
+	resp, err := context.sendAPIGetRequest(path.Join("modules", moduleID, "compatibility"), map[string]string{"dataset": datasetID})
+	if err != nil {
+		return nil, err
+	}
+
+	type getModuleCompatibilityResponse struct {
+		Data types.ModuleCompatibility `json:"data"`
+	}
+	respObject := getModuleCompatibilityResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&respObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON decode error")
+	}
+
+	return &respObject.Data, nil
+}
+
+// GetCompatibleModules returns all active modules of the given type which can be applied to the dataset.
+// If the module type is empty, modules of all types are returned.
+func (context Context) GetCompatibleModules(datasetID, moduleType string) (result []types.CompatibleModule, err error) {
+
+	query := map[string]string{}
+	if moduleType != "" {
+		query["type"] = moduleType
+	}
+	resp, err := context.sendAPIGetRequest(path.Join("datasets", datasetID, "compatible-modules"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	type getCompatibleModulesResponse struct {
+		Data []types.CompatibleModule `json:"data"`
+	}
+	respObject := getCompatibleModulesResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&respObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON decode error")
+	}
+
+	return respObject.Data, nil
+}
+
 // CreateModule creates a new module given the provided parameters.
-func (context Context) CreateModule(id, moduleType, label, name, description, source, sourceAddress string) (string, error) {
+func (context Context) CreateModule(id, moduleType, label, name, description, source, sourceAddress, registryUsername, registryPassword string) (string, error) {
 
 	if id == "" {
 		panic("id argument cannot be empty")
@@ -112,13 +157,15 @@ func (context Context) CreateModule(id, moduleType, label, name, description, so
 	}
 
 	module := types.Module{
-		ID:            id,
-		Type:          moduleType,
-		Label:         label,
-		Name:          name,
-		Description:   description,
-		Source:        source,
-		SourceAddress: sourceAddress,
+		ID:               id,
+		Type:             moduleType,
+		Label:            label,
+		Name:             name,
+		Description:      description,
+		Source:           source,
+		SourceAddress:    sourceAddress,
+		RegistryUsername: registryUsername,
+		RegistryPassword: registryPassword,
 	}
 
 	moduleBytes, err := json.Marshal(&module)
@@ -140,6 +187,72 @@ func (context Context) CreateModule(id, moduleType, label, name, description, so
 	return id, nil
 }
 
+// GetModuleVersions returns all versions of a module ordered by version.
+func (context Context) GetModuleVersions(id string) (result []types.Module, err error) {
+
+	resp, err := context.sendAPIGetRequest(path.Join("modules", id, "versions"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	type getModuleVersionsResponse struct {
+		Data []types.Module `json:"data"`
+	}
+	respObject := getModuleVersionsResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&respObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON decode error")
+	}
+
+	return respObject.Data, nil
+}
+
+// CreateModuleVersion creates a new version of an existing module and returns the ID of the new version.
+// The label, name and description are taken from the latest version if they are empty.
+func (context Context) CreateModuleVersion(id, label, name, description, source, sourceAddress, registryUsername, registryPassword string) (string, error) {
+
+	if id == "" {
+		panic("id argument cannot be empty")
+	}
+	if ModuleSourceValid(source) == false {
+		panic("invalid data source: " + source)
+	}
+	if ModuleSourceAddressRequired(source) && sourceAddress == "" {
+		panic("data source address expected")
+	}
+	if nameRegex.MatchString(name) == false {
+		return "", errors.New("invalid module name")
+	}
+
+	module := types.Module{
+		Label:            label,
+		Name:             name,
+		Description:      description,
+		Source:           source,
+		SourceAddress:    sourceAddress,
+		RegistryUsername: registryUsername,
+		RegistryPassword: registryPassword,
+	}
+
+	moduleBytes, err := json.Marshal(&module)
+	if err != nil {
+		return "", err
+	}
+	resp, err := context.sendAPIPostRequest(path.Join("modules", id, "versions"), bytes.NewReader(moduleBytes), "application/json")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Extract ID from location header.
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", errors.New("the location of the new version is missing")
+	}
+
+	return fmt.Sprintf("%s/%s", path.Base(path.Dir(location)), path.Base(location)), nil
+}
+
 // UpdateModule applies the given updates to the module fields.
 func (context Context) UpdateModule(id string, updates map[string]interface{}) (err error) {
 	if id == "" {
@@ -159,6 +272,44 @@ func (context Context) UpdateModule(id string, updates map[string]interface{}) (
 	return nil
 }
 
+// ArchiveModule archives a module and returns the unfinished jobs which depended on it. The service refuses
+// to archive modules which unfinished jobs depend on, unless a replacement model is given to migrate the jobs
+// to or archiving is forced.
+func (context Context) ArchiveModule(id, replacement string, force bool) (result []types.Job, err error) {
+	if id == "" {
+		panic("id argument cannot be empty")
+	}
+	updates := map[string]interface{}{"status": types.ModuleArchived}
+	if replacement != "" {
+		updates["replacement"] = replacement
+	}
+	if force {
+		updates["force"] = true
+	}
+	moduleBytes, err := json.Marshal(&updates)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := context.sendAPIPatchRequest(path.Join("modules", id), bytes.NewReader(moduleBytes), "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	type archiveModuleResponse struct {
+		Data struct {
+			Jobs []types.Job `json:"jobs"`
+		} `json:"data"`
+	}
+	respObject := archiveModuleResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&respObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON decode error")
+	}
+
+	return respObject.Data.Jobs, nil
+}
+
 // ValidModuleTypes is a list of possible module types.
 var ValidModuleTypes = []string{
 	types.ModuleModel,
@@ -182,6 +333,7 @@ var ValidModuleSources = []string{
 	types.ModuleDownload,
 	types.ModuleLocal,
 	types.ModuleRegistry,
+	types.ModuleBuild,
 }
 
 // ModuleSourceValid checks if the provided module source is valid.
@@ -205,6 +357,8 @@ func ModuleSourceAddressRequired(source string) bool {
 		return true
 	case types.ModuleRegistry:
 		return true
+	case types.ModuleBuild:
+		return true
 	default:
 		panic("unknown source")
 	}
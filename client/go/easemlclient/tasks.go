@@ -9,8 +9,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-// GetTasks returns all tasks from the service.
-func (context Context) GetTasks(job, user, status, stage, dataset, objective, modelName string) (result []types.Task, err error) {
+// GetTasks returns all tasks from the service. If the order is given, the tasks are sorted by their quality
+// in the "asc", "desc", "best" or "worst" order. The last two need the tasks to be filtered by job or objective.
+func (context Context) GetTasks(job, user, status, stage, dataset, objective, modelName, order string) (result []types.Task, err error) {
 
 	result = []types.Task{}
 	nextCursor := ""
@@ -42,6 +43,10 @@ func (context Context) GetTasks(job, user, status, stage, dataset, objective, mo
 		if modelName != "" {
 			query["model"] = modelName
 		}
+		if order != "" {
+			query["order-by"] = "quality"
+			query["order"] = order
+		}
 		resp, err := context.sendAPIGetRequest("tasks", query)
 		if err != nil {
 			return nil, err
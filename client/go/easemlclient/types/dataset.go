@@ -17,6 +17,12 @@ const (
 	// DatasetGitLfs is a data set that has been fetched from a git repository
 	DatasetGit = "git"
 
+	// DatasetS3 is a data set that has been fetched from an S3-compatible object store.
+	DatasetS3 = "s3"
+
+	// DatasetPrediction is a data set that holds the predictions of a prediction job.
+	DatasetPrediction = "prediction"
+
 	// DatasetCreated is the status of a dataset when it is recorded in the system but the data is not yet transferred.
 	DatasetCreated = "created"
 
@@ -40,17 +46,22 @@ const (
 
 // Dataset contains information about datasets.
 type Dataset struct {
-	ID            string    `json:"id"`
-	User          string    `json:"user"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	SchemaIn      string    `json:"schema-in"`
-	SchemaOut     string    `json:"schema-out"`
-	Source        string    `json:"source"`
-	SourceAddress string    `json:"source-address"`
-	CreationTime  time.Time `json:"creation-time"`
-	Status        string    `json:"status"`
-	StatusMessage string    `json:"status-message"`
-	Process       string    `json:"process"`
-	AccessKey	  string    `json:"access-key"`
+	ID               string    `json:"id"`
+	User             string    `json:"user"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	SchemaIn         string    `json:"schema-in"`
+	SchemaOut        string    `json:"schema-out"`
+	SchemaInHash     string    `json:"schema-in-hash"`
+	SchemaOutHash    string    `json:"schema-out-hash"`
+	Source           string    `json:"source"`
+	SourceAddress    string    `json:"source-address"`
+	CreationTime     time.Time `json:"creation-time"`
+	Status           string    `json:"status"`
+	StatusMessage    string    `json:"status-message"`
+	Process          string    `json:"process"`
+	AccessKey        string    `json:"access-key"`
+	Checksum         string    `json:"checksum"`
+	TransferredBytes int64     `json:"transferred-bytes"`
+	TotalBytes       int64     `json:"total-bytes"`
 }
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"time"
+)
+
+const (
+	// DeploymentStarting is a deployment that was created but is not yet serving predictions.
+	DeploymentStarting = "starting"
+
+	// DeploymentRunning is a deployment whose model is loaded and which accepts prediction requests.
+	DeploymentRunning = "running"
+
+	// DeploymentStopped is a deployment that was stopped by the user and doesn't accept requests anymore.
+	DeploymentStopped = "stopped"
+
+	// DeploymentError is a deployment that could not be started. The error information is logged.
+	DeploymentError = "error"
+)
+
+// Deployment contains information about a trained model which is served by the engine.
+type Deployment struct {
+	ID            string       `json:"id"`
+	User          string       `json:"user"`
+	Task          string       `json:"task"`
+	Job           string       `json:"job"`
+	Model         string       `json:"model"`
+	SchemaIn      string       `json:"schema-in"`
+	SchemaOut     string       `json:"schema-out"`
+	Image         string       `json:"image"`
+	CreationTime  time.Time    `json:"creation-time"`
+	RunningTime   TimeInterval `json:"running-time"`
+	NumRequests   uint64       `json:"num-requests"`
+	Status        string       `json:"status"`
+	StatusMessage string       `json:"status-message"`
+	Process       string       `json:"process"`
+}
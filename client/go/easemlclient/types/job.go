@@ -36,17 +36,46 @@ const (
 	DefaultMaxTasks = 100
 )
 
+const (
+	// JobTrain is the kind of jobs which train and evaluate models on a dataset. Jobs without a kind are training jobs.
+	JobTrain = "train"
+
+	// JobPredict is the kind of jobs which apply the model of a completed task to a dataset that contains only inputs.
+	// The predictions are stored as a new dataset.
+	JobPredict = "predict"
+)
+
+const (
+	// SamplerRandom samples configurations uniformly at random using a seeded generator.
+	SamplerRandom = "random"
+
+	// SamplerSobol samples configurations from the Sobol low-discrepancy sequence.
+	SamplerSobol = "sobol"
+
+	// SamplerHalton samples configurations from the Halton low-discrepancy sequence.
+	SamplerHalton = "halton"
+
+	// SamplerLHS samples configurations from Latin hypercube designs of the size of the job.
+	SamplerLHS = "lhs"
+)
+
 // Job contains information about jobs.
 type Job struct {
 	ID              string       `json:"id"`
 	User            string       `json:"user"`
+	Kind            string       `json:"kind,omitempty"`
 	Dataset         string       `json:"dataset"`
+	Task            string       `json:"task,omitempty"`
+	Deployment      string       `json:"deployment,omitempty"`
+	Predictions     string       `json:"predictions,omitempty"`
 	Models          []string     `json:"models"`
 	ConfigSpace     string       `json:"config-space"`
 	AcceptNewModels bool         `json:"accept-new-models"`
 	Objective       string       `json:"objective"`
 	AltObjectives   []string     `json:"alt-objectives"`
 	MaxTasks        uint64       `json:"max-tasks"`
+	Sampler         string       `json:"sampler"`
+	SamplerSeed     int64        `json:"sampler-seed"`
 	CreationTime    time.Time    `json:"creation-time"`
 	RunningTime     TimeInterval `json:"running-time"`
 	RunningDuration uint64       `json:"running-duration"`
@@ -26,6 +26,15 @@ const (
 	// ModuleRegistry is a module that is obtained from a Docker registry.
 	ModuleRegistry = "registry"
 
+	// ModuleBuild is a module whose image is built from a directory or a git repository with a Dockerfile.
+	ModuleBuild = "build"
+
+	// ModuleBuiltin is a module which is implemented natively by the engine and has no image.
+	ModuleBuiltin = "builtin"
+
+	// ModuleVersionLatest is the version in module references which stands for the latest active version.
+	ModuleVersionLatest = "latest"
+
 	// ModuleCreated is the status of a module that is recorded in the system but not yet transferred.
 	ModuleCreated = "created"
 
@@ -44,19 +53,141 @@ const (
 
 // Module contains information about modules which are stateless Docker images.
 type Module struct {
-	ID            string        `json:"id"`
-	User          string        `json:"user"`
-	Type          string        `json:"type"`
-	Label         string        `json:"label"`
-	Name          string        `json:"name"`
-	Description   string        `json:"description"`
-	SchemaIn      string        `json:"schema-in"`
-	SchemaOut     string        `json:"schema-out"`
-	ConfigSpace   string        `json:"config-space"`
-	Source        string        `json:"source"`
-	SourceAddress string        `json:"source-address"`
-	CreationTime  time.Time     `json:"creation-time"`
-	Status        string        `json:"status"`
-	StatusMessage string        `json:"status-message"`
-	Process       string        `json:"process"`
+	ID               string             `json:"id"`
+	Version          int                `json:"version,omitempty"`
+	User             string             `json:"user"`
+	Type             string             `json:"type"`
+	Label            string             `json:"label"`
+	Name             string             `json:"name"`
+	Description      string             `json:"description"`
+	SchemaIn         string             `json:"schema-in"`
+	SchemaOut        string             `json:"schema-out"`
+	SchemaInHash     string             `json:"schema-in-hash"`
+	SchemaOutHash    string             `json:"schema-out-hash"`
+	ConfigSpace      string             `json:"config-space"`
+	Source           string             `json:"source"`
+	SourceAddress    string             `json:"source-address"`
+	BuildTag         string             `json:"build-tag,omitempty"`
+	BuildLog         string             `json:"build-log,omitempty"`
+	RegistryUsername string             `json:"registry-username,omitempty"`
+	RegistryPassword string             `json:"registry-password,omitempty"`
+	ImageDigest      string             `json:"image-digest,omitempty"`
+	ImageID          string             `json:"image-id,omitempty"`
+	CreationTime     time.Time          `json:"creation-time"`
+	Status           string             `json:"status"`
+	StatusMessage    string             `json:"status-message"`
+	Process          string             `json:"process"`
+	Conformance      *ConformanceReport `json:"conformance,omitempty"`
+	Objective        *ObjectiveMetadata `json:"objective-metadata,omitempty"`
+}
+
+const (
+	// ObjectiveMaximize is the direction of objectives whose higher values are better.
+	ObjectiveMaximize = "maximize"
+
+	// ObjectiveMinimize is the direction of objectives whose lower values are better.
+	ObjectiveMinimize = "minimize"
+
+	// ObjectiveOutputPerSample is the output of objectives which report the value of each sample.
+	ObjectiveOutputPerSample = "per-sample"
+
+	// ObjectiveOutputPerClass is the output of objectives which report the value of each class.
+	ObjectiveOutputPerClass = "per-class"
+
+	// ObjectiveOutputConfusionMatrix is the output of objectives which report a confusion matrix.
+	ObjectiveOutputConfusionMatrix = "confusion-matrix"
+)
+
+// ObjectiveMetadata describes the values computed by an objective module. The direction tells whether higher
+// or lower values are better, the optional bounds limit the values and the outputs list the breakdowns of the
+// value which the objective reports in addition to it.
+type ObjectiveMetadata struct {
+	Direction string   `json:"direction"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Outputs   []string `json:"outputs,omitempty"`
+}
+
+// IsMinimized returns true if lower values of the objective are better. Objectives without metadata are maximized.
+func (m *ObjectiveMetadata) IsMinimized() bool {
+	return m != nil && m.Direction == ObjectiveMinimize
+}
+
+const (
+	// ConformancePassed is the status of a conformance test case which succeeded.
+	ConformancePassed = "passed"
+
+	// ConformanceFailed is the status of a conformance test case which failed.
+	ConformanceFailed = "failed"
+
+	// ConformanceSkipped is the status of a conformance test case which was not run because
+	// a test case it depends on failed.
+	ConformanceSkipped = "skipped"
+)
+
+// ConformanceReport summarizes a model conformance run. The model is trained and applied to several
+// generated datasets with several sampled configurations and its predictions are checked against
+// its output schema. The run can be repeated with the validate model command and the seed.
+type ConformanceReport struct {
+	Image    string           `json:"image"`
+	Time     time.Time        `json:"time"`
+	Duration float64          `json:"duration"`
+	Seed     int64            `json:"seed"`
+	Passed   bool             `json:"passed"`
+	Tests    int              `json:"tests"`
+	Failures int              `json:"failures"`
+	Skipped  int              `json:"skipped"`
+	Runs     []ConformanceRun `json:"runs"`
+}
+
+// ConformanceRun contains the test cases for one generated dataset and one sampled configuration.
+// The dims map the dimension variables of the model schemas to the values used to generate the dataset.
+type ConformanceRun struct {
+	Name   string            `json:"name"`
+	Dims   map[string]int    `json:"dims"`
+	Config string            `json:"config"`
+	Cases  []ConformanceCase `json:"cases"`
+}
+
+// ConformanceCase is the outcome of a single step of a conformance run. The duration is given in seconds.
+type ConformanceCase struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Message  string  `json:"message,omitempty"`
+	Duration float64 `json:"duration"`
+}
+
+// SchemaMatchFailure describes a constraint of a module schema which is violated by a dataset schema.
+type SchemaMatchFailure struct {
+	Path       string `json:"path"`
+	Constraint string `json:"constraint"`
+	Expected   string `json:"expected"`
+	Found      string `json:"found"`
+	Message    string `json:"message"`
+}
+
+// SchemaMatchReport explains the result of matching a dataset schema against a module schema.
+type SchemaMatchReport struct {
+	Match       bool                 `json:"match"`
+	NodeMapping map[string]string    `json:"node-mapping"`
+	Failures    []SchemaMatchFailure `json:"failures"`
+	Suggestions []string             `json:"suggestions"`
+}
+
+// ModuleCompatibility describes whether a module can be applied to a dataset. Reports are only
+// given for the schemas which the module specifies.
+type ModuleCompatibility struct {
+	Module     string             `json:"module"`
+	Dataset    string             `json:"dataset"`
+	Compatible bool               `json:"compatible"`
+	SchemaIn   *SchemaMatchReport `json:"schema-in,omitempty"`
+	SchemaOut  *SchemaMatchReport `json:"schema-out,omitempty"`
+}
+
+// CompatibleModule is a module whose schemas accept the schemas of some dataset. The dimension bindings
+// map the dimension variables of the module schemas to the dimensions found in the dataset schemas.
+type CompatibleModule struct {
+	Module        Module                 `json:"module"`
+	SchemaInDims  map[string]interface{} `json:"schema-in-dims,omitempty"`
+	SchemaOutDims map[string]interface{} `json:"schema-out-dims,omitempty"`
 }
@@ -62,6 +62,28 @@ type TaskStageDurations struct {
 	Evaluating uint64 `json:"evaluating"`
 }
 
+const (
+	// TaskOrderBest orders tasks from the best to the worst quality given the direction of their objective.
+	TaskOrderBest = "best"
+
+	// TaskOrderWorst orders tasks from the worst to the best quality given the direction of their objective.
+	TaskOrderWorst = "worst"
+)
+
+// QualityDetails holds the breakdowns of the quality of a task on the validation set which are reported by
+// the objective in addition to the quality.
+type QualityDetails struct {
+	PerClass        map[string]float64 `json:"per-class,omitempty"`
+	ConfusionMatrix *ConfusionMatrix   `json:"confusion-matrix,omitempty"`
+}
+
+// ConfusionMatrix counts the samples of each actual class (rows) which were predicted as each class (columns).
+// Rows and columns follow the order of the labels.
+type ConfusionMatrix struct {
+	Labels []string `json:"labels"`
+	Counts [][]int  `json:"counts"`
+}
+
 // Task contains information about tasks.
 type Task struct {
 	ID              string             `json:"id"`
@@ -77,6 +99,7 @@ type Task struct {
 	QualityTrain    float64            `json:"quality-train"`
 	QualityExpected float64            `json:"quality-expected"`
 	AltQualities    []float64          `json:"alt-qualities"`
+	QualityDetails  *QualityDetails    `json:"quality-details,omitempty"`
 	Status          string             `json:"status"`
 	StatusMessage   string             `json:"status-message"`
 	Stage           string             `json:"stage"`
@@ -17,7 +17,10 @@ function transformDataItem (input) {
     schemaOut: input['schema-out'],
     sourceAddress: input['source-address'],
     creationTime: new Date(input['creation-time']),
-    status: input.status
+    status: input.status,
+    checksum: input.checksum,
+    transferredBytes: input['transferred-bytes'] || 0,
+    totalBytes: input['total-bytes'] || 0
   }
 }
 
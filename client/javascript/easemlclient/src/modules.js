@@ -14,6 +14,7 @@ function transformDataItem (input) {
     schemaIn: input['schema-in'],
     schemaOut: input['schema-out'],
     configSpace: input['config-space'],
+    objectiveMetadata: input['objective-metadata'],
     user: input.user,
     source: input.source,
     sourceAddress: input['source-address'],
@@ -26,6 +26,7 @@ function transformDataItem (input) {
     qualityTrain: input['quality-train'],
     qualityExpected: input['quality-expected'],
     altQualities: input['alt-qualities'],
+    qualityDetails: input['quality-details'],
     status: input.status,
     statusMessage: input['status-message'],
     stage: input.stage,
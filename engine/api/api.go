@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/ds3lab/easeml/engine/database/model"
 	"github.com/ds3lab/easeml/engine/logger"
+	"github.com/ds3lab/easeml/engine/modules"
 	"github.com/ds3lab/easeml/engine/storage"
 )
 
@@ -11,4 +12,5 @@ type Context struct {
 	ModelContext   model.Context
 	StorageContext storage.Context
 	Logger         logger.Logger
+	Runtime        modules.Runtime
 }
@@ -33,6 +33,8 @@ func (apiContext Context) DatasetsGet(w http.ResponseWriter, r *http.Request) {
 	source := query.Get("source")
 	schemaIn := query.Get("schema-in")
 	schemaOut := query.Get("schema-out")
+	schemaInHash := query.Get("schema-in-hash")
+	schemaOutHash := query.Get("schema-out-hash")
 	cursor := query.Get("cursor")
 	limitStr := query.Get("limit")
 	orderBy := query.Get("order-by")
@@ -79,6 +81,12 @@ func (apiContext Context) DatasetsGet(w http.ResponseWriter, r *http.Request) {
 	if schemaOut != "" {
 		filters["schema-out"] = schemaOut
 	}
+	if schemaInHash != "" {
+		filters["schema-in-hash"] = schemaInHash
+	}
+	if schemaOutHash != "" {
+		filters["schema-out-hash"] = schemaOutHash
+	}
 
 	// Access model.
 	result, cm, err := modelContext.GetDatasets(filters, limit, cursor, orderBy, order)
@@ -107,6 +115,8 @@ func (apiContext Context) DatasetsGet(w http.ResponseWriter, r *http.Request) {
 		query.Set("source", source)
 		query.Set("schema-in", schemaIn)
 		query.Set("schema-out", schemaOut)
+		query.Set("schema-in-hash", schemaInHash)
+		query.Set("schema-out-hash", schemaOutHash)
 		query.Set("limit", strconv.Itoa(limit))
 		query.Set("order-by", orderBy)
 		query.Set("order", order)
@@ -204,6 +214,44 @@ func (apiContext Context) DatasetsByIDGet(w http.ResponseWriter, r *http.Request
 	responses.RespondWithJSON(w, http.StatusOK, response)
 }
 
+// DatasetsCompatibleModulesGet returns all active modules which can be applied to a specific dataset.
+func (apiContext Context) DatasetsCompatibleModulesGet(w http.ResponseWriter, r *http.Request) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Get path parameters. Format the ID as user-id/dataset-id.
+	vars := mux.Vars(r)
+	userID := vars["user-id"]
+	id := vars["id"]
+	moduleType := r.URL.Query().Get("type")
+
+	// Validate parameters.
+	if id == "" {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'id' parameter is required.", nil)
+		return
+	}
+	id = fmt.Sprintf("%s/%s", userID, id)
+
+	// Access model.
+	result, err := modelContext.GetCompatibleModules(id, moduleType)
+	if errors.Cause(err) == model.ErrNotFound {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), err)
+		return
+	} else if errors.Cause(err) == model.ErrBadInput {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Parameters were wrong.", errors.WithStack(err))
+		return
+	} else if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
+	// Build the response.
+	var response = map[string]interface{}{}
+	response["data"] = result
+	responses.RespondWithJSON(w, http.StatusOK, response)
+}
+
 // DatasetsByIDPatch updates fields of a specific dataset by ID.
 func (apiContext Context) DatasetsByIDPatch(w http.ResponseWriter, r *http.Request) {
 
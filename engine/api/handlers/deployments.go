@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ds3lab/easeml/engine/api/responses"
+	"github.com/ds3lab/easeml/engine/database/model"
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/modules"
+	"github.com/ds3lab/easeml/engine/storage"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
+	"github.com/mholt/archiver"
+	"github.com/pkg/errors"
+)
+
+// DeploymentsGet returns all (visible) deployments.
+func (apiContext Context) DeploymentsGet(w http.ResponseWriter, r *http.Request) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Extract query parameters.
+	query := r.URL.Query()
+	idStr := query.Get("id")
+	user := query.Get("user")
+	task := query.Get("task")
+	deploymentModel := query.Get("model")
+	status := query.Get("status")
+	cursor := query.Get("cursor")
+	limitStr := query.Get("limit")
+	orderBy := query.Get("order-by")
+	order := query.Get("order")
+
+	// Parse non-string parametes.
+	id := []bson.ObjectId{}
+	idSlice := []string{}
+	if idStr != "" {
+		idSlice = strings.Split(idStr, ",")
+		id = make([]bson.ObjectId, len(idSlice), len(idSlice))
+		for i := range idSlice {
+			if bson.IsObjectIdHex(idSlice[i]) == false {
+				responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'id' parameter is not a valid list of IDs.", nil)
+				return
+			}
+			id[i] = bson.ObjectIdHex(idSlice[i])
+		}
+	}
+	limit := 20
+	if limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'limit' parameter is not a valid integer.", errors.WithStack(err))
+			return
+		}
+		if limit < 1 {
+			limit = 1
+		}
+		if limit > 100 {
+			limit = 100
+		}
+	}
+
+	// Build the filters map.
+	var filters = map[string]interface{}{}
+	if len(id) > 0 {
+		filters["id"] = id
+	}
+	if user != "" {
+		filters["user"] = user
+	}
+	if task != "" {
+		filters["task"] = task
+	}
+	if deploymentModel != "" {
+		filters["model"] = deploymentModel
+	}
+	if status != "" {
+		filters["status"] = status
+	}
+
+	// Access model.
+	result, cm, err := modelContext.GetDeployments(filters, limit, cursor, orderBy, order)
+	if errors.Cause(err) == model.ErrBadInput {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Parameters were wrong.", errors.WithStack(err))
+		return
+	} else if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
+	// Build the response.
+	var response = map[string]interface{}{}
+	response["data"] = result
+	response["metadata"] = cm
+
+	// Add the next link.
+	response["links"] = map[string]interface{}{
+		"next": nil,
+	}
+	if cm.NextPageCursor != "" {
+		query = url.Values{}
+		query.Set("id", strings.Join(idSlice, ","))
+		query.Set("user", user)
+		query.Set("task", task)
+		query.Set("model", deploymentModel)
+		query.Set("status", status)
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("order-by", orderBy)
+		query.Set("order", order)
+		query.Set("cursor", cm.NextPageCursor)
+		for key := range query {
+			if query.Get(key) == "" {
+				query.Del(key)
+			}
+		}
+		nextURL := url.URL{
+			Scheme:   "http",
+			Host:     r.Host,
+			Path:     r.URL.Path,
+			RawQuery: query.Encode(),
+		}
+		response["links"].(map[string]interface{})["next"] = nextURL.String()
+	}
+
+	// Return response.
+	responses.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// DeploymentsPost creates a new deployment of the task specified in the request body.
+func (apiContext Context) DeploymentsPost(w http.ResponseWriter, r *http.Request) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Parse body.
+	var deployment types.Deployment
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&deployment); err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Invalid request payload.", errors.WithStack(err))
+		return
+	}
+	defer r.Body.Close()
+
+	// Access model.
+	deployment, err := modelContext.CreateDeployment(deployment)
+	if errors.Cause(err) == types.ErrUnauthorized {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusForbidden, "Unauthorized access.", errors.WithStack(err))
+		return
+	}
+	if errors.Cause(err) == model.ErrBadInput {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Bad input parameters.", errors.WithStack(err))
+		return
+	}
+	if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
+	// Return response.
+	var resourceURL = "http://" + r.Host + "/deployments/" + string(deployment.ID.Hex())
+	w.Header().Set("Location", resourceURL)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// DeploymentsByIDGet returns a specific deployment by ID.
+func (apiContext Context) DeploymentsByIDGet(w http.ResponseWriter, r *http.Request) {
+
+	deployment, ok := apiContext.getDeployment(w, r)
+	if ok == false {
+		return
+	}
+
+	// Build the response.
+	var response = map[string]interface{}{}
+	response["data"] = deployment
+	responses.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// DeploymentsByIDPatch updates fields of a specific deployment by ID. Deployments can only be stopped.
+func (apiContext Context) DeploymentsByIDPatch(w http.ResponseWriter, r *http.Request) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Get path parameters.
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Validate parameters.
+	if id == "" {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'id' parameter is required.", nil)
+		return
+	}
+	if bson.IsObjectIdHex(id) == false {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), model.ErrNotFound)
+		return
+	}
+
+	// Parse body.
+	var patchBody map[string]*json.RawMessage
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&patchBody); err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Invalid request payload", errors.WithStack(err))
+		return
+	}
+	defer r.Body.Close()
+
+	// Parse specific fields from the body.
+	var updates = map[string]interface{}{}
+	if rawStatus, ok := patchBody["status"]; ok {
+		var status string
+		if err := json.Unmarshal(*rawStatus, &status); err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Body is not properly formatted JSON.", errors.WithStack(err))
+			return
+		}
+		if status != types.DeploymentStopped {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The status of a deployment can only be set to \"stopped\".", nil)
+			return
+		}
+		updates["status"] = status
+	}
+
+	// Access model.
+	_, err := modelContext.UpdateDeployment(bson.ObjectIdHex(id), updates)
+	if errors.Cause(err) == model.ErrNotFound {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.WithStack(err))
+		return
+	} else if errors.Cause(err) == model.ErrBadInput {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.WithStack(err))
+		return
+	} else if errors.Cause(err) == types.ErrUnauthorized {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized), errors.WithStack(err))
+		return
+	} else if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+	if _, ok := updates["status"]; ok {
+		apiContext.StorageContext.AppendDeploymentLog(id, "deployment stopped")
+	}
+
+	// Return response.
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeploymentsLogsGet serves the log of a specific deployment.
+func (apiContext Context) DeploymentsLogsGet(w http.ResponseWriter, r *http.Request) {
+
+	deployment, ok := apiContext.getDeployment(w, r)
+	if ok == false {
+		return
+	}
+
+	logsPath, err := apiContext.StorageContext.GetDeploymentPath(deployment.ID.Hex(), "logs")
+	if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+	apiContext.ServeLocalResource(logsPath, storage.DeploymentLogFile, deployment.CreationTime, w, r)
+}
+
+// maxPredictRequestBytes is the largest request body accepted by DeploymentsPredictPost.
+const maxPredictRequestBytes = 256 << 20
+
+// predictRequestTimeout is how long DeploymentsPredictPost waits for the model server to handle a request.
+const predictRequestTimeout = 5 * time.Minute
+
+// DeploymentsPredictPost passes the samples given in the request body to the model server of the deployment
+// and returns the predictions. The samples are either a tar archive with an "input" directory in the dataset format, in which
+// case the predictions are returned as a tar archive with an "output" directory, or a JSON object which maps
+// sample names to objects of named tensors, in which case the predictions are returned as JSON.
+func (apiContext Context) DeploymentsPredictPost(w http.ResponseWriter, r *http.Request) {
+
+	deployment, ok := apiContext.getDeployment(w, r)
+	if ok == false {
+		return
+	}
+	body := &countingReader{reader: http.MaxBytesReader(w, r.Body, maxPredictRequestBytes)}
+	defer r.Body.Close()
+	id := deployment.ID.Hex()
+
+	if deployment.Status != types.DeploymentRunning {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusConflict,
+			fmt.Sprintf("The deployment is not running, its status is \"%s\".", deployment.Status), nil)
+		return
+	}
+
+	// Every request gets its own directory in the serving directory of the deployment. It is removed
+	// after the response.
+	servingPath, err := apiContext.StorageContext.GetDeploymentPath(id, "serving")
+	if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+	requestID := bson.NewObjectId().Hex()
+	requestPath := modules.ServingRequestPath(servingPath, requestID)
+	keepRequest := false
+	defer func() {
+		if keepRequest == false {
+			os.RemoveAll(requestPath)
+		}
+	}()
+	dataPath := filepath.Join(requestPath, "data")
+	outputPath := filepath.Join(requestPath, "predictions")
+	metadataPath := filepath.Join(requestPath, "metadata")
+	for _, path := range []string{dataPath, outputPath, metadataPath} {
+		if err := os.MkdirAll(path, storage.DefaultFilePerm); err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+			return
+		}
+	}
+
+	// Write the samples to the data directory.
+	isJSON := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+	if isJSON {
+		var samples map[string]map[string]interface{}
+		if err := json.NewDecoder(body).Decode(&samples); err != nil {
+			respondWithPayloadError(apiContext, w, r, err, body.count)
+			return
+		}
+		if err := modules.DumpTensorSamples(samples, dataPath); err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Invalid samples: "+err.Error(), errors.WithStack(err))
+			return
+		}
+	} else {
+		archivePath := filepath.Join(requestPath, "input.tar")
+		f, err := os.Create(archivePath)
+		if err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+			return
+		}
+		_, err = io.Copy(f, body)
+		f.Close()
+		if err != nil {
+			respondWithPayloadError(apiContext, w, r, err, body.count)
+			return
+		}
+		if err := storage.UnpackDatasetArchive(archivePath, dataPath); err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Invalid samples archive: "+err.Error(), errors.WithStack(err))
+			return
+		}
+	}
+
+	// The samples must match the input schema of the model.
+	if err := modules.CheckPredictionInput(deployment.SchemaIn, dataPath); err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Invalid samples: "+err.Error(), errors.WithStack(err))
+		return
+	}
+
+	// Pass the request to the model server of the deployment.
+	start := time.Now()
+	modelOutput, err := modules.ServeRequest(servingPath, requestID, predictRequestTimeout)
+	if err == modules.ErrServerBusy {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusServiceUnavailable, "The deployment is busy, try again later.", err)
+		return
+	} else if err == modules.ErrServerTimeout {
+		// The model server removes the request once it is done with it.
+		keepRequest = true
+		apiContext.StorageContext.AppendDeploymentLog(id, fmt.Sprintf("request %s timed out", requestID))
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusGatewayTimeout, "The deployment did not answer in time.", err)
+		return
+	}
+	if err == nil {
+		err = modules.CheckPredictionOutput(deployment.SchemaOut, dataPath, outputPath)
+	}
+	if err != nil {
+		apiContext.StorageContext.AppendDeploymentLog(id, fmt.Sprintf("request %s failed: %s\n%s", requestID, err.Error(), modelOutput))
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Prediction failed.", errors.WithStack(err))
+		return
+	}
+	apiContext.StorageContext.AppendDeploymentLog(id, fmt.Sprintf("request %s completed in %s", requestID, time.Since(start)))
+	modelContext := context.Get(r, "modelContext").(model.Context)
+	modelContext.CountDeploymentRequest(deployment.ID)
+
+	// Return the predictions in the format of the request.
+	if isJSON {
+		predictions, err := modules.LoadPredictedSamples(outputPath)
+		if err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+			return
+		}
+		var response = map[string]interface{}{}
+		response["data"] = predictions
+		responses.RespondWithJSON(w, http.StatusOK, response)
+		return
+	}
+	archivePath := filepath.Join(requestPath, "output.tar")
+	if err := archiver.Tar.Make(archivePath, []string{filepath.Join(outputPath, "output")}); err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+	f, err := os.Open(archivePath)
+	if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, f)
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.count += int64(n)
+	return
+}
+
+// respondWithPayloadError responds to a request whose body could not be read. The body is too large if
+// the whole limit was read before the error.
+func respondWithPayloadError(apiContext Context, w http.ResponseWriter, r *http.Request, err error, count int64) {
+	if count >= maxPredictRequestBytes {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusRequestEntityTooLarge, "The request payload is too large.", errors.WithStack(err))
+		return
+	}
+	responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Invalid request payload.", errors.WithStack(err))
+}
+
+// getDeployment looks up the deployment given by the "id" path parameter. If it is not found, an error
+// response is written and false is returned.
+func (apiContext Context) getDeployment(w http.ResponseWriter, r *http.Request) (deployment types.Deployment, ok bool) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Get path parameters.
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Validate parameters.
+	if id == "" {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'id' parameter is required.", nil)
+		return
+	}
+	if bson.IsObjectIdHex(id) == false {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), model.ErrNotFound)
+		return
+	}
+
+	// Access model.
+	deployment, err := modelContext.GetDeploymentByID(bson.ObjectIdHex(id))
+	if errors.Cause(err) == model.ErrNotFound {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.WithStack(err))
+		return
+	} else if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
+	return deployment, true
+}
@@ -27,7 +27,9 @@ func (apiContext Context) JobsGet(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	idStr := query.Get("id")
 	user := query.Get("user")
+	kind := query.Get("kind")
 	dataset := query.Get("dataset")
+	task := query.Get("task")
 	jobModel := query.Get("model")
 	objective := query.Get("objective")
 	status := query.Get("status")
@@ -71,9 +73,15 @@ func (apiContext Context) JobsGet(w http.ResponseWriter, r *http.Request) {
 	if user != "" {
 		filters["user"] = user
 	}
+	if kind != "" {
+		filters["kind"] = kind
+	}
 	if dataset != "" {
 		filters["dataset"] = dataset
 	}
+	if task != "" {
+		filters["task"] = task
+	}
 	if jobModel != "" {
 		filters["model"] = jobModel
 	}
@@ -110,7 +118,9 @@ func (apiContext Context) JobsGet(w http.ResponseWriter, r *http.Request) {
 		query = url.Values{}
 		query.Set("id", strings.Join(idSlice, ","))
 		query.Set("user", user)
+		query.Set("kind", kind)
 		query.Set("dataset", dataset)
+		query.Set("task", task)
 		query.Set("model", jobModel)
 		query.Set("objective", objective)
 		query.Set("status", status)
@@ -11,6 +11,7 @@ import (
 	"github.com/ds3lab/easeml/engine/api/responses"
 	"github.com/ds3lab/easeml/engine/database/model"
 	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/storage"
 
 	"github.com/gorilla/context"
 	"github.com/gorilla/mux"
@@ -35,6 +36,8 @@ func (apiContext Context) ModulesGet(w http.ResponseWriter, r *http.Request) {
 	source := query.Get("source")
 	schemaIn := query.Get("schema-in")
 	schemaOut := query.Get("schema-out")
+	schemaInHash := query.Get("schema-in-hash")
+	schemaOutHash := query.Get("schema-out-hash")
 	cursor := query.Get("cursor")
 	limitStr := query.Get("limit")
 	orderBy := query.Get("order-by")
@@ -87,6 +90,12 @@ func (apiContext Context) ModulesGet(w http.ResponseWriter, r *http.Request) {
 	if schemaOut != "" {
 		filters["schema-out"] = schemaOut
 	}
+	if schemaInHash != "" {
+		filters["schema-in-hash"] = schemaInHash
+	}
+	if schemaOutHash != "" {
+		filters["schema-out-hash"] = schemaOutHash
+	}
 
 	// Access model.
 	result, cm, err := modelContext.GetModules(filters, limit, cursor, orderBy, order)
@@ -117,6 +126,8 @@ func (apiContext Context) ModulesGet(w http.ResponseWriter, r *http.Request) {
 		query.Set("source", source)
 		query.Set("schema-in", schemaIn)
 		query.Set("schema-out", schemaOut)
+		query.Set("schema-in-hash", schemaInHash)
+		query.Set("schema-out-hash", schemaOutHash)
 		query.Set("limit", strconv.Itoa(limit))
 		query.Set("order-by", orderBy)
 		query.Set("order", order)
@@ -154,6 +165,12 @@ func (apiContext Context) ModulesPost(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
+	// The registry password is stored encrypted.
+	if err := apiContext.encryptRegistryPassword(&module); err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
 	// Access model.
 	module, err := modelContext.CreateModule(module)
 	if errors.Cause(err) == types.ErrUnauthorized {
@@ -179,6 +196,115 @@ func (apiContext Context) ModulesPost(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 }
 
+// encryptRegistryPassword encrypts the registry password of the module with the secret key of the service.
+func (apiContext Context) encryptRegistryPassword(module *types.Module) (err error) {
+	if module.RegistryPassword == "" {
+		return nil
+	}
+	key, err := apiContext.StorageContext.GetSecretKey()
+	if err != nil {
+		return
+	}
+	module.RegistryPassword, err = storage.EncryptSecret(key, module.RegistryPassword)
+	return
+}
+
+// ModulesVersionsGet returns all versions of a specific module ordered by version.
+func (apiContext Context) ModulesVersionsGet(w http.ResponseWriter, r *http.Request) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Get path parameters. Format the ID as user-id/module-id.
+	vars := mux.Vars(r)
+	userID := vars["user-id"]
+	id := vars["id"]
+
+	// Validate parameters.
+	if id == "" {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'id' parameter is required.", nil)
+		return
+	}
+	id = fmt.Sprintf("%s/%s", userID, id)
+
+	// Access model.
+	result, err := modelContext.GetModuleVersions(id)
+	if errors.Cause(err) == model.ErrNotFound {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), err)
+		return
+	} else if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
+	// Build the response.
+	var response = map[string]interface{}{}
+	response["data"] = result
+	responses.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// ModulesVersionsPost creates a new version of a specific module. The new version is specified in the request body.
+func (apiContext Context) ModulesVersionsPost(w http.ResponseWriter, r *http.Request) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Get path parameters. Format the ID as user-id/module-id.
+	vars := mux.Vars(r)
+	userID := vars["user-id"]
+	id := vars["id"]
+
+	// Validate parameters.
+	if id == "" {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'id' parameter is required.", nil)
+		return
+	}
+	id = fmt.Sprintf("%s/%s", userID, id)
+
+	// Parse body.
+	var module types.Module
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&module); err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Invalid request payload.", errors.WithStack(err))
+		return
+	}
+	defer r.Body.Close()
+
+	// The registry password is stored encrypted.
+	if err := apiContext.encryptRegistryPassword(&module); err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
+	// Access model.
+	module, err := modelContext.CreateModuleVersion(id, module)
+	if errors.Cause(err) == model.ErrNotFound {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), err)
+		return
+	}
+	if errors.Cause(err) == types.ErrUnauthorized {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusForbidden, "Unauthorized access.", errors.WithStack(err))
+		return
+	}
+	if errors.Cause(err) == types.ErrIdentifierTaken {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusConflict, "Identifier taken.", errors.WithStack(err))
+		return
+	}
+	if errors.Cause(err) == model.ErrBadInput {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Bad input parameters.", errors.WithStack(err))
+		return
+	}
+	if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
+	// Return response.
+	var resourceURL = "http://" + r.Host + "/modules/" + module.ID
+	w.Header().Set("Location", resourceURL)
+	w.WriteHeader(http.StatusCreated)
+}
+
 // ModulesByIDGet returns a specific module by ID.
 func (apiContext Context) ModulesByIDGet(w http.ResponseWriter, r *http.Request) {
 
@@ -214,6 +340,48 @@ func (apiContext Context) ModulesByIDGet(w http.ResponseWriter, r *http.Request)
 	responses.RespondWithJSON(w, http.StatusOK, response)
 }
 
+// ModulesCompatibilityGet explains whether a specific module can be applied to the dataset given in the query.
+func (apiContext Context) ModulesCompatibilityGet(w http.ResponseWriter, r *http.Request) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Get path parameters. Format the ID as user-id/module-id.
+	vars := mux.Vars(r)
+	userID := vars["user-id"]
+	id := vars["id"]
+	datasetID := r.URL.Query().Get("dataset")
+
+	// Validate parameters.
+	if id == "" {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'id' parameter is required.", nil)
+		return
+	}
+	if datasetID == "" {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The 'dataset' parameter is required.", nil)
+		return
+	}
+	id = fmt.Sprintf("%s/%s", userID, id)
+
+	// Access model.
+	compatibility, err := modelContext.GetModuleCompatibility(id, datasetID)
+	if errors.Cause(err) == model.ErrNotFound {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), err)
+		return
+	} else if errors.Cause(err) == model.ErrBadInput {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "The dataset has no schema.", errors.WithStack(err))
+		return
+	} else if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+
+	// Build the response.
+	var response = map[string]interface{}{}
+	response["data"] = compatibility
+	responses.RespondWithJSON(w, http.StatusOK, response)
+}
+
 // ModulesByIDPatch updates fields of a specific module by ID.
 func (apiContext Context) ModulesByIDPatch(w http.ResponseWriter, r *http.Request) {
 
@@ -268,6 +436,12 @@ func (apiContext Context) ModulesByIDPatch(w http.ResponseWriter, r *http.Reques
 		updates["status"] = status
 	}
 
+	// Archiving needs to take care of the jobs which depend on the module.
+	if status, ok := updates["status"]; ok && status == types.ModuleArchived {
+		apiContext.modulesArchive(w, r, id, patchBody, updates)
+		return
+	}
+
 	// Access model.
 	_, err := modelContext.UpdateModule(id, updates)
 	if errors.Cause(err) == model.ErrNotFound {
@@ -285,6 +459,65 @@ func (apiContext Context) ModulesByIDPatch(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 }
 
+// modulesArchive archives a module and applies the other updates. If unfinished jobs depend on the module,
+// archiving is refused unless the body gives a "replacement" model to migrate the jobs to or sets "force".
+func (apiContext Context) modulesArchive(w http.ResponseWriter, r *http.Request, id string, patchBody map[string]*json.RawMessage, updates map[string]interface{}) {
+
+	// Get context variables.
+	modelContext := context.Get(r, "modelContext").(model.Context)
+
+	// Parse specific fields from the body.
+	var replacement string
+	if rawReplacement, ok := patchBody["replacement"]; ok {
+		if err := json.Unmarshal(*rawReplacement, &replacement); err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Body is not properly formatted JSON.", errors.WithStack(err))
+			return
+		}
+	}
+	var force bool
+	if rawForce, ok := patchBody["force"]; ok {
+		if err := json.Unmarshal(*rawForce, &force); err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, "Body is not properly formatted JSON.", errors.WithStack(err))
+			return
+		}
+	}
+	delete(updates, "status")
+
+	// Access model.
+	jobs, err := modelContext.ArchiveModule(id, replacement, force)
+	if errors.Cause(err) == model.ErrNotFound {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.WithStack(err))
+		return
+	} else if errors.Cause(err) == model.ErrBadInput {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.WithStack(err))
+		return
+	} else if errors.Cause(err) == model.ErrInUse {
+		jobIDs := make([]string, len(jobs))
+		for i := range jobs {
+			jobIDs[i] = jobs[i].ID.Hex()
+		}
+		message := fmt.Sprintf("The module is used by unfinished jobs: %s. Give a replacement model to migrate them "+
+			"or force archiving to stop giving them new tasks of the module.", strings.Join(jobIDs, ", "))
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusConflict, message, errors.WithStack(err))
+		return
+	} else if err != nil {
+		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+		return
+	}
+	if len(updates) > 0 {
+		_, err = modelContext.UpdateModule(id, updates)
+		if err != nil {
+			responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
+			return
+		}
+	}
+
+	// The dependent jobs are returned so that the client can report them.
+	var response = map[string]interface{}{}
+	response["data"] = map[string]interface{}{"jobs": jobs}
+	responses.RespondWithJSON(w, http.StatusOK, response)
+}
+
 // ModulesUploadHandler handles all data upload related requests.
 func (apiContext Context) ModulesUploadHandler(basePath string) http.HandlerFunc {
 
@@ -292,9 +292,10 @@ func (apiContext Context) TaskImageDownload(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Build model and serve the tar file.
-	newImageTag := strings.Replace(task.Model, "/", "-", -1) + ":" + strings.Replace(task.ID, "/", "-", -1)
-	imageReader, err := modules.BuildModelImageWithMemory(taskModel.SourceAddress, allPaths.Parameters, newImageTag)
+	// Build model and serve the tar file. This works for archived models as well. Model versions are
+	// separated by "@" which cannot appear in image names.
+	newImageTag := strings.NewReplacer("/", "-", "@", "-").Replace(task.Model) + ":" + strings.Replace(task.ID, "/", "-", -1)
+	imageReader, err := modules.BuildModelImageWithMemory(apiContext.Runtime, taskModel.SourceAddress, allPaths.Parameters, newImageTag)
 	if err != nil {
 		responses.Context(apiContext).RespondWithError(w, r, http.StatusInternalServerError, "Something went wrong.", errors.WithStack(err))
 		return
@@ -122,6 +122,12 @@ func New(context api.Context) http.Handler {
 			Pattern: "/datasets/{user-id}/{id}",
 			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.DatasetsByIDGet),
 		},
+		Route{
+			Name:    "GetDatasetCompatibleModules",
+			Methods: []string{"GET"},
+			Pattern: "/datasets/{user-id}/{id}/compatible-modules",
+			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.DatasetsCompatibleModulesGet),
+		},
 		Route{
 			Name:    "PatchDataset",
 			Methods: []string{"PATCH"},
@@ -167,6 +173,24 @@ func New(context api.Context) http.Handler {
 			Pattern: "/modules/{user-id}/{id}",
 			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.ModulesByIDGet),
 		},
+		Route{
+			Name:    "GetModuleCompatibility",
+			Methods: []string{"GET"},
+			Pattern: "/modules/{user-id}/{id}/compatibility",
+			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.ModulesCompatibilityGet),
+		},
+		Route{
+			Name:    "GetModuleVersions",
+			Methods: []string{"GET"},
+			Pattern: "/modules/{user-id}/{id}/versions",
+			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.ModulesVersionsGet),
+		},
+		Route{
+			Name:    "PostModuleVersion",
+			Methods: []string{"POST"},
+			Pattern: "/modules/{user-id}/{id}/versions",
+			Handler: commonMiddleware.Append(middlewareContext.DisallowAnon).ThenFunc(handlerContext.ModulesVersionsPost),
+		},
 		Route{
 			Name:    "PatchModule",
 			Methods: []string{"PATCH"},
@@ -257,6 +281,42 @@ func New(context api.Context) http.Handler {
 			Pattern: "/tasks/{job-id}/{id}/image/download",
 			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.TaskImageDownload),
 		},
+		Route{
+			Name:    "GetDeployments",
+			Methods: []string{"GET"},
+			Pattern: "/deployments",
+			Handler: commonMiddleware.Append(middlewareContext.DisallowAnon).ThenFunc(handlerContext.DeploymentsGet),
+		},
+		Route{
+			Name:    "PostDeployments",
+			Methods: []string{"POST"},
+			Pattern: "/deployments",
+			Handler: commonMiddleware.Append(middlewareContext.DisallowAnon).ThenFunc(handlerContext.DeploymentsPost),
+		},
+		Route{
+			Name:    "GetDeployment",
+			Methods: []string{"GET"},
+			Pattern: "/deployments/{id}",
+			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.DeploymentsByIDGet),
+		},
+		Route{
+			Name:    "PatchDeployment",
+			Methods: []string{"PATCH"},
+			Pattern: "/deployments/{id}",
+			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.DeploymentsByIDPatch),
+		},
+		Route{
+			Name:    "GetDeploymentLogs",
+			Methods: []string{"GET"},
+			Pattern: "/deployments/{id}/logs",
+			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.DeploymentsLogsGet),
+		},
+		Route{
+			Name:    "PostDeploymentPredict",
+			Methods: []string{"POST"},
+			Pattern: "/deployments/{id}/predict",
+			Handler: commonMiddleware.Append(middlewareContext.HideFromAnon).ThenFunc(handlerContext.DeploymentsPredictPost),
+		},
 	}
 
 	router := mux.NewRouter().StrictSlash(true).PathPrefix("/api/v1").Subrouter()
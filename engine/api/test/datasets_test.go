@@ -264,6 +264,57 @@ func TestDatasetsGetById(t *testing.T) {
 	forest.ExpectStatus(t, r, 404)
 }
 
+func TestDatasetsCompatibleModules(t *testing.T) {
+	var config *forest.RequestConfig
+	var r *http.Response
+	var err error
+
+	// Create a dataset and three modules. Only the first module is active and accepts the dataset.
+	_, err = createDataset(types.Dataset{ID: "user2/dataset21", User: "user2", Name: "Dataset 21", Source: "download", SchemaIn: testSchemaInSrc1, SchemaOut: testSchemaOutSrc1})
+	assert.Nil(t, err)
+	var modules = []types.Module{
+		types.Module{ID: "user2/module21", User: "user2", Name: "Module 21", Source: "download", Type: "model", SchemaIn: testSchemaInSrc1,
+			SchemaOut: `{"nodes":{"n":{"singleton":true,"type":"tensor","dim":["d"]}}}`},
+		types.Module{ID: "user2/module22", User: "user2", Name: "Module 22", Source: "download", Type: "model", SchemaIn: testSchemaInSrc1,
+			SchemaOut: `{"nodes":{"n":{"singleton":true,"type":"tensor","dim":[8]}}}`},
+		types.Module{ID: "user2/module23", User: "user2", Name: "Module 23", Source: "download", Type: "model", SchemaIn: testSchemaInSrc1, SchemaOut: testSchemaOutSrc1},
+	}
+	for _, module := range modules {
+		_, err = createModule(module)
+		assert.Nil(t, err)
+	}
+	context, err := model.Connect(testDbAddr, testDbName, false)
+	assert.Nil(t, err)
+	defer context.Session.Close()
+	context.User.ID = types.UserRoot
+	assert.Nil(t, context.UpdateModuleStatus("user2/module21", types.ModuleActive, ""))
+	assert.Nil(t, context.UpdateModuleStatus("user2/module22", types.ModuleActive, ""))
+
+	// Authenticate as root. Use an invalid type. Should return 400.
+	config = forest.NewConfig("/datasets/user2/dataset21/compatible-modules").Header("X-API-KEY", rootAPIKey).Query("type", "dataset")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 400)
+
+	// Authenticate as root. Get compatible models. Should return 200 with the dimension bindings.
+	config = forest.NewConfig("/datasets/user2/dataset21/compatible-modules").Header("X-API-KEY", rootAPIKey).Query("type", "model")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 200)
+	ids := []string{}
+	forest.ExpectJSONHash(t, r, func(hash map[string]interface{}) {
+		for _, item := range hash["data"].([]interface{}) {
+			ids = append(ids, item.(map[string]interface{})["module"].(map[string]interface{})["id"].(string))
+		}
+	})
+	assert.Contains(t, ids, "user2/module21")
+	assert.NotContains(t, ids, "user2/module22")
+	assert.NotContains(t, ids, "user2/module23")
+
+	// Authenticate as root. Get compatible modules of a missing dataset. Should return 404.
+	config = forest.NewConfig("/datasets/user2/missing/compatible-modules").Header("X-API-KEY", rootAPIKey)
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 404)
+}
+
 func TestDatasetsPatch(t *testing.T) {
 	var config *forest.RequestConfig
 	var r *http.Response
@@ -250,6 +250,87 @@ func TestModulesGetById(t *testing.T) {
 	forest.ExpectStatus(t, r, 404)
 }
 
+func TestModulesCompatibility(t *testing.T) {
+	var config *forest.RequestConfig
+	var r *http.Response
+	var err error
+
+	// Create a module and two datasets, one of which has a mismatching output schema.
+	_, err = createModule(types.Module{ID: "user2/module11", User: "user2", Name: "Module 11", Source: "download", Type: "model", SchemaIn: testSchemaInSrc1, SchemaOut: testSchemaOutSrc1})
+	assert.Nil(t, err)
+	_, err = createDataset(types.Dataset{ID: "user2/dataset11", User: "user2", Name: "Dataset 11", Source: "download", SchemaIn: testSchemaInSrc1, SchemaOut: testSchemaOutSrc1})
+	assert.Nil(t, err)
+	_, err = createDataset(types.Dataset{ID: "user2/dataset12", User: "user2", Name: "Dataset 12", Source: "download", SchemaIn: testSchemaInSrc1,
+		SchemaOut: `{"nodes":{"node1_src":{"singleton":true,"type":"tensor","dim":[8]}}}`})
+	assert.Nil(t, err)
+
+	// Authenticate as root. Omit the dataset. Should return 400.
+	config = forest.NewConfig("/modules/user2/module11/compatibility").Header("X-API-KEY", rootAPIKey)
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 400)
+
+	// Authenticate as root. Check a matching dataset. Should return 200.
+	config = forest.NewConfig("/modules/user2/module11/compatibility").Header("X-API-KEY", rootAPIKey).Query("dataset", "user2/dataset11")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 200)
+	assert.Equal(t, true, forest.JSONPath(t, r, ".data.compatible"))
+
+	// Authenticate as root. Check a mismatching dataset. Should return 200 with the failing dimension.
+	config = forest.NewConfig("/modules/user2/module11/compatibility").Header("X-API-KEY", rootAPIKey).Query("dataset", "user2/dataset12")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 200)
+	assert.Equal(t, false, forest.JSONPath(t, r, ".data.compatible"))
+	assert.Equal(t, true, forest.JSONPath(t, r, ".data.schema-in.match"))
+	assert.Equal(t, "nodes.node1_src.fields.field.dim[0]", forest.JSONPath(t, r, ".data.schema-out.failures.0.path"))
+
+	// Authenticate as root. Check a missing dataset. Should return 404.
+	config = forest.NewConfig("/modules/user2/module11/compatibility").Header("X-API-KEY", rootAPIKey).Query("dataset", "user2/missing")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 404)
+}
+
+func TestModulesGetBySchemaHash(t *testing.T) {
+	var config *forest.RequestConfig
+	var r *http.Response
+	var err error
+
+	// Create a module with the same structure as the test schema but with renamed nodes.
+	module, err := createModule(types.Module{ID: "user2/module12", User: "user2", Name: "Module 12", Source: "download", Type: "model",
+		SchemaIn: `{"nodes":{"renamed":{"singleton":true,"type":"category","class":"renamed"}},"classes":{"renamed":{"dim":16}}}`, SchemaOut: testSchemaOutSrc1})
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", module.SchemaInHash)
+
+	// Authenticate as root. Filter by the input schema hash. Should return the module.
+	config = forest.NewConfig("/modules").Header("X-API-KEY", rootAPIKey).Query("schema-in-hash", module.SchemaInHash).Query("id", "user2/module12")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 200)
+	assert.Equal(t, "user2/module12", forest.JSONPath(t, r, ".data.0.id"))
+	assert.Equal(t, module.SchemaInHash, forest.JSONPath(t, r, ".data.0.schema-in-hash"))
+
+	// Authenticate as root. Filter by an unknown hash. Should return no modules.
+	config = forest.NewConfig("/modules").Header("X-API-KEY", rootAPIKey).Query("schema-in-hash", "unknown").Query("id", "user2/module12")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 200)
+	assert.EqualValues(t, 0, forest.JSONPath(t, r, ".metadata.total-result-size"))
+
+	// Authenticate as root. Filter by a structurally identical input schema. Should return the module.
+	config = forest.NewConfig("/modules").Header("X-API-KEY", rootAPIKey).Query("schema-in", testSchemaInSrc1).Query("id", "user2/module12")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 200)
+	assert.Equal(t, "user2/module12", forest.JSONPath(t, r, ".data.0.id"))
+
+	// Some schemas do not match themselves. Filtering by such a schema must not return the module even
+	// though the hashes are equal.
+	schemaIn := `{"nodes":{"t1":{"singleton":true,"type":"tensor","dim":["a+","a"]}}}`
+	_, err = createModule(types.Module{ID: "user2/module13", User: "user2", Name: "Module 13", Source: "download", Type: "model",
+		SchemaIn: schemaIn, SchemaOut: testSchemaOutSrc1})
+	assert.Nil(t, err)
+	config = forest.NewConfig("/modules").Header("X-API-KEY", rootAPIKey).Query("schema-in", schemaIn).Query("id", "user2/module13")
+	r = client.GET(t, config)
+	forest.ExpectStatus(t, r, 200)
+	assert.EqualValues(t, 0, forest.JSONPath(t, r, ".metadata.total-result-size"))
+}
+
 func TestModulesPatch(t *testing.T) {
 	var config *forest.RequestConfig
 	var r *http.Response
@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Archives an item given its id.",
+	Long:  ``,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+
+	viper.BindPFlags(archiveCmd.PersistentFlags())
+
+}
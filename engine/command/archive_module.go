@@ -0,0 +1,56 @@
+package command
+
+import (
+	"fmt"
+
+	client "github.com/ds3lab/easeml/client/go/easemlclient"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var archiveModuleReplacement string
+var archiveModuleForce bool
+
+var archiveModuleCmd = &cobra.Command{
+	Use:   "module id",
+	Short: "Archives a module so that it is not used by new tasks.",
+	Long: `Archives a module so that it is not used by new tasks. Completed tasks of the module are not affected.
+If unfinished jobs depend on the module, it is only archived if the jobs are migrated to a replacement model
+or if archiving is forced, in which case the jobs get no new tasks of the module.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if apiKey == "" {
+			apiKey = viper.GetString("api-key")
+		}
+		serverAddress := viper.GetString("server-address")
+		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
+
+		jobs, err := context.ArchiveModule(args[0], archiveModuleReplacement, archiveModuleForce)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("SUCCESS: Module \"%s\" archived.\n", args[0])
+		for i := range jobs {
+			if archiveModuleReplacement != "" {
+				fmt.Printf("Job \"%s\" migrated to \"%s\".\n", jobs[i].ID, archiveModuleReplacement)
+			} else {
+				fmt.Printf("Job \"%s\" gets no new tasks of the module.\n", jobs[i].ID)
+			}
+		}
+
+	},
+}
+
+func init() {
+	archiveCmd.AddCommand(archiveModuleCmd)
+
+	archiveModuleCmd.Flags().StringVar(&archiveModuleReplacement, "replacement", "", "Model that replaces the module "+
+		"in the unfinished jobs which depend on it. Can be pinned to a version with user-id/module-id@version.")
+	archiveModuleCmd.Flags().BoolVar(&archiveModuleForce, "force", false, "Archive the module even if unfinished jobs "+
+		"depend on it.")
+
+}
@@ -6,9 +6,23 @@ import (
 	"io/ioutil"
 	"os"
 
+	"github.com/ds3lab/easeml/engine/modules"
+	"github.com/ds3lab/easeml/engine/storage"
+
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 )
 
+// getRuntime returns the module runtime selected with the runtime flag.
+func getRuntime() (modules.Runtime, error) {
+	storageContext := storage.Context{WorkingDir: viper.GetString("working-dir")}
+	root, err := storageContext.GetRuntimePath(viper.GetString("runtime"))
+	if err != nil {
+		return nil, err
+	}
+	return modules.NewRuntime(viper.GetString("runtime"), root, viper.GetString("runtime-host"))
+}
+
 func loadStream(source string) (string, error) {
 
 	var file *os.File
@@ -15,7 +15,7 @@ import (
 	"github.com/spf13/viper"
 )
 
-var datasetID, datasetName, datasetDescription, datasetSchema, datasetSource, datasetSourceAddress, accessKey string
+var datasetID, datasetName, datasetDescription, datasetSchema, datasetSource, datasetSourceAddress, accessKey, datasetChecksum string
 
 var createDatasetCmd = &cobra.Command{
 	Use:   "dataset",
@@ -61,9 +61,10 @@ var createDatasetCmd = &cobra.Command{
 			}
 
 			// Check if we can infer the schema.
-			var schemaIn, schemaOut *sch.Schema
-			schemaIn, schemaOut, err = storage.InferDatasetSchema(datasetSourceAddress)
-			if err != nil || schemaIn == nil || schemaOut == nil {
+			// Datasets without outputs can only be used for predictions.
+			var schemaIn *sch.Schema
+			schemaIn, _, err = storage.InferDatasetSchema(datasetSourceAddress)
+			if err != nil || schemaIn == nil {
 				if err == nil {
 					err = errors.New("dataset schema missing")
 				}
@@ -135,7 +136,7 @@ var createDatasetCmd = &cobra.Command{
 				}
 			}
 
-			_, err := context.CreateDataset(datasetID, datasetName, descriptionString, datasetSource, datasetSourceAddress,accessKey)
+			_, err := context.CreateDataset(datasetID, datasetName, descriptionString, datasetSource, datasetSourceAddress, accessKey, datasetChecksum)
 			if err != nil {
 				fmt.Println(err.Error())
 				return
@@ -170,4 +171,6 @@ func init() {
 	createDatasetCmd.Flags().StringVar(&datasetSource, "source", "", fmt.Sprintf("Dataset source [choices: %s]",strings.Join(client.ValidDatasetSources, ", ")))
 	createDatasetCmd.Flags().StringVar(&datasetSourceAddress, "source-address", "", "Dataset source address.")
 	createDatasetCmd.Flags().StringVar(&accessKey, "access-key", "", "Data-source specific accessKey, i.e. oauth token.")
+	createDatasetCmd.Flags().StringVar(&datasetChecksum, "checksum", "", "Expected sha256 digest of the downloaded dataset file. "+
+		"Only applicable to the download source.")
 }
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"fmt"
+
+	client "github.com/ds3lab/easeml/client/go/easemlclient"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var deploymentTask string
+
+var createDeploymentCmd = &cobra.Command{
+	Use:   "deployment",
+	Short: "Deploys a completed task so that its trained model serves predictions.",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if apiKey == "" {
+			apiKey = viper.GetString("api-key")
+		}
+		serverAddress := viper.GetString("server-address")
+		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
+
+		// Deployment task is required.
+		for deploymentTask == "" {
+			err := readLine("Deployment Task: ", &deploymentTask)
+			if err != nil {
+				fmt.Printf(err.Error() + "\n")
+				return
+			}
+		}
+
+		result, err := context.CreateDeployment(deploymentTask)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Printf("SUCCESS: Deployment \"%s\" created.\n", result)
+
+	},
+}
+
+func init() {
+	createCmd.AddCommand(createDeploymentCmd)
+
+	createDeploymentCmd.Flags().StringVar(&deploymentTask, "task", "", "Completed task to deploy.")
+
+}
@@ -15,6 +15,8 @@ var jobDataset, jobObjective string
 var jobModels, jobAltObjectives []string
 var jobAcceptNewModels bool
 var jobMaxTasks uint64
+var jobSampler string
+var jobSamplerSeed int64
 
 var createJobCmd = &cobra.Command{
 	Use:   "job",
@@ -122,7 +124,7 @@ var createJobCmd = &cobra.Command{
 
 		// TODO: Refine this. Enable us to detect when a flag wasn't set.
 
-		result, err := context.CreateJob(jobDataset, jobObjective, jobModels, jobAltObjectives, jobAcceptNewModels, jobMaxTasks)
+		result, err := context.CreateJob(jobDataset, jobObjective, jobModels, jobAltObjectives, jobAcceptNewModels, jobMaxTasks, jobSampler, jobSamplerSeed)
 		if err != nil {
 			fmt.Println(err.Error())
 			return
@@ -144,5 +146,8 @@ func init() {
 	createJobCmd.Flags().BoolVar(&jobAcceptNewModels, "accept-new-models", false, "Set to indicate that new models "+
 		"applicable to the job will also be added.")
 	createJobCmd.Flags().Uint64Var(&jobMaxTasks, "max-tasks", types.DefaultMaxTasks, "Maximum number of tasks to spawn from this job.")
+	createJobCmd.Flags().StringVar(&jobSampler, "sampler", "", "Sampler which draws the task configurations instead of the optimizer. "+
+		"One of: "+strings.Join([]string{types.SamplerRandom, types.SamplerSobol, types.SamplerHalton, types.SamplerLHS}, ", ")+".")
+	createJobCmd.Flags().Int64Var(&jobSamplerSeed, "sampler-seed", 0, "Seed of the sampler.")
 
 }
@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	client "github.com/ds3lab/easeml/client/go/easemlclient"
@@ -13,6 +14,8 @@ import (
 )
 
 var moduleID, moduleType, moduleLabel, moduleName, moduleDescription, moduleSchema, moduleSource, moduleSourceAddress string
+var moduleRegistryUsername, moduleRegistryPassword string
+var moduleNewVersion bool
 
 var createModuleCmd = &cobra.Command{
 	Use:   "module",
@@ -23,11 +26,14 @@ var createModuleCmd = &cobra.Command{
 		if apiKey == "" {
 			apiKey = viper.GetString("api-key")
 		}
+		if moduleRegistryPassword == "" {
+			moduleRegistryPassword = viper.GetString("registry-password")
+		}
 		serverAddress := viper.GetString("server-address")
 		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
 
-		// Module type is required.
-		for client.ModuleTypeValid(moduleType) == false {
+		// Module type is required. New versions have the type of the module.
+		for moduleNewVersion == false && client.ModuleTypeValid(moduleType) == false {
 			prompt := fmt.Sprintf("Module Type [choices - %s]: ", strings.Join(client.ValidModuleTypes, ", "))
 			err := readLine(prompt, &moduleType)
 			if err != nil {
@@ -55,13 +61,27 @@ var createModuleCmd = &cobra.Command{
 			}
 		}
 
+		// Modules are built by the controller so local build sources are given as absolute paths.
+		if moduleSource == types.ModuleBuild && modules.IsGitAddress(moduleSourceAddress) == false {
+			absPath, err := filepath.Abs(strings.TrimPrefix(moduleSourceAddress, "file://"))
+			if err != nil {
+				fmt.Printf(err.Error() + "\n")
+				return
+			}
+			moduleSourceAddress = absPath
+		}
+
 		// If the source is upload, the data is available to us and we
 		// will try to infer its id, name and description.
 		var defaultID, defaultName, defaultDescription string
 		if moduleSource == types.ModuleUpload {
-			var err error
-			defaultID, defaultName, defaultDescription, _, _, _, err =
-				modules.InferModuleProperties(moduleSourceAddress)
+			runtime, err := getRuntime()
+			if err != nil {
+				fmt.Printf(err.Error() + "\n")
+				return
+			}
+			defaultID, defaultName, defaultDescription, _, _, _, _, err =
+				modules.InferModuleProperties(runtime, moduleSourceAddress)
 
 			if err != nil {
 				fmt.Printf(err.Error() + "\n")
@@ -141,7 +161,8 @@ var createModuleCmd = &cobra.Command{
 				}
 			}
 
-			_, err := context.CreateModule(moduleID, moduleType, moduleLabel, moduleName, descriptionString, moduleSource, moduleSourceAddress)
+			_, err := context.CreateModule(moduleID, moduleType, moduleLabel, moduleName, descriptionString, moduleSource, moduleSourceAddress,
+				moduleRegistryUsername, moduleRegistryPassword)
 			if err != nil {
 				fmt.Println(err.Error())
 				return
@@ -149,8 +170,30 @@ var createModuleCmd = &cobra.Command{
 			// TODO: Poll the module status until it becomes "ready", to enable the user to have feedback about the process
 			fmt.Printf("SUCCESS: Module \"%s\" created.\n", moduleID)
 
+		} else if moduleNewVersion {
+
+			// Module description is optional. It is taken from the latest version if not given.
+			var descriptionString string
+			if moduleDescription != "" {
+				var err error
+				descriptionString, err = loadStream(moduleDescription)
+				if err != nil {
+					fmt.Println("Error: " + err.Error())
+					return
+				}
+			}
+
+			versionID, err := context.CreateModuleVersion(moduleID, moduleLabel, moduleName, descriptionString, moduleSource, moduleSourceAddress,
+				moduleRegistryUsername, moduleRegistryPassword)
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			moduleID = versionID
+			fmt.Printf("SUCCESS: Module version \"%s\" created.\n", moduleID)
+
 		} else if module.Source != types.ModuleUpload || module.Status != types.ModuleCreated {
-			fmt.Printf("Error: Module \"%s\" already exists.\n", moduleID)
+			fmt.Printf("Error: Module \"%s\" already exists. Use --new-version to add a new version.\n", moduleID)
 			return
 		}
 
@@ -177,6 +220,15 @@ func init() {
 	createModuleCmd.Flags().StringVar(&moduleDescription, "description", "", "Module description. "+
 		"Can be a path to a text file or \"-\" in order to read the description from stdin.")
 	createModuleCmd.Flags().StringVar(&moduleSource, "source", "", "Module source.")
-	createModuleCmd.Flags().StringVar(&moduleSourceAddress, "source-address", "", "Module source address.")
+	createModuleCmd.Flags().StringVar(&moduleSourceAddress, "source-address", "", "Module source address. "+
+		"For the build source it is a directory with a Dockerfile that is accessible to the controller or a git "+
+		"repository address which can be followed by #ref:dir to select the revision and the build directory.")
+	createModuleCmd.Flags().BoolVar(&moduleNewVersion, "new-version", false, "Add a new version to the module "+
+		"if it already exists. The type, label, name and description are taken from the latest version unless given.")
+	createModuleCmd.Flags().StringVar(&moduleRegistryUsername, "registry-username", "", "Username for the private registry "+
+		"the module is pulled from.")
+	createModuleCmd.Flags().StringVar(&moduleRegistryPassword, "registry-password", "", "Password for the private registry "+
+		"the module is pulled from. It is stored encrypted. Can also be given with the EASEML_REGISTRY_PASSWORD "+
+		"environment variable.")
 
 }
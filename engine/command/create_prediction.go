@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+
+	client "github.com/ds3lab/easeml/client/go/easemlclient"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var predictionDataset, predictionTask, predictionDeployment, predictionOutput string
+
+var createPredictionCmd = &cobra.Command{
+	Use:   "prediction",
+	Short: "Creates a new job which makes predictions with a trained model on a dataset with only inputs.",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if apiKey == "" {
+			apiKey = viper.GetString("api-key")
+		}
+		serverAddress := viper.GetString("server-address")
+		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
+
+		// Prediction dataset is required.
+		for predictionDataset == "" {
+			err := readLine("Prediction Dataset: ", &predictionDataset)
+			if err != nil {
+				fmt.Printf(err.Error() + "\n")
+				return
+			}
+		}
+
+		// Either the task or its deployment is required.
+		for predictionTask == "" && predictionDeployment == "" {
+			err := readLine("Prediction Task: ", &predictionTask)
+			if err != nil {
+				fmt.Printf(err.Error() + "\n")
+				return
+			}
+		}
+
+		result, err := context.CreatePredictionJob(predictionDataset, predictionTask, predictionDeployment, predictionOutput)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Printf("SUCCESS: Job \"%s\" created.\n", result)
+
+	},
+}
+
+func init() {
+	createCmd.AddCommand(createPredictionCmd)
+
+	createPredictionCmd.Flags().StringVar(&predictionDataset, "dataset", "", "Dataset with only inputs to make predictions on.")
+	createPredictionCmd.Flags().StringVar(&predictionTask, "task", "", "Completed task whose model makes the predictions.")
+	createPredictionCmd.Flags().StringVar(&predictionDeployment, "deployment", "", "Deployment whose task makes the predictions.")
+	createPredictionCmd.Flags().StringVar(&predictionOutput, "output", "", "ID of the dataset which stores the predictions. "+
+		"Generated from the job ID if empty.")
+
+}
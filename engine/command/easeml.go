@@ -20,6 +20,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/ds3lab/easeml/engine/modules"
+
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -83,6 +85,16 @@ func init() {
 		"Path to the working directory that stores all ease.ml files.")
 	viper.BindPFlag("working-dir", rootCmd.PersistentFlags().Lookup("working-dir"))
 
+	rootCmd.PersistentFlags().String("runtime", modules.DefaultRuntime, "Runtime used to run modules. "+
+		"One of: "+strings.Join(modules.Runtimes, ", ")+". The process runtime runs modules as local processes from "+
+		"their directories and needs no Docker daemon. The podman runtime runs modules as containers through the "+
+		"API socket of Podman, which can be rootless.")
+	viper.BindPFlag("runtime", rootCmd.PersistentFlags().Lookup("runtime"))
+
+	rootCmd.PersistentFlags().String("runtime-host", "", "Address of the API socket of the container runtime, "+
+		"e.g. unix:///run/user/1000/podman/podman.sock. If empty, the default socket of the runtime is used.")
+	viper.BindPFlag("runtime-host", rootCmd.PersistentFlags().Lookup("runtime-host"))
+
 	rootCmd.Long = getEasemlSign() + "\n" + rootCmd.Long
 }
 
@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generates a given item.",
+	Long:  ``,
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+
+	viper.BindPFlags(generateCmd.PersistentFlags())
+
+}
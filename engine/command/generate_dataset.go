@@ -0,0 +1,134 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ds3lab/easeml/engine/storage"
+
+	sch "github.com/ds3lab/easeml/schema/go/easemlschema/schema"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var generateDatasetSchemaIn, generateDatasetSchemaOut, generateDatasetOut string
+var generateDatasetSamples, generateDatasetValSamples, generateDatasetInstances, generateDatasetDefaultDim int
+var generateDatasetDims []string
+var generateDatasetSeed int64
+
+var generateDatasetCmd = &cobra.Command{
+	Use:   "dataset",
+	Short: "Generates a random dataset which conforms to the given input and output schemas.",
+	Long: `Generates a random dataset with a train and val split which conforms to the given input and output schemas.
+Variable dimensions are bound with the --dim flag or take the default value. If the output path ends with ".tar"
+the dataset is written as a tar archive, otherwise it is written to a new directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if generateDatasetSchemaIn == "" || generateDatasetSchemaOut == "" || generateDatasetOut == "" {
+			fmt.Println("Error: The --schema-in, --schema-out and --out flags are required.")
+			return
+		}
+
+		schemaIn, err := loadSchemaStream(generateDatasetSchemaIn)
+		if err != nil {
+			fmt.Println("Error: " + errors.Wrap(err, "input schema").Error())
+			return
+		}
+		schemaOut, err := loadSchemaStream(generateDatasetSchemaOut)
+		if err != nil {
+			fmt.Println("Error: " + errors.Wrap(err, "output schema").Error())
+			return
+		}
+
+		dims, err := parseDimBindings(generateDatasetDims)
+		if err != nil {
+			fmt.Println("Error: " + err.Error())
+			return
+		}
+
+		// Without an explicit seed every run produces a different dataset.
+		if cmd.Flags().Changed("seed") == false {
+			generateDatasetSeed = time.Now().UnixNano()
+		}
+
+		valSamples := generateDatasetValSamples
+		if valSamples <= 0 {
+			valSamples = generateDatasetSamples
+		}
+
+		options := storage.GenerateDatasetOptions{
+			TrainSamples:  generateDatasetSamples,
+			ValSamples:    valSamples,
+			NodeInstances: generateDatasetInstances,
+			Dims:          dims,
+			DefaultDim:    generateDatasetDefaultDim,
+			Seed:          generateDatasetSeed,
+		}
+		err = storage.GenerateDataset(schemaIn, schemaOut, generateDatasetOut, options)
+		if err != nil {
+			fmt.Println("Error: " + err.Error())
+			return
+		}
+
+		fmt.Printf("SUCCESS: Dataset written to \"%s\" (seed: %d).\n", generateDatasetOut, generateDatasetSeed)
+	},
+}
+
+func loadSchemaStream(source string) (*sch.Schema, error) {
+	schemaString, err := loadStream(source)
+	if err != nil {
+		return nil, err
+	}
+	var schemaStruct interface{}
+	err = json.Unmarshal([]byte(schemaString), &schemaStruct)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON decode error")
+	}
+	schema, schErr := sch.Load(schemaStruct)
+	if schErr != nil {
+		return nil, errors.Wrap(schErr, "schema load error")
+	}
+	return schema, nil
+}
+
+// parseDimBindings parses a list of dimension bindings of the form name=value.
+func parseDimBindings(bindings []string) (map[string]int, error) {
+	result := map[string]int{}
+	for _, b := range bindings {
+		splits := strings.SplitN(b, "=", 2)
+		if len(splits) != 2 {
+			return nil, errors.Errorf("dimension binding \"%s\" must be of the form name=value", b)
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(splits[1]))
+		if err != nil {
+			return nil, errors.Errorf("dimension binding \"%s\" must have an integer value", b)
+		}
+		result[strings.TrimSpace(splits[0])] = value
+	}
+	return result, nil
+}
+
+func init() {
+	generateCmd.AddCommand(generateDatasetCmd)
+
+	generateDatasetCmd.Flags().StringVar(&generateDatasetSchemaIn, "schema-in", "", "Input schema. "+
+		"Can be a path to a schema file or \"-\" in order to read the schema from stdin.")
+	generateDatasetCmd.Flags().StringVar(&generateDatasetSchemaOut, "schema-out", "", "Output schema. "+
+		"Can be a path to a schema file or \"-\" in order to read the schema from stdin.")
+	generateDatasetCmd.Flags().StringVar(&generateDatasetOut, "out", "", "Output directory, or a tar archive if the path ends with \".tar\".")
+	generateDatasetCmd.Flags().IntVar(&generateDatasetSamples, "samples", 10, "Number of samples in the train split.")
+	generateDatasetCmd.Flags().IntVar(&generateDatasetValSamples, "val-samples", 0, "Number of samples in the val split. "+
+		"Defaults to the number of train samples.")
+	generateDatasetCmd.Flags().IntVar(&generateDatasetInstances, "instances", 10, "Number of instances of each non-singleton node "+
+		"in a sample. It also bounds the number of links of each instance.")
+	generateDatasetCmd.Flags().StringSliceVar(&generateDatasetDims, "dim", []string{}, "Value of a dimension variable "+
+		"given as name=value. Can be repeated.")
+	generateDatasetCmd.Flags().IntVar(&generateDatasetDefaultDim, "default-dim", 10, "Value of dimension variables "+
+		"which are not bound with --dim.")
+	generateDatasetCmd.Flags().Int64Var(&generateDatasetSeed, "seed", 0, "Seed of the random generator. "+
+		"A time based seed is used if omitted.")
+}
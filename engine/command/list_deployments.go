@@ -0,0 +1,58 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	client "github.com/ds3lab/easeml/client/go/easemlclient"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var listDeploymentUser, listDeploymentStatus, listDeploymentTask, listDeploymentModel string
+
+var listDeploymentsCmd = &cobra.Command{
+	Use:   "deployments",
+	Short: "Lists deployments.",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if apiKey == "" {
+			apiKey = viper.GetString("api-key")
+		}
+		serverAddress := viper.GetString("server-address")
+		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
+
+		result, err := context.GetDeployments(listDeploymentUser, listDeploymentStatus, listDeploymentTask, listDeploymentModel)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Printf("Number of results: %d\n\n", len(result))
+
+		if len(result) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+			fmt.Fprintln(w, "ID\tUSER\tTASK\tMODEL\tREQUESTS\tSTATUS")
+
+			for _, r := range result {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", r.ID, r.User, r.Task, r.Model, r.NumRequests, r.Status)
+			}
+
+			w.Flush()
+
+		}
+
+	},
+}
+
+func init() {
+	listCmd.AddCommand(listDeploymentsCmd)
+
+	listDeploymentsCmd.Flags().StringVar(&listDeploymentUser, "user", "", "Filter deployments by user.")
+	listDeploymentsCmd.Flags().StringVar(&listDeploymentStatus, "status", "", "Filter deployments by status.")
+	listDeploymentsCmd.Flags().StringVar(&listDeploymentTask, "task", "", "Filter deployments by task.")
+	listDeploymentsCmd.Flags().StringVar(&listDeploymentModel, "model", "", "Filter deployments by model.")
+
+}
@@ -2,6 +2,7 @@ package command
 
 import (
 	client "github.com/ds3lab/easeml/client/go/easemlclient"
+	"github.com/ds3lab/easeml/client/go/easemlclient/types"
 	"fmt"
 	"os"
 	"text/tabwriter"
@@ -33,10 +34,14 @@ var listJobsCmd = &cobra.Command{
 
 		if len(result) > 0 {
 			w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
-			fmt.Fprintln(w, "USER\tDATASET\tOBJECTIVE\tNUM MODELS\tRUNNING TIME\tSTATUS")
+			fmt.Fprintln(w, "USER\tKIND\tDATASET\tOBJECTIVE\tNUM MODELS\tRUNNING TIME\tSTATUS")
 
 			for _, r := range result {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", r.User, r.Dataset, r.Objective, len(r.Models), r.RunningDuration-r.PauseDuration, r.Status)
+				kind := r.Kind
+				if kind == "" {
+					kind = types.JobTrain
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n", r.User, kind, r.Dataset, r.Objective, len(r.Models), r.RunningDuration-r.PauseDuration, r.Status)
 			}
 
 			w.Flush()
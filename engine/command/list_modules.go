@@ -4,6 +4,8 @@ import (
 	client "github.com/ds3lab/easeml/client/go/easemlclient"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -11,6 +13,7 @@ import (
 )
 
 var listModuleType, listModuleUser, listModuleStatus, listModuleSource, listModuleSchemaIn, listModuleSchemaOut string
+var listModuleForDataset, listModuleVersionsOf string
 
 var listModulesCmd = &cobra.Command{
 	Use:   "modules",
@@ -24,6 +27,18 @@ var listModulesCmd = &cobra.Command{
 		serverAddress := viper.GetString("server-address")
 		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
 
+		// Modules compatible with a dataset are found by the service, other filters do not apply.
+		if listModuleForDataset != "" {
+			listCompatibleModules(context, listModuleForDataset, listModuleType)
+			return
+		}
+
+		// The version history of a module is listed on its own, other filters do not apply.
+		if listModuleVersionsOf != "" {
+			listModuleVersions(context, listModuleVersionsOf)
+			return
+		}
+
 		var schemaStringIn string
 		if listModuleSchemaIn != "" {
 			var err error
@@ -66,6 +81,64 @@ var listModulesCmd = &cobra.Command{
 	},
 }
 
+func listCompatibleModules(context client.Context, datasetID, moduleType string) {
+
+	result, err := context.GetCompatibleModules(datasetID, moduleType)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Number of results: %d\n\n", len(result))
+
+	if len(result) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tTYPE\tINPUT DIMS\tOUTPUT DIMS")
+
+		for _, r := range result {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Module.ID, r.Module.Name, r.Module.Type, formatDimBindings(r.SchemaInDims), formatDimBindings(r.SchemaOutDims))
+		}
+
+		w.Flush()
+	}
+}
+
+func listModuleVersions(context client.Context, moduleID string) {
+
+	result, err := context.GetModuleVersions(moduleID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Number of results: %d\n\n", len(result))
+
+	if len(result) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+		fmt.Fprintln(w, "ID\tVERSION\tNAME\tSTATUS\tSOURCE\tSOURCE ADDRESS\tCREATION TIME")
+
+		for _, r := range result {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Version, r.Name, r.Status, r.Source, r.SourceAddress, r.CreationTime.String())
+		}
+
+		w.Flush()
+	}
+}
+
+func formatDimBindings(dims map[string]interface{}) string {
+	keys := make([]string, 0, len(dims))
+	for k := range dims {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	bindings := make([]string, len(keys))
+	for i, k := range keys {
+		bindings[i] = fmt.Sprintf("%s=%v", k, dims[k])
+	}
+	if len(bindings) == 0 {
+		return "-"
+	}
+	return strings.Join(bindings, ", ")
+}
+
 func init() {
 	listCmd.AddCommand(listModulesCmd)
 
@@ -77,5 +150,7 @@ func init() {
 		"Can be a path to a schema file or \"-\" in order to read the schema from stdin.")
 	listModulesCmd.Flags().StringVar(&listModuleSchemaOut, "schema-out", "", "Filter modules by output schema. "+
 		"Can be a path to a schema file or \"-\" in order to read the schema from stdin.")
+	listModulesCmd.Flags().StringVar(&listModuleForDataset, "for-dataset", "", "List active modules which can be applied to the given dataset.")
+	listModulesCmd.Flags().StringVar(&listModuleVersionsOf, "versions-of", "", "List all versions of the given module.")
 
 }
@@ -11,7 +11,7 @@ import (
 	"github.com/spf13/viper"
 )
 
-var listTaskJob, listTaskUser, listTaskStatus, listTaskStage, listTaskDataset, listTaskObjective, listTaskModel string
+var listTaskJob, listTaskUser, listTaskStatus, listTaskStage, listTaskDataset, listTaskObjective, listTaskModel, listTaskOrder string
 
 var listTasksCmd = &cobra.Command{
 	Use:   "tasks",
@@ -25,7 +25,7 @@ var listTasksCmd = &cobra.Command{
 		serverAddress := viper.GetString("server-address")
 		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
 
-		result, err := context.GetTasks(listTaskJob, listTaskUser, listTaskStatus, listTaskStage, listTaskDataset, listTaskObjective, listTaskModel)
+		result, err := context.GetTasks(listTaskJob, listTaskUser, listTaskStatus, listTaskStage, listTaskDataset, listTaskObjective, listTaskModel, listTaskOrder)
 		if err != nil {
 			fmt.Println(err.Error())
 			return
@@ -60,5 +60,6 @@ func init() {
 	listTasksCmd.Flags().StringVar(&listTaskDataset, "dataset", "", "Filter tasks by dataset.")
 	listTasksCmd.Flags().StringVar(&listTaskObjective, "objective", "", "Filter tasks by objective.")
 	listTasksCmd.Flags().StringVar(&listTaskModel, "model", "", "Show only tasks that contain the given model.")
+	listTasksCmd.Flags().StringVar(&listTaskOrder, "order", "", "Sort tasks by quality: \"best\" or \"worst\" first given the objective direction (needs --job or --objective), or \"asc\" or \"desc\".")
 
 }
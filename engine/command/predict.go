@@ -0,0 +1,87 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	client "github.com/ds3lab/easeml/client/go/easemlclient"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var predictData, predictOutput string
+
+var predictCmd = &cobra.Command{
+	Use:   "predict deployment-id",
+	Short: "Sends samples to a deployment and stores its predictions.",
+	Long: `Sends samples to a deployment and stores its predictions. The samples are either a tar file with an
+"input" directory in the dataset format, or a JSON file which maps sample names to objects of named tensors.
+Predictions of a tar file are a tar file with an "output" directory. Predictions of a JSON file are JSON.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if apiKey == "" {
+			apiKey = viper.GetString("api-key")
+		}
+		serverAddress := viper.GetString("server-address")
+		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
+
+		if predictData == "" {
+			fmt.Println("The data path must be specified.")
+			return
+		}
+		contentType := "application/x-tar"
+		if strings.HasSuffix(predictData, ".json") {
+			contentType = "application/json"
+		} else if predictOutput == "" {
+			fmt.Println("The output path must be specified for tar files.")
+			return
+		}
+
+		data, err := os.Open(predictData)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer data.Close()
+
+		predictions, err := context.PredictWithDeployment(args[0], data, contentType)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer predictions.Close()
+
+		// JSON predictions are written to the standard output if no output path is given.
+		var output io.Writer = os.Stdout
+		if predictOutput != "" {
+			f, err := os.Create(predictOutput)
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			defer f.Close()
+			output = f
+		}
+		if _, err := io.Copy(output, predictions); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if predictOutput != "" {
+			fmt.Printf("SUCCESS: Predictions written to \"%s\".\n", predictOutput)
+		}
+
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(predictCmd)
+
+	predictCmd.Flags().StringVar(&predictData, "data", "", "Path to a tar or JSON file with the samples.")
+	predictCmd.Flags().StringVar(&predictOutput, "output", "", "Path of the file where the predictions are written.")
+
+}
@@ -31,12 +31,18 @@ var runEvalCmd = &cobra.Command{
 			return
 		}
 
+		runtime, err := getRuntime()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
 		command := []string{
 			"eval",
 			"--actual", modules.MntPrefix + runEvalActual,
 			"--predicted", modules.MntPrefix + runEvalPredicted,
 		}
-		outReader, err := modules.RunContainerAndCollectOutput(modelImageName, nil, command, nil)
+		outReader, err := runtime.Run(modelImageName, nil, command, nil)
 		if err != nil {
 			fmt.Println("Error while running the container: ")
 			fmt.Print(err)
@@ -43,12 +43,18 @@ var runPredictCmd = &cobra.Command{
 			return
 		}
 
+		runtime, err := getRuntime()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
 		// If the image is a tar file we load it.
 		fileStat, err := os.Stat(modelImageName)
 		if err == nil {
 			if fileStat.IsDir() == false {
 				modelImagePath := modelImageName
-				modelImageName, err = modules.LoadImage(modelImagePath)
+				modelImageName, err = runtime.LoadImage(modelImagePath)
 				if err != nil {
 					fmt.Printf("Error while loading image from \"%s\":\n", modelImagePath)
 					fmt.Println(err)
@@ -63,7 +69,7 @@ var runPredictCmd = &cobra.Command{
 			"--memory", modules.MntPrefix + runPredictMemory,
 			"--output", modules.MntPrefix + runPredictOutput,
 		}
-		outReader, err := modules.RunContainerAndCollectOutput(modelImageName, nil, command, runPredictGpuDevices)
+		outReader, err := runtime.Run(modelImageName, nil, command, runPredictGpuDevices)
 		if err != nil {
 			fmt.Println("Error while running the container: ")
 			fmt.Print(err)
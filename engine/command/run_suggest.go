@@ -1,9 +1,11 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/ds3lab/easeml/engine/modules"
@@ -14,33 +16,72 @@ import (
 
 var runSuggestSpace, runSuggestHistory string
 var runSuggestNumTasks int
+var runSuggestSampler modules.SamplerOptions
+var runSuggestSkip int
 
 var runSuggestCmd = &cobra.Command{
 	Use:   "suggest [image]",
 	Short: "Runs a suggest command on an optimizer given its docker image.",
-	Long:  ``,
-	Args:  cobra.ExactArgs(1),
+	Long: `Runs a suggest command on an optimizer given its docker image. If a sampler is specified, no image is
+needed and the configurations are drawn locally from the search space.`,
+	Args: cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
 
-		modelImageName := args[0]
-
 		// Ensure all input parameters point to existing files and folders.
 		if _, err := os.Stat(runSuggestSpace); os.IsNotExist(err) {
 			fmt.Printf("Config space path \"%s\" doesn't exist.\n", runSuggestSpace)
 			return
 		}
+
+		if runSuggestSampler.Name != "" {
+			space, err := loadSuggestSpace(runSuggestSpace)
+			if err != nil {
+				fmt.Println("Error while loading the config space: ")
+				fmt.Print(err)
+				fmt.Println()
+				return
+			}
+			configs, err := modules.SampleConfigs(space, runSuggestSampler, runSuggestSkip, runSuggestNumTasks)
+			if err != nil {
+				fmt.Println("Error while sampling: ")
+				fmt.Print(err)
+				fmt.Println()
+				return
+			}
+			for i := range configs {
+				line, err := json.Marshal(configs[i].Dump())
+				if err != nil {
+					panic(err)
+				}
+				fmt.Println(string(line))
+			}
+			return
+		}
+
+		if len(args) == 0 {
+			fmt.Println("Either an optimizer image or a sampler must be specified.")
+			return
+		}
+		modelImageName := args[0]
+
 		if _, err := os.Stat(runSuggestHistory); os.IsNotExist(err) {
 			fmt.Printf("History path \"%s\" doesn't exist.\n", runSuggestHistory)
 			return
 		}
 
+		runtime, err := getRuntime()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
 		command := []string{
 			"suggest",
 			"--space", modules.MntPrefix + runSuggestSpace,
 			"--history", modules.MntPrefix + runSuggestHistory,
 			"--num-tasks", strconv.Itoa(runSuggestNumTasks),
 		}
-		outReader, err := modules.RunContainerAndCollectOutput(modelImageName, nil, command, nil)
+		outReader, err := runtime.Run(modelImageName, nil, command, nil)
 		if err != nil {
 			fmt.Println("Error while running the container: ")
 			fmt.Print(err)
@@ -54,6 +95,40 @@ var runSuggestCmd = &cobra.Command{
 	},
 }
 
+// loadSuggestSpace loads a config space from a JSON file. If the path is a directory, the config spaces
+// of all JSON files in it are joined with a .choice element.
+func loadSuggestSpace(path string) (modules.ConfigElem, error) {
+	paths := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		paths, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no JSON files found in \"%s\"", path)
+		}
+	}
+
+	choices := []interface{}{}
+	for i := range paths {
+		data, err := ioutil.ReadFile(paths[i])
+		if err != nil {
+			return nil, err
+		}
+		var space interface{}
+		if err = json.Unmarshal(data, &space); err != nil {
+			return nil, fmt.Errorf("%s: %s", paths[i], err.Error())
+		}
+		choices = append(choices, space)
+	}
+	if len(choices) == 1 {
+		return modules.LoadConfig(choices[0])
+	}
+	return modules.LoadConfig(map[string]interface{}{".choice": choices})
+}
+
 func init() {
 	runCmd.AddCommand(runSuggestCmd)
 
@@ -63,6 +138,11 @@ func init() {
 		"Each JSON file represents one branch of the search space. All of them will be concatenated using a .choice element.")
 	runSuggestCmd.Flags().StringVarP(&runSuggestHistory, "history", "i", "", "Directory containing the history.")
 	runSuggestCmd.Flags().IntVarP(&runSuggestNumTasks, "num-tasks", "n", 1, "Number of new tasks the optimizers should suggest.")
+	runSuggestCmd.Flags().StringVar(&runSuggestSampler.Name, "sampler", "", "Sampler used instead of an optimizer. "+
+		"One of: random, sobol, halton, lhs.")
+	runSuggestCmd.Flags().Int64Var(&runSuggestSampler.Seed, "seed", 0, "Seed of the sampler.")
+	runSuggestCmd.Flags().IntVar(&runSuggestSampler.Size, "size", modules.DefaultSamplerSize, "Size of the Latin hypercube designs.")
+	runSuggestCmd.Flags().IntVar(&runSuggestSkip, "skip", 0, "Number of configurations to skip at the start of the sequence of the sampler.")
 
 	viper.BindPFlags(runSuggestCmd.PersistentFlags())
 
@@ -37,13 +37,19 @@ var runTrainCmd = &cobra.Command{
 			return
 		}
 
+		runtime, err := getRuntime()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
 		command := []string{
 			"train",
 			"--data", modules.MntPrefix + runTrainData,
 			"--conf", modules.MntPrefix + runTrainConfig,
 			"--output", modules.MntPrefix + runTrainOutput,
 		}
-		outReader, err := modules.RunContainerAndCollectOutput(modelImageName, nil, command, runTrainGpuDevices)
+		outReader, err := runtime.Run(modelImageName, nil, command, runTrainGpuDevices)
 		if err != nil {
 			fmt.Println("Error while running the container: ")
 			fmt.Print(err)
@@ -46,6 +46,12 @@ var showDatasetCmd = &cobra.Command{
 		}
 		fmt.Fprintf(w, "SOURCE:\t%s\n", result.Source)
 		fmt.Fprintf(w, "SOURCE ADDRESS:\t%s\n", result.SourceAddress)
+		if result.Checksum != "" {
+			fmt.Fprintf(w, "CHECKSUM:\tsha256:%s\n", result.Checksum)
+		}
+		if result.TransferredBytes > 0 || result.TotalBytes > 0 {
+			fmt.Fprintf(w, "TRANSFERRED:\t%s\n", formatTransferProgress(result.TransferredBytes, result.TotalBytes))
+		}
 		fmt.Fprintf(w, "CREATION TIME:\t%s\n", result.CreationTime)
 		w.Flush()
 
@@ -77,3 +83,10 @@ var showDatasetCmd = &cobra.Command{
 func init() {
 	showCmd.AddCommand(showDatasetCmd)
 }
+
+func formatTransferProgress(transferredBytes, totalBytes int64) string {
+	if totalBytes <= 0 {
+		return fmt.Sprintf("%d bytes", transferredBytes)
+	}
+	return fmt.Sprintf("%d / %d bytes (%.1f%%)", transferredBytes, totalBytes, 100*float64(transferredBytes)/float64(totalBytes))
+}
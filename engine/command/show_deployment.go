@@ -0,0 +1,66 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	client "github.com/ds3lab/easeml/client/go/easemlclient"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var showDeploymentLogs bool
+
+var showDeploymentCmd = &cobra.Command{
+	Use:   "deployment id",
+	Short: "Shows deployment given its id.",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if apiKey == "" {
+			apiKey = viper.GetString("api-key")
+		}
+		serverAddress := viper.GetString("server-address")
+		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
+
+		if showDeploymentLogs {
+			logs, err := context.GetDeploymentLogs(args[0])
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Print(logs)
+			return
+		}
+
+		result, err := context.GetDeploymentByID(args[0])
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+
+		fmt.Fprintf(w, "ID:\t%s\n", result.ID)
+		fmt.Fprintf(w, "USER:\t%s\n", result.User)
+		fmt.Fprintf(w, "TASK:\t%s\n", result.Task)
+		fmt.Fprintf(w, "MODEL:\t%s\n", result.Model)
+		fmt.Fprintf(w, "STATUS:\t%s\n", result.Status)
+		if result.StatusMessage != "" {
+			fmt.Fprintf(w, "STATUS MESSAGE:\t%s\n", result.StatusMessage)
+		}
+		fmt.Fprintf(w, "CREATION TIME:\t%s\n", result.CreationTime)
+		fmt.Fprintf(w, "REQUESTS:\t%d\n", result.NumRequests)
+		w.Flush()
+
+	},
+}
+
+func init() {
+	showCmd.AddCommand(showDeploymentCmd)
+
+	showDeploymentCmd.Flags().BoolVar(&showDeploymentLogs, "logs", false, "Show the deployment log instead.")
+}
@@ -2,8 +2,10 @@ package command
 
 import (
 	client "github.com/ds3lab/easeml/client/go/easemlclient"
+	"github.com/ds3lab/easeml/client/go/easemlclient/types"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/ghodss/yaml"
@@ -38,6 +40,9 @@ var showModuleCmd = &cobra.Command{
 		w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
 
 		fmt.Fprintf(w, "ID:\t%s\n", result.ID)
+		if result.Version > 0 {
+			fmt.Fprintf(w, "VERSION:\t%d\n", result.Version)
+		}
 		fmt.Fprintf(w, "TYPE:\t%s\n", result.Type)
 		fmt.Fprintf(w, "NAME:\t%s\n", result.Name)
 		fmt.Fprintf(w, "STATUS:\t%s\n", result.Status)
@@ -46,6 +51,15 @@ var showModuleCmd = &cobra.Command{
 		}
 		fmt.Fprintf(w, "SOURCE:\t%s\n", result.Source)
 		fmt.Fprintf(w, "SOURCE ADDRESS:\t%s\n", result.SourceAddress)
+		if result.BuildTag != "" {
+			fmt.Fprintf(w, "BUILD TAG:\t%s\n", result.BuildTag)
+		}
+		if result.Objective != nil {
+			fmt.Fprintf(w, "OBJECTIVE DIRECTION:\t%s\n", result.Objective.Direction)
+			if len(result.Objective.Outputs) > 0 {
+				fmt.Fprintf(w, "OBJECTIVE OUTPUTS:\t%s\n", strings.Join(result.Objective.Outputs, ", "))
+			}
+		}
 		fmt.Fprintf(w, "CREATION TIME:\t%s\n", result.CreationTime)
 		w.Flush()
 
@@ -71,6 +85,11 @@ var showModuleCmd = &cobra.Command{
 			}
 		}
 
+		// The build log is only of interest if the build failed.
+		if result.BuildLog != "" && result.Status == types.ModuleError {
+			fmt.Printf("BUILD LOG:\n\n%s\n", result.BuildLog)
+		}
+
 	},
 }
 
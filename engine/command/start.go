@@ -27,6 +27,7 @@ import (
 	"github.com/ds3lab/easeml/engine/process/daemon"
 	"github.com/ds3lab/easeml/engine/process/scheduler"
 	"github.com/ds3lab/easeml/engine/process/worker"
+	"github.com/ds3lab/easeml/engine/storage"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -61,6 +62,12 @@ var startCmd = &cobra.Command{
 			RootAPIKey:      make(chan string, 1),
 			DebugLog:        debugLog,
 			GpuDevices:      startGpuDevices,
+			Runtime:         viper.GetString("runtime"),
+			RuntimeHost:     viper.GetString("runtime-host"),
+			S3Endpoint:      viper.GetString("s3-endpoint"),
+			S3Region:        viper.GetString("s3-region"),
+
+			RequireConformance: viper.GetBool("require-conformance"),
 		}
 
 		var wg sync.WaitGroup
@@ -170,6 +177,14 @@ func init() {
 	startCmd.PersistentFlags().Uint("listener-period", 250,
 		"Duration in miliseconds between two database listener queries.")
 
+	startCmd.PersistentFlags().String("s3-endpoint", storage.DefaultS3Endpoint,
+		"Endpoint of the S3-compatible object store used by datasets with source s3 (e.g. http://localhost:9000 for MinIO).")
+	startCmd.PersistentFlags().String("s3-region", storage.DefaultS3Region,
+		"Region used to sign requests sent to the S3-compatible object store.")
+
+	startCmd.PersistentFlags().Bool("require-conformance", false,
+		"Reject uploaded models which fail the conformance suite. By default the report is only stored with the model.")
+
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// startCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
@@ -181,7 +196,8 @@ func init() {
 		"(zero based) that specified which GPU devices to make available to each module executed by a worker "+
 		"process. If -1 is specified, then all GPU devices are made available. If empty, then only CPU is used. "+
 		"For example --gpu 0,2 means that GPU0 and GPU2 will be made available to the module. "+
-		"NVidia Docker runtime needs to be installed to use this feature (https://github.com/NVIDIA/nvidia-docker).")
+		"NVidia Docker runtime needs to be installed to use this feature (https://github.com/NVIDIA/nvidia-docker). "+
+		"With the podman runtime, the devices are passed as CDI devices.")
 
 	// Bind with viper config.
 	viper.BindPFlags(startCmd.PersistentFlags())
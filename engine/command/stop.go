@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stops an item given its id.",
+	Long:  ``,
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+
+	viper.BindPFlags(stopCmd.PersistentFlags())
+
+}
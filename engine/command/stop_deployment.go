@@ -0,0 +1,38 @@
+package command
+
+import (
+	"fmt"
+
+	client "github.com/ds3lab/easeml/client/go/easemlclient"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var stopDeploymentCmd = &cobra.Command{
+	Use:   "deployment id",
+	Short: "Stops a deployment so that it doesn't serve predictions anymore.",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if apiKey == "" {
+			apiKey = viper.GetString("api-key")
+		}
+		serverAddress := viper.GetString("server-address")
+		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
+
+		err := context.StopDeployment(args[0])
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("SUCCESS: Deployment \"%s\" stopped.\n", args[0])
+
+	},
+}
+
+func init() {
+	stopCmd.AddCommand(stopDeploymentCmd)
+}
@@ -0,0 +1,87 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	client "github.com/ds3lab/easeml/client/go/easemlclient"
+	"github.com/ds3lab/easeml/client/go/easemlclient/types"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var validateCompatibilityCmd = &cobra.Command{
+	Use:   "compatibility [module-id] [dataset-id]",
+	Short: "Explains whether a module can be applied to a dataset.",
+	Long:  ``,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if apiKey == "" {
+			apiKey = viper.GetString("api-key")
+		}
+		serverAddress := viper.GetString("server-address")
+		context := client.Context{ServerAddress: serverAddress, UserCredentials: client.APIKeyCredentials{APIKey: apiKey}}
+
+		result, err := context.GetModuleCompatibility(args[0], args[1])
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+		fmt.Fprintf(w, "MODULE:\t%s\n", result.Module)
+		fmt.Fprintf(w, "DATASET:\t%s\n", result.Dataset)
+		fmt.Fprintf(w, "COMPATIBLE:\t%t\n", result.Compatible)
+		w.Flush()
+
+		printMatchReport("INPUT SCHEMA", result.SchemaIn)
+		printMatchReport("OUTPUT SCHEMA", result.SchemaOut)
+
+		if result.Compatible == false {
+			os.Exit(1)
+		}
+	},
+}
+
+func printMatchReport(title string, report *types.SchemaMatchReport) {
+	if report == nil || report.Match {
+		return
+	}
+
+	fmt.Printf("\n%s:\n\n", title)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "MODULE NODE\tDATASET NODE")
+	nodes := make([]string, 0, len(report.NodeMapping))
+	for k := range report.NodeMapping {
+		nodes = append(nodes, k)
+	}
+	sort.Strings(nodes)
+	for _, k := range nodes {
+		fmt.Fprintf(w, "%s\t%s\n", k, report.NodeMapping[k])
+	}
+	w.Flush()
+	fmt.Println()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "PATH\tCONSTRAINT\tEXPECTED\tFOUND\tMESSAGE")
+	for _, f := range report.Failures {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Path, f.Constraint, f.Expected, f.Found, f.Message)
+	}
+	w.Flush()
+
+	if len(report.Suggestions) > 0 {
+		fmt.Println("\nSUGGESTIONS:")
+		for _, s := range report.Suggestions {
+			fmt.Printf("  - %s\n", s)
+		}
+	}
+}
+
+func init() {
+	validateCmd.AddCommand(validateCompatibilityCmd)
+}
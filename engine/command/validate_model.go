@@ -1,45 +1,112 @@
 package command
 
 import (
-	"github.com/ds3lab/easeml/engine/modules"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/ds3lab/easeml/engine/modules"
+	"github.com/ds3lab/easeml/engine/storage"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var validateModelData, validateModelConfig, validateModelOutput string
+var validateModelReportJSON, validateModelReportJUnit string
+var validateModelOptions = modules.DefaultConformanceOptions
 
 var validateModelCmd = &cobra.Command{
 	Use:   "model [image]",
-	Short: "Runs a model command on a model given its docker image.",
-	Long:  ``,
-	Args:  cobra.ExactArgs(1),
+	Short: "Runs the conformance test suite on a model given its docker image.",
+	Long: `Generates random datasets from the schemas of the model, samples configurations from its config space,
+and for each combination runs train and predict on the train and val splits. The predictions must load and match
+the output schema of the model.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		modelImageName := args[0]
 
-		_, _, _, jsonSchemaIn, jsonSchemaOut, configSpace, err := modules.InferModuleProperties(modelImageName)
+		runtime, err := getRuntime()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		_, _, _, jsonSchemaIn, jsonSchemaOut, configSpace, _, err := modules.InferModuleProperties(runtime, modelImageName)
 		if err != nil {
 			fmt.Println("Error while getting data from the container: ")
 			fmt.Print(err)
 			fmt.Println()
+			return
+		}
+
+		if cmd.Flags().Changed("seed") == false {
+			validateModelOptions.Seed = time.Now().UnixNano()
 		}
 
-		err = modules.ValidateModel(modelImageName, jsonSchemaIn, jsonSchemaOut, configSpace, true)
+		report, err := modules.RunConformanceSuite(runtime, modelImageName, jsonSchemaIn, jsonSchemaOut, configSpace, validateModelOptions)
 		if err != nil {
 			fmt.Println("Validation failed: ")
 			fmt.Print(err)
 			fmt.Println()
+			return
 		}
 
-		fmt.Println("SUCCESS: Validation completed.")
+		for _, r := range report.Runs {
+			for _, c := range r.Cases {
+				fmt.Printf("%-8s %s/%s (%.1fs)", c.Status, r.Name, c.Name, c.Duration)
+				if c.Message != "" {
+					fmt.Printf(": %s", c.Message)
+				}
+				fmt.Println()
+			}
+		}
+
+		if validateModelReportJSON != "" {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err == nil {
+				err = ioutil.WriteFile(validateModelReportJSON, data, storage.DefaultFilePerm)
+			}
+			if err != nil {
+				fmt.Println("Error while writing the JSON report: " + err.Error())
+			}
+		}
+		if validateModelReportJUnit != "" {
+			data, err := modules.ConformanceReportJUnit(report)
+			if err == nil {
+				err = ioutil.WriteFile(validateModelReportJUnit, data, storage.DefaultFilePerm)
+			}
+			if err != nil {
+				fmt.Println("Error while writing the JUnit report: " + err.Error())
+			}
+		}
+
+		if report.Passed == false {
+			fmt.Printf("FAILED: %d of %d tests failed, %d skipped (seed: %d).\n", report.Failures, report.Tests, report.Skipped, validateModelOptions.Seed)
+			os.Exit(1)
+		}
+		fmt.Printf("SUCCESS: Validation completed, %d tests passed (seed: %d).\n", report.Tests, validateModelOptions.Seed)
 	},
 }
 
 func init() {
 	validateCmd.AddCommand(validateModelCmd)
 
+	validateModelCmd.Flags().IntVar(&validateModelOptions.NumDatasets, "datasets", validateModelOptions.NumDatasets, "Number of generated datasets.")
+	validateModelCmd.Flags().IntVar(&validateModelOptions.NumConfigs, "configs", validateModelOptions.NumConfigs, "Number of configurations "+
+		"sampled from the config space. Each one is tested on every dataset.")
+	validateModelCmd.Flags().IntVar(&validateModelOptions.NumSamples, "samples", validateModelOptions.NumSamples, "Number of samples in each split.")
+	validateModelCmd.Flags().IntVar(&validateModelOptions.MinDim, "min-dim", validateModelOptions.MinDim, "Smallest value of dimension variables.")
+	validateModelCmd.Flags().IntVar(&validateModelOptions.MaxDim, "max-dim", validateModelOptions.MaxDim, "Largest value of dimension variables.")
+	validateModelCmd.Flags().Int64Var(&validateModelOptions.Seed, "seed", 0, "Seed of the random generator. "+
+		"A time based seed is used if omitted.")
+	validateModelCmd.Flags().BoolVar(&validateModelOptions.KeepFiles, "keep-files", false, "Keep the generated data, model memory and predictions.")
+	validateModelCmd.Flags().StringVar(&validateModelReportJSON, "report-json", "", "Path of the JSON report.")
+	validateModelCmd.Flags().StringVar(&validateModelReportJUnit, "report-junit", "", "Path of the JUnit XML report.")
+
 	//loginCmd.PersistentFlags().BoolVarP(&saveAPIKey, "save", "s", false, "Write the resulting API key to the config file.")
 
 	viper.BindPFlags(validateModelCmd.PersistentFlags())
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"strings"
@@ -94,6 +95,9 @@ func (context Context) GetDatasets(
 		case "user", "status", "source", "source-address":
 			setDefault(&query, k, bson.M{})
 			query[k].(bson.M)["$eq"] = v.(string)
+		case "schema-in-hash", "schema-out-hash":
+			setDefault(&query, k, bson.M{})
+			query[k].(bson.M)["$eq"] = v.(string)
 		case "schema-in":
 			schInput, err = deserializeSchema(v.(string))
 			if err != nil {
@@ -198,26 +202,40 @@ func (context Context) GetDatasets(
 		return
 	}
 
-	// If there is a schema filter, then we do manual shema matching here.
+	// If there is a schema filter, then we do manual shema matching here. Results are cached by the
+	// structural hashes of the schemas.
 	if schInput != nil || schOutput != nil {
+		var schInputHash, schOutputHash string
+		if schInput != nil {
+			schInputHash = schInput.Hash()
+		}
+		if schOutput != nil {
+			schOutputHash = schOutput.Hash()
+		}
+
 		var allResultsFiltered []types.Dataset
 		for i := range allResults {
 
-			schInputSrc, err := deserializeSchema(allResults[i].SchemaIn)
-			if err != nil {
-				panic(err)
-			}
-			schOutputSrc, err := deserializeSchema(allResults[i].SchemaOut)
-			if err != nil {
-				panic(err)
-			}
-
 			var match = true
 			if schInput != nil {
-				match, _ = schInput.Match(schInputSrc, false)
+				match = matchStructuralCached(schInputHash, allResults[i].SchemaInHash, func() bool {
+					schInputSrc, err := deserializeSchema(allResults[i].SchemaIn)
+					if err != nil {
+						panic(err)
+					}
+					match, _ := schInput.Match(schInputSrc, false)
+					return match
+				})
 			}
 			if match && schOutput != nil {
-				match, _ = schOutput.Match(schOutputSrc, false)
+				match = matchStructuralCached(schOutputHash, allResults[i].SchemaOutHash, func() bool {
+					schOutputSrc, err := deserializeSchema(allResults[i].SchemaOut)
+					if err != nil {
+						panic(err)
+					}
+					match, _ := schOutput.Match(schOutputSrc, false)
+					return match
+				})
 			}
 			if match {
 				allResultsFiltered = append(allResultsFiltered, allResults[i])
@@ -279,12 +297,24 @@ func (context Context) CreateDataset(dataset types.Dataset) (result types.Datase
 		err = errors.Wrap(ErrBadInput, "the id must be of the format dataset-id or user-id/dataset-id")
 		return
 	}
-	if dataset.Source != types.DatasetUpload && dataset.Source != types.DatasetLocal && dataset.Source != types.DatasetDownload && dataset.Source != types.DatasetGit {
+	if dataset.Source != types.DatasetUpload && dataset.Source != types.DatasetLocal && dataset.Source != types.DatasetDownload &&
+		dataset.Source != types.DatasetGit && dataset.Source != types.DatasetS3 {
 		err = errors.Wrapf(ErrBadInput,
-			"value of source can be \"%s\", \"%s\", \"%s\" or \"%s\", but found \"%s\"",
-			types.DatasetUpload, types.DatasetLocal, types.DatasetDownload, types.DatasetGit, dataset.Source)
+			"value of source can be \"%s\", \"%s\", \"%s\", \"%s\" or \"%s\", but found \"%s\"",
+			types.DatasetUpload, types.DatasetLocal, types.DatasetDownload, types.DatasetGit, types.DatasetS3, dataset.Source)
 		return
 	}
+	if dataset.Checksum != "" {
+		if dataset.Source != types.DatasetDownload {
+			err = errors.Wrapf(ErrBadInput, "a checksum can only be given for datasets with source \"%s\"", types.DatasetDownload)
+			return
+		}
+		dataset.Checksum = strings.ToLower(strings.TrimPrefix(dataset.Checksum, "sha256:"))
+		if sum, decodeErr := hex.DecodeString(dataset.Checksum); decodeErr != nil || len(sum) != sha256.Size {
+			err = errors.Wrap(ErrBadInput, "the checksum must be a hex encoded sha256 digest")
+			return
+		}
+	}
 	// Validate the schemas.
 	if dataset.SchemaIn != "" {
 		_, err = deserializeSchema(dataset.SchemaIn)
@@ -297,6 +327,11 @@ func (context Context) CreateDataset(dataset types.Dataset) (result types.Datase
 			err = errors.Wrap(ErrBadInput, "json schema input compact error")
 			return
 		}
+		dataset.SchemaInHash, err = structuralSchemaHash(dataset.SchemaIn)
+		if err != nil {
+			err = errors.Wrap(ErrBadInput, "the given input schema definition is invalid")
+			return
+		}
 	}
 	if dataset.SchemaOut != "" {
 		_, err = deserializeSchema(dataset.SchemaOut)
@@ -309,6 +344,11 @@ func (context Context) CreateDataset(dataset types.Dataset) (result types.Datase
 			err = errors.Wrap(ErrBadInput, "json schema output compact error")
 			return
 		}
+		dataset.SchemaOutHash, err = structuralSchemaHash(dataset.SchemaOut)
+		if err != nil {
+			err = errors.Wrap(ErrBadInput, "the given output schema definition is invalid")
+			return
+		}
 	}
 
 	// Load schema structure.
@@ -319,6 +359,8 @@ func (context Context) CreateDataset(dataset types.Dataset) (result types.Datase
 	dataset.User = context.User.ID
 	dataset.CreationTime = time.Now()
 	dataset.Status = types.DatasetCreated
+	dataset.TransferredBytes = 0
+	dataset.TotalBytes = 0
 
 	c := context.Session.DB(context.DBName).C("datasets")
 	err = c.Insert(dataset)
@@ -374,6 +416,11 @@ func (context Context) UpdateDataset(id string, updates map[string]interface{})
 				}
 			}
 			valueUpdates["schema-in"] = schemaString
+			valueUpdates["schema-in-hash"], err = structuralSchemaHash(schemaString)
+			if err != nil {
+				err = errors.Wrap(ErrBadInput, "the given input schema definition is invalid")
+				return
+			}
 		case "schema-out":
 			schemaString := v.(string)
 			if schemaString != "" {
@@ -387,6 +434,11 @@ func (context Context) UpdateDataset(id string, updates map[string]interface{})
 					err = errors.Wrap(ErrBadInput, "json schema output compact error")
 					return
 				}
+				valueUpdates["schema-out-hash"], err = structuralSchemaHash(schemaString)
+				if err != nil {
+					err = errors.Wrap(ErrBadInput, "the given output schema definition is invalid")
+					return
+				}
 				valueUpdates["schema-out"] = schemaString
 			}
 		case "status":
@@ -406,8 +458,12 @@ func (context Context) UpdateDataset(id string, updates map[string]interface{})
 			valueUpdates["status"] = status
 		case "status-message":
 			valueUpdates["status-message"] = v.(string)
-		case "accessKey":
-			valueUpdates["accessKey"]=v.(string)
+		case "access-key":
+			valueUpdates["access-key"] = v.(string)
+		case "transferred-bytes":
+			valueUpdates["transferred-bytes"] = v.(int64)
+		case "total-bytes":
+			valueUpdates["total-bytes"] = v.(int64)
 		default:
 			err = errors.Wrap(ErrBadInput, "invalid value of parameter updates")
 			return
@@ -539,9 +595,16 @@ func (context Context) UpdateDatasetStatus(id string, status string, statusMessa
 	return
 }
 
+// UpdateDatasetTransferProgress records the number of bytes transferred so far and the total number of bytes
+// to transfer. The total is zero if it is unknown.
+func (context Context) UpdateDatasetTransferProgress(id string, transferredBytes, totalBytes int64) (err error) {
+	_, err = context.UpdateDataset(id, F{"transferred-bytes": transferredBytes, "total-bytes": totalBytes})
+	return
+}
+
 // FlushDatasetAccessKey removes a used accessKey from the database.
 func (context Context) FlushDatasetAccessKey(id string) (err error) {
-	_, err = context.UpdateDataset(id, F{"accessKey": ""})
+	_, err = context.UpdateDataset(id, F{"access-key": ""})
 	return
 }
 
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -308,6 +309,18 @@ func TestCreateDataset(t *testing.T) {
 	newDataset, err = context.CreateDataset(types.Dataset{ID: "root/dataset1"})
 	assert.Equal(ErrBadInput, errors.Cause(err))
 
+	// A checksum is only accepted for downloaded datasets and must be a sha256 digest.
+	dataset = types.Dataset{ID: "root/dataset2", Source: "upload", Checksum: strings.Repeat("ab", 32)}
+	newDataset, err = context.CreateDataset(dataset)
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	dataset = types.Dataset{ID: "root/dataset2", Source: "download", SourceAddress: "http://dataset2", Checksum: "sha256:abc"}
+	newDataset, err = context.CreateDataset(dataset)
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	dataset.Checksum = "sha256:" + strings.Repeat("AB", 32)
+	newDataset, err = context.CreateDataset(dataset)
+	assert.Nil(err)
+	assert.Equal(strings.Repeat("ab", 32), newDataset.Checksum)
+
 	// Drop the test database.
 	err = connection.Session.DB(TestDBName).DropDatabase()
 	assert.Nil(err)
@@ -0,0 +1,486 @@
+package model
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+)
+
+// GetDeploymentByID returns the deployment given its id.
+func (context Context) GetDeploymentByID(id bson.ObjectId) (result types.Deployment, err error) {
+
+	c := context.Session.DB(context.DBName).C("deployments")
+	var allResults []types.Deployment
+
+	// Only the root user can look up deployments other than their own.
+	if context.User.IsRoot() {
+		err = c.Find(bson.M{"_id": id}).All(&allResults)
+	} else {
+		err = c.Find(bson.M{"_id": id, "user": bson.M{"$in": []string{context.User.ID, types.UserRoot}}}).All(&allResults)
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "mongo find failed")
+		return
+	}
+
+	if len(allResults) == 0 {
+		err = ErrNotFound
+		return
+	}
+
+	return allResults[0], nil
+}
+
+// GetDeployments lists all deployments given some filter criteria.
+func (context Context) GetDeployments(
+	filters F,
+	limit int,
+	cursor string,
+	sortBy string,
+	order string,
+) (result []types.Deployment, cm types.CollectionMetadata, err error) {
+
+	c := context.Session.DB(context.DBName).C("deployments")
+
+	// Validate the parameters.
+	if sortBy != "" &&
+		sortBy != "user" &&
+		sortBy != "task" &&
+		sortBy != "model" &&
+		sortBy != "creation-time" &&
+		sortBy != "status" {
+		err = errors.Wrapf(ErrBadInput, "cannot sort by \"%s\"", sortBy)
+		return
+	}
+	if order != "" && order != "asc" && order != "desc" {
+		err = errors.Wrapf(ErrBadInput, "order can be either \"asc\" or \"desc\", not \"%s\"", order)
+		return
+	}
+	if order == "" {
+		order = "asc"
+	}
+
+	// If the user is not root then we need to limit access.
+	query := bson.M{}
+	if context.User.IsRoot() == false {
+		query = bson.M{"user": bson.M{"$in": []string{context.User.ID, types.UserRoot}}}
+	}
+
+	// Build a query given the parameters.
+	query, err = deploymentQuery(query, filters)
+	if err != nil {
+		return
+	}
+
+	// We count the result size given the filters. This is before pagination.
+	var resultSize int
+	resultSize, err = c.Find(query).Count()
+	if err != nil {
+		err = errors.Wrap(err, "mongo find failed")
+		return
+	}
+
+	// If a cursor was specified then we have to do a range query.
+	if cursor != "" {
+		comparer := "$gt"
+		if order == "desc" {
+			comparer = "$lt"
+		}
+
+		// If there is no sorting then the cursor only points to the _id field.
+		if sortBy != "" {
+			splits := strings.Split(cursor, "-")
+			cursor = splits[1]
+			var decoded []byte
+			decoded, err = hex.DecodeString(splits[0])
+			if err != nil {
+				err = errors.Wrap(err, "hex decode string failed")
+				return
+			}
+			var otherCursor interface{}
+			switch sortBy {
+			case "user", "task", "model", "status":
+				otherCursor = string(decoded)
+			case "creation-time":
+				var t time.Time
+				t.GobDecode(decoded)
+				otherCursor = t
+			}
+
+			setDefault(&query, "$or", bson.M{})
+			query["$or"] = []bson.M{
+				bson.M{sortBy: bson.M{comparer: otherCursor}},
+				bson.M{sortBy: bson.M{"$eq": otherCursor}, "_id": bson.M{comparer: bson.ObjectIdHex(cursor)}},
+			}
+		} else {
+			if bson.IsObjectIdHex(cursor) == false {
+				err = errors.Wrap(ErrBadInput, "invalid cursor")
+				return
+			}
+			setDefault(&query, "_id", bson.M{})
+			query["_id"].(bson.M)[comparer] = bson.ObjectIdHex(cursor)
+		}
+	}
+
+	// Execute the query.
+	q := c.Find(query)
+
+	// We always sort by _id, but we may also sort by a specific field.
+	if sortBy == "" {
+		if order == "asc" {
+			q = q.Sort("_id")
+		} else {
+			q = q.Sort("-_id")
+		}
+	} else {
+		if order == "asc" {
+			q = q.Sort(sortBy, "_id")
+		} else {
+			q = q.Sort("-"+sortBy, "-_id")
+		}
+	}
+
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	// Collect the results.
+	var allResults []types.Deployment
+	err = q.All(&allResults)
+	if err != nil {
+		err = errors.Wrap(err, "mongo find failed")
+		return
+	}
+
+	// Compute the next cursor.
+	nextCursor := ""
+	if limit > 0 && len(allResults) == limit {
+		lastResult := allResults[len(allResults)-1]
+		nextCursor = lastResult.ID.Hex()
+
+		if sortBy != "" {
+			var encoded string
+			var b []byte
+			switch sortBy {
+			case "user":
+				b = []byte(lastResult.User)
+			case "task":
+				b = []byte(lastResult.Task)
+			case "model":
+				b = []byte(lastResult.Model)
+			case "creation-time":
+				b, err = lastResult.CreationTime.GobEncode()
+			case "status":
+				b = []byte(lastResult.Status)
+			}
+			encoded = hex.EncodeToString(b)
+			nextCursor = encoded + "-" + nextCursor
+		}
+	}
+
+	// Assemble the results.
+	result = allResults
+	cm = types.CollectionMetadata{
+		TotalResultSize:    resultSize,
+		ReturnedResultSize: len(result),
+		NextPageCursor:     nextCursor,
+	}
+	return
+}
+
+// deploymentQuery extends the query with the given deployment filters.
+func deploymentQuery(query bson.M, filters F) (bson.M, error) {
+	for k, v := range filters {
+		switch k {
+		case "id":
+			setDefault(&query, "_id", bson.M{})
+			query["_id"].(bson.M)["$in"] = v.([]bson.ObjectId)
+		case "user", "task", "model", "status":
+			setDefault(&query, k, bson.M{})
+			query[k].(bson.M)["$eq"] = v.(string)
+		case "job":
+			setDefault(&query, k, bson.M{})
+			query[k].(bson.M)["$eq"] = v.(bson.ObjectId)
+		default:
+			return nil, errors.Wrap(ErrBadInput, "invalid value of argument filters")
+		}
+	}
+	return query, nil
+}
+
+// CreateDeployment adds a given deployment to the database. The deployed task must be completed and
+// the deployment takes over the model and its schemas from it.
+func (context Context) CreateDeployment(deployment types.Deployment) (result types.Deployment, err error) {
+
+	// Validate that the task exists and is completed.
+	var task types.Task
+	task, err = context.GetTaskByID(deployment.Task)
+	if err != nil && errors.Cause(err) != ErrNotFound {
+		err = errors.Wrap(err, "error while trying to access the referenced task")
+		return
+	} else if errors.Cause(err) == ErrNotFound || task.Status != types.TaskCompleted {
+		err = errors.Wrapf(ErrBadInput,
+			"the referenced task \"%s\" does not exist or is not completed", deployment.Task)
+		return
+	}
+
+	// The model of the task may be archived, but it has to be there.
+	var module types.Module
+	module, err = context.GetModuleByID(task.Model)
+	if err != nil {
+		err = errors.Wrap(err, "error while trying to access the model of the referenced task")
+		return
+	}
+
+	// Give default values to some fields. Copy some from the task.
+	deployment.ID = bson.NewObjectId()
+	deployment.User = context.User.ID
+	deployment.Job = task.Job
+	deployment.Model = task.Model
+	deployment.SchemaIn = module.SchemaIn
+	deployment.SchemaOut = module.SchemaOut
+	deployment.Image = ""
+	deployment.CreationTime = time.Now()
+	deployment.RunningTime = types.TimeInterval{}
+	deployment.NumRequests = 0
+	deployment.Status = types.DeploymentStarting
+	deployment.StatusMessage = ""
+
+	c := context.Session.DB(context.DBName).C("deployments")
+	err = c.Insert(deployment)
+	if err != nil {
+		err = errors.Wrap(err, "mongo insert failed")
+		return
+	}
+
+	return deployment, nil
+}
+
+// UpdateDeployment updates the information about a given deployment.
+func (context Context) UpdateDeployment(id bson.ObjectId, updates map[string]interface{}) (result types.Deployment, err error) {
+
+	// Try to find the deployment so that we can read its state and correctly handle state transitions.
+	var currentDeployment types.Deployment
+	currentDeployment, err = context.GetDeploymentByID(id)
+	if err != nil {
+		err = errors.Wrap(err, "error while doing resource lookup")
+		return
+	}
+	if context.User.IsRoot() == false && currentDeployment.User != context.User.ID {
+		err = types.ErrUnauthorized
+		return
+	}
+
+	// Build the update document. Validate values.
+	valueUpdates := bson.M{}
+	for k, v := range updates {
+		switch k {
+		case "status":
+			status := v.(string)
+
+			// If the update is the same as the current state, then just skip.
+			if status == currentDeployment.Status {
+				continue
+			}
+
+			// Perform state transition validations.
+			switch status {
+			case types.DeploymentStarting:
+				if currentDeployment.Status != types.DeploymentRunning {
+					err = errors.Wrap(ErrBadInput,
+						"transition to the starting state is only allowed from the running state")
+					return
+				}
+
+			case types.DeploymentRunning:
+				if currentDeployment.Status != types.DeploymentStarting {
+					err = errors.Wrap(ErrBadInput,
+						"transition to the running state is only allowed from the starting state")
+					return
+				}
+				valueUpdates["running-time.start"] = time.Now()
+
+			case types.DeploymentStopped:
+				if currentDeployment.Status != types.DeploymentStarting &&
+					currentDeployment.Status != types.DeploymentRunning {
+					err = errors.Wrap(ErrBadInput,
+						"transition to the stopped state is only allowed from the starting or running state")
+					return
+				}
+				valueUpdates["running-time.end"] = time.Now()
+				valueUpdates["process"] = nil
+
+			case types.DeploymentError:
+				valueUpdates["running-time.end"] = time.Now()
+				valueUpdates["process"] = nil
+
+			default:
+				err = errors.Wrapf(ErrBadInput,
+					"value of status can be \"%s\", \"%s\", \"%s\" or \"%s\", but found \"%s\"",
+					types.DeploymentStarting, types.DeploymentRunning, types.DeploymentStopped, types.DeploymentError, status)
+				return
+			}
+
+			// If the new status has passed validation, set it.
+			valueUpdates["status"] = status
+
+		case "status-message":
+			valueUpdates["status-message"] = v.(string)
+
+		case "image":
+			valueUpdates["image"] = v.(string)
+
+		default:
+			err = errors.Wrap(ErrBadInput, "invalid value of parameter updates")
+			return
+		}
+	}
+
+	// If there were no updates, then we can skip this step.
+	if len(valueUpdates) > 0 {
+		c := context.Session.DB(context.DBName).C("deployments")
+		err = c.Update(bson.M{"_id": id}, bson.M{"$set": valueUpdates})
+		if err != nil {
+			err = errors.Wrap(err, "mongo update failed")
+			return
+		}
+	}
+
+	// Get the updated deployment.
+	result, err = context.GetDeploymentByID(id)
+	if err != nil {
+		err = errors.Wrap(err, "deployment get by ID failed")
+		return
+	}
+
+	return
+}
+
+// UpdateDeploymentStatus sets the status of the deployment and assigns the given status message.
+func (context Context) UpdateDeploymentStatus(id bson.ObjectId, status string, statusMessage string) (err error) {
+	_, err = context.UpdateDeployment(id, F{"status": status, "status-message": statusMessage})
+	return
+}
+
+// CountDeploymentRequest increments the number of prediction requests served by a deployment.
+func (context Context) CountDeploymentRequest(id bson.ObjectId) (err error) {
+
+	c := context.Session.DB(context.DBName).C("deployments")
+	err = c.Update(bson.M{"_id": id}, bson.M{"$inc": bson.M{"num-requests": 1}})
+	if err == mgo.ErrNotFound {
+		err = ErrNotFound
+		return
+	} else if err != nil {
+		err = errors.Wrap(err, "mongo update failed")
+		return
+	}
+
+	return
+}
+
+// LockDeployment scans the available deployments (that are not currently locked), applies the specified filters,
+// sorts them if specified and locks the first one by assigning it to the specified process.
+func (context Context) LockDeployment(
+	filters F,
+	processID bson.ObjectId,
+	sortBy string,
+	order string,
+) (result types.Deployment, err error) {
+	c := context.Session.DB(context.DBName).C("deployments")
+
+	// We are looking only for instances that are not already locked.
+	query := bson.M{"process": nil}
+
+	// If the user is not root then we need to limit access.
+	if context.User.IsRoot() == false {
+		query["user"] = bson.M{"$in": []string{context.User.ID, types.UserRoot}}
+	}
+
+	// Build a query given the parameters.
+	query, err = deploymentQuery(query, filters)
+	if err != nil {
+		return
+	}
+
+	// Build the query.
+	q := c.Find(query)
+
+	// We always sort by _id, but we may also sort by a specific field.
+	if sortBy == "" {
+		if order == "asc" {
+			q = q.Sort("_id")
+		} else {
+			q = q.Sort("-_id")
+		}
+	} else {
+		if order == "asc" {
+			q = q.Sort(sortBy, "_id")
+		} else {
+			q = q.Sort("-"+sortBy, "-_id")
+		}
+	}
+
+	q = q.Limit(1)
+
+	change := mgo.Change{Update: bson.M{"$set": bson.M{"process": processID}}, ReturnNew: false}
+
+	var oneResult types.Deployment
+	var changeInfo *mgo.ChangeInfo
+	changeInfo, err = q.Apply(change, &oneResult)
+	if err == mgo.ErrNotFound || changeInfo.Updated < 1 {
+		err = ErrNotFound
+		return
+	} else if err != nil {
+		err = errors.Wrap(err, "mongo find failed")
+		return
+	} else if changeInfo.Updated > 1 {
+		// Fail safe. This should never happen.
+		panic(changeInfo)
+	}
+
+	return oneResult, nil
+}
+
+// UnlockDeployment releases the lock on a given deployment.
+func (context Context) UnlockDeployment(id bson.ObjectId, processID bson.ObjectId) (err error) {
+
+	c := context.Session.DB(context.DBName).C("deployments")
+	err = c.Update(bson.M{"_id": id, "process": processID}, bson.M{"$set": bson.M{"process": nil}})
+	if err == mgo.ErrNotFound {
+		err = ErrNotFound
+		return
+	} else if err != nil {
+		err = errors.Wrap(err, "mongo update failed")
+		return
+	}
+
+	return
+}
+
+// ReleaseDeploymentLockByProcess releases all deployments that have been locked by a given process and
+// are not in the error state. Running deployments are put back in the starting state so that another
+// process would load their models again.
+func (context Context) ReleaseDeploymentLockByProcess(processID bson.ObjectId) (numReleased int, err error) {
+
+	c := context.Session.DB(context.DBName).C("deployments")
+	var changeInfo *mgo.ChangeInfo
+	changeInfo, err = c.UpdateAll(
+		bson.M{"process": processID, "status": bson.M{"$in": []string{types.DeploymentStarting, types.DeploymentRunning}}},
+		bson.M{"$set": bson.M{"process": nil, "status": types.DeploymentStarting}},
+	)
+	if err == mgo.ErrNotFound {
+		err = ErrNotFound
+		return
+	} else if err != nil {
+		err = errors.Wrap(err, "mongo update failed")
+		return
+	}
+
+	return changeInfo.Updated, nil
+}
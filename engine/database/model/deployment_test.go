@@ -0,0 +1,110 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ds3lab/easeml/engine/database"
+	"github.com/ds3lab/easeml/engine/database/model/types"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentLifecycle(t *testing.T) {
+	assert := assert.New(t)
+
+	// Establish a connection.
+	connection, err := database.Connect(MongoInstance, TestDBName)
+	assert.Nil(err)
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+	jobID := bson.NewObjectId()
+	var module = types.Module{
+		ObjectID:  bson.NewObjectId(),
+		ID:        "root/model1",
+		User:      "root",
+		Type:      types.ModuleModel,
+		SchemaIn:  "schema-in",
+		SchemaOut: "schema-out",
+		Status:    types.ModuleActive,
+	}
+	var task1 = types.Task{
+		ObjectID:      bson.NewObjectId(),
+		ID:            jobID.Hex() + "/1",
+		Job:           jobID,
+		User:          "root",
+		Dataset:       "root/dataset1",
+		Model:         "root/model1",
+		Objective:     "root/objective1",
+		AltObjectives: []string{},
+		AltQualities:  []float64{},
+		CreationTime:  time.Now(),
+		Status:        types.TaskCompleted,
+	}
+	var task2 = task1
+	task2.ObjectID = bson.NewObjectId()
+	task2.ID = jobID.Hex() + "/2"
+	task2.Status = types.TaskRunning
+	var context = Context{Session: connection.Session, DBName: connection.DBName, User: types.User{ID: types.UserRoot}}
+
+	// Add a test model and tasks to the test database.
+	err = connection.Session.DB(TestDBName).C("modules").Insert(module)
+	assert.Nil(err)
+	err = connection.Session.DB(TestDBName).C("tasks").Insert(task1, task2)
+	assert.Nil(err)
+
+	// Only completed tasks can be deployed.
+	_, err = context.CreateDeployment(types.Deployment{Task: task2.ID})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	_, err = context.CreateDeployment(types.Deployment{Task: jobID.Hex() + "/3"})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+
+	deployment, err := context.CreateDeployment(types.Deployment{Task: task1.ID})
+	assert.Nil(err)
+	assert.Equal(types.DeploymentStarting, deployment.Status)
+	assert.Equal("root/model1", deployment.Model)
+	assert.Equal("schema-in", deployment.SchemaIn)
+	assert.Equal(jobID, deployment.Job)
+
+	result, cm, err := context.GetDeployments(F{"task": task1.ID}, 0, "", "", "")
+	assert.Nil(err)
+	assert.Equal(1, cm.TotalResultSize)
+	assert.Equal(deployment.ID, result[0].ID)
+
+	// The deployment is started by the process which locks it.
+	processID := bson.NewObjectId()
+	locked, err := context.LockDeployment(F{"status": types.DeploymentStarting}, processID, "", "")
+	assert.Nil(err)
+	assert.Equal(deployment.ID, locked.ID)
+	_, err = context.LockDeployment(F{"status": types.DeploymentStarting}, processID, "", "")
+	assert.Equal(ErrNotFound, errors.Cause(err))
+	err = context.UpdateDeploymentStatus(deployment.ID, types.DeploymentRunning, "")
+	assert.Nil(err)
+
+	// If the process dies, the deployment has to be started again.
+	numReleased, err := context.ReleaseDeploymentLockByProcess(processID)
+	assert.Nil(err)
+	assert.Equal(1, numReleased)
+	deployment, err = context.GetDeploymentByID(deployment.ID)
+	assert.Nil(err)
+	assert.Equal(types.DeploymentStarting, deployment.Status)
+
+	// Stopped deployments cannot be started again.
+	err = context.UpdateDeploymentStatus(deployment.ID, types.DeploymentStopped, "")
+	assert.Nil(err)
+	err = context.UpdateDeploymentStatus(deployment.ID, types.DeploymentRunning, "")
+	assert.Equal(ErrBadInput, errors.Cause(err))
+
+	err = context.CountDeploymentRequest(deployment.ID)
+	assert.Nil(err)
+	deployment, err = context.GetDeploymentByID(deployment.ID)
+	assert.Nil(err)
+	assert.Equal(uint64(1), deployment.NumRequests)
+	assert.True(deployment.IsEnded())
+
+	// Drop the test database.
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+}
@@ -55,6 +55,8 @@ func (context Context) Initialize(databaseName string) (err error) {
 			mgo.Index{Key: []string{"status"}},
 			mgo.Index{Key: []string{"process"}},
 			mgo.Index{Key: []string{"creation-time"}},
+			mgo.Index{Key: []string{"schema-in-hash"}},
+			mgo.Index{Key: []string{"schema-out-hash"}},
 		},
 		"modules": []mgo.Index{
 			mgo.Index{Key: []string{"id"}, Unique: true},
@@ -62,6 +64,8 @@ func (context Context) Initialize(databaseName string) (err error) {
 			mgo.Index{Key: []string{"type"}},
 			mgo.Index{Key: []string{"status"}},
 			mgo.Index{Key: []string{"process"}},
+			mgo.Index{Key: []string{"schema-in-hash"}},
+			mgo.Index{Key: []string{"schema-out-hash"}},
 		},
 		"jobs": []mgo.Index{
 			mgo.Index{Key: []string{"user"}},
@@ -81,6 +85,14 @@ func (context Context) Initialize(databaseName string) (err error) {
 			mgo.Index{Key: []string{"user"}},
 			mgo.Index{Key: []string{"status"}},
 			mgo.Index{Key: []string{"stage"}},
+			mgo.Index{Key: []string{"job", "model", "config"}},
+		},
+		"deployments": []mgo.Index{
+			mgo.Index{Key: []string{"user"}},
+			mgo.Index{Key: []string{"task"}},
+			mgo.Index{Key: []string{"model"}},
+			mgo.Index{Key: []string{"status"}},
+			mgo.Index{Key: []string{"process"}},
 		},
 	}
 
@@ -144,6 +156,14 @@ func (context Context) Initialize(databaseName string) (err error) {
 		}
 	}
 
+	// Compute structural schema hashes of documents created before they were stored.
+	for _, collection := range []string{"datasets", "modules"} {
+		err = backfillSchemaHashes(db.C(collection))
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create the root user if needed.
 	_, err = context.GetUserByID(types.UserRoot)
 	if err == ErrNotFound {
@@ -162,3 +182,38 @@ func (context Context) Initialize(databaseName string) (err error) {
 
 	return nil
 }
+
+// backfillSchemaHashes computes the missing schema hashes of all documents in the given collection.
+func backfillSchemaHashes(c *mgo.Collection) (err error) {
+	query := bson.M{"$or": []bson.M{
+		bson.M{"schema-in": bson.M{"$nin": []interface{}{"", nil}}, "schema-in-hash": bson.M{"$exists": false}},
+		bson.M{"schema-out": bson.M{"$nin": []interface{}{"", nil}}, "schema-out-hash": bson.M{"$exists": false}},
+	}}
+	var documents []struct {
+		ObjectID  bson.ObjectId `bson:"_id"`
+		SchemaIn  string        `bson:"schema-in"`
+		SchemaOut string        `bson:"schema-out"`
+	}
+	err = c.Find(query).All(&documents)
+	if err != nil {
+		return errors.Wrap(err, "mongo find failed")
+	}
+
+	for i := range documents {
+		var hashIn, hashOut string
+		hashIn, err = structuralSchemaHash(documents[i].SchemaIn)
+		if err != nil {
+			return errors.Wrapf(err, "schema hash of document %s failed", documents[i].ObjectID.Hex())
+		}
+		hashOut, err = structuralSchemaHash(documents[i].SchemaOut)
+		if err != nil {
+			return errors.Wrapf(err, "schema hash of document %s failed", documents[i].ObjectID.Hex())
+		}
+		err = c.UpdateId(documents[i].ObjectID, bson.M{"$set": bson.M{"schema-in-hash": hashIn, "schema-out-hash": hashOut}})
+		if err != nil {
+			return errors.Wrap(err, "mongo update failed")
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/ds3lab/easeml/engine/utils"
 	"testing"
 
@@ -9,6 +11,7 @@ import (
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,7 +51,7 @@ func TestInitialize(t *testing.T) {
 	// Verify that all the collections have been created.
 	names, err := connection.Session.DB("testdb").CollectionNames()
 	assert.Nil(err)
-	assert.ElementsMatch(names, []string{"users", "processes", "datasets", "modules", "jobs", "tasks"})
+	assert.ElementsMatch(names, []string{"users", "processes", "datasets", "modules", "jobs", "tasks", "deployments"})
 
 	// Verify that the root user has been created.
 	n, err := connection.Session.DB("testdb").C("users").Find(bson.M{"id": types.UserRoot}).Count()
@@ -59,3 +62,50 @@ func TestInitialize(t *testing.T) {
 	err = connection.Session.DB("testdb").DropDatabase()
 	assert.Nil(err)
 }
+
+func TestInitializeDuplicateTasks(t *testing.T) {
+	assert := assert.New(t)
+	var MongoInstance = utils.GetEnvVariableOrDefault("EASEML_DATABASE_ADDRESS", "localhost")
+	connection, err := database.Connect(MongoInstance, "testdb")
+	assert.Nil(err)
+	err = connection.Session.DB("testdb").DropDatabase()
+	assert.Nil(err)
+
+	// Databases created before task configs were canonicalized can hold duplicate tasks.
+	job := types.Job{ID: bson.NewObjectId(), User: "root", Models: []string{"root/model1"}, Status: types.JobRunning}
+	configs := []string{`{"b": 2, "a": 1.0}`, `{"a":1,"b":2}`, `{"a":1,"b":2}`}
+	c := connection.Session.DB("testdb").C("tasks")
+	for i := range configs {
+		task := types.Task{ObjectID: bson.NewObjectId(), ID: fmt.Sprintf("%s/%010d", job.ID.Hex(), i+1), Job: job.ID,
+			User: "root", Model: "root/model1", Config: configs[i], Status: types.TaskCompleted}
+		assert.Nil(c.Insert(task))
+	}
+	assert.Nil(connection.Session.DB("testdb").C("jobs").Insert(job))
+
+	// Initialization must not fail on them.
+	var context = Context{Session: connection.Session, DBName: connection.DBName, User: types.User{ID: types.UserRoot}}
+	err = context.Initialize("testdb")
+	assert.Nil(err)
+
+	// After canonicalization all configs can be compared as they are.
+	canonical := func(config interface{}) (string, error) {
+		result, err := json.Marshal(config)
+		return string(result), err
+	}
+	numUpdated, err := context.CanonicalizeTaskConfigs(canonical)
+	assert.Nil(err)
+	assert.Equal(1, numUpdated)
+	n, err := c.Find(bson.M{"job": job.ID, "config": `{"a":1,"b":2}`}).Count()
+	assert.Nil(err)
+	assert.Equal(3, n)
+
+	// New duplicates are refused, other configs are accepted.
+	_, err = context.CreateTask(types.Task{Job: job.ID, Model: "root/model1", Config: `{"a":1,"b":2}`})
+	assert.Equal(ErrDuplicate, errors.Cause(err))
+	_, err = context.CreateTask(types.Task{Job: job.ID, Model: "root/model1", Config: `{"a":2,"b":2}`})
+	assert.Nil(err)
+
+	// Drop the test database.
+	err = connection.Session.DB("testdb").DropDatabase()
+	assert.Nil(err)
+}
@@ -88,9 +88,17 @@ func (context Context) GetJobs(
 		case "id":
 			setDefault(&query, "_id", bson.M{})
 			query["_id"].(bson.M)["$in"] = v.([]bson.ObjectId)
-		case "user", "dataset", "objective", "status":
+		case "user", "dataset", "objective", "status", "task":
 			setDefault(&query, k, bson.M{})
 			query[k].(bson.M)["$eq"] = v.(string)
+		case "kind":
+			// Jobs without a kind are training jobs.
+			setDefault(&query, k, bson.M{})
+			if v.(string) == types.JobTrain {
+				query[k].(bson.M)["$ne"] = types.JobPredict
+			} else {
+				query[k].(bson.M)["$eq"] = v.(string)
+			}
 		case "accept-new-models":
 			setDefault(&query, k, bson.M{})
 			query[k].(bson.M)["$eq"] = v.(bool)
@@ -250,9 +258,17 @@ func (context Context) CountJobs(filters F) (count int, err error) {
 		case "id":
 			setDefault(&query, "_id", bson.M{})
 			query["_id"].(bson.M)["$in"] = v.([]bson.ObjectId)
-		case "user", "dataset", "objective", "status":
+		case "user", "dataset", "objective", "status", "task":
 			setDefault(&query, k, bson.M{})
 			query[k].(bson.M)["$eq"] = v.(string)
+		case "kind":
+			// Jobs without a kind are training jobs.
+			setDefault(&query, k, bson.M{})
+			if v.(string) == types.JobTrain {
+				query[k].(bson.M)["$ne"] = types.JobPredict
+			} else {
+				query[k].(bson.M)["$eq"] = v.(string)
+			}
 		case "accept-new-models":
 			setDefault(&query, k, bson.M{})
 			query[k].(bson.M)["$eq"] = v.(bool)
@@ -280,6 +296,15 @@ func (context Context) CountJobs(filters F) (count int, err error) {
 // CreateJob adds a given job to the database.
 func (context Context) CreateJob(job types.Job) (result types.Job, err error) {
 
+	// Prediction jobs are validated separately as they have no objective and models to choose from.
+	if job.Kind == types.JobPredict {
+		return context.createPredictionJob(job)
+	} else if job.Kind != "" && job.Kind != types.JobTrain {
+		err = errors.Wrapf(ErrBadInput,
+			"value of kind can be \"%s\" or \"%s\", but found \"%s\"", types.JobTrain, types.JobPredict, job.Kind)
+		return
+	}
+
 	// Validate that the dataset exists and is active.
 	var dataset types.Dataset
 	dataset, err = context.GetDatasetByID(job.Dataset)
@@ -288,7 +313,16 @@ func (context Context) CreateJob(job types.Job) (result types.Job, err error) {
 		return
 	} else if err == ErrNotFound || dataset.Status != types.DatasetValidated {
 		err = errors.Wrapf(ErrBadInput,
-			"the referenced objective \"%s\" does not exist or is not verified and active", job.Dataset)
+			"the referenced dataset \"%s\" does not exist or is not verified and active", job.Dataset)
+		return
+	} else if dataset.Source == types.DatasetPrediction {
+		err = errors.Wrapf(ErrBadInput,
+			"the referenced dataset \"%s\" contains predictions so it cannot be used for training", job.Dataset)
+		return
+	} else if dataset.SchemaOut == "" {
+		err = errors.Wrapf(ErrBadInput,
+			"the referenced dataset \"%s\" has no outputs so it can only be used for predictions", job.Dataset)
+		return
 	}
 
 	// Validate that the objective exists and is active.
@@ -325,9 +359,25 @@ func (context Context) CreateJob(job types.Job) (result types.Job, err error) {
 		}
 	}
 
+	// Validate the sampler. Jobs without a sampler get their tasks from the optimizer.
+	if job.Sampler != "" {
+		var found bool
+		for i := range types.Samplers {
+			if job.Sampler == types.Samplers[i] {
+				found = true
+				break
+			}
+		}
+		if found == false {
+			err = errors.Wrapf(ErrBadInput, "the sampler \"%s\" is not one of %s", job.Sampler, strings.Join(types.Samplers, ", "))
+			return
+		}
+	}
+
 	// Give default values to some fields.
 	job.ID = bson.NewObjectId()
 	job.User = context.User.ID
+	job.Kind = types.JobTrain
 	job.CreationTime = time.Now()
 	job.Status = types.JobScheduled
 	job.PauseDuration = 0
@@ -339,21 +389,14 @@ func (context Context) CreateJob(job types.Job) (result types.Job, err error) {
 
 	// Validate that the models exist and are active.
 	if len(job.Models) > 0 {
-		var models []types.Module
-		models, _, err = context.GetModules(F{"id": job.Models}, 0, "", "", "")
+		var models map[string]types.Module
+		models, err = context.getJobModels(job.Models)
 		if err != nil {
 			err = errors.Wrap(err, "error while trying to access the referenced models")
 			return
 		}
 		for i := range job.Models {
-			var found bool
-			for j := range models {
-				if job.Models[i] == models[j].ID && models[j].Status == types.ModuleActive {
-					found = true
-					break
-				}
-			}
-			if found == false {
+			if module, ok := models[job.Models[i]]; ok == false || module.Status != types.ModuleActive {
 				err = errors.Wrapf(ErrBadInput,
 					"the referenced model \"%s\" does not exist or is not active", job.Models[i])
 			}
@@ -382,6 +425,154 @@ func (context Context) CreateJob(job types.Job) (result types.Job, err error) {
 
 }
 
+// createPredictionJob adds a job which applies the model of a completed task to a dataset that contains only
+// inputs. The task can be given directly or through a deployment of it. The predictions are stored as a new
+// dataset which is identified by the predictions field. It is generated from the job identifier if empty.
+func (context Context) createPredictionJob(job types.Job) (result types.Job, err error) {
+
+	// The deployment determines the task if it is given.
+	if job.Deployment != "" {
+		var deployment types.Deployment
+		deployment, err = context.GetDeploymentByID(job.Deployment)
+		if err == ErrNotFound {
+			err = errors.Wrapf(ErrBadInput, "the referenced deployment \"%s\" does not exist", job.Deployment.Hex())
+			return
+		} else if err != nil {
+			err = errors.Wrap(err, "error while trying to access the referenced deployment")
+			return
+		}
+		if job.Task != "" && job.Task != deployment.Task {
+			err = errors.Wrapf(ErrBadInput, "the referenced deployment \"%s\" does not serve the task \"%s\"", job.Deployment.Hex(), job.Task)
+			return
+		}
+		job.Task = deployment.Task
+	}
+
+	// Validate that the task exists and is completed.
+	var task types.Task
+	task, err = context.GetTaskByID(job.Task)
+	if err != nil && err != ErrNotFound {
+		err = errors.Wrap(err, "error while trying to access the referenced task")
+		return
+	} else if err == ErrNotFound || task.Status != types.TaskCompleted {
+		err = errors.Wrapf(ErrBadInput, "the referenced task \"%s\" does not exist or is not completed", job.Task)
+		return
+	}
+	var model types.Module
+	model, err = context.GetModuleByID(task.Model)
+	if err != nil {
+		err = errors.Wrap(err, "error while trying to access the model of the task")
+		return
+	}
+
+	// Validate that the dataset exists, contains only inputs and that they match the model.
+	var dataset types.Dataset
+	dataset, err = context.GetDatasetByID(job.Dataset)
+	if err != nil && err != ErrNotFound {
+		err = errors.Wrap(err, "error while trying to access the referenced dataset")
+		return
+	} else if err == ErrNotFound || dataset.Status != types.DatasetValidated {
+		err = errors.Wrapf(ErrBadInput,
+			"the referenced dataset \"%s\" does not exist or is not verified and active", job.Dataset)
+		return
+	} else if dataset.SchemaOut != "" {
+		err = errors.Wrapf(ErrBadInput, "the referenced dataset \"%s\" must contain only inputs", job.Dataset)
+		return
+	}
+	var match bool
+	match, _, err = matchSchemasCached(model.SchemaIn, dataset.SchemaIn)
+	if err != nil {
+		err = errors.Wrap(err, "error while matching the dataset with the model")
+		return
+	} else if match == false {
+		err = errors.Wrapf(ErrBadInput,
+			"the input schema of the dataset \"%s\" does not match the input schema of the model \"%s\"", job.Dataset, model.ID)
+		return
+	}
+
+	// Give default values to some fields.
+	job.ID = bson.NewObjectId()
+	job.User = context.User.ID
+	job.Models = []string{task.Model}
+	job.ConfigSpace = ""
+	job.AcceptNewModels = false
+	job.Objective = ""
+	job.AltObjectives = []string{}
+	job.MaxTasks = 0
+	job.Sampler = ""
+	job.CreationTime = time.Now()
+	job.Status = types.JobScheduled
+	job.PauseDuration = 0
+	job.RunningDuration = 0 // This field will be omitted when empty.
+
+	// The predictions dataset is added right away to reserve its identifier. It remains in the created state
+	// until the predictions are made.
+	if job.Predictions == "" {
+		job.Predictions = fmt.Sprintf("predictions-%s", job.ID.Hex())
+	}
+	ids := strings.Split(job.Predictions, "/")
+	if len(ids) == 1 {
+		job.Predictions = fmt.Sprintf("%s/%s", context.User.ID, job.Predictions)
+	} else if len(ids) != 2 || ids[0] != context.User.ID {
+		err = errors.Wrap(ErrBadInput, "the predictions must be of the format dataset-id or user-id/dataset-id")
+		return
+	}
+	predictions := types.Dataset{
+		ObjectID:      bson.NewObjectId(),
+		ID:            job.Predictions,
+		User:          context.User.ID,
+		Name:          fmt.Sprintf("Predictions of %s", dataset.Name),
+		Description:   fmt.Sprintf("Predictions of the task %s on the dataset %s.", job.Task, job.Dataset),
+		Source:        types.DatasetPrediction,
+		SourceAddress: job.ID.Hex(),
+		CreationTime:  job.CreationTime,
+		Status:        types.DatasetCreated,
+	}
+	c := context.Session.DB(context.DBName).C("datasets")
+	err = c.Insert(predictions)
+	if err != nil {
+		lastError, ok := err.(*mgo.LastError)
+		if ok && lastError.Code == 11000 {
+			err = errors.Wrapf(ErrBadInput, "the predictions dataset \"%s\" already exists", job.Predictions)
+			return
+		}
+		err = errors.Wrap(err, "mongo insert failed")
+		return
+	}
+	job.Kind = types.JobPredict
+
+	// The predictions dataset must not outlive a job which could not be added.
+	err = context.Session.DB(context.DBName).C("jobs").Insert(job)
+	if err != nil {
+		err = errors.Wrap(err, "mongo insert failed")
+		if removeErr := c.RemoveId(predictions.ObjectID); removeErr != nil {
+			err = errors.Wrapf(err, "predictions dataset \"%s\" removal failed: %s", job.Predictions, removeErr.Error())
+		}
+		return
+	}
+
+	return job, nil
+}
+
+// getJobModels resolves the model references of a job to model versions. A reference without a version
+// resolves to the latest active version of the model. References which cannot be resolved are left out.
+func (context Context) getJobModels(refs []string) (result map[string]types.Module, err error) {
+
+	result = map[string]types.Module{}
+	for i := range refs {
+		var module types.Module
+		module, err = context.ResolveModuleVersion(refs[i])
+		if errors.Cause(err) == ErrNotFound || errors.Cause(err) == ErrBadInput {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		result[refs[i]] = module
+	}
+
+	return result, nil
+}
+
 // GetJobConfigSpaceByID searches for a job by ID and builds a complete config space given its models.
 func (context Context) GetJobConfigSpaceByID(id bson.ObjectId) (configSpace string, err error) {
 
@@ -401,8 +592,8 @@ func (context Context) GetJobConfigSpaceByID(id bson.ObjectId) (configSpace stri
 func (context Context) GetJobConfigSpace(job types.Job) (configSpace string, err error) {
 
 	// Get all the modules of this job.
-	var models []types.Module
-	models, _, err = context.GetModules(F{"id": job.Models}, 0, "", "", "")
+	var models map[string]types.Module
+	models, err = context.getJobModels(job.Models)
 	if err != nil {
 		err = errors.Wrap(err, "error while trying to access the referenced models")
 		return
@@ -435,17 +626,16 @@ func (context Context) GetJobConfigSpace(job types.Job) (configSpace string, err
 
 	// Build the config space by building a .choice structure above the model config spaces.
 	configSpaceList := make([]string, len(job.Models))
+	// The config space refers to the models as the job does so that the tasks follow the latest version
+	// of the models which are not pinned to a version.
 	for i := range job.Models {
-		for j := range models {
-			if job.Models[i] == models[j].ID {
-				// If the model config space was redefined, then we use that instead of the default.
-				if configDef, ok := redefinedConfigSpaces[models[j].ID]; ok {
-					// TODO: Validate the redefined config space by checking that it is a subset of the default.
-					configSpaceList[i] = configDef
-				} else {
-					configSpaceList[i] = fmt.Sprintf("{\"id\" : \"%s\", \"config\" : %s }", models[j].ID, models[j].ConfigSpace)
-				}
-				break
+		if module, ok := models[job.Models[i]]; ok {
+			// If the model config space was redefined, then we use that instead of the default.
+			if configDef, ok := redefinedConfigSpaces[job.Models[i]]; ok {
+				// TODO: Validate the redefined config space by checking that it is a subset of the default.
+				configSpaceList[i] = configDef
+			} else {
+				configSpaceList[i] = fmt.Sprintf("{\"id\" : \"%s\", \"config\" : %s }", job.Models[i], module.ConfigSpace)
 			}
 		}
 	}
@@ -472,6 +662,13 @@ func (context Context) UpdateJob(id bson.ObjectId, updates map[string]interface{
 	// Build the update document. Validate values.
 	valueUpdates := bson.M{}
 	for k, v := range updates {
+
+		// The model of a prediction job is given by its task.
+		if currentJob.IsPredict() && (k == "models" || k == "accept-new-models" || k == "max-tasks") {
+			err = errors.Wrapf(ErrBadInput, "the %s of a prediction job cannot be changed", k)
+			return
+		}
+
 		switch k {
 		case "models":
 			// TODO: Maybe check that no models have been removed.
@@ -481,23 +678,17 @@ func (context Context) UpdateJob(id bson.ObjectId, updates map[string]interface{
 
 			// Validate that the models exist and are active.
 			if len(updateModels) > 0 {
-				var foundModels []types.Module
-				foundModels, _, err = context.GetModules(F{"id": updateModels}, 0, "", "", "")
+				var foundModels map[string]types.Module
+				foundModels, err = context.getJobModels(updateModels)
 				if err != nil {
 					err = errors.Wrap(err, "error while trying to access the referenced models")
 					return
 				}
 				configSpaceList := []string{}
 				for i := range updateModels {
-					var found bool
-					for j := range foundModels {
-						if updateModels[i] == foundModels[j].ID && foundModels[j].Status == types.ModuleActive {
-							configSpaceList = append(configSpaceList, fmt.Sprintf("{\"id\" : \"%s\", \"config\" : %s }", foundModels[j].ID, foundModels[j].ConfigSpace))
-							found = true
-							break
-						}
-					}
-					if found == false {
+					if module, ok := foundModels[updateModels[i]]; ok && module.Status == types.ModuleActive {
+						configSpaceList = append(configSpaceList, fmt.Sprintf("{\"id\" : \"%s\", \"config\" : %s }", updateModels[i], module.ConfigSpace))
+					} else {
 						err = errors.Wrapf(ErrBadInput,
 							"the referenced model \"%s\" does not exist or is active", updateModels)
 					}
@@ -544,6 +735,10 @@ func (context Context) UpdateJob(id bson.ObjectId, updates map[string]interface{
 				}
 
 			case types.JobPausing:
+				if currentJob.IsPredict() {
+					err = errors.Wrap(ErrBadInput, "prediction jobs cannot be paused")
+					return
+				}
 				if currentJob.Status != types.JobRunning {
 					err = errors.Wrap(ErrBadInput,
 						"transition to the pausing state is only allowed from the running state")
@@ -575,9 +770,11 @@ func (context Context) UpdateJob(id bson.ObjectId, updates map[string]interface{
 				valueUpdates["running-time.end"] = time.Now()
 
 			case types.JobTerminating:
+				// Prediction jobs wait in the scheduled state until a worker picks them up.
 				if currentJob.Status != types.JobRunning &&
 					currentJob.Status != types.JobPausing &&
-					currentJob.Status != types.JobPaused {
+					currentJob.Status != types.JobPaused &&
+					(currentJob.Status != types.JobScheduled || currentJob.IsPredict() == false) {
 					err = errors.Wrap(ErrBadInput,
 						"transition to the terminating state is only allowed from the running, pausing or paused state")
 					return
@@ -662,9 +859,17 @@ func (context Context) LockJob(
 		case "id":
 			setDefault(&query, "_id", bson.M{})
 			query["_id"].(bson.M)["$in"] = v.([]bson.ObjectId)
-		case "user", "dataset", "objective", "status":
+		case "user", "dataset", "objective", "status", "task":
 			setDefault(&query, k, bson.M{})
 			query[k].(bson.M)["$eq"] = v.(string)
+		case "kind":
+			// Jobs without a kind are training jobs.
+			setDefault(&query, k, bson.M{})
+			if v.(string) == types.JobTrain {
+				query[k].(bson.M)["$ne"] = types.JobPredict
+			} else {
+				query[k].(bson.M)["$eq"] = v.(string)
+			}
 		case "accept-new-models":
 			setDefault(&query, k, bson.M{})
 			query[k].(bson.M)["$eq"] = v.(bool)
@@ -775,11 +980,24 @@ func (context Context) AddModelToApplicableJobs(module types.Module) (err error)
 		return
 	}
 
-	// Add the given model to all those jobs.
+	// Add the given model to all those jobs. Jobs which already reference a version of the model
+	// are left as they are. Other jobs follow the latest version of the model.
+	moduleID, _ := SplitModuleVersion(module.ID)
 	for i := range jobs {
 
+		var referenced bool
+		for j := range jobs[i].Models {
+			if refID, _ := SplitModuleVersion(jobs[i].Models[j]); refID == moduleID {
+				referenced = true
+				break
+			}
+		}
+		if referenced {
+			continue
+		}
+
 		// Extend the list of models.
-		extendedModels := append(jobs[i].Models, module.ID)
+		extendedModels := append(jobs[i].Models, moduleID)
 
 		// Update the job.
 		_, err = context.UpdateJob(jobs[i].ID, F{"models": extendedModels})
@@ -793,11 +1011,24 @@ func (context Context) AddModelToApplicableJobs(module types.Module) (err error)
 }
 
 // ReleaseJobLockByProcess releases all jobs that have been locked by a given process and
-// are not in the error state.
+// are not in the error state. Running prediction jobs are scheduled again.
 func (context Context) ReleaseJobLockByProcess(processID bson.ObjectId) (numReleased int, err error) {
 
 	c := context.Session.DB(context.DBName).C("jobs")
 	var changeInfo *mgo.ChangeInfo
+	changeInfo, err = c.UpdateAll(
+		bson.M{"process": processID, "kind": types.JobPredict, "status": types.JobRunning},
+		bson.M{"$set": bson.M{"process": nil, "status": types.JobScheduled}},
+	)
+	if err == mgo.ErrNotFound {
+		err = ErrNotFound
+		return
+	} else if err != nil {
+		err = errors.Wrap(err, "mongo update failed")
+		return
+	}
+	numReleased = changeInfo.Updated
+
 	changeInfo, err = c.UpdateAll(
 		bson.M{"process": processID, "status": bson.M{"$ne": types.JobError}},
 		bson.M{"$set": bson.M{"process": nil}},
@@ -810,5 +1041,5 @@ func (context Context) ReleaseJobLockByProcess(processID bson.ObjectId) (numRele
 		return
 	}
 
-	return changeInfo.Updated, nil
+	return numReleased + changeInfo.Updated, nil
 }
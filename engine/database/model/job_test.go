@@ -353,3 +353,127 @@ func TestLockJobs(t *testing.T) {
 	err = connection.Session.DB(TestDBName).DropDatabase()
 	assert.Nil(err)
 }
+
+func TestPredictionJob(t *testing.T) {
+	assert := assert.New(t)
+
+	// Establish a connection.
+	connection, err := database.Connect(MongoInstance, TestDBName)
+	assert.Nil(err)
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+	schemaIn := `{"nodes":{"x":{"singleton":true,"type":"tensor","dim":[2]}}}`
+	schemaOut := `{"nodes":{"y":{"singleton":true,"type":"tensor","dim":[1]}}}`
+	var module = types.Module{
+		ObjectID:  bson.NewObjectId(),
+		ID:        "root/model1",
+		User:      "root",
+		Type:      types.ModuleModel,
+		SchemaIn:  schemaIn,
+		SchemaOut: schemaOut,
+		Status:    types.ModuleActive,
+	}
+	var inputs = types.Dataset{
+		ObjectID: bson.NewObjectId(),
+		ID:       "root/inputs",
+		User:     "root",
+		SchemaIn: schemaIn,
+		Status:   types.DatasetValidated,
+	}
+	var other = inputs
+	other.ObjectID = bson.NewObjectId()
+	other.ID = "root/other"
+	other.SchemaIn = `{"nodes":{"x":{"singleton":true,"type":"tensor","dim":[3]}}}`
+	var full = inputs
+	full.ObjectID = bson.NewObjectId()
+	full.ID = "root/full"
+	full.SchemaOut = schemaOut
+	var predicted = full
+	predicted.ObjectID = bson.NewObjectId()
+	predicted.ID = "root/predicted"
+	predicted.Source = types.DatasetPrediction
+	trainJob := types.Job{ID: bson.NewObjectId(), User: "root", Dataset: "root/full", Status: types.JobRunning}
+	var task = types.Task{
+		ObjectID: bson.NewObjectId(),
+		ID:       trainJob.ID.Hex() + "/1",
+		Job:      trainJob.ID,
+		User:     "root",
+		Dataset:  "root/full",
+		Model:    "root/model1",
+		Status:   types.TaskCompleted,
+	}
+	var deployment = types.Deployment{ID: bson.NewObjectId(), User: "root", Task: task.ID, Status: types.DeploymentRunning}
+	var context = Context{Session: connection.Session, DBName: connection.DBName, User: types.User{ID: types.UserRoot}}
+
+	// Add the test documents to the test database.
+	db := connection.Session.DB(TestDBName)
+	assert.Nil(db.C("modules").Insert(module))
+	assert.Nil(db.C("datasets").Insert(inputs, other, full, predicted))
+	assert.Nil(db.C("jobs").Insert(trainJob))
+	assert.Nil(db.C("tasks").Insert(task))
+	assert.Nil(db.C("deployments").Insert(deployment))
+	assert.Nil(context.Initialize(TestDBName))
+
+	// The dataset must contain only inputs which match the model.
+	_, err = context.CreateJob(types.Job{Kind: types.JobPredict, Task: task.ID, Dataset: other.ID})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	_, err = context.CreateJob(types.Job{Kind: types.JobPredict, Task: task.ID, Dataset: full.ID})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	_, err = context.CreateJob(types.Job{Kind: types.JobPredict, Task: task.ID, Dataset: inputs.ID, Predictions: "other"})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	_, err = context.CreateJob(types.Job{Dataset: inputs.ID, Objective: "root/objective1"})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+
+	// Predictions cannot be used for training.
+	_, err = context.CreateJob(types.Job{Dataset: predicted.ID, Objective: "root/objective1"})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	if err != nil {
+		assert.Contains(err.Error(), "contains predictions")
+	}
+
+	// The task can be given through its deployment.
+	job, err := context.CreateJob(types.Job{Kind: types.JobPredict, Deployment: deployment.ID, Dataset: inputs.ID})
+	assert.Nil(err)
+	assert.Equal(task.ID, job.Task)
+	assert.Equal([]string{"root/model1"}, job.Models)
+	assert.Equal(types.JobScheduled, job.Status)
+	assert.Equal("root/predictions-"+job.ID.Hex(), job.Predictions)
+	predictions, err := context.GetDatasetByID(job.Predictions)
+	assert.Nil(err)
+	assert.Equal(types.DatasetPrediction, predictions.Source)
+	assert.Equal(types.DatasetCreated, predictions.Status)
+
+	// Prediction jobs are not given to the optimizer.
+	count, err := context.CountJobs(F{"kind": types.JobTrain})
+	assert.Nil(err)
+	assert.Equal(1, count)
+	count, err = context.CountJobs(F{"kind": types.JobPredict, "task": task.ID})
+	assert.Nil(err)
+	assert.Equal(1, count)
+
+	// Prediction jobs are run by a worker and cannot be paused.
+	processID := bson.NewObjectId()
+	locked, err := context.LockJob(F{"kind": types.JobPredict, "status": types.JobScheduled}, processID, "", "")
+	assert.Nil(err)
+	assert.Equal(job.ID, locked.ID)
+	_, err = context.UpdateJob(job.ID, F{"status": types.JobRunning})
+	assert.Nil(err)
+	_, err = context.UpdateJob(job.ID, F{"status": types.JobPausing})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	_, err = context.UpdateJob(job.ID, F{"max-tasks": uint64(10)})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+
+	// If the worker dies, the job is scheduled again.
+	numReleased, err := context.ReleaseJobLockByProcess(processID)
+	assert.Nil(err)
+	assert.Equal(1, numReleased)
+	job, err = context.GetJobByID(job.ID)
+	assert.Nil(err)
+	assert.Equal(types.JobScheduled, job.Status)
+	_, err = context.UpdateJob(job.ID, F{"status": types.JobTerminating})
+	assert.Nil(err)
+
+	// Drop the test database.
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+}
@@ -18,6 +18,12 @@ var (
 
 	// ErrBadInput can be returned when a model function is given invalid parameters.
 	ErrBadInput = e.New("the provided set of parameters is invalid")
+
+	// ErrDuplicate can be returned when a resource with the same content already exists.
+	ErrDuplicate = e.New("an identical resource already exists")
+
+	// ErrInUse can be returned when a resource cannot be changed because other resources depend on it.
+	ErrInUse = e.New("the resource is in use")
 )
 
 // Context contains information needed to access the data model and authorize the acessor.
@@ -3,6 +3,8 @@ package model
 import (
 	"encoding/hex"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,7 +16,8 @@ import (
 	"github.com/pkg/errors"
 )
 
-// GetModuleByID returns the module given its id. The id is given as "user-id/module-id".
+// GetModuleByID returns the module given its id. The id is given as "user-id/module-id". Versions after
+// the first one have the id "user-id/module-id@version".
 func (context Context) GetModuleByID(id string) (result types.Module, err error) {
 
 	// If the id is not given as user-id/module-id we assume user-id is the current user.
@@ -46,6 +49,219 @@ func (context Context) GetModuleByID(id string) (result types.Module, err error)
 	return allResults[0], nil
 }
 
+// SplitModuleVersion splits a module reference of the form "user-id/module-id@version" into the module id
+// and the version. The version is empty if the reference does not have one.
+func SplitModuleVersion(ref string) (id, version string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// ModuleVersionID returns the id of a given version of a module. The first version keeps the id of the
+// module so that modules created before versioning remain their own first version.
+func ModuleVersionID(id string, version int) string {
+	if version <= 1 {
+		return id
+	}
+	return fmt.Sprintf("%s@%d", id, version)
+}
+
+// moduleReferenceMatches checks if the module version with the given id is referenced by the given
+// reference. References without a version and "latest" references match all versions of the module.
+func moduleReferenceMatches(ref, id string) bool {
+	if ref == id {
+		return true
+	}
+	refID, refVersion := SplitModuleVersion(ref)
+	baseID, _ := SplitModuleVersion(id)
+	if refID != baseID {
+		return false
+	}
+	if refVersion == "" || refVersion == types.ModuleVersionLatest {
+		return true
+	}
+	version, err := strconv.Atoi(refVersion)
+	return err == nil && ModuleVersionID(refID, version) == id
+}
+
+// GetModuleVersions returns all versions of a module ordered by version. The id is given as
+// "user-id/module-id" and any version in it is ignored.
+func (context Context) GetModuleVersions(id string) (result []types.Module, err error) {
+
+	// If the id is not given as user-id/module-id we assume user-id is the current user.
+	id, _ = SplitModuleVersion(id)
+	ids := strings.Split(id, "/")
+	if len(ids) == 1 {
+		id = fmt.Sprintf("%s/%s", context.User.ID, id)
+	}
+
+	c := context.Session.DB(context.DBName).C("modules")
+	query := bson.M{"id": bson.M{"$regex": "^" + regexp.QuoteMeta(id) + "(@[0-9]+)?$"}}
+
+	// Only the root user can look up modules other than their own.
+	if context.User.IsRoot() == false {
+		query["user"] = bson.M{"$in": []string{context.User.ID, types.UserRoot}}
+	}
+
+	// Modules created before versioning have no version so they sort as the first one.
+	err = c.Find(query).Sort("version", "_id").All(&result)
+	if err != nil {
+		err = errors.Wrap(err, "mongo find failed")
+		return
+	}
+
+	if len(result) == 0 {
+		err = ErrNotFound
+		return
+	}
+	for i := range result {
+		if result[i].Version == 0 {
+			result[i].Version = 1
+		}
+	}
+
+	return result, nil
+}
+
+// ResolveModuleVersion returns the module version given a reference of the form "user-id/module-id@version".
+// If the version is omitted or is "latest", the latest active version of the module is returned.
+func (context Context) ResolveModuleVersion(ref string) (result types.Module, err error) {
+
+	id, version := SplitModuleVersion(ref)
+	if version != "" && version != types.ModuleVersionLatest {
+		var v int
+		v, err = strconv.Atoi(version)
+		if err != nil || v < 1 {
+			err = errors.Wrapf(ErrBadInput, "invalid module version \"%s\"", version)
+			return
+		}
+		return context.GetModuleByID(ModuleVersionID(id, v))
+	}
+
+	var versions []types.Module
+	versions, err = context.GetModuleVersions(id)
+	if err != nil {
+		return
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Status == types.ModuleActive {
+			return versions[i], nil
+		}
+	}
+
+	err = ErrNotFound
+	return
+}
+
+// GetModuleCompatibility explains whether the module can be applied to the dataset by matching the dataset
+// schemas against the module schemas. Both ids are given as "user-id/id".
+func (context Context) GetModuleCompatibility(moduleID, datasetID string) (result types.ModuleCompatibility, err error) {
+
+	module, err := context.GetModuleByID(moduleID)
+	if err != nil {
+		return
+	}
+	dataset, err := context.GetDatasetByID(datasetID)
+	if err != nil {
+		return
+	}
+
+	if dataset.SchemaIn == "" && dataset.SchemaOut == "" {
+		err = errors.Wrap(ErrBadInput, "the dataset schema has not been inferred yet")
+		return
+	}
+
+	result = types.ModuleCompatibility{Module: module.ID, Dataset: dataset.ID, Compatible: true}
+
+	result.SchemaIn, err = explainSchemaMatch(module.SchemaIn, dataset.SchemaIn)
+	if err != nil {
+		return
+	}
+	result.SchemaOut, err = explainSchemaMatch(module.SchemaOut, dataset.SchemaOut)
+	if err != nil {
+		return
+	}
+
+	result.Compatible = (result.SchemaIn == nil || result.SchemaIn.Match) && (result.SchemaOut == nil || result.SchemaOut.Match)
+	return
+}
+
+// GetCompatibleModules returns all active modules of the given type whose schemas accept the schemas of the
+// dataset. If the module type is empty, modules of all types are considered.
+func (context Context) GetCompatibleModules(datasetID, moduleType string) (result []types.CompatibleModule, err error) {
+
+	if moduleType != "" && moduleType != types.ModuleModel && moduleType != types.ModuleObjective && moduleType != types.ModuleOptimizer {
+		err = errors.Wrapf(ErrBadInput, "invalid module type \"%s\"", moduleType)
+		return
+	}
+
+	dataset, err := context.GetDatasetByID(datasetID)
+	if err != nil {
+		return
+	}
+	if dataset.SchemaIn == "" && dataset.SchemaOut == "" {
+		err = errors.Wrap(ErrBadInput, "the dataset schema has not been inferred yet")
+		return
+	}
+
+	filters := F{"status": types.ModuleActive}
+	if moduleType != "" {
+		filters["type"] = moduleType
+	}
+	modules, _, err := context.GetModules(filters, 0, "", "", "")
+	if err != nil {
+		return
+	}
+
+	result = []types.CompatibleModule{}
+	for i := range modules {
+		matchIn, dimsIn, err := matchSchemasCached(modules[i].SchemaIn, dataset.SchemaIn)
+		if err != nil {
+			return nil, err
+		}
+		if matchIn == false {
+			continue
+		}
+		matchOut, dimsOut, err := matchSchemasCached(modules[i].SchemaOut, dataset.SchemaOut)
+		if err != nil {
+			return nil, err
+		}
+		if matchOut == false {
+			continue
+		}
+		result = append(result, types.CompatibleModule{Module: modules[i], SchemaInDims: dimsIn, SchemaOutDims: dimsOut})
+	}
+
+	return result, nil
+}
+
+// explainSchemaMatch matches the source schema against the destination schema. No report is returned if
+// the destination schema is not specified.
+func explainSchemaMatch(destination, source string) (*types.SchemaMatchReport, error) {
+
+	schDestination, err := deserializeSchema(destination)
+	if err != nil || schDestination == nil {
+		return nil, err
+	}
+	schSource, err := deserializeSchema(source)
+	if err != nil {
+		return nil, err
+	}
+
+	report := schDestination.Explain(schSource)
+	result := &types.SchemaMatchReport{
+		Match:       report.Match,
+		NodeMapping: report.NodeMapping,
+		Failures:    make([]types.SchemaMatchFailure, len(report.Failures)),
+		Suggestions: report.Suggestions,
+	}
+	for i, f := range report.Failures {
+		result.Failures[i] = types.SchemaMatchFailure(f)
+	}
+	return result, nil
+}
+
 // GetModules lists all modules given some filter criteria.
 func (context Context) GetModules(
 	filters F,
@@ -96,6 +312,9 @@ func (context Context) GetModules(
 		case "user", "type", "label", "status", "source", "source-address":
 			setDefault(&query, k, bson.M{})
 			query[k].(bson.M)["$eq"] = v.(string)
+		case "schema-in-hash", "schema-out-hash":
+			setDefault(&query, k, bson.M{})
+			query[k].(bson.M)["$eq"] = v.(string)
 		case "schema-in":
 			schInput, err = deserializeSchema(v.(string))
 			if err != nil {
@@ -194,26 +413,40 @@ func (context Context) GetModules(
 		return
 	}
 
-	// If there is a schema filter, then we do manual shema matching here.
+	// If there is a schema filter, then we do manual shema matching here. Results are cached by the
+	// structural hashes of the schemas.
 	if schInput != nil || schOutput != nil {
+		var schInputHash, schOutputHash string
+		if schInput != nil {
+			schInputHash = schInput.Hash()
+		}
+		if schOutput != nil {
+			schOutputHash = schOutput.Hash()
+		}
+
 		var allResultsFiltered []types.Module
 		for i := range allResults {
 
-			schInputDst, err := deserializeSchema(allResults[i].SchemaIn)
-			if err != nil {
-				panic(err)
-			}
-			schOutputDst, err := deserializeSchema(allResults[i].SchemaOut)
-			if err != nil {
-				panic(err)
-			}
-
 			var match = true
 			if schInput != nil {
-				match, _ = schInputDst.Match(schInput, false)
+				match = matchStructuralCached(allResults[i].SchemaInHash, schInputHash, func() bool {
+					schInputDst, err := deserializeSchema(allResults[i].SchemaIn)
+					if err != nil {
+						panic(err)
+					}
+					match, _ := schInputDst.Match(schInput, false)
+					return match
+				})
 			}
 			if match && schOutput != nil {
-				match, _ = schOutputDst.Match(schOutput, false)
+				match = matchStructuralCached(allResults[i].SchemaOutHash, schOutputHash, func() bool {
+					schOutputDst, err := deserializeSchema(allResults[i].SchemaOut)
+					if err != nil {
+						panic(err)
+					}
+					match, _ := schOutputDst.Match(schOutput, false)
+					return match
+				})
 			}
 			if match {
 				allResultsFiltered = append(allResultsFiltered, allResults[i])
@@ -279,13 +512,70 @@ func (context Context) CreateModule(module types.Module) (result types.Module, e
 		err = errors.Wrap(ErrBadInput, "the id must be of the format module-id or user-id/module-id")
 		return
 	}
+	if strings.Contains(module.ID, "@") {
+		err = errors.Wrap(ErrBadInput, "the id cannot contain \"@\" which separates the module id from the version")
+		return
+	}
+	module.Version = 1
+
+	return context.insertModule(module)
+}
+
+// CreateModuleVersion adds a new version of an existing module to the database. The new version gets the
+// next version number and the id "user-id/module-id@version". It has the type of the module and unless they
+// are given, it takes the label, name and description of the latest version. Older versions are unaffected.
+func (context Context) CreateModuleVersion(id string, module types.Module) (result types.Module, err error) {
+
+	if _, version := SplitModuleVersion(id); version != "" {
+		err = errors.Wrap(ErrBadInput, "new versions are created from the module id without a version")
+		return
+	}
+	versions, err := context.GetModuleVersions(id)
+	if err != nil {
+		return
+	}
+	latest := versions[len(versions)-1]
+	if latest.User != context.User.ID {
+		err = types.ErrUnauthorized
+		return
+	}
+	if module.Type != "" && module.Type != latest.Type {
+		err = errors.Wrapf(ErrBadInput, "the type of a new version must be \"%s\"", latest.Type)
+		return
+	}
+
+	baseID, _ := SplitModuleVersion(versions[0].ID)
+	module.Version = latest.Version + 1
+	module.ID = ModuleVersionID(baseID, module.Version)
+	module.Type = latest.Type
+	if module.Label == "" {
+		module.Label = latest.Label
+	}
+	if module.Name == "" {
+		module.Name = latest.Name
+	}
+	if module.Description == "" {
+		module.Description = latest.Description
+	}
+
+	return context.insertModule(module)
+}
+
+// insertModule validates the fields of a module and adds it to the database. The id is already validated.
+func (context Context) insertModule(module types.Module) (result types.Module, err error) {
+
 	if module.Source != types.ModuleUpload &&
 		module.Source != types.ModuleLocal &&
 		module.Source != types.ModuleRegistry &&
-		module.Source != types.ModuleDownload {
+		module.Source != types.ModuleDownload &&
+		module.Source != types.ModuleBuild {
 		err = errors.Wrapf(ErrBadInput,
-			"value of source can be \"%s\", \"%s\", \"%s\" or \"%s\", but found \"%s\"",
-			types.ModuleUpload, types.ModuleLocal, types.ModuleDownload, types.ModuleRegistry, module.Source)
+			"value of source can be \"%s\", \"%s\", \"%s\", \"%s\" or \"%s\", but found \"%s\"",
+			types.ModuleUpload, types.ModuleLocal, types.ModuleDownload, types.ModuleRegistry, types.ModuleBuild, module.Source)
+		return
+	}
+	if module.Source == types.ModuleBuild && module.SourceAddress == "" {
+		err = errors.Wrap(ErrBadInput, "modules built from source need a source address")
 		return
 	}
 	if module.Type != types.ModuleModel &&
@@ -308,6 +598,11 @@ func (context Context) CreateModule(module types.Module) (result types.Module, e
 			err = errors.Wrap(ErrBadInput, "json schema input compact error")
 			return
 		}
+		module.SchemaInHash, err = structuralSchemaHash(module.SchemaIn)
+		if err != nil {
+			err = errors.Wrap(ErrBadInput, "the given input schema definition is invalid")
+			return
+		}
 	}
 	if module.SchemaOut != "" {
 		_, err = deserializeSchema(module.SchemaOut)
@@ -320,6 +615,11 @@ func (context Context) CreateModule(module types.Module) (result types.Module, e
 			err = errors.Wrap(ErrBadInput, "json schema output compact error")
 			return
 		}
+		module.SchemaOutHash, err = structuralSchemaHash(module.SchemaOut)
+		if err != nil {
+			err = errors.Wrap(ErrBadInput, "the given output schema definition is invalid")
+			return
+		}
 	}
 
 	// Give default values to some fields.
@@ -344,6 +644,74 @@ func (context Context) CreateModule(module types.Module) (result types.Module, e
 
 }
 
+// EnsureBuiltinModule records a module which is implemented natively by the engine. A new record is owned
+// by the root user and immediately active. An existing record gets the given name, description, schemas and
+// objective metadata but keeps its status so that archived built-in modules stay archived. Only the root user can do this.
+func (context Context) EnsureBuiltinModule(module types.Module) (result types.Module, err error) {
+
+	if context.User.IsRoot() == false {
+		err = types.ErrUnauthorized
+		return
+	}
+	if module.Source != types.ModuleBuiltin {
+		err = errors.Wrapf(ErrBadInput, "the source of built-in modules must be \"%s\"", types.ModuleBuiltin)
+		return
+	}
+	module.SchemaInHash, err = structuralSchemaHash(module.SchemaIn)
+	if err != nil {
+		err = errors.Wrap(ErrBadInput, "the given input schema definition is invalid")
+		return
+	}
+	module.SchemaOutHash, err = structuralSchemaHash(module.SchemaOut)
+	if err != nil {
+		err = errors.Wrap(ErrBadInput, "the given output schema definition is invalid")
+		return
+	}
+
+	c := context.Session.DB(context.DBName).C("modules")
+
+	result, err = context.GetModuleByID(module.ID)
+	if err == nil {
+		updates := bson.M{
+			"name":            module.Name,
+			"description":     module.Description,
+			"schema-in":       module.SchemaIn,
+			"schema-in-hash":  module.SchemaInHash,
+			"schema-out":      module.SchemaOut,
+			"schema-out-hash": module.SchemaOutHash,
+		}
+		if module.Objective != nil {
+			updates["objective-metadata"] = module.Objective
+		}
+		err = c.Update(bson.M{"_id": result.ObjectID}, bson.M{"$set": updates})
+		if err != nil {
+			err = errors.Wrap(err, "mongo update failed")
+			return
+		}
+		return context.GetModuleByID(module.ID)
+	} else if err != ErrNotFound {
+		return
+	}
+
+	module.ObjectID = bson.NewObjectId()
+	module.User = types.UserRoot
+	module.Version = 1
+	module.CreationTime = time.Now()
+	module.Status = types.ModuleActive
+
+	err = c.Insert(module)
+	if err != nil {
+		// Another controller may have recorded the same module in the meantime.
+		if mgo.IsDup(err) {
+			return context.GetModuleByID(module.ID)
+		}
+		err = errors.Wrap(err, "mongo insert failed")
+		return
+	}
+
+	return module, nil
+}
+
 // UpdateModule updates the information about a given module.
 func (context Context) UpdateModule(id string, updates map[string]interface{}) (result types.Module, err error) {
 
@@ -380,6 +748,11 @@ func (context Context) UpdateModule(id string, updates map[string]interface{}) (
 				}
 			}
 			valueUpdates["schema-in"] = schemaString
+			valueUpdates["schema-in-hash"], err = structuralSchemaHash(schemaString)
+			if err != nil {
+				err = errors.Wrap(ErrBadInput, "the given input schema definition is invalid")
+				return
+			}
 		case "schema-out":
 			schemaString := v.(string)
 			if schemaString != "" {
@@ -393,6 +766,11 @@ func (context Context) UpdateModule(id string, updates map[string]interface{}) (
 					err = errors.Wrap(ErrBadInput, "json schema output compact error")
 					return
 				}
+				valueUpdates["schema-out-hash"], err = structuralSchemaHash(schemaString)
+				if err != nil {
+					err = errors.Wrap(ErrBadInput, "the given output schema definition is invalid")
+					return
+				}
 				valueUpdates["schema-out"] = schemaString
 			}
 		case "config-space":
@@ -414,6 +792,18 @@ func (context Context) UpdateModule(id string, updates map[string]interface{}) (
 
 		case "status-message":
 			valueUpdates["status-message"] = v.(string)
+		case "conformance":
+			valueUpdates["conformance"] = v.(*types.ConformanceReport)
+		case "objective-metadata":
+			valueUpdates["objective-metadata"] = v.(*types.ObjectiveMetadata)
+		case "build-tag":
+			valueUpdates["build-tag"] = v.(string)
+		case "build-log":
+			valueUpdates["build-log"] = v.(string)
+		case "image-digest":
+			valueUpdates["image-digest"] = v.(string)
+		case "image-id":
+			valueUpdates["image-id"] = v.(string)
 
 		default:
 			err = errors.Wrap(ErrBadInput, "invalid value of parameter updates")
@@ -546,6 +936,183 @@ func (context Context) UpdateModuleStatus(id string, status string, statusMessag
 	return
 }
 
+// GetModuleDependentJobs returns the unfinished jobs which would be left without the module if it was
+// archived. Jobs depend on their objectives and on the model versions they are pinned to. Jobs which follow
+// the latest version of a model only depend on it if no other version of the model is active.
+func (context Context) GetModuleDependentJobs(id string) (result []types.Job, err error) {
+
+	module, err := context.GetModuleByID(id)
+	if err != nil {
+		return
+	}
+	result, _, err = context.getModuleDependentJobs(module)
+	return
+}
+
+// getModuleDependentJobs returns the jobs which depend on the module and whether another version of the
+// module is active.
+func (context Context) getModuleDependentJobs(module types.Module) (result []types.Job, otherActive bool, err error) {
+
+	versions, err := context.GetModuleVersions(module.ID)
+	if err != nil {
+		return
+	}
+	for i := range versions {
+		if versions[i].ID != module.ID && versions[i].Status == types.ModuleActive {
+			otherActive = true
+		}
+	}
+
+	// Modules can be used in jobs of all users so we need to look at all of them.
+	c := context.Session.DB(context.DBName).C("jobs")
+	query := bson.M{
+		"status": bson.M{"$nin": []string{types.JobCompleted, types.JobTerminating, types.JobTerminated, types.JobError}},
+	}
+	var jobs []types.Job
+	err = c.Find(query).Sort("_id").All(&jobs)
+	if err != nil {
+		err = errors.Wrap(err, "mongo find failed")
+		return
+	}
+
+	result = []types.Job{}
+	for i := range jobs {
+		if moduleIsObjective(jobs[i], module.ID) || len(moduleJobReferences(jobs[i], module.ID, otherActive)) > 0 {
+			result = append(result, jobs[i])
+		}
+	}
+
+	return result, otherActive, nil
+}
+
+// moduleIsObjective checks if the module is an objective or an alternative objective of the job.
+func moduleIsObjective(job types.Job, id string) bool {
+	if job.Objective == id {
+		return true
+	}
+	for i := range job.AltObjectives {
+		if job.AltObjectives[i] == id {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleJobReferences returns the model references of the job which would be left without a model version
+// if the module version with the given id was archived.
+func moduleJobReferences(job types.Job, id string, otherActive bool) (result []string) {
+	for i := range job.Models {
+		if moduleReferenceMatches(job.Models[i], id) {
+			_, version := SplitModuleVersion(job.Models[i])
+			if otherActive == false || (version != "" && version != types.ModuleVersionLatest) {
+				result = append(result, job.Models[i])
+			}
+		}
+	}
+	return
+}
+
+// ArchiveModule archives a module so that it is not used by new tasks. Tasks which have already been run
+// are not affected. If unfinished jobs depend on the module, ErrInUse is returned together with those jobs,
+// unless a replacement model is given or archiving is forced. Jobs are migrated to the replacement by
+// replacing their references to the archived model. The replacement must be visible to the owners of all
+// migrated jobs. Forced archiving leaves the jobs as they are, and they get no new tasks of the archived
+// module. The returned jobs are the dependent jobs.
+//
+// The module is archived before its jobs are migrated so that no new jobs can start using it. If the
+// migration fails it can be completed by archiving the module again with the same replacement.
+func (context Context) ArchiveModule(id string, replacement string, force bool) (result []types.Job, err error) {
+
+	module, err := context.GetModuleByID(id)
+	if err != nil {
+		return
+	}
+	if context.User.IsRoot() == false && module.User != context.User.ID {
+		err = ErrNotFound
+		return
+	}
+	if module.Status == types.ModuleArchived && replacement == "" {
+		return []types.Job{}, nil
+	}
+
+	result, otherActive, err := context.getModuleDependentJobs(module)
+	if err != nil {
+		return
+	}
+
+	if len(result) > 0 && replacement != "" {
+
+		// Only models can be replaced since the objectives of jobs cannot be changed.
+		var replacementModule types.Module
+		replacementModule, err = context.ResolveModuleVersion(replacement)
+		if errors.Cause(err) == ErrNotFound {
+			err = errors.Wrapf(ErrBadInput, "the replacement \"%s\" does not exist or is not active", replacement)
+			return
+		} else if err != nil {
+			return
+		}
+		if replacementModule.Type != types.ModuleModel || replacementModule.ID == module.ID {
+			err = errors.Wrapf(ErrBadInput, "the replacement \"%s\" must be another active model", replacement)
+			return
+		}
+		for i := range result {
+			if moduleIsObjective(result[i], module.ID) {
+				err = errors.Wrapf(ErrBadInput, "the objective of job \"%s\" cannot be replaced", result[i].ID.Hex())
+				return
+			}
+			if result[i].IsPredict() {
+				err = errors.Wrapf(ErrBadInput, "the model of prediction job \"%s\" cannot be replaced", result[i].ID.Hex())
+				return
+			}
+			if replacementModule.User != types.UserRoot && replacementModule.User != result[i].User && result[i].User != types.UserRoot {
+				err = errors.Wrapf(ErrBadInput, "the replacement \"%s\" is not accessible to the owner of job \"%s\"", replacement, result[i].ID.Hex())
+				return
+			}
+		}
+
+	} else if len(result) > 0 && force == false {
+		err = ErrInUse
+		return
+	}
+
+	if module.Status != types.ModuleArchived {
+		err = context.UpdateModuleStatus(module.ID, types.ModuleArchived, "")
+		if err != nil {
+			return
+		}
+	}
+	if replacement == "" {
+		return
+	}
+
+	// Jobs of other users are migrated as well, so this is done on behalf of the root user.
+	rootContext := context
+	rootContext.User = types.User{ID: types.UserRoot}
+	for i := range result {
+		replaced := map[string]bool{}
+		for _, ref := range moduleJobReferences(result[i], module.ID, otherActive) {
+			replaced[ref] = true
+		}
+		models := []string{}
+		seen := map[string]bool{}
+		for _, ref := range result[i].Models {
+			if replaced[ref] {
+				ref = replacement
+			}
+			if seen[ref] == false {
+				seen[ref] = true
+				models = append(models, ref)
+			}
+		}
+		_, err = rootContext.UpdateJob(result[i].ID, F{"models": models})
+		if err != nil {
+			err = errors.Wrap(err, "job update failed")
+			return
+		}
+	}
+	return
+}
+
 // ReleaseModuleLockByProcess releases all modules that have been locked by a given process and
 // are not in the error state.
 func (context Context) ReleaseModuleLockByProcess(processID bson.ObjectId) (numReleased int, err error) {
@@ -253,6 +253,160 @@ func TestCreateModule(t *testing.T) {
 	newModule, err = context.CreateModule(types.Module{ID: "root/module1"})
 	assert.Equal(ErrBadInput, errors.Cause(err))
 
+	// Modules built from source need a source address.
+	_, err = context.CreateModule(types.Module{ID: "root/module2", Type: "model", Source: "build"})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	newModule, err = context.CreateModule(types.Module{ID: "root/module2", Type: "model", Source: "build", SourceAddress: "/modules/module2"})
+	assert.Nil(err)
+	assert.Equal("created", newModule.Status)
+
+	// Drop the test database.
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+}
+
+func TestModuleVersions(t *testing.T) {
+	assert := assert.New(t)
+
+	// Establish a connection.
+	connection, err := database.Connect(MongoInstance, TestDBName)
+	assert.Nil(err)
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+	var context = Context{Session: connection.Session, DBName: connection.DBName, User: types.User{ID: types.UserRoot}}
+	c := connection.Session.DB(TestDBName).C("modules")
+
+	// The first version keeps the module id.
+	module, err := context.CreateModule(types.Module{ID: "module1", Type: "model", Name: "Module1", Source: "upload"})
+	assert.Nil(err)
+	assert.Equal("root/module1", module.ID)
+	assert.Equal(1, module.Version)
+	_, err = context.CreateModule(types.Module{ID: "module2@2", Type: "model", Source: "upload"})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	assert.Nil(c.Update(bson.M{"id": "root/module1"}, bson.M{"$set": bson.M{"status": "active"}}))
+
+	// New versions get the next version number and inherit the type and name.
+	version, err := context.CreateModuleVersion("root/module1", types.Module{Source: "build", SourceAddress: "/modules/module1"})
+	assert.Nil(err)
+	assert.Equal("root/module1@2", version.ID)
+	assert.Equal(2, version.Version)
+	assert.Equal("model", version.Type)
+	assert.Equal("Module1", version.Name)
+	_, err = context.CreateModuleVersion("root/module1@2", types.Module{Source: "upload"})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	_, err = context.CreateModuleVersion("root/module1", types.Module{Type: "objective", Source: "upload"})
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	_, err = context.CreateModuleVersion("root/module3", types.Module{Source: "upload"})
+	assert.Equal(ErrNotFound, errors.Cause(err))
+
+	versions, err := context.GetModuleVersions("root/module1@2")
+	assert.Nil(err)
+	assert.Len(versions, 2)
+	assert.Equal("root/module1", versions[0].ID)
+	assert.Equal("root/module1@2", versions[1].ID)
+
+	// References without a version follow the latest active version.
+	resolved, err := context.ResolveModuleVersion("root/module1")
+	assert.Nil(err)
+	assert.Equal("root/module1", resolved.ID)
+	assert.Nil(c.Update(bson.M{"id": "root/module1@2"}, bson.M{"$set": bson.M{"status": "active"}}))
+	resolved, err = context.ResolveModuleVersion("root/module1@latest")
+	assert.Nil(err)
+	assert.Equal("root/module1@2", resolved.ID)
+	resolved, err = context.ResolveModuleVersion("root/module1@1")
+	assert.Nil(err)
+	assert.Equal("root/module1", resolved.ID)
+	_, err = context.ResolveModuleVersion("root/module1@first")
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	_, err = context.ResolveModuleVersion("root/module1@3")
+	assert.Equal(ErrNotFound, errors.Cause(err))
+
+	assert.True(moduleReferenceMatches("root/module1", "root/module1@2"))
+	assert.True(moduleReferenceMatches("root/module1@2", "root/module1@2"))
+	assert.True(moduleReferenceMatches("root/module1@1", "root/module1"))
+	assert.False(moduleReferenceMatches("root/module1@1", "root/module1@2"))
+	assert.False(moduleReferenceMatches("root/module10", "root/module1@2"))
+
+	// Drop the test database.
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+}
+
+func TestArchiveModule(t *testing.T) {
+	assert := assert.New(t)
+
+	// Establish a connection.
+	connection, err := database.Connect(MongoInstance, TestDBName)
+	assert.Nil(err)
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+	var context = Context{Session: connection.Session, DBName: connection.DBName, User: types.User{ID: types.UserRoot}}
+
+	// Add the modules and a job which depends on them.
+	c := connection.Session.DB(TestDBName).C("modules")
+	for _, module := range []types.Module{
+		{ObjectID: bson.NewObjectId(), ID: "root/model1", Version: 1, User: "root", Type: "model", ConfigSpace: "{}", Status: "active"},
+		{ObjectID: bson.NewObjectId(), ID: "root/model2", Version: 1, User: "root", Type: "model", ConfigSpace: "{}", Status: "active"},
+		{ObjectID: bson.NewObjectId(), ID: "root/model2@2", Version: 2, User: "root", Type: "model", ConfigSpace: "{}", Status: "active"},
+		{ObjectID: bson.NewObjectId(), ID: "root/objective1", Version: 1, User: "root", Type: "objective", Status: "active"},
+		{ObjectID: bson.NewObjectId(), ID: "user2/model3", Version: 1, User: "user2", Type: "model", ConfigSpace: "{}", Status: "active"},
+	} {
+		assert.Nil(c.Insert(module))
+	}
+	var job = types.Job{
+		ID:        bson.NewObjectId(),
+		User:      "user1",
+		Dataset:   "root/dataset1",
+		Models:    []string{"root/model1", "root/model2"},
+		Objective: "root/objective1",
+		Status:    "running",
+	}
+	assert.Nil(connection.Session.DB(TestDBName).C("jobs").Insert(job))
+
+	// Jobs which follow the latest version do not depend on a version if another one is active.
+	jobs, err := context.GetModuleDependentJobs("root/model2@2")
+	assert.Nil(err)
+	assert.Len(jobs, 0)
+	jobs, err = context.GetModuleDependentJobs("root/model1")
+	assert.Nil(err)
+	assert.Len(jobs, 1)
+
+	// Archiving is refused unless the jobs are migrated.
+	jobs, err = context.ArchiveModule("root/model1", "", false)
+	assert.Equal(ErrInUse, errors.Cause(err))
+	assert.Len(jobs, 1)
+	_, err = context.ArchiveModule("root/model1", "root/objective1", false)
+	assert.Equal(ErrBadInput, errors.Cause(err))
+
+	// Jobs cannot be migrated to models which their owners cannot access.
+	_, err = context.ArchiveModule("root/model1", "user2/model3", false)
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	module, err := context.GetModuleByID("root/model1")
+	assert.Nil(err)
+	assert.Equal("active", module.Status)
+
+	jobs, err = context.ArchiveModule("root/model1", "root/model2@2", false)
+	assert.Nil(err)
+	assert.Len(jobs, 1)
+	module, err = context.GetModuleByID("root/model1")
+	assert.Nil(err)
+	assert.Equal("archived", module.Status)
+	job, err = context.GetJobByID(job.ID)
+	assert.Nil(err)
+	assert.Equal([]string{"root/model2@2", "root/model2"}, job.Models)
+
+	// Archiving again with a replacement completes an interrupted migration.
+	jobs, err = context.ArchiveModule("root/model1", "root/model2@2", false)
+	assert.Nil(err)
+	assert.Len(jobs, 0)
+
+	// Objectives cannot be replaced but archiving can be forced.
+	_, err = context.ArchiveModule("root/objective1", "root/model2", false)
+	assert.Equal(ErrBadInput, errors.Cause(err))
+	jobs, err = context.ArchiveModule("root/objective1", "", true)
+	assert.Nil(err)
+	assert.Len(jobs, 1)
+
 	// Drop the test database.
 	err = connection.Session.DB(TestDBName).DropDatabase()
 	assert.Nil(err)
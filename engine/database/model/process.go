@@ -525,6 +525,10 @@ func (context Context) TerminateDeadProcesses(cutoffTime time.Time) (err error)
 			if err != nil {
 				return err
 			}
+			context.ReleaseDeploymentLockByProcess(process.ID)
+			if err != nil {
+				return err
+			}
 
 		} else {
 			found = false
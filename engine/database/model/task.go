@@ -3,6 +3,7 @@ package model
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math"
 	"strings"
@@ -75,8 +76,18 @@ func (context Context) GetTasks(
 		err = errors.Wrapf(ErrBadInput, "cannot sort by \"%s\"", sortBy)
 		return
 	}
+	if order == types.TaskOrderBest || order == types.TaskOrderWorst {
+		if sortBy == "" {
+			sortBy = "quality"
+		}
+		order, err = context.qualityOrder(filters, order)
+		if err != nil {
+			return
+		}
+	}
 	if order != "" && order != "asc" && order != "desc" {
-		err = errors.Wrapf(ErrBadInput, "order can be either \"asc\" or \"desc\", not \"%s\"", order)
+		err = errors.Wrapf(ErrBadInput, "order can be \"asc\", \"desc\", \"%s\" or \"%s\", not \"%s\"",
+			types.TaskOrderBest, types.TaskOrderWorst, order)
 		return
 	}
 	if order == "" {
@@ -309,10 +320,10 @@ func (context Context) CreateTask(task types.Task) (result types.Task, err error
 			"the referenced objective \"%s\" does not exist or is running", task.Job)
 	}
 
-	// Validate that the models exist and are active.
+	// Validate that the model version is referenced by the job.
 	var found bool
 	for i := range job.Models {
-		if task.Model == job.Models[i] {
+		if moduleReferenceMatches(job.Models[i], task.Model) {
 			found = true
 			break
 		}
@@ -323,6 +334,14 @@ func (context Context) CreateTask(task types.Task) (result types.Task, err error
 			task.Model, job.ID)
 	}
 
+	// Archived models get no new tasks.
+	var module types.Module
+	module, err = context.GetModuleByID(task.Model)
+	if err == nil && module.Status == types.ModuleArchived {
+		err = errors.Wrapf(ErrBadInput, "the referenced model \"%s\" is archived", task.Model)
+		return
+	}
+
 	// Give default values to some fields. Copy some from the job.
 	task.ObjectID = bson.NewObjectId()
 	task.User = job.User
@@ -338,8 +357,20 @@ func (context Context) CreateTask(task types.Task) (result types.Task, err error
 	task.Quality = 0.0
 	task.AltQualities = make([]float64, len(task.AltObjectives))
 
-	// Get next ID.
+	// Refuse tasks with the same model and config as an existing task of the job. The config is compared
+	// as is so it should be in canonical form.
 	c := context.Session.DB(context.DBName).C("tasks")
+	var numDuplicates int
+	numDuplicates, err = c.Find(bson.M{"job": task.Job, "model": task.Model, "config": task.Config}).Count()
+	if err != nil {
+		err = errors.Wrap(err, "mongo find failed")
+		return
+	} else if numDuplicates > 0 {
+		err = errors.Wrapf(ErrDuplicate, "job \"%s\" already has a task of model \"%s\" with the same config", task.Job.Hex(), task.Model)
+		return
+	}
+
+	// Get next ID.
 	query := bson.M{"job": bson.M{"$eq": task.Job}}
 	var resultSize int
 	resultSize, err = c.Find(query).Count()
@@ -364,6 +395,69 @@ func (context Context) CreateTask(task types.Task) (result types.Task, err error
 
 }
 
+// CanonicalizeTaskConfigs rewrites the configs of all tasks in the form given by the canonical function and
+// returns the number of updated tasks. Tasks created before configs were canonicalized can then be found by
+// their config. Configs which are not valid JSON are left as they are.
+func (context Context) CanonicalizeTaskConfigs(canonical func(config interface{}) (string, error)) (numUpdated int, err error) {
+
+	c := context.Session.DB(context.DBName).C("tasks")
+	var task struct {
+		ObjectID bson.ObjectId `bson:"_id"`
+		Config   string        `bson:"config"`
+	}
+	iter := c.Find(nil).Select(bson.M{"_id": 1, "config": 1}).Iter()
+	for iter.Next(&task) {
+		var config interface{}
+		if json.Unmarshal([]byte(task.Config), &config) != nil {
+			continue
+		}
+		var canonicalConfig string
+		canonicalConfig, err = canonical(config)
+		if err != nil || canonicalConfig == task.Config {
+			continue
+		}
+		err = c.UpdateId(task.ObjectID, bson.M{"$set": bson.M{"config": canonicalConfig}})
+		if err != nil {
+			iter.Close()
+			return numUpdated, errors.Wrap(err, "mongo update failed")
+		}
+		numUpdated++
+	}
+	err = iter.Close()
+	if err != nil {
+		return numUpdated, errors.Wrap(err, "mongo iterate failed")
+	}
+
+	return numUpdated, nil
+}
+
+// qualityOrder translates the "best" and "worst" orders to "asc" or "desc" given the direction of the
+// objective of the tasks. The tasks must be filtered by their job or by their objective.
+func (context Context) qualityOrder(filters F, order string) (string, error) {
+
+	objectiveID, ok := filters["objective"].(string)
+	if ok == false {
+		jobID, ok := filters["job"].(bson.ObjectId)
+		if ok == false {
+			return "", errors.Wrapf(ErrBadInput, "the \"%s\" order needs the tasks to be filtered by job or objective", order)
+		}
+		job, err := context.GetJobByID(jobID)
+		if err != nil {
+			return "", err
+		}
+		objectiveID = job.Objective
+	}
+	objective, err := context.GetModuleByID(objectiveID)
+	if err != nil {
+		return "", err
+	}
+
+	if (order == types.TaskOrderBest) == objective.Objective.IsMinimized() {
+		return "asc", nil
+	}
+	return "desc", nil
+}
+
 // UpdateTask updates the information about a given task.
 func (context Context) UpdateTask(id string, updates map[string]interface{}) (result types.Task, err error) {
 
@@ -387,6 +481,8 @@ func (context Context) UpdateTask(id string, updates map[string]interface{}) (re
 			valueUpdates["quality-expected"] = v.(float64)
 		case "alt-qualities":
 			valueUpdates["alt-qualities"] = v.([]float64)
+		case "quality-details":
+			valueUpdates["quality-details"] = v.(*types.QualityDetails)
 		case "status":
 			status := v.(string)
 
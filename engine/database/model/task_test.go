@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -207,6 +208,72 @@ func TestGetTasks(t *testing.T) {
 	assert.Nil(err)
 }
 
+func TestGetTasksBestOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	// Establish a connection.
+	connection, err := database.Connect(MongoInstance, TestDBName)
+	assert.Nil(err)
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+	var context = Context{Session: connection.Session, DBName: connection.DBName, User: types.User{ID: types.UserRoot}}
+
+	// The error is minimized and the accuracy has no metadata so it is maximized.
+	c := connection.Session.DB(TestDBName).C("modules")
+	err = c.Insert(
+		types.Module{ObjectID: bson.NewObjectId(), ID: "root/error", User: "root", Type: types.ModuleObjective,
+			Status: types.ModuleActive, Objective: &types.ObjectiveMetadata{Direction: types.ObjectiveMinimize}},
+		types.Module{ObjectID: bson.NewObjectId(), ID: "root/accuracy", User: "root", Type: types.ModuleObjective,
+			Status: types.ModuleActive},
+	)
+	assert.Nil(err)
+
+	jobID := bson.NewObjectId()
+	c = connection.Session.DB(TestDBName).C("jobs")
+	err = c.Insert(types.Job{ID: jobID, User: "root", Objective: "root/error"})
+	assert.Nil(err)
+
+	c = connection.Session.DB(TestDBName).C("tasks")
+	otherJobID := bson.NewObjectId()
+	for i, quality := range []float64{0.2, 0.1, 0.3} {
+		err = c.Insert(types.Task{ObjectID: bson.NewObjectId(), ID: fmt.Sprintf("%s/%d", jobID.Hex(), i+1), Job: jobID,
+			User: "root", Objective: "root/error", Quality: quality})
+		assert.Nil(err)
+		err = c.Insert(types.Task{ObjectID: bson.NewObjectId(), ID: fmt.Sprintf("%s/%d", otherJobID.Hex(), i+1), Job: otherJobID,
+			User: "root", Objective: "root/accuracy", Quality: quality})
+		assert.Nil(err)
+	}
+	qualities := func(tasks []types.Task) []float64 {
+		result := []float64{}
+		for i := range tasks {
+			result = append(result, tasks[i].Quality)
+		}
+		return result
+	}
+
+	// Lower errors are better.
+	result, _, err := context.GetTasks(F{"job": jobID}, 0, "", "", types.TaskOrderBest)
+	assert.Nil(err)
+	assert.Equal([]float64{0.1, 0.2, 0.3}, qualities(result))
+
+	result, _, err = context.GetTasks(F{"objective": "root/error"}, 0, "", "quality", types.TaskOrderWorst)
+	assert.Nil(err)
+	assert.Equal([]float64{0.3, 0.2, 0.1}, qualities(result))
+
+	// Higher accuracies are better.
+	result, _, err = context.GetTasks(F{"objective": "root/accuracy"}, 0, "", "quality", types.TaskOrderBest)
+	assert.Nil(err)
+	assert.Equal([]float64{0.3, 0.2, 0.1}, qualities(result))
+
+	// The tasks must be filtered by job or objective.
+	_, _, err = context.GetTasks(F{}, 0, "", "quality", types.TaskOrderBest)
+	assert.Equal(ErrBadInput, errors.Cause(err))
+
+	// Drop the test database.
+	err = connection.Session.DB(TestDBName).DropDatabase()
+	assert.Nil(err)
+}
+
 func TestCreateTask(t *testing.T) {
 	assert := assert.New(t)
 
@@ -242,6 +309,10 @@ func TestCreateTask(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal("scheduled", task.Status)
 
+	// A task with the same model and config is refused.
+	_, err = context.CreateTask(task)
+	assert.Equal(ErrDuplicate, errors.Cause(err))
+
 	// Drop the test database.
 	err = connection.Session.DB(TestDBName).DropDatabase()
 	assert.Nil(err)
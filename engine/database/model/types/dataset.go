@@ -19,6 +19,14 @@ const (
 	// DatasetGit is a data set that has been fetched from a git repository
 	DatasetGit = "git"
 
+	// DatasetS3 is a data set that has been fetched from an S3-compatible object store. The source address
+	// is formatted as bucket/prefix and the access key holds the credentials as access-key-id:secret-access-key.
+	DatasetS3 = "s3"
+
+	// DatasetPrediction is a data set that holds the predictions of a prediction job. The source address is the
+	// identifier of the job.
+	DatasetPrediction = "prediction"
+
 	// DatasetCreated is the status of a dataset when it is recorded in the system but the data is not yet transferred.
 	DatasetCreated = "created"
 
@@ -42,18 +50,23 @@ const (
 
 // Dataset contains information about datasets.
 type Dataset struct {
-	ObjectID      bson.ObjectId `bson:"_id"`
-	ID            string        `bson:"id" json:"id"`
-	User          string        `bson:"user" json:"user"`
-	Name          string        `bson:"name" json:"name"`
-	Description   string        `bson:"description" json:"description"`
-	SchemaIn      string        `bson:"schema-in" json:"schema-in"`
-	SchemaOut     string        `bson:"schema-out" json:"schema-out"`
-	Source        string        `bson:"source" json:"source"`
-	SourceAddress string        `bson:"source-address" json:"source-address"`
-	CreationTime  time.Time     `bson:"creation-time" json:"creation-time"`
-	Status        string        `bson:"status" json:"status"`
-	StatusMessage string        `bson:"status-message" json:"status-message"`
-	Process       bson.ObjectId `bson:"process,omitempty" json:"process"`
-	AccessKey	  string     	`bson:"access-key,omitempty" json:"access-key"`
+	ObjectID         bson.ObjectId `bson:"_id"`
+	ID               string        `bson:"id" json:"id"`
+	User             string        `bson:"user" json:"user"`
+	Name             string        `bson:"name" json:"name"`
+	Description      string        `bson:"description" json:"description"`
+	SchemaIn         string        `bson:"schema-in" json:"schema-in"`
+	SchemaOut        string        `bson:"schema-out" json:"schema-out"`
+	SchemaInHash     string        `bson:"schema-in-hash,omitempty" json:"schema-in-hash"`
+	SchemaOutHash    string        `bson:"schema-out-hash,omitempty" json:"schema-out-hash"`
+	Source           string        `bson:"source" json:"source"`
+	SourceAddress    string        `bson:"source-address" json:"source-address"`
+	CreationTime     time.Time     `bson:"creation-time" json:"creation-time"`
+	Status           string        `bson:"status" json:"status"`
+	StatusMessage    string        `bson:"status-message" json:"status-message"`
+	Process          bson.ObjectId `bson:"process,omitempty" json:"process"`
+	AccessKey        string        `bson:"access-key,omitempty" json:"access-key"`
+	Checksum         string        `bson:"checksum,omitempty" json:"checksum"`
+	TransferredBytes int64         `bson:"transferred-bytes" json:"transferred-bytes"`
+	TotalBytes       int64         `bson:"total-bytes" json:"total-bytes"`
 }
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	// DeploymentStarting is a deployment that was created but is not yet serving predictions.
+	DeploymentStarting = "starting"
+
+	// DeploymentRunning is a deployment whose model is loaded and which accepts prediction requests.
+	DeploymentRunning = "running"
+
+	// DeploymentStopped is a deployment that was stopped by the user and doesn't accept requests anymore.
+	DeploymentStopped = "stopped"
+
+	// DeploymentError is a deployment that could not be started. The error information is logged.
+	DeploymentError = "error"
+)
+
+// Deployment contains information about a trained model which is served by the engine.
+type Deployment struct {
+	ID            bson.ObjectId `bson:"_id" json:"id"`
+	User          string        `bson:"user" json:"user"`
+	Task          string        `bson:"task" json:"task"`
+	Job           bson.ObjectId `bson:"job" json:"job"`
+	Model         string        `bson:"model" json:"model"`
+	SchemaIn      string        `bson:"schema-in" json:"schema-in"`
+	SchemaOut     string        `bson:"schema-out" json:"schema-out"`
+	Image         string        `bson:"image" json:"image"`
+	CreationTime  time.Time     `bson:"creation-time" json:"creation-time"`
+	RunningTime   TimeInterval  `bson:"running-time" json:"running-time"`
+	NumRequests   uint64        `bson:"num-requests" json:"num-requests"`
+	Status        string        `bson:"status" json:"status"`
+	StatusMessage string        `bson:"status-message" json:"status-message"`
+	Process       bson.ObjectId `bson:"process,omitempty" json:"process"`
+}
+
+// IsEnded returns true when the deployment has either stopped or is in an error state.
+func (deployment Deployment) IsEnded() bool {
+	return deployment.Status == DeploymentStopped || deployment.Status == DeploymentError
+}
@@ -36,19 +36,54 @@ const (
 
 	// DefaultMaxTasks is the default number of tasks per job.
 	DefaultMaxTasks = 100
+
+	// JobSpaceExhausted is the status message of a job which has a task for every config of its config space.
+	JobSpaceExhausted = "space exhausted"
+)
+
+const (
+	// JobTrain is the kind of jobs which train and evaluate models on a dataset. Jobs without a kind are training jobs.
+	JobTrain = "train"
+
+	// JobPredict is the kind of jobs which apply the model of a completed task to a dataset that contains only inputs.
+	// The predictions are stored as a new dataset.
+	JobPredict = "predict"
+)
+
+const (
+	// SamplerRandom samples configurations uniformly at random using a seeded generator.
+	SamplerRandom = "random"
+
+	// SamplerSobol samples configurations from the Sobol low-discrepancy sequence.
+	SamplerSobol = "sobol"
+
+	// SamplerHalton samples configurations from the Halton low-discrepancy sequence.
+	SamplerHalton = "halton"
+
+	// SamplerLHS samples configurations from Latin hypercube designs of the size of the job.
+	SamplerLHS = "lhs"
 )
 
+// Samplers lists all valid job samplers. Jobs without a sampler get their tasks from the optimizer.
+var Samplers = []string{SamplerRandom, SamplerSobol, SamplerHalton, SamplerLHS}
+
 // Job contains information about jobs.
 type Job struct {
 	ID                bson.ObjectId `bson:"_id" json:"id"`
 	User              string        `bson:"user" json:"user"`
+	Kind              string        `bson:"kind,omitempty" json:"kind"`
 	Dataset           string        `bson:"dataset" json:"dataset"`
+	Task              string        `bson:"task,omitempty" json:"task,omitempty"`
+	Deployment        bson.ObjectId `bson:"deployment,omitempty" json:"deployment,omitempty"`
+	Predictions       string        `bson:"predictions,omitempty" json:"predictions,omitempty"`
 	Models            []string      `bson:"models" json:"models"`
 	ConfigSpace       string        `bson:"config-space" json:"config-space"`
 	AcceptNewModels   bool          `bson:"accept-new-models" json:"accept-new-models"`
 	Objective         string        `bson:"objective" json:"objective"`
 	AltObjectives     []string      `bson:"alt-objectives" json:"alt-objectives"`
 	MaxTasks          uint64        `bson:"max-tasks" json:"max-tasks"`
+	Sampler           string        `bson:"sampler,omitempty" json:"sampler"`
+	SamplerSeed       int64         `bson:"sampler-seed" json:"sampler-seed"`
 	CreationTime      time.Time     `bson:"creation-time" json:"creation-time"`
 	RunningTime       TimeInterval  `bson:"running-time" json:"running-time"`
 	RunningDuration   uint64        `bson:"running-duration,omitempty" json:"running-duration"`
@@ -60,6 +95,11 @@ type Job struct {
 	Process           bson.ObjectId `bson:"process,omitempty" json:"process"`
 }
 
+// IsPredict returns true when the job makes predictions with the model of a completed task.
+func (job Job) IsPredict() bool {
+	return job.Kind == JobPredict
+}
+
 // IsStarted returns true when the job has passed the "scheduled" state.
 func (job Job) IsStarted() bool {
 	return job.Status != JobScheduled
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/globalsign/mgo/bson"
@@ -28,6 +29,15 @@ const (
 	// ModuleRegistry is a module that is obtained from a Docker registry.
 	ModuleRegistry = "registry"
 
+	// ModuleBuild is a module whose image is built from a directory or a git repository with a Dockerfile.
+	ModuleBuild = "build"
+
+	// ModuleBuiltin is a module which is implemented natively by the engine and has no image.
+	ModuleBuiltin = "builtin"
+
+	// ModuleVersionLatest is the version in module references which stands for the latest active version.
+	ModuleVersionLatest = "latest"
+
 	// ModuleCreated is the status of a module that is recorded in the system but not yet transferred.
 	ModuleCreated = "created"
 
@@ -46,20 +56,164 @@ const (
 
 // Module contains information about modules which are stateless Docker images.
 type Module struct {
-	ObjectID      bson.ObjectId `bson:"_id"`
-	ID            string        `bson:"id" json:"id"`
-	User          string        `bson:"user" json:"user"`
-	Type          string        `bson:"type" json:"type"`
-	Label         string        `bson:"label" json:"label"`
-	Name          string        `bson:"name" json:"name"`
-	Description   string        `bson:"description" json:"description"`
-	SchemaIn      string        `bson:"schema-in" json:"schema-in"`
-	SchemaOut     string        `bson:"schema-out" json:"schema-out"`
-	ConfigSpace   string        `bson:"config-space" json:"config-space"`
-	Source        string        `bson:"source" json:"source"`
-	SourceAddress string        `bson:"source-address" json:"source-address"`
-	CreationTime  time.Time     `bson:"creation-time" json:"creation-time"`
-	Status        string        `bson:"status" json:"status"`
-	StatusMessage string        `bson:"status-message" json:"status-message"`
-	Process       bson.ObjectId `bson:"process,omitempty" json:"process"`
+	ObjectID         bson.ObjectId      `bson:"_id"`
+	ID               string             `bson:"id" json:"id"`
+	Version          int                `bson:"version,omitempty" json:"version,omitempty"`
+	User             string             `bson:"user" json:"user"`
+	Type             string             `bson:"type" json:"type"`
+	Label            string             `bson:"label" json:"label"`
+	Name             string             `bson:"name" json:"name"`
+	Description      string             `bson:"description" json:"description"`
+	SchemaIn         string             `bson:"schema-in" json:"schema-in"`
+	SchemaOut        string             `bson:"schema-out" json:"schema-out"`
+	SchemaInHash     string             `bson:"schema-in-hash,omitempty" json:"schema-in-hash"`
+	SchemaOutHash    string             `bson:"schema-out-hash,omitempty" json:"schema-out-hash"`
+	ConfigSpace      string             `bson:"config-space" json:"config-space"`
+	Source           string             `bson:"source" json:"source"`
+	SourceAddress    string             `bson:"source-address" json:"source-address"`
+	BuildTag         string             `bson:"build-tag,omitempty" json:"build-tag,omitempty"`
+	BuildLog         string             `bson:"build-log,omitempty" json:"build-log,omitempty"`
+	RegistryUsername string             `bson:"registry-username,omitempty" json:"registry-username,omitempty"`
+	RegistryPassword string             `bson:"registry-password,omitempty" json:"registry-password,omitempty"`
+	ImageDigest      string             `bson:"image-digest,omitempty" json:"image-digest,omitempty"`
+	ImageID          string             `bson:"image-id,omitempty" json:"image-id,omitempty"`
+	CreationTime     time.Time          `bson:"creation-time" json:"creation-time"`
+	Status           string             `bson:"status" json:"status"`
+	StatusMessage    string             `bson:"status-message" json:"status-message"`
+	Process          bson.ObjectId      `bson:"process,omitempty" json:"process"`
+	Conformance      *ConformanceReport `bson:"conformance,omitempty" json:"conformance,omitempty"`
+	Objective        *ObjectiveMetadata `bson:"objective-metadata,omitempty" json:"objective-metadata,omitempty"`
+}
+
+// MarshalJSON encodes the module without its registry password. The password can be given when a module
+// is created but it is never returned.
+func (module Module) MarshalJSON() ([]byte, error) {
+	type moduleJSON Module
+	result := moduleJSON(module)
+	result.RegistryPassword = ""
+	return json.Marshal(result)
+}
+
+const (
+	// ObjectiveMaximize is the direction of objectives whose higher values are better.
+	ObjectiveMaximize = "maximize"
+
+	// ObjectiveMinimize is the direction of objectives whose lower values are better.
+	ObjectiveMinimize = "minimize"
+
+	// ObjectiveOutputPerSample is the output of objectives which report the value of each sample.
+	ObjectiveOutputPerSample = "per-sample"
+
+	// ObjectiveOutputPerClass is the output of objectives which report the value of each class.
+	ObjectiveOutputPerClass = "per-class"
+
+	// ObjectiveOutputConfusionMatrix is the output of objectives which report a confusion matrix.
+	ObjectiveOutputConfusionMatrix = "confusion-matrix"
+)
+
+// ObjectiveMetadata describes the values computed by an objective module. The direction tells whether higher
+// or lower values are better, the optional bounds limit the values and the outputs list the breakdowns of the
+// value which the objective reports in addition to it.
+type ObjectiveMetadata struct {
+	Direction string   `bson:"direction" json:"direction"`
+	Min       *float64 `bson:"min,omitempty" json:"min,omitempty"`
+	Max       *float64 `bson:"max,omitempty" json:"max,omitempty"`
+	Outputs   []string `bson:"outputs,omitempty" json:"outputs,omitempty"`
+}
+
+// IsMinimized returns true if lower values of the objective are better. Objectives without metadata are maximized.
+func (m *ObjectiveMetadata) IsMinimized() bool {
+	return m != nil && m.Direction == ObjectiveMinimize
+}
+
+// HasOutput returns true if the objective declares the given output.
+func (m *ObjectiveMetadata) HasOutput(output string) bool {
+	if m == nil {
+		return false
+	}
+	for i := range m.Outputs {
+		if m.Outputs[i] == output {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// ConformancePassed is the status of a conformance test case which succeeded.
+	ConformancePassed = "passed"
+
+	// ConformanceFailed is the status of a conformance test case which failed.
+	ConformanceFailed = "failed"
+
+	// ConformanceSkipped is the status of a conformance test case which was not run because
+	// a test case it depends on failed.
+	ConformanceSkipped = "skipped"
+)
+
+// ConformanceReport summarizes a model conformance run. The model is trained and applied to several
+// generated datasets with several sampled configurations and its predictions are checked against
+// its output schema. The run can be repeated with the validate model command and the seed.
+type ConformanceReport struct {
+	Image    string           `bson:"image" json:"image"`
+	Time     time.Time        `bson:"time" json:"time"`
+	Duration float64          `bson:"duration" json:"duration"`
+	Seed     int64            `bson:"seed" json:"seed"`
+	Passed   bool             `bson:"passed" json:"passed"`
+	Tests    int              `bson:"tests" json:"tests"`
+	Failures int              `bson:"failures" json:"failures"`
+	Skipped  int              `bson:"skipped" json:"skipped"`
+	Runs     []ConformanceRun `bson:"runs" json:"runs"`
+}
+
+// ConformanceRun contains the test cases for one generated dataset and one sampled configuration.
+// The dims map the dimension variables of the model schemas to the values used to generate the dataset.
+type ConformanceRun struct {
+	Name   string            `bson:"name" json:"name"`
+	Dims   map[string]int    `bson:"dims" json:"dims"`
+	Config string            `bson:"config" json:"config"`
+	Cases  []ConformanceCase `bson:"cases" json:"cases"`
+}
+
+// ConformanceCase is the outcome of a single step of a conformance run. The duration is given in seconds.
+type ConformanceCase struct {
+	Name     string  `bson:"name" json:"name"`
+	Status   string  `bson:"status" json:"status"`
+	Message  string  `bson:"message,omitempty" json:"message,omitempty"`
+	Duration float64 `bson:"duration" json:"duration"`
+}
+
+// SchemaMatchFailure describes a constraint of a module schema which is violated by a dataset schema.
+type SchemaMatchFailure struct {
+	Path       string `json:"path"`
+	Constraint string `json:"constraint"`
+	Expected   string `json:"expected"`
+	Found      string `json:"found"`
+	Message    string `json:"message"`
+}
+
+// SchemaMatchReport explains the result of matching a dataset schema against a module schema.
+type SchemaMatchReport struct {
+	Match       bool                 `json:"match"`
+	NodeMapping map[string]string    `json:"node-mapping"`
+	Failures    []SchemaMatchFailure `json:"failures"`
+	Suggestions []string             `json:"suggestions"`
+}
+
+// ModuleCompatibility describes whether a module can be applied to a dataset. Reports are only
+// given for the schemas which the module specifies.
+type ModuleCompatibility struct {
+	Module     string             `json:"module"`
+	Dataset    string             `json:"dataset"`
+	Compatible bool               `json:"compatible"`
+	SchemaIn   *SchemaMatchReport `json:"schema-in,omitempty"`
+	SchemaOut  *SchemaMatchReport `json:"schema-out,omitempty"`
+}
+
+// CompatibleModule is a module whose schemas accept the schemas of some dataset. The dimension bindings
+// map the dimension variables of the module schemas to the dimensions found in the dataset schemas.
+type CompatibleModule struct {
+	Module        Module                 `json:"module"`
+	SchemaInDims  map[string]interface{} `json:"schema-in-dims,omitempty"`
+	SchemaOutDims map[string]interface{} `json:"schema-out-dims,omitempty"`
 }
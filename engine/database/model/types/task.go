@@ -64,6 +64,28 @@ type TaskStageDurations struct {
 	Evaluating uint64 `bson:"evaluating" json:"evaluating"`
 }
 
+const (
+	// TaskOrderBest orders tasks from the best to the worst quality given the direction of their objective.
+	TaskOrderBest = "best"
+
+	// TaskOrderWorst orders tasks from the worst to the best quality given the direction of their objective.
+	TaskOrderWorst = "worst"
+)
+
+// QualityDetails holds the breakdowns of the quality of a task on the validation set which are reported by
+// the objective in addition to the quality.
+type QualityDetails struct {
+	PerClass        map[string]float64 `bson:"per-class,omitempty" json:"per-class,omitempty"`
+	ConfusionMatrix *ConfusionMatrix   `bson:"confusion-matrix,omitempty" json:"confusion-matrix,omitempty"`
+}
+
+// ConfusionMatrix counts the samples of each actual class (rows) which were predicted as each class (columns).
+// Rows and columns follow the order of the labels.
+type ConfusionMatrix struct {
+	Labels []string `bson:"labels" json:"labels"`
+	Counts [][]int  `bson:"counts" json:"counts"`
+}
+
 // Task contains information about tasks.
 type Task struct {
 	ObjectID        bson.ObjectId      `bson:"_id"`
@@ -80,6 +102,7 @@ type Task struct {
 	QualityTrain    float64            `bson:"quality-train" json:"quality-train"`
 	QualityExpected float64            `bson:"quality-expected" json:"quality-expected"`
 	AltQualities    []float64          `bson:"alt-qualities" json:"alt-qualities"`
+	QualityDetails  *QualityDetails    `bson:"quality-details,omitempty" json:"quality-details,omitempty"`
 	Status          string             `bson:"status" json:"status"`
 	StatusMessage   string             `bson:"status-message" json:"status-message"`
 	Stage           string             `bson:"stage" json:"stage"`
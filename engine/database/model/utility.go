@@ -2,9 +2,12 @@ package model
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 
+	"github.com/ds3lab/easeml/engine/database"
 	sch "github.com/ds3lab/easeml/schema/go/easemlschema/schema"
 
 	"github.com/globalsign/mgo/bson"
@@ -40,6 +43,83 @@ func deserializeSchema(data string) (schema *sch.Schema, err error) {
 	return
 }
 
+// structuralSchemaHash returns the hash of the canonical form of the schema. It is invariant to the naming of
+// nodes, classes and dimension variables. The hash of an empty schema is empty.
+func structuralSchemaHash(data string) (string, error) {
+	schema, err := deserializeSchema(data)
+	if err != nil || schema == nil {
+		return "", err
+	}
+	return schema.Hash(), nil
+}
+
+// matchStructuralCached returns the result of match for a destination and a source schema given by their
+// structural hashes. The result is cached by the pair of hashes. Structurally identical schemas do not always
+// match each other, so the hashes alone never decide the result. Empty hashes are not cached.
+func matchStructuralCached(destinationHash, sourceHash string, match func() bool) bool {
+	if destinationHash == "" || sourceHash == "" {
+		return match()
+	}
+	key := "schema-structural-match/" + destinationHash + "/" + sourceHash
+	if item, found := database.Cache.Get(key); found {
+		return item.(bool)
+	}
+	result := match()
+	database.Cache.SetDefault(key, result)
+	return result
+}
+
+// schemaMatchResult is the cached result of matching two schemas.
+type schemaMatchResult struct {
+	match bool
+	dims  map[string]interface{}
+}
+
+// schemaHash returns a hash of the schema which does not depend on the JSON formatting.
+func schemaHash(schema string) string {
+	if compact, err := jsonCompact(schema); err == nil {
+		schema = compact
+	}
+	hash := sha256.Sum256([]byte(schema))
+	return hex.EncodeToString(hash[:])
+}
+
+// matchSchemasCached matches the source schema against the destination schema and returns the bindings of the
+// destination dimension variables. An empty destination schema accepts any source. Results are cached by the
+// hashes of both schemas because matching can require trying many permutations of nodes and fields.
+func matchSchemasCached(destination, source string) (match bool, dims map[string]interface{}, err error) {
+
+	if destination == "" {
+		return true, nil, nil
+	}
+
+	key := "schema-match/" + schemaHash(destination) + "/" + schemaHash(source)
+	if item, found := database.Cache.Get(key); found {
+		result := item.(schemaMatchResult)
+		return result.match, result.dims, nil
+	}
+
+	schDestination, err := deserializeSchema(destination)
+	if err != nil {
+		return
+	}
+	schSource, err := deserializeSchema(source)
+	if err != nil {
+		return
+	}
+
+	match, matching := schDestination.Match(schSource, true)
+	if match {
+		dims = map[string]interface{}{}
+		if srcDims, ok := matching.Dump().(map[string]interface{})["src-dims"]; ok {
+			dims = srcDims.(map[string]interface{})
+		}
+	}
+
+	database.Cache.SetDefault(key, schemaMatchResult{match: match, dims: dims})
+	return
+}
+
 func jsonCompact(input string) (string, error) {
 	var buf bytes.Buffer
 	err := json.Compact(&buf, []byte(input))
@@ -0,0 +1,184 @@
+package modules
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ds3lab/easeml/engine/utils"
+
+	"github.com/mholt/archiver"
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+)
+
+// buildImageRepository is the repository of the images built from module sources.
+const buildImageRepository = "easeml"
+
+// buildContextIgnored lists the files which are left out of build contexts.
+var buildContextIgnored = map[string]bool{".git": true}
+
+// IsGitAddress returns true if the source address of a module refers to a git repository rather than
+// to a local directory.
+func IsGitAddress(address string) bool {
+	address = strings.Split(address, "#")[0]
+	for _, prefix := range []string{"git@", "git://", "ssh://", "http://", "https://"} {
+		if strings.HasPrefix(address, prefix) {
+			return true
+		}
+	}
+	return strings.HasSuffix(address, ".git")
+}
+
+// FetchBuildSource copies the build source of a module to the destination directory and returns the
+// directory of the build context. The address is either a local directory or a git repository. As with
+// Docker, a git address can be followed by a fragment of the form #ref:dir which selects the branch, tag or
+// commit to check out and the subdirectory of the repository that is used as the build context.
+func FetchBuildSource(address, destination string, output io.Writer) (contextDir string, err error) {
+
+	if IsGitAddress(address) == false {
+		address = strings.TrimPrefix(address, "file://")
+		if stats, err := os.Stat(address); err != nil {
+			return "", errors.Wrap(err, "build source access error")
+		} else if stats.IsDir() == false {
+			return "", errors.Errorf("the build source \"%s\" must be a directory", address)
+		}
+		if err = copy.Copy(address, destination); err != nil {
+			return "", errors.Wrap(err, "build source copy error")
+		}
+		return destination, nil
+	}
+
+	url, ref, subdir := address, "", ""
+	if i := strings.Index(address, "#"); i >= 0 {
+		url = address[:i]
+		fragment := strings.SplitN(address[i+1:], ":", 2)
+		ref = fragment[0]
+		if len(fragment) > 1 {
+			subdir = fragment[1]
+		}
+	}
+
+	// Neither the URL nor the ref may be taken for an option of git.
+	if strings.HasPrefix(url, "-") {
+		return "", errors.Errorf("the git repository address \"%s\" must not start with \"-\"", url)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", errors.Errorf("the git reference \"%s\" must not start with \"-\"", ref)
+	}
+	if err = runGit(output, "", "clone", "--recurse-submodules", "--", url, destination); err != nil {
+		return "", err
+	}
+	if ref != "" {
+		if err = runGit(output, destination, "checkout", ref, "--"); err != nil {
+			return "", err
+		}
+	}
+
+	contextDir = filepath.Join(destination, filepath.FromSlash(subdir))
+	if rel, err := filepath.Rel(destination, contextDir); err != nil || strings.HasPrefix(rel, "..") {
+		return "", errors.Errorf("the build context directory \"%s\" is outside of the repository", subdir)
+	}
+	return contextDir, nil
+}
+
+// runGit runs a git command and writes its output to the build log. The command fails rather than asking
+// for credentials.
+func runGit(output io.Writer, dir string, args ...string) error {
+	outStr, errStr, err := utils.ExecExternal(dir, "git", args...)
+	fmt.Fprint(output, outStr, errStr)
+	if err != nil {
+		return errors.Wrapf(err, "git %s failed", args[0])
+	}
+	return nil
+}
+
+// BuildContextHash computes a hash of the content of a build context directory. It depends only on the
+// relative paths, permissions and contents of the files so it is the same wherever the directory is.
+func BuildContextHash(contextDir string) (string, error) {
+	hash := sha256.New()
+	err := filepath.Walk(contextDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return err
+		}
+		if buildContextIgnored[info.Name()] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		fmt.Fprintf(hash, "%s\x00%s\x00", filepath.ToSlash(rel), info.Mode())
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "%s\x00", target)
+		case info.Mode().IsRegular():
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err = io.Copy(hash, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "build context hash error")
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ModuleImageTag returns the tag of the image of a module built from a build context with the given hash.
+// Builds of the same content get the same tag. All versions of a module share the image repository.
+func ModuleImageTag(moduleID, contextHash string) string {
+	if len(contextHash) > 16 {
+		contextHash = contextHash[:16]
+	}
+	moduleID = strings.SplitN(moduleID, "@", 2)[0]
+	return fmt.Sprintf("%s/%s:%s", buildImageRepository, strings.ToLower(moduleID), contextHash)
+}
+
+// BuildModuleImage builds the image of a module from a build context directory which contains a
+// Dockerfile. The build log is written to the output.
+func BuildModuleImage(runtime Runtime, contextDir, tag string, output io.Writer) error {
+
+	if _, err := os.Stat(filepath.Join(contextDir, "Dockerfile")); err != nil {
+		return errors.Errorf("the build context has no Dockerfile")
+	}
+
+	files, err := ioutil.ReadDir(contextDir)
+	if err != nil {
+		return errors.Wrap(err, "build context read error")
+	}
+	buildContextFiles := []string{}
+	for i := range files {
+		if buildContextIgnored[files[i].Name()] == false {
+			buildContextFiles = append(buildContextFiles, filepath.Join(contextDir, files[i].Name()))
+		}
+	}
+	sort.Strings(buildContextFiles)
+
+	var b bytes.Buffer
+	err = archiver.Tar.Write(&b, buildContextFiles)
+	if err != nil {
+		return errors.Wrap(err, "failed to write the build context")
+	}
+	return runtime.BuildImage(&b, tag, output)
+}
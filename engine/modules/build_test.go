@@ -0,0 +1,114 @@
+package modules
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGitAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsGitAddress("https://github.com/DS3Lab/easeml.git"))
+	assert.True(IsGitAddress("https://github.com/DS3Lab/easeml#master:modules/models/logreg"))
+	assert.True(IsGitAddress("git@github.com:DS3Lab/easeml.git"))
+	assert.True(IsGitAddress("/home/user/easeml.git"))
+	assert.False(IsGitAddress("/home/user/easeml/modules/models/logreg"))
+	assert.False(IsGitAddress("file:///home/user/easeml/modules/models/logreg"))
+}
+
+func TestBuildModuleFromSource(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := ioutil.TempDir("", "easeml_build_")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	modulePath := writeProcessModule(t, root)
+
+	// The hash depends only on the content of the build context.
+	hash, err := BuildContextHash(modulePath)
+	assert.Nil(err)
+	var log bytes.Buffer
+	contextDir, err := FetchBuildSource("file://"+modulePath, filepath.Join(root, "copy"), &log)
+	assert.Nil(err)
+	assert.Nil(os.Mkdir(filepath.Join(contextDir, ".git"), 0700))
+	assert.Nil(ioutil.WriteFile(filepath.Join(contextDir, ".git", "HEAD"), []byte("ref: refs/heads/master\n"), 0600))
+	copyHash, err := BuildContextHash(contextDir)
+	assert.Nil(err)
+	assert.Equal(hash, copyHash)
+	assert.Nil(ioutil.WriteFile(filepath.Join(contextDir, "run.sh"), []byte("echo changed\n"), 0600))
+	copyHash, err = BuildContextHash(contextDir)
+	assert.Nil(err)
+	assert.NotEqual(hash, copyHash)
+
+	tag := ModuleImageTag("root/Echo-Model", hash)
+	assert.Equal("easeml/root/echo-model:"+hash[:16], tag)
+	assert.Equal(tag, ModuleImageTag("root/Echo-Model@2", hash))
+
+	runtime := ProcessRuntime{Root: filepath.Join(root, "runtime")}
+	assert.Nil(BuildModuleImage(runtime, modulePath, tag, &log))
+	assert.Contains(log.String(), "Successfully tagged "+tag)
+	info, err := runtime.InspectImage(tag)
+	assert.Nil(err)
+	assert.Contains(info.Files, "config-space.json")
+
+	// Build contexts need a Dockerfile.
+	assert.Nil(os.Remove(filepath.Join(contextDir, "Dockerfile")))
+	assert.NotNil(BuildModuleImage(runtime, contextDir, tag, &log))
+
+	_, err = FetchBuildSource(filepath.Join(root, "missing"), filepath.Join(root, "missing-copy"), &log)
+	assert.NotNil(err)
+}
+
+func TestFetchBuildSourceFromGit(t *testing.T) {
+	assert := assert.New(t)
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root, err := ioutil.TempDir("", "easeml_build_")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	repoPath := filepath.Join(root, "repo")
+	assert.Nil(copy.Copy(writeProcessModule(t, root), filepath.Join(repoPath, "models", "echo")))
+	git := func(args ...string) {
+		args = append([]string{"-C", repoPath, "-c", "user.name=easeml", "-c", "user.email=easeml@localhost"}, args...)
+		output, err := exec.Command("git", args...).CombinedOutput()
+		assert.Nil(err, string(output))
+	}
+	git("init", "--quiet")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "Add echo model")
+	git("tag", "v1")
+	git("rm", "--quiet", "-r", "models")
+	git("commit", "--quiet", "-m", "Remove echo model")
+
+	var log bytes.Buffer
+	contextDir, err := FetchBuildSource(repoPath+"/.git#v1:models/echo", filepath.Join(root, "clone"), &log)
+	assert.Nil(err, log.String())
+	assert.Equal(filepath.Join(root, "clone", "models", "echo"), contextDir)
+	_, err = os.Stat(filepath.Join(contextDir, "Dockerfile"))
+	assert.Nil(err)
+
+	_, err = FetchBuildSource(repoPath+"/.git#v1:../..", filepath.Join(root, "clone-outside"), &log)
+	assert.NotNil(err)
+	_, err = FetchBuildSource(repoPath+"/.git#v2", filepath.Join(root, "clone-missing"), &log)
+	assert.NotNil(err)
+	assert.True(strings.Contains(log.String(), "v2"))
+
+	// Addresses and refs which git would take for options are rejected.
+	_, err = FetchBuildSource(repoPath+"/.git#--orphan=evil", filepath.Join(root, "clone-option"), &log)
+	assert.NotNil(err)
+	_, err = os.Stat(filepath.Join(root, "clone-option"))
+	assert.True(os.IsNotExist(err))
+	_, err = FetchBuildSource("--upload-pack=touch evil.git", filepath.Join(root, "clone-upload-pack"), &log)
+	assert.NotNil(err)
+}
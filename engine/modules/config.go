@@ -1,110 +1,346 @@
 package modules
 
 import (
-	"errors"
+	"encoding/json"
+	"fmt"
 	"math"
 	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxConstraintAttempts is the number of samples drawn from a map with constraints before we give up
+// on finding one that satisfies all of them.
+const maxConstraintAttempts = 1000
+
+// Scales of numeric domains. With the log scale values are distributed uniformly in the log space
+// between the bounds. With the exp scale the bounds are exponents of 10.
+const (
+	ScaleLinear = "linear"
+	ScaleLog    = "log"
+	ScaleExp    = "exp"
 )
 
 // ConfigElem is.
 type ConfigElem interface {
-	Sample() ConfigElem
+	// Sample draws a random sample. It fails if no sample satisfying the constraints was found.
+	Sample() (ConfigElem, error)
 	Expand(rangeCount int) []ConfigElem
 	Dump() interface{}
+
+	// Dims returns the number of coordinates consumed by SampleAt.
+	Dims() int
+
+	// SampleAt maps a point of the unit hypercube to a sample. It returns false if the sample
+	// violates a constraint of the space.
+	SampleAt(position []float64) (ConfigElem, bool)
+}
+
+// ConfigError is returned when a config space definition is invalid. The path points to the offending
+// element of the definition.
+type ConfigError struct {
+	Path    string
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Path == "" {
+		return "invalid config space: " + e.Message
+	}
+	return fmt.Sprintf("invalid config space at \"%s\": %s", e.Path, e.Message)
+}
+
+func configErrorf(path, format string, args ...interface{}) error {
+	return &ConfigError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// domainKeys lists the properties allowed in each type of domain object. The first one identifies the type.
+var domainKeys = map[string][]string{
+	".choice":    {".choice"},
+	".float":     {".float", ".scale", ".step"},
+	".int":       {".int", ".scale", ".step"},
+	".normal":    {".normal", ".step"},
+	".lognormal": {".lognormal", ".step"},
+	".when":      {".when", ".value"},
 }
 
 // LoadConfig is.
 func LoadConfig(input interface{}) (ConfigElem, error) {
-	if stringInput, ok := input.(string); ok {
-		return &ConfigConst{Value: stringInput}, nil
-	} else if floatInput, ok := input.(float64); ok {
-		return &ConfigConst{Value: floatInput}, nil
-	} else if mapInput, ok2 := input.(map[string]interface{}); ok2 {
-
-		// Check if we are dealing with a special element.
-		if _, ok := mapInput[".choice"]; ok {
-
-			if choices, ok := mapInput[".choice"].([]interface{}); ok {
-
-				loadedChoices := make([]ConfigElem, len(choices))
-				for i := range choices {
-					var err error
-					loadedChoices[i], err = LoadConfig(choices[i])
-					if err != nil {
-						return nil, errors.New("invalid input config format")
-					}
-				}
-				return &ConfigChoice{Choices: loadedChoices}, nil
+	return loadConfig(input, "")
+}
+
+// loadConfig loads a config element which is not a parameter of a map. Conditionals are not allowed here.
+func loadConfig(input interface{}, path string) (ConfigElem, error) {
+	result, err := loadConfigValue(input, path)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := result.(*ConfigCond); ok {
+		return nil, configErrorf(path, "\".when\" can only be used for parameters of an object")
+	}
+	return result, nil
+}
 
+func loadConfigValue(input interface{}, path string) (ConfigElem, error) {
+	switch value := input.(type) {
+	case string, float64, bool, nil:
+		return &ConfigConst{Value: value}, nil
+	case []interface{}:
+		for i := range value {
+			if _, ok := value[i].(map[string]interface{}); ok {
+				return nil, configErrorf(fmt.Sprintf("%s[%d]", path, i), "lists can contain only constants, use \".choice\" for a choice of objects")
 			}
-			return nil, errors.New("invalid input config format")
+		}
+		return &ConfigConst{Value: value}, nil
+	case map[string]interface{}:
+		return loadConfigObject(value, path)
+	}
+	return nil, configErrorf(path, "unsupported value type %T", input)
+}
 
-		} else if _, ok := mapInput[".float"]; ok {
+func loadConfigObject(input map[string]interface{}, path string) (ConfigElem, error) {
 
-			floatRange, ok := mapInput[".float"].([]interface{})
-			if ok == false {
-				return nil, errors.New("invalid input config format")
+	// Find out if we are dealing with a domain object.
+	domain := ""
+	for k := range input {
+		if _, ok := domainKeys[k]; ok {
+			if domain != "" {
+				return nil, configErrorf(path, "properties \"%s\" and \"%s\" cannot be used together", domain, k)
 			}
-			floatRangeFrom, ok := floatRange[0].(float64)
-			if ok == false {
-				return nil, errors.New("invalid input config format")
-			}
-			floatRangeTo, ok := floatRange[1].(float64)
-			if ok == false {
-				return nil, errors.New("invalid input config format")
+			domain = k
+		}
+	}
+	if domain == "" {
+		return loadConfigMap(input, path)
+	}
+	for k := range input {
+		if containsString(domainKeys[domain], k) == false {
+			return nil, configErrorf(path, "property \"%s\" is not allowed in a \"%s\" domain", k, domain)
+		}
+	}
+
+	switch domain {
+	case ".choice":
+		choices, ok := input[".choice"].([]interface{})
+		if ok == false || len(choices) == 0 {
+			return nil, configErrorf(path, "\".choice\" must be a non-empty list")
+		}
+		result := &ConfigChoice{Choices: make([]ConfigElem, len(choices))}
+		for i := range choices {
+			var err error
+			result.Choices[i], err = loadConfig(choices[i], fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
 			}
+		}
+		return result, nil
 
-			result := ConfigFloat{From: floatRangeFrom, To: floatRangeTo}
+	case ".float":
+		from, to, err := loadRange(input, ".float", path)
+		if err != nil {
+			return nil, err
+		}
+		result := &ConfigFloat{From: from, To: to}
+		result.Scale, err = loadScale(input, path, from)
+		if err != nil {
+			return nil, err
+		}
+		result.Step, err = loadStep(input, path, result.Scale)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
 
-			if scale, ok := mapInput[".scale"]; ok {
-				result.Scale, ok = scale.(string)
-				if ok == false {
-					return nil, errors.New("invalid input config format")
+	case ".int":
+		from, to, err := loadRange(input, ".int", path)
+		if err != nil {
+			return nil, err
+		}
+		if from != math.Round(from) || to != math.Round(to) {
+			return nil, configErrorf(path, "bounds of \".int\" must be integers")
+		}
+		result := &ConfigInt{From: int(from), To: int(to)}
+		result.Scale, err = loadScale(input, path, from)
+		if err != nil {
+			return nil, err
+		}
+		if result.Scale == ScaleExp && from < 0 {
+			// Negative powers of 10 are not integers.
+			return nil, configErrorf(path, "bounds of \".int\" must not be negative with the \"%s\" scale", ScaleExp)
+		}
+		step, err := loadStep(input, path, result.Scale)
+		if err != nil {
+			return nil, err
+		}
+		if step != math.Round(step) {
+			return nil, configErrorf(path, "\".step\" of an \".int\" domain must be an integer")
+		}
+		result.Step = int(step)
+		return result, nil
+
+	case ".normal", ".lognormal":
+		params, ok := input[domain].([]interface{})
+		if ok == false || len(params) != 2 {
+			return nil, configErrorf(path, "\"%s\" must be a list with the mean and the standard deviation", domain)
+		}
+		mean, ok1 := params[0].(float64)
+		std, ok2 := params[1].(float64)
+		if ok1 == false || ok2 == false {
+			return nil, configErrorf(path, "\"%s\" must be a list of two numbers", domain)
+		}
+		if std <= 0 {
+			return nil, configErrorf(path, "standard deviation of \"%s\" must be positive", domain)
+		}
+		step, err := loadStep(input, path, ScaleLinear)
+		if err != nil {
+			return nil, err
+		}
+		return &ConfigNormal{Mean: mean, Std: std, Log: domain == ".lognormal", Step: step}, nil
+
+	case ".when":
+		conditions, ok := input[".when"].(map[string]interface{})
+		if ok == false || len(conditions) == 0 {
+			return nil, configErrorf(path, "\".when\" must be an object which maps parameter names to their values")
+		}
+		result := &ConfigCond{When: map[string][]interface{}{}}
+		for k, v := range conditions {
+			if values, ok := v.([]interface{}); ok {
+				if len(values) == 0 {
+					return nil, configErrorf(joinConfigPath(path, ".when."+k), "list of accepted values cannot be empty")
 				}
+				result.When[k] = values
+			} else {
+				result.When[k] = []interface{}{v}
 			}
+		}
+		value, ok := input[".value"]
+		if ok == false {
+			return nil, configErrorf(path, "\".when\" requires a \".value\" property")
+		}
+		var err error
+		result.Value, err = loadConfig(value, path)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
 
-			return &result, nil
+	panic("unreachable")
+}
 
-		} else if _, ok := mapInput[".int"]; ok {
+func loadConfigMap(input map[string]interface{}, path string) (ConfigElem, error) {
+	result := &ConfigMap{Params: map[string]ConfigElem{}}
+	for k, v := range input {
+		if k == ".constraints" {
+			continue
+		} else if strings.HasPrefix(k, ".") {
+			return nil, configErrorf(path, "unknown property \"%s\"", k)
+		}
+		var err error
+		result.Params[k], err = loadConfigValue(v, joinConfigPath(path, k))
+		if err != nil {
+			return nil, err
+		}
+	}
 
-			intRange, ok := mapInput[".int"].([]interface{})
-			if ok == false {
-				return nil, errors.New("invalid input config format")
-			}
-			intRangeFrom, ok := intRange[0].(float64)
-			if ok == false {
-				return nil, errors.New("invalid input config format")
+	// Conditions must refer to sibling parameters without forming cycles.
+	for k, v := range result.Params {
+		if cond, ok := v.(*ConfigCond); ok {
+			for name := range cond.When {
+				if _, ok := result.Params[name]; ok == false {
+					return nil, configErrorf(joinConfigPath(path, k), "condition refers to unknown parameter \"%s\"", name)
+				}
 			}
-			intRangeTo, ok := intRange[1].(float64)
-			if ok == false {
-				return nil, errors.New("invalid input config format")
+		}
+	}
+	if _, err := result.order(); err != nil {
+		return nil, configErrorf(path, "%s", err.Error())
+	}
+
+	if constraints, ok := input[".constraints"]; ok {
+		list, ok := constraints.([]interface{})
+		if ok == false {
+			return nil, configErrorf(path, "\".constraints\" must be a list")
+		}
+		for i := range list {
+			constraint, err := loadConstraint(list[i], result, fmt.Sprintf("%s[%d]", joinConfigPath(path, ".constraints"), i))
+			if err != nil {
+				return nil, err
 			}
+			result.Constraints = append(result.Constraints, constraint)
+		}
+		if _, err := result.Sample(); err != nil {
+			return nil, configErrorf(path, "%s", err.Error())
+		}
+	}
 
-			result := ConfigInt{From: int(math.Round(intRangeFrom)), To: int(math.Round(intRangeTo))}
+	return result, nil
+}
 
-			if scale, ok := mapInput[".scale"]; ok {
-				result.Scale, ok = scale.(string)
-				if ok == false {
-					return nil, errors.New("invalid input config format")
-				}
-			}
+func loadRange(input map[string]interface{}, key, path string) (from, to float64, err error) {
+	bounds, ok := input[key].([]interface{})
+	if ok == false || len(bounds) != 2 {
+		return 0, 0, configErrorf(path, "\"%s\" must be a list with the lower and upper bound", key)
+	}
+	from, ok1 := bounds[0].(float64)
+	to, ok2 := bounds[1].(float64)
+	if ok1 == false || ok2 == false {
+		return 0, 0, configErrorf(path, "bounds of \"%s\" must be numbers", key)
+	}
+	if from > to {
+		return 0, 0, configErrorf(path, "lower bound of \"%s\" is greater than the upper bound", key)
+	}
+	return from, to, nil
+}
 
-			return &result, nil
+func loadScale(input map[string]interface{}, path string, from float64) (string, error) {
+	value, ok := input[".scale"]
+	if ok == false {
+		return "", nil
+	}
+	scale, ok := value.(string)
+	if ok == false || (scale != ScaleLinear && scale != ScaleLog && scale != ScaleExp) {
+		return "", configErrorf(path, "\".scale\" must be one of \"%s\", \"%s\" or \"%s\"", ScaleLinear, ScaleLog, ScaleExp)
+	}
+	if scale == ScaleLog && from <= 0 {
+		return "", configErrorf(path, "bounds must be positive with the \"%s\" scale", ScaleLog)
+	}
+	return scale, nil
+}
 
-		} else {
+func loadStep(input map[string]interface{}, path string, scale string) (float64, error) {
+	value, ok := input[".step"]
+	if ok == false {
+		return 0, nil
+	}
+	step, ok := value.(float64)
+	if ok == false || step <= 0 {
+		return 0, configErrorf(path, "\".step\" must be a positive number")
+	}
+	if scale != "" && scale != ScaleLinear {
+		return 0, configErrorf(path, "\".step\" can only be used with the \"%s\" scale", ScaleLinear)
+	}
+	return step, nil
+}
 
-			result := ConfigMap{}
-			for k, v := range mapInput {
-				var err error
-				result[k], err = LoadConfig(v)
-				if err != nil {
-					return nil, errors.New("invalid input config format")
-				}
-			}
-			return &result, nil
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func containsString(list []string, s string) bool {
+	for i := range list {
+		if list[i] == s {
+			return true
 		}
 	}
-	return nil, errors.New("invalid input config format")
+	return false
 }
 
 // ConfigConst is.
@@ -113,7 +349,7 @@ type ConfigConst struct {
 }
 
 // Sample is.
-func (c *ConfigConst) Sample() ConfigElem { return c }
+func (c *ConfigConst) Sample() (ConfigElem, error) { return c, nil }
 
 // Expand is.
 func (c *ConfigConst) Expand(rangeCount int) []ConfigElem { return []ConfigElem{c} }
@@ -121,16 +357,30 @@ func (c *ConfigConst) Expand(rangeCount int) []ConfigElem { return []ConfigElem{
 // Dump is.
 func (c *ConfigConst) Dump() interface{} { return c.Value }
 
+// Dims is.
+func (c *ConfigConst) Dims() int { return 0 }
+
+// SampleAt is.
+func (c *ConfigConst) SampleAt(position []float64) (ConfigElem, bool) { return c, true }
+
 // ConfigChoice is.
 type ConfigChoice struct {
 	Choices []ConfigElem
 }
 
 // Sample is.
-func (c *ConfigChoice) Sample() ConfigElem { return c.Choices[rand.Intn(len(c.Choices))] }
+func (c *ConfigChoice) Sample() (ConfigElem, error) {
+	return c.Choices[rand.Intn(len(c.Choices))].Sample()
+}
 
 // Expand is.
-func (c *ConfigChoice) Expand(rangeCount int) []ConfigElem { return c.Choices }
+func (c *ConfigChoice) Expand(rangeCount int) []ConfigElem {
+	result := []ConfigElem{}
+	for i := range c.Choices {
+		result = append(result, c.Choices[i].Expand(rangeCount)...)
+	}
+	return result
+}
 
 // Dump is.
 func (c *ConfigChoice) Dump() interface{} {
@@ -141,40 +391,71 @@ func (c *ConfigChoice) Dump() interface{} {
 	for i := range c.Choices {
 		choices[i] = c.Choices[i].Dump()
 	}
-	result[".choices"] = choices
+	result[".choice"] = choices
+
+	return result
+}
 
+// Dims is. The first coordinate picks the choice and every choice has its own coordinates after it.
+func (c *ConfigChoice) Dims() int {
+	result := 1
+	for i := range c.Choices {
+		result += c.Choices[i].Dims()
+	}
 	return result
 }
 
+// SampleAt is.
+func (c *ConfigChoice) SampleAt(position []float64) (ConfigElem, bool) {
+	choice := minInt(int(position[0]*float64(len(c.Choices))), len(c.Choices)-1)
+	offset := 1
+	for i := 0; i < choice; i++ {
+		offset += c.Choices[i].Dims()
+	}
+	return c.Choices[choice].SampleAt(position[offset : offset+c.Choices[choice].Dims()])
+}
+
 // ConfigFloat is.
 type ConfigFloat struct {
 	From, To float64
 	Scale    string
+	Step     float64
 }
 
 // Sample is.
-func (c *ConfigFloat) Sample() ConfigElem {
-	if c.Scale == "linear" || c.Scale == "" {
-		return &ConfigConst{Value: rand.Float64()*(c.To-c.From) + c.From}
-	}
-	panic("not implemented yet")
+func (c *ConfigFloat) Sample() (ConfigElem, error) {
+	return &ConfigConst{Value: c.value(rand.Float64())}, nil
 }
 
 // Expand is.
 func (c *ConfigFloat) Expand(rangeCount int) []ConfigElem {
-	result := make([]ConfigElem, rangeCount)
-	for i := range result {
-		if c.Scale == "linear" || c.Scale == "" {
-			result[i] = &ConfigConst{
-				Value: ((c.To-c.From)/float64(rangeCount))*float64(i) + c.From,
-			}
-		} else {
-			panic("not implemented yet")
-		}
+	values := make([]interface{}, rangeCount)
+	for i := range values {
+		values[i] = c.value(float64(i) / float64(rangeCount))
+	}
+	return uniqueConsts(values)
+}
+
+// value maps a position between 0 and 1 to a value of the domain.
+func (c *ConfigFloat) value(position float64) float64 {
+	switch c.Scale {
+	case ScaleLog:
+		return math.Exp(math.Log(c.From) + position*(math.Log(c.To)-math.Log(c.From)))
+	case ScaleExp:
+		return math.Pow(10, c.From+position*(c.To-c.From))
+	}
+	result := c.From + position*(c.To-c.From)
+	if c.Step > 0 {
+		result = roundFloat(c.From + math.Min(math.Round((result-c.From)/c.Step), c.lastStep())*c.Step)
 	}
 	return result
 }
 
+// lastStep returns the number of steps from the lower bound to the last grid point within the bounds.
+func (c *ConfigFloat) lastStep() float64 {
+	return math.Floor(roundFloat((c.To - c.From) / c.Step))
+}
+
 // Dump is.
 func (c *ConfigFloat) Dump() interface{} {
 	result := map[string]interface{}{}
@@ -183,101 +464,353 @@ func (c *ConfigFloat) Dump() interface{} {
 	if c.Scale != "" {
 		result[".scale"] = c.Scale
 	}
+	if c.Step > 0 {
+		result[".step"] = c.Step
+	}
 
 	return result
 }
 
+// Dims is.
+func (c *ConfigFloat) Dims() int { return 1 }
+
+// SampleAt is.
+func (c *ConfigFloat) SampleAt(position []float64) (ConfigElem, bool) {
+	return &ConfigConst{Value: c.value(position[0])}, true
+}
+
 // ConfigInt is.
 type ConfigInt struct {
 	From, To int
 	Scale    string
+	Step     int
 }
 
 // Sample is.
-func (c *ConfigInt) Sample() ConfigElem {
-	if c.Scale == "linear" || c.Scale == "" {
-		return &ConfigConst{Value: rand.Intn(c.To-c.From) + c.From}
+func (c *ConfigInt) Sample() (ConfigElem, error) {
+	return &ConfigConst{Value: c.value(rand.Float64())}, nil
+}
+
+// value maps a position between 0 and 1 to a value of the domain. Every value is reachable.
+func (c *ConfigInt) value(position float64) int {
+	switch c.Scale {
+	case ScaleLog:
+		// Sampling from [From, To + 1) and rounding down makes every integer reachable.
+		value := math.Exp(math.Log(float64(c.From)) + position*(math.Log(float64(c.To+1))-math.Log(float64(c.From))))
+		return minInt(int(value), c.To)
+	case ScaleExp:
+		return pow10(c.From + minInt(int(position*float64(c.To-c.From+1)), c.To-c.From))
 	}
-	panic("not implemented yet")
+	count := c.size()
+	return c.From + minInt(int(position*float64(count)), count-1)*c.step()
 }
 
-// Expand is.
+// Expand is. No more values are returned than the domain contains.
 func (c *ConfigInt) Expand(rangeCount int) []ConfigElem {
-	result := make([]ConfigElem, rangeCount)
-	for i := range result {
-		if c.Scale == "linear" || c.Scale == "" {
-			result[i] = &ConfigConst{
-				Value: ((c.To-c.From)/rangeCount)*i + c.From,
-			}
-		} else {
-			panic("not implemented yet")
-		}
+	if size := c.size(); rangeCount > size {
+		rangeCount = size
 	}
-	return result
+	values := make([]interface{}, rangeCount)
+	for i := range values {
+		values[i] = c.value(float64(i) / float64(rangeCount))
+	}
+	return uniqueConsts(values)
+}
+
+// size returns the number of distinct values of the domain.
+func (c *ConfigInt) size() int {
+	if c.Scale == ScaleLog || c.Scale == ScaleExp {
+		return c.To - c.From + 1
+	}
+	return (c.To-c.From)/c.step() + 1
+}
+
+func (c *ConfigInt) step() int {
+	if c.Step > 0 {
+		return c.Step
+	}
+	return 1
 }
 
 // Dump is.
 func (c *ConfigInt) Dump() interface{} {
 	result := map[string]interface{}{}
 
-	result[".float"] = []int{c.From, c.To}
+	result[".int"] = []int{c.From, c.To}
 	if c.Scale != "" {
 		result[".scale"] = c.Scale
 	}
+	if c.Step > 0 {
+		result[".step"] = c.Step
+	}
 
 	return result
 }
 
-// ConfigMap is.
-type ConfigMap map[string]ConfigElem
+// Dims is.
+func (c *ConfigInt) Dims() int { return 1 }
+
+// SampleAt is.
+func (c *ConfigInt) SampleAt(position []float64) (ConfigElem, bool) {
+	return &ConfigConst{Value: c.value(position[0])}, true
+}
+
+// ConfigNormal is a real domain with a normal prior. If Log is set, the logarithm of the value is
+// normally distributed.
+type ConfigNormal struct {
+	Mean, Std float64
+	Log       bool
+	Step      float64
+}
 
 // Sample is.
-func (c *ConfigMap) Sample() ConfigElem {
-	result := ConfigMap{}
-	for k, v := range *c {
-		result[k] = v.Sample()
+func (c *ConfigNormal) Sample() (ConfigElem, error) {
+	return &ConfigConst{Value: c.value(rand.NormFloat64())}, nil
+}
+
+// Expand returns values at evenly spaced quantiles of the distribution.
+func (c *ConfigNormal) Expand(rangeCount int) []ConfigElem {
+	values := make([]interface{}, rangeCount)
+	for i := range values {
+		p := (float64(i) + 0.5) / float64(rangeCount)
+		values[i] = c.value(math.Sqrt2 * math.Erfinv(2*p-1))
+	}
+	return uniqueConsts(values)
+}
+
+func (c *ConfigNormal) value(z float64) float64 {
+	result := c.Mean + z*c.Std
+	if c.Log {
+		result = math.Exp(result)
+	}
+	if c.Step > 0 {
+		result = math.Round(result/c.Step) * c.Step
 	}
-	return &result
+	return result
 }
 
+// Dump is.
+func (c *ConfigNormal) Dump() interface{} {
+	result := map[string]interface{}{}
+
+	if c.Log {
+		result[".lognormal"] = []float64{c.Mean, c.Std}
+	} else {
+		result[".normal"] = []float64{c.Mean, c.Std}
+	}
+	if c.Step > 0 {
+		result[".step"] = c.Step
+	}
+
+	return result
+}
+
+// Dims is.
+func (c *ConfigNormal) Dims() int { return 1 }
+
+// SampleAt maps the position through the inverse of the normal CDF.
+func (c *ConfigNormal) SampleAt(position []float64) (ConfigElem, bool) {
+	p := math.Min(math.Max(position[0], 1e-12), 1-1e-12)
+	return &ConfigConst{Value: c.value(math.Sqrt2 * math.Erfinv(2*p-1))}, true
+}
+
+// ConfigCond is a parameter which is present only when the sibling parameters have one of the given values.
+type ConfigCond struct {
+	When  map[string][]interface{}
+	Value ConfigElem
+}
+
+// Sample is. Conditions are resolved by the enclosing map so a conditional on its own is sampled as its value.
+func (c *ConfigCond) Sample() (ConfigElem, error) { return c.Value.Sample() }
+
 // Expand is.
-func (c *ConfigMap) Expand(rangeCount int) []ConfigElem {
+func (c *ConfigCond) Expand(rangeCount int) []ConfigElem { return c.Value.Expand(rangeCount) }
 
-	expansions := map[string][]ConfigElem{}
-	keys := []string{}
-	counts := []int{}
-	for k, v := range *c {
-		expansions[k] = v.Expand(rangeCount)
-		keys = append(keys, k)
-		counts = append(counts, len(expansions[k]))
+// Dump is.
+func (c *ConfigCond) Dump() interface{} {
+	when := map[string]interface{}{}
+	for k, v := range c.When {
+		when[k] = v
 	}
+	return map[string]interface{}{".when": when, ".value": c.Value.Dump()}
+}
 
-	result := []ConfigElem{}
-	pos := make([]int, len(keys))
-	for {
-
-		// Build the current iteration of expansions.
-		newMap := ConfigMap{}
-		for i, k := range keys {
-			newMap[k] = expansions[k][pos[i]]
-		}
-		result = append(result, &newMap)
-
-		// Adjust positions.
-		allZeros := true
-		for i := range pos {
-			pos[i] = (pos[i] + 1) % counts[i]
-			if pos[i] > 0 {
-				allZeros = false
+// Dims is.
+func (c *ConfigCond) Dims() int { return c.Value.Dims() }
+
+// SampleAt is.
+func (c *ConfigCond) SampleAt(position []float64) (ConfigElem, bool) {
+	return c.Value.SampleAt(position)
+}
+
+// holds checks the condition against the sampled sibling parameters.
+func (c *ConfigCond) holds(params map[string]ConfigElem) bool {
+	for name, accepted := range c.When {
+		param, ok := params[name]
+		if ok == false {
+			return false
+		}
+		found := false
+		for i := range accepted {
+			if configValuesEqual(param.Dump(), accepted[i]) {
+				found = true
 				break
 			}
 		}
+		if found == false {
+			return false
+		}
+	}
+	return true
+}
+
+// ConfigConstraint compares two parameters of a map, or a parameter and a constant. Operands which are
+// strings refer to parameters. Constraints involving parameters which are not present are satisfied.
+type ConfigConstraint struct {
+	Left, Right interface{}
+	Op          string
+}
+
+var constraintOps = []string{"<", "<=", ">", ">=", "==", "!="}
+
+func loadConstraint(input interface{}, params *ConfigMap, path string) (ConfigConstraint, error) {
+	list, ok := input.([]interface{})
+	if ok == false || len(list) != 3 {
+		return ConfigConstraint{}, configErrorf(path, "constraint must be a list of the form [left, operator, right]")
+	}
+	op, ok := list[1].(string)
+	if ok == false || containsString(constraintOps, op) == false {
+		return ConfigConstraint{}, configErrorf(path, "operator must be one of %s", strings.Join(constraintOps, ", "))
+	}
+	for _, operand := range []interface{}{list[0], list[2]} {
+		switch value := operand.(type) {
+		case string:
+			if _, ok := params.Params[value]; ok == false {
+				return ConfigConstraint{}, configErrorf(path, "constraint refers to unknown parameter \"%s\"", value)
+			}
+		case float64:
+		default:
+			return ConfigConstraint{}, configErrorf(path, "operands must be parameter names or numbers")
+		}
+	}
+	return ConfigConstraint{Left: list[0], Op: op, Right: list[2]}, nil
+}
 
-		// If we are back to all zeros, then we have enumerated them all.
-		if allZeros {
-			break
+func (c ConfigConstraint) holds(params map[string]ConfigElem) bool {
+	operand := func(o interface{}) (interface{}, bool) {
+		if name, ok := o.(string); ok {
+			param, ok := params[name]
+			if ok == false {
+				return nil, false
+			}
+			return param.Dump(), true
 		}
+		return o, true
+	}
+	left, ok1 := operand(c.Left)
+	right, ok2 := operand(c.Right)
+	if ok1 == false || ok2 == false {
+		return true
+	}
+
+	switch c.Op {
+	case "==":
+		return configValuesEqual(left, right)
+	case "!=":
+		return configValuesEqual(left, right) == false
+	}
+	l, ok1 := toFloat(left)
+	r, ok2 := toFloat(right)
+	if ok1 == false || ok2 == false {
+		return false
+	}
+	switch c.Op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
 	}
+	return l >= r
+}
+
+func (c ConfigConstraint) dump() interface{} {
+	return []interface{}{c.Left, c.Op, c.Right}
+}
+
+// ConfigMap is.
+type ConfigMap struct {
+	Params      map[string]ConfigElem
+	Constraints []ConfigConstraint
+}
+
+// Sample is. Samples are drawn until one satisfies all constraints or the number of attempts runs out.
+func (c *ConfigMap) Sample() (ConfigElem, error) {
+	order, err := c.order()
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; attempt < maxConstraintAttempts; attempt++ {
+		params := map[string]ConfigElem{}
+		for _, k := range order {
+			v := c.Params[k]
+			if cond, ok := v.(*ConfigCond); ok {
+				if cond.holds(params) == false {
+					continue
+				}
+				v = cond.Value
+			}
+			params[k], err = v.Sample()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if c.satisfies(params) {
+			return &ConfigMap{Params: params}, nil
+		}
+	}
+	return nil, errors.Errorf("no sample satisfying all constraints was found in %d attempts", maxConstraintAttempts)
+}
+
+// Expand is.
+func (c *ConfigMap) Expand(rangeCount int) []ConfigElem {
+	order, err := c.order()
+	if err != nil {
+		panic(err)
+	}
+
+	result := []ConfigElem{}
+	params := map[string]ConfigElem{}
+	var expand func(i int)
+	expand = func(i int) {
+		if i == len(order) {
+			if c.satisfies(params) {
+				expansion := &ConfigMap{Params: map[string]ConfigElem{}}
+				for k, v := range params {
+					expansion.Params[k] = v
+				}
+				result = append(result, expansion)
+			}
+			return
+		}
+		k := order[i]
+		v := c.Params[k]
+		if cond, ok := v.(*ConfigCond); ok {
+			if cond.holds(params) == false {
+				expand(i + 1)
+				return
+			}
+			v = cond.Value
+		}
+		for _, e := range v.Expand(rangeCount) {
+			params[k] = e
+			expand(i + 1)
+		}
+		delete(params, k)
+	}
+	expand(0)
+
 	return result
 }
 
@@ -285,9 +818,312 @@ func (c *ConfigMap) Expand(rangeCount int) []ConfigElem {
 func (c *ConfigMap) Dump() interface{} {
 	result := map[string]interface{}{}
 
-	for k, v := range *c {
+	for k, v := range c.Params {
 		result[k] = v.Dump()
 	}
+	if len(c.Constraints) > 0 {
+		constraints := make([]interface{}, len(c.Constraints))
+		for i := range c.Constraints {
+			constraints[i] = c.Constraints[i].dump()
+		}
+		result[".constraints"] = constraints
+	}
+
+	return result
+}
 
+// Dims is.
+func (c *ConfigMap) Dims() int {
+	result := 0
+	for _, v := range c.Params {
+		result += v.Dims()
+	}
 	return result
 }
+
+// SampleAt is. Parameters take their coordinates in the order of their names. Conditional parameters
+// which are not present keep their coordinates so that the layout does not depend on the position.
+func (c *ConfigMap) SampleAt(position []float64) (ConfigElem, bool) {
+	names := make([]string, 0, len(c.Params))
+	for k := range c.Params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	offsets := map[string]int{}
+	offset := 0
+	for _, k := range names {
+		offsets[k] = offset
+		offset += c.Params[k].Dims()
+	}
+
+	order, err := c.order()
+	if err != nil {
+		panic(err)
+	}
+	params := map[string]ConfigElem{}
+	for _, k := range order {
+		v := c.Params[k]
+		if cond, ok := v.(*ConfigCond); ok && cond.holds(params) == false {
+			continue
+		}
+		sample, ok := v.SampleAt(position[offsets[k] : offsets[k]+v.Dims()])
+		if ok == false {
+			return nil, false
+		}
+		params[k] = sample
+	}
+	if c.satisfies(params) == false {
+		return nil, false
+	}
+	return &ConfigMap{Params: params}, true
+}
+
+func (c *ConfigMap) satisfies(params map[string]ConfigElem) bool {
+	for i := range c.Constraints {
+		if c.Constraints[i].holds(params) == false {
+			return false
+		}
+	}
+	return true
+}
+
+// order returns the parameter names sorted so that every conditional parameter comes after the
+// parameters its condition refers to.
+func (c *ConfigMap) order() ([]string, error) {
+	names := make([]string, 0, len(c.Params))
+	for k := range c.Params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	result := make([]string, 0, len(names))
+	state := map[string]int{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			return errors.Errorf("conditions of parameter \"%s\" form a cycle", name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		if cond, ok := c.Params[name].(*ConfigCond); ok {
+			deps := make([]string, 0, len(cond.When))
+			for dep := range cond.When {
+				deps = append(deps, dep)
+			}
+			sort.Strings(deps)
+			for _, dep := range deps {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = 2
+		result = append(result, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// CanonicalConfig returns the canonical JSON form of a sampled config. Configs which are equal have equal
+// canonical forms regardless of key order, whitespace and number formatting.
+func CanonicalConfig(config interface{}) (string, error) {
+	value := config
+	if elem, err := LoadConfig(config); err == nil {
+		value = elem.Dump()
+	}
+	result, err := json.Marshal(roundConfigFloats(value))
+	if err != nil {
+		return "", errors.Wrap(err, "config encode error")
+	}
+	return string(result), nil
+}
+
+// floatPrecision is the number of significant digits of float values in canonical configs. It hides the
+// rounding errors of adding up steps so that 0.1 * 3 equals 0.3.
+const floatPrecision = 12
+
+func roundFloat(value float64) float64 {
+	result, err := strconv.ParseFloat(strconv.FormatFloat(value, 'g', floatPrecision, 64), 64)
+	if err != nil {
+		return value
+	}
+	return result
+}
+
+// roundConfigFloats returns a copy of a config value with all floats rounded to floatPrecision digits.
+func roundConfigFloats(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		return roundFloat(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i := range v {
+			result[i] = roundConfigFloats(v[i])
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k := range v {
+			result[k] = roundConfigFloats(v[k])
+		}
+		return result
+	}
+	return value
+}
+
+// EnumerateConfigs lists all configs of a finite config space. It returns false if the space is infinite
+// or has more than limit configs.
+func EnumerateConfigs(space ConfigElem, limit int) ([]ConfigElem, bool) {
+	result, ok := enumerateConfigs(space, limit)
+	return result, ok && len(result) <= limit
+}
+
+func enumerateConfigs(space ConfigElem, limit int) ([]ConfigElem, bool) {
+	values := []interface{}{}
+	switch c := space.(type) {
+	case *ConfigConst:
+		return []ConfigElem{c}, true
+	case *ConfigCond:
+		return enumerateConfigs(c.Value, limit)
+	case *ConfigChoice:
+		result := []ConfigElem{}
+		for i := range c.Choices {
+			choices, ok := enumerateConfigs(c.Choices[i], limit-len(result))
+			if ok == false {
+				return nil, false
+			}
+			result = append(result, choices...)
+		}
+		return result, len(result) <= limit
+	case *ConfigInt:
+		switch c.Scale {
+		case ScaleLog:
+			for v := c.From; v <= c.To && len(values) <= limit; v++ {
+				values = append(values, v)
+			}
+		case ScaleExp:
+			for v := c.From; v <= c.To && len(values) <= limit; v++ {
+				values = append(values, pow10(v))
+			}
+		default:
+			for v := c.From; v <= c.To && len(values) <= limit; v += c.step() {
+				values = append(values, v)
+			}
+		}
+	case *ConfigFloat:
+		if c.Scale != "" && c.Scale != ScaleLinear || c.Step <= 0 || c.lastStep() > float64(limit) {
+			return nil, false
+		}
+		last := c.lastStep()
+		for k := 0.0; k <= last; k++ {
+			values = append(values, roundFloat(c.From+k*c.Step))
+		}
+	case *ConfigMap:
+		return c.enumerate(limit)
+	default:
+		return nil, false
+	}
+	return uniqueConsts(values), len(values) <= limit
+}
+
+// enumerate lists the configs of a map in the same way as Expand but with all values of each parameter.
+func (c *ConfigMap) enumerate(limit int) ([]ConfigElem, bool) {
+	order, err := c.order()
+	if err != nil {
+		panic(err)
+	}
+
+	result := []ConfigElem{}
+	params := map[string]ConfigElem{}
+	var enumerate func(i int) bool
+	enumerate = func(i int) bool {
+		if i == len(order) {
+			if c.satisfies(params) {
+				expansion := &ConfigMap{Params: map[string]ConfigElem{}}
+				for k, v := range params {
+					expansion.Params[k] = v
+				}
+				result = append(result, expansion)
+			}
+			return len(result) <= limit
+		}
+		k := order[i]
+		v := c.Params[k]
+		if cond, ok := v.(*ConfigCond); ok {
+			if cond.holds(params) == false {
+				return enumerate(i + 1)
+			}
+			v = cond.Value
+		}
+		values, ok := enumerateConfigs(v, limit)
+		if ok == false {
+			return false
+		}
+		for _, e := range values {
+			params[k] = e
+			if enumerate(i+1) == false {
+				return false
+			}
+		}
+		delete(params, k)
+		return true
+	}
+	if enumerate(0) == false {
+		return nil, false
+	}
+	return result, true
+}
+
+// configValuesEqual compares dumped config values. Numbers are compared regardless of their type.
+func configValuesEqual(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func uniqueConsts(values []interface{}) []ConfigElem {
+	result := []ConfigElem{}
+	for i := range values {
+		duplicate := false
+		for j := 0; j < i; j++ {
+			if values[i] == values[j] {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate == false {
+			result = append(result, &ConfigConst{Value: values[i]})
+		}
+	}
+	return result
+}
+
+func pow10(exponent int) int {
+	return int(math.Pow(10, float64(exponent)))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -3,6 +3,7 @@ package modules
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,7 +48,7 @@ func TestSample(t *testing.T) {
 	conf, err := LoadConfig(input)
 	assert.Nil(err)
 
-	sample := conf.Sample()
+	sample := sampleConfig(t, conf)
 	assert.NotNil(sample)
 
 	b, err := json.Marshal(sample.Dump())
@@ -77,3 +78,278 @@ func TestExpand(t *testing.T) {
 	expansion := conf.Expand(3)
 	assert.Equal(27, len(expansion))
 }
+
+func loadConfigJSON(t *testing.T, src string) (ConfigElem, error) {
+	var input interface{}
+	if err := json.Unmarshal([]byte(src), &input); err != nil {
+		t.Fatal(err)
+	}
+	return LoadConfig(input)
+}
+
+func sampleConfig(t *testing.T, conf ConfigElem) ConfigElem {
+	sample, err := conf.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sample
+}
+
+func TestLoadErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := map[string]string{
+		`{"a": {".float": [1]}}`:                                                         `"a"`,
+		`{"a": {".float": [2, 1]}}`:                                                      "lower bound",
+		`{"a": {".int": [1.5, 3]}}`:                                                      "integers",
+		`{"a": {".float": [0, 1], ".scale": "log"}}`:                                     "positive",
+		`{"a": {".float": [1, 2], ".scale": "cubic"}}`:                                   ".scale",
+		`{"a": {".float": [1, 2], ".scale": "log", ".step": 0.1}}`:                       ".step",
+		`{"a": {".choice": []}}`:                                                         "non-empty",
+		`{"a": {".choice": [1], ".float": [1, 2]}}`:                                      "cannot be used together",
+		`{"a": {".float": [1, 2], ".foo": 1}}`:                                           "not allowed",
+		`{"a": {".normal": [0, -1]}}`:                                                    "standard deviation",
+		`{"a": {".when": {"b": 1}, ".value": 2}}`:                                        "unknown parameter",
+		`{"a": {".when": {"b": 1}, ".value": 2}, "b": {".when": {"a": 2}, ".value": 1}}`: "cycle",
+		`{"a": {".choice": [{".when": {"b": 1}, ".value": 2}]}}`:                         "can only be used",
+		`{"a": 1, ".constraints": [["a", "<", "b"]]}`:                                    "unknown parameter",
+		`{"a": 1, ".constraints": [["a", "~", 2]]}`:                                      "operator",
+		`{"a": {".int": [1, 3]}, ".constraints": [["a", ">", 5]]}`:                       "no sample",
+		`{"a": {".foo": 1}}`:                                                             "unknown property",
+		`{"a": {".int": [-3, -1], ".scale": "exp"}}`:                                     "must not be negative",
+	}
+	for src, expected := range cases {
+		_, err := loadConfigJSON(t, src)
+		if assert.NotNil(err, src) {
+			assert.Contains(err.Error(), expected, src)
+			_, ok := err.(*ConfigError)
+			assert.True(ok, src)
+		}
+	}
+}
+
+func TestExpandSmallRange(t *testing.T) {
+	assert := assert.New(t)
+
+	// Expanding to more values than the domain contains returns each value once.
+	values := []interface{}{}
+	for _, e := range (&ConfigInt{From: 1, To: 3}).Expand(10) {
+		values = append(values, e.Dump())
+	}
+	assert.Equal([]interface{}{1, 2, 3}, values)
+
+	values = []interface{}{}
+	for _, e := range (&ConfigInt{From: 0, To: 100, Step: 25}).Expand(4) {
+		values = append(values, e.Dump())
+	}
+	assert.Equal([]interface{}{0, 25, 50, 75}, values)
+}
+
+func TestSampleUnsatisfiable(t *testing.T) {
+	conf := &ConfigMap{
+		Params:      map[string]ConfigElem{"a": &ConfigInt{From: 1, To: 3}},
+		Constraints: []ConfigConstraint{{Left: "a", Op: ">", Right: 5.0}},
+	}
+	_, err := conf.Sample()
+	assert.NotNil(t, err)
+}
+
+func TestConditional(t *testing.T) {
+	assert := assert.New(t)
+
+	conf, err := loadConfigJSON(t, `{
+		"optimizer": {".choice": ["sgd", "adam"]},
+		"momentum": {".when": {"optimizer": "sgd"}, ".value": {".float": [0, 0.9]}},
+		"nesterov": {".when": {"momentum": [0, 0.3]}, ".value": true}
+	}`)
+	assert.Nil(err)
+
+	for i := 0; i < 50; i++ {
+		sample := sampleConfig(t, conf).Dump().(map[string]interface{})
+		_, hasMomentum := sample["momentum"]
+		assert.Equal(sample["optimizer"] == "sgd", hasMomentum)
+	}
+
+	expansion := conf.Expand(3)
+	assert.Len(expansion, 4)
+	for _, e := range expansion {
+		values := e.Dump().(map[string]interface{})
+		_, hasNesterov := values["nesterov"]
+		assert.Equal(values["momentum"] == 0.0 || values["momentum"] == 0.3, hasNesterov)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	assert := assert.New(t)
+
+	conf, err := loadConfigJSON(t, `{
+		"min_samples": {".int": [1, 10]},
+		"max_samples": {".int": [1, 10]},
+		".constraints": [["min_samples", "<=", "max_samples"], ["max_samples", "!=", 7]]
+	}`)
+	assert.Nil(err)
+
+	for i := 0; i < 50; i++ {
+		sample := sampleConfig(t, conf).Dump().(map[string]interface{})
+		assert.True(sample["min_samples"].(int) <= sample["max_samples"].(int))
+		assert.NotEqual(7, sample["max_samples"])
+	}
+	for _, e := range conf.Expand(10) {
+		values := e.Dump().(map[string]interface{})
+		assert.True(values["min_samples"].(int) <= values["max_samples"].(int))
+		assert.NotEqual(7, values["max_samples"])
+	}
+}
+
+func TestScalesAndDistributions(t *testing.T) {
+	assert := assert.New(t)
+
+	conf, err := loadConfigJSON(t, `{
+		"lr": {".float": [0.0001, 0.1], ".scale": "log"},
+		"decay": {".float": [-4, -1], ".scale": "exp"},
+		"units": {".int": [16, 512], ".scale": "log"},
+		"batch": {".int": [8, 64], ".step": 8},
+		"dropout": {".float": [0, 0.5], ".step": 0.1},
+		"noise": {".normal": [0, 1]},
+		"scale": {".lognormal": [0, 0.5], ".step": 0.01}
+	}`)
+	assert.Nil(err)
+
+	for i := 0; i < 100; i++ {
+		sample := sampleConfig(t, conf).Dump().(map[string]interface{})
+		lr := sample["lr"].(float64)
+		assert.True(lr >= 0.0001 && lr <= 0.1)
+		decay := sample["decay"].(float64)
+		assert.True(decay >= 0.0001 && decay <= 0.1)
+		units := sample["units"].(int)
+		assert.True(units >= 16 && units <= 512)
+		assert.Equal(0, sample["batch"].(int)%8)
+		dropout := sample["dropout"].(float64)
+		assert.InDelta(math.Round(dropout*10)/10, dropout, 1e-9)
+		assert.True(sample["scale"].(float64) >= 0)
+	}
+
+	noise := conf.(*ConfigMap).Params["noise"].Expand(3)
+	assert.Len(noise, 3)
+	assert.InDelta(0.0, noise[1].Dump().(float64), 1e-9)
+	assert.True(noise[0].Dump().(float64) < 0)
+
+	batch := conf.(*ConfigMap).Params["batch"].Expand(20)
+	for _, b := range batch {
+		assert.Equal(0, b.Dump().(int)%8)
+	}
+}
+
+func TestDumpRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	src := `{
+		"a": {".choice": ["x", {"b": {".int": [1, 4], ".step": 1}}]},
+		"c": {".float": [1, 2], ".scale": "log"},
+		"d": {".when": {"a": "x"}, ".value": {".lognormal": [0, 1]}},
+		"e": [1, 2, 3],
+		"f": {".normal": [1, 2], ".step": 0.5},
+		".constraints": [["c", "<", 1.5]]
+	}`
+	conf, err := loadConfigJSON(t, src)
+	assert.Nil(err)
+
+	dumped, err := json.Marshal(conf.Dump())
+	assert.Nil(err)
+	reloaded, err := loadConfigJSON(t, string(dumped))
+	assert.Nil(err)
+	assert.Equal(conf, reloaded)
+}
+
+func TestCanonicalConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	var a, b interface{}
+	assert.Nil(json.Unmarshal([]byte(`{"b": 1.0, "a": {"y": [1, 2], "x": "s"}}`), &a))
+	assert.Nil(json.Unmarshal([]byte(`{ "a": {"x": "s", "y": [1.0, 2.0]}, "b": 1 }`), &b))
+	canonicalA, err := CanonicalConfig(a)
+	assert.Nil(err)
+	canonicalB, err := CanonicalConfig(b)
+	assert.Nil(err)
+	assert.Equal(`{"a":{"x":"s","y":[1,2]},"b":1}`, canonicalA)
+	assert.Equal(canonicalA, canonicalB)
+
+	// Sampled configs have the same canonical form as their decoded JSON.
+	conf, err := loadConfigJSON(t, `{"depth": {".int": [1, 3]}, "rate": {".float": [0, 1]}}`)
+	assert.Nil(err)
+	sample := sampleConfig(t, conf)
+	canonicalSample, err := CanonicalConfig(sample.Dump())
+	assert.Nil(err)
+	var decoded interface{}
+	assert.Nil(json.Unmarshal([]byte(canonicalSample), &decoded))
+	canonicalDecoded, err := CanonicalConfig(decoded)
+	assert.Nil(err)
+	assert.Equal(canonicalSample, canonicalDecoded)
+}
+
+func TestEnumerateConfigs(t *testing.T) {
+	assert := assert.New(t)
+
+	conf, err := loadConfigJSON(t, `{
+		"optimizer": {".choice": ["sgd", "adam"]},
+		"momentum": {".when": {"optimizer": "sgd"}, ".value": {".float": [0, 1], ".step": 0.5}},
+		"depth": {".int": [1, 4]},
+		"width": {".int": [0, 2], ".scale": "exp"},
+		".constraints": [["depth", "<=", 2]]
+	}`)
+	assert.Nil(err)
+	configs, ok := EnumerateConfigs(conf, 1000)
+	assert.True(ok)
+	// sgd has 3 momentum values, adam has none, depth has 2 values and width has 3.
+	assert.Len(configs, (3+1)*2*3)
+	dumps := map[string]bool{}
+	for i := range configs {
+		canonical, err := CanonicalConfig(configs[i].Dump())
+		assert.Nil(err)
+		dumps[canonical] = true
+	}
+	assert.Len(dumps, len(configs))
+
+	// Every sample is one of the enumerated configs.
+	for i := 0; i < 100; i++ {
+		canonical, err := CanonicalConfig(sampleConfig(t, conf).Dump())
+		assert.Nil(err)
+		assert.True(dumps[canonical], canonical)
+	}
+
+	_, ok = EnumerateConfigs(conf, 10)
+	assert.False(ok)
+	conf, err = loadConfigJSON(t, `{"rate": {".float": [0, 1]}}`)
+	assert.Nil(err)
+	_, ok = EnumerateConfigs(conf, 1000)
+	assert.False(ok)
+
+	// Steps do not accumulate rounding errors.
+	conf, err = loadConfigJSON(t, `{"rate": {".float": [0, 1], ".step": 0.1}}`)
+	assert.Nil(err)
+	configs, ok = EnumerateConfigs(conf, 1000)
+	assert.True(ok)
+	if assert.Len(configs, 11) {
+		assert.Equal(0.3, configs[3].Dump().(map[string]interface{})["rate"])
+		assert.Equal(0.9, configs[9].Dump().(map[string]interface{})["rate"])
+	}
+	step := 0.1
+	canonical, err := CanonicalConfig(map[string]interface{}{"rate": step * 3})
+	assert.Nil(err)
+	assert.Equal(`{"rate":0.3}`, canonical)
+
+	// Ranges which are not a multiple of the step end at the last grid point.
+	conf, err = loadConfigJSON(t, `{"rate": {".float": [0, 1], ".step": 0.4}}`)
+	assert.Nil(err)
+	configs, ok = EnumerateConfigs(conf, 1000)
+	assert.True(ok)
+	if assert.Len(configs, 3) {
+		assert.Equal(0.8, configs[2].Dump().(map[string]interface{})["rate"])
+	}
+	rate := conf.(*ConfigMap).Params["rate"].(*ConfigFloat)
+	assert.Equal(0.8, rate.value(1))
+	for i := 0; i < 100; i++ {
+		value := sampleConfig(t, conf).Dump().(map[string]interface{})["rate"].(float64)
+		assert.Contains([]float64{0, 0.4, 0.8}, value)
+	}
+}
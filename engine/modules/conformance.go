@@ -0,0 +1,450 @@
+package modules
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/storage"
+	"github.com/ds3lab/easeml/schema/go/easemlschema/dataset"
+	"github.com/ds3lab/easeml/schema/go/easemlschema/schema"
+
+	"github.com/pkg/errors"
+)
+
+// ConformanceOptions controls the extent of a model conformance run.
+type ConformanceOptions struct {
+	// NumDatasets is the number of generated datasets. Each dataset draws its own values of the
+	// dimension variables uniformly from the range [MinDim, MaxDim].
+	NumDatasets int
+	MinDim      int
+	MaxDim      int
+
+	// NumConfigs is the number of configurations sampled from the config space. Every configuration
+	// is tested on every dataset.
+	NumConfigs int
+
+	// NumSamples is the number of samples in each split and NodeInstances the number of instances
+	// of each non-singleton node in a sample.
+	NumSamples    int
+	NodeInstances int
+
+	// Seed initializes the random generator used for datasets and configurations.
+	Seed int64
+
+	// KeepFiles prevents the removal of the generated data, model memory and predictions.
+	KeepFiles bool
+}
+
+// DefaultConformanceOptions are used when validating a model from the command line.
+var DefaultConformanceOptions = ConformanceOptions{
+	NumDatasets:   3,
+	MinDim:        1,
+	MaxDim:        16,
+	NumConfigs:    2,
+	NumSamples:    5,
+	NodeInstances: 4,
+}
+
+// SmokeConformanceOptions are used by the module validator which runs on every model upload. They
+// test a single configuration on a single dataset. The seed is fixed so that the same model always
+// gets the same report.
+var SmokeConformanceOptions = ConformanceOptions{
+	NumDatasets:   1,
+	MinDim:        1,
+	MaxDim:        16,
+	NumConfigs:    1,
+	NumSamples:    5,
+	NodeInstances: 4,
+	Seed:          1,
+}
+
+// conformanceRunner runs a model command and returns its output.
+type conformanceRunner func(command []string) (string, error)
+
+// RunConformanceSuite tests a model image by training it and running predictions on the train and val
+// splits of random datasets generated from its own schemas, once for every sampled configuration. Each
+// prediction output must load and match the output schema. Failed test cases are recorded in the
+// report. An error is returned only if the suite cannot be set up.
+func RunConformanceSuite(runtime Runtime, modelImageName, schemaStringIn, schemaStringOut, configSpace string, options ConformanceOptions) (*types.ConformanceReport, error) {
+	run := func(command []string) (string, error) {
+		outReader, err := runtime.Run(modelImageName, nil, command, nil)
+		if err != nil {
+			return "", err
+		}
+		defer outReader.Close()
+		output, err := ioutil.ReadAll(outReader)
+		return string(output), err
+	}
+	report, err := runConformanceSuite(run, schemaStringIn, schemaStringOut, configSpace, options)
+	if report != nil {
+		report.Image = modelImageName
+	}
+	return report, err
+}
+
+func runConformanceSuite(run conformanceRunner, schemaStringIn, schemaStringOut, configSpace string, options ConformanceOptions) (*types.ConformanceReport, error) {
+
+	if options.NumDatasets < 1 || options.NumConfigs < 1 || options.NumSamples < 1 || options.NodeInstances < 1 {
+		return nil, errors.New("the number of datasets, configs, samples and node instances must be positive")
+	}
+	if options.MinDim < 1 || options.MaxDim < options.MinDim {
+		return nil, errors.New("the dimension range must be non-empty and contain only positive values")
+	}
+
+	schemaIn, err := loadSchemaString(schemaStringIn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load input schema")
+	}
+	schemaOut, err := loadSchemaString(schemaStringOut)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load output schema")
+	}
+
+	// Sample all configurations upfront.
+	if strings.TrimSpace(configSpace) == "" {
+		configSpace = "{}"
+	}
+	var configStruct interface{}
+	err = json.Unmarshal([]byte(configSpace), &configStruct)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode config space JSON")
+	}
+	config, err := LoadConfig(configStruct)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load config space")
+	}
+	samplerOptions := SamplerOptions{Name: types.SamplerRandom, Seed: options.Seed}
+	sampledConfigs, err := SampleConfigs(config, samplerOptions, 0, options.NumConfigs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sample configs")
+	}
+	configs := make([]string, len(sampledConfigs))
+	for i := range sampledConfigs {
+		configJSON, err := json.Marshal(sampledConfigs[i].Dump())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to serialize config")
+		}
+		configs[i] = string(configJSON)
+	}
+
+	tempDirName, err := ioutil.TempDir("", "easeml_model_conformance")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate temp directory")
+	}
+	if options.KeepFiles == false {
+		defer os.RemoveAll(tempDirName)
+	}
+
+	startTime := time.Now()
+	report := &types.ConformanceReport{Time: startTime, Seed: options.Seed, Runs: []types.ConformanceRun{}}
+	dimVars := schemaDimVars(schemaIn, schemaOut)
+	dimRand := rand.New(rand.NewSource(options.Seed))
+
+	for i := 0; i < options.NumDatasets; i++ {
+
+		dims := map[string]int{}
+		for _, name := range dimVars {
+			dims[name] = options.MinDim + dimRand.Intn(options.MaxDim-options.MinDim+1)
+		}
+		datasetPath := filepath.Join(tempDirName, fmt.Sprintf("dataset-%d", i+1))
+		generateOptions := storage.GenerateDatasetOptions{
+			TrainSamples:  options.NumSamples,
+			ValSamples:    options.NumSamples,
+			NodeInstances: options.NodeInstances,
+			Dims:          dims,
+			DefaultDim:    options.MinDim,
+			Seed:          options.Seed + int64(i),
+		}
+		err = storage.GenerateDataset(schemaIn, schemaOut, datasetPath, generateOptions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate dataset")
+		}
+
+		for j := range configs {
+			runPath := filepath.Join(tempDirName, fmt.Sprintf("run-%d-%d", i+1, j+1))
+			testRun, err := runConformanceTests(run, schemaOut, datasetPath, runPath, configs[j])
+			if err != nil {
+				return nil, err
+			}
+			testRun.Name = fmt.Sprintf("dataset-%d/config-%d", i+1, j+1)
+			testRun.Dims = dims
+			report.Runs = append(report.Runs, testRun)
+		}
+	}
+
+	for _, r := range report.Runs {
+		for _, c := range r.Cases {
+			report.Tests++
+			switch c.Status {
+			case types.ConformanceFailed:
+				report.Failures++
+			case types.ConformanceSkipped:
+				report.Skipped++
+			}
+		}
+	}
+	report.Passed = report.Failures == 0 && report.Skipped == 0
+	report.Duration = time.Since(startTime).Seconds()
+
+	return report, nil
+}
+
+// ConformanceReportError returns an error describing the first failed test case of the report, or
+// nil if all test cases passed.
+func ConformanceReportError(report *types.ConformanceReport) error {
+	for _, r := range report.Runs {
+		for _, c := range r.Cases {
+			if c.Status == types.ConformanceFailed {
+				return errors.Errorf("%d of %d conformance tests failed, first failure in %s/%s: %s",
+					report.Failures, report.Tests, r.Name, c.Name, c.Message)
+			}
+		}
+	}
+	return nil
+}
+
+// runConformanceTests trains the model on the train split of the given dataset and runs predictions on
+// both splits. Predictions are skipped if the training fails.
+func runConformanceTests(run conformanceRunner, schemaOut *schema.Schema, datasetPath, runPath, config string) (types.ConformanceRun, error) {
+
+	result := types.ConformanceRun{Config: config, Cases: []types.ConformanceCase{}}
+
+	// All paths must exist before they are passed to the model, otherwise they are not mounted.
+	memoryPath := filepath.Join(runPath, "memory")
+	metadataPath := filepath.Join(runPath, "metadata")
+	for _, p := range []string{memoryPath, metadataPath, filepath.Join(runPath, "predictions", "train"), filepath.Join(runPath, "predictions", "val")} {
+		err := os.MkdirAll(p, storage.DefaultFilePerm)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to generate temp directory")
+		}
+	}
+	configFilePath := filepath.Join(runPath, "config.json")
+	err := ioutil.WriteFile(configFilePath, []byte(config), storage.DefaultFilePerm)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to write config file")
+	}
+
+	trainCase := runConformanceCase("train", func() error {
+		_, err := run([]string{
+			"train",
+			"--data", MntPrefix + filepath.Join(datasetPath, "train"),
+			"--conf", MntPrefix + configFilePath,
+			"--output", MntPrefix + memoryPath,
+			"--metadata", MntPrefix + metadataPath,
+		})
+		return errors.Wrap(err, "train failed")
+	})
+	result.Cases = append(result.Cases, trainCase)
+
+	for _, split := range []string{"train", "val"} {
+		name := "predict-" + split
+		if trainCase.Status != types.ConformancePassed {
+			result.Cases = append(result.Cases, types.ConformanceCase{Name: name, Status: types.ConformanceSkipped, Message: "training failed"})
+			continue
+		}
+		splitPath := filepath.Join(datasetPath, split)
+		predictionsPath := filepath.Join(runPath, "predictions", split)
+		result.Cases = append(result.Cases, runConformanceCase(name, func() error {
+			_, err := run([]string{
+				"predict",
+				"--data", MntPrefix + splitPath,
+				"--memory", MntPrefix + memoryPath,
+				"--output", MntPrefix + predictionsPath,
+				"--metadata", MntPrefix + metadataPath,
+			})
+			if err != nil {
+				return errors.Wrap(err, "predict failed")
+			}
+			return checkPredictions(schemaOut, filepath.Join(splitPath, "input"), filepath.Join(predictionsPath, "output"))
+		}))
+	}
+
+	return result, nil
+}
+
+func runConformanceCase(name string, test func() error) types.ConformanceCase {
+	start := time.Now()
+	err := test()
+	result := types.ConformanceCase{Name: name, Status: types.ConformancePassed, Duration: time.Since(start).Seconds()}
+	if err != nil {
+		result.Status = types.ConformanceFailed
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// checkPredictions verifies that the predictions load, match the output schema and contain a sample
+// for every input sample.
+func checkPredictions(schemaOut *schema.Schema, inputPath, predictionsPath string) error {
+	predictions, err := dataset.Load(predictionsPath, true, dataset.DefaultOpener{})
+	if err != nil {
+		return errors.Wrap(err, "failed to load predictions")
+	}
+	predSchema, err := predictions.InferSchema()
+	if err != nil {
+		return errors.Wrap(err, "failed to infer schema of predictions")
+	}
+	if match, _ := schemaOut.Match(predSchema, false); match == false {
+		return errors.New("schema of predictions doesn't match the output schema")
+	}
+
+	input, err := dataset.Load(inputPath, true, dataset.DefaultOpener{})
+	if err != nil {
+		return errors.Wrap(err, "failed to load input data")
+	}
+	missing := []string{}
+	for name, child := range input.Children {
+		if _, ok := child.(*dataset.Directory); ok == false {
+			continue
+		}
+		if _, ok := predictions.Children[name].(*dataset.Directory); ok == false {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf("predictions are missing for samples: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func loadSchemaString(schemaString string) (*schema.Schema, error) {
+	var schemaStruct interface{}
+	err := json.Unmarshal([]byte(schemaString), &schemaStruct)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode schema JSON")
+	}
+	result, schemaErr := schema.Load(schemaStruct)
+	if schemaErr != nil {
+		return nil, schemaErr
+	}
+	return result, nil
+}
+
+// schemaDimVars returns the sorted names of dimension variables which appear in the given schemas.
+func schemaDimVars(schemas ...*schema.Schema) []string {
+	names := map[string]bool{}
+	add := func(d schema.Dim) {
+		if v, ok := d.(*schema.VarDim); ok {
+			names[strings.TrimRight(v.Value, "?+*")] = true
+		}
+	}
+	for _, s := range schemas {
+		for _, node := range s.Nodes {
+			for _, field := range node.Fields {
+				if tensor, ok := field.(*schema.Tensor); ok {
+					for _, d := range tensor.Dim {
+						add(d)
+					}
+				}
+			}
+		}
+		for _, class := range s.Classes {
+			add(class.Dim)
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// ConformanceReportJUnit renders a conformance report in the JUnit XML format. Every run becomes a test suite.
+func ConformanceReportJUnit(report *types.ConformanceReport) ([]byte, error) {
+	formatTime := func(seconds float64) string { return fmt.Sprintf("%.3f", seconds) }
+
+	result := junitTestSuites{
+		Name:     report.Image,
+		Tests:    report.Tests,
+		Failures: report.Failures,
+		Skipped:  report.Skipped,
+		Time:     formatTime(report.Duration),
+	}
+	for _, r := range report.Runs {
+		suite := junitTestSuite{Name: r.Name, Properties: []junitProperty{{Name: "config", Value: r.Config}}}
+		for _, name := range sortedDimNames(r.Dims) {
+			suite.Properties = append(suite.Properties, junitProperty{Name: "dim." + name, Value: fmt.Sprint(r.Dims[name])})
+		}
+		var duration float64
+		for _, c := range r.Cases {
+			testCase := junitTestCase{Name: c.Name, ClassName: r.Name, Time: formatTime(c.Duration)}
+			switch c.Status {
+			case types.ConformanceFailed:
+				testCase.Failure = &junitMessage{Message: c.Message}
+				suite.Failures++
+			case types.ConformanceSkipped:
+				testCase.Skipped = &junitMessage{Message: c.Message}
+				suite.Skipped++
+			}
+			suite.Tests++
+			duration += c.Duration
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		suite.Time = formatTime(duration)
+		result.Suites = append(result.Suites, suite)
+	}
+
+	output, err := xml.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode JUnit report")
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+func sortedDimNames(dims map[string]int) []string {
+	result := make([]string, 0, len(dims))
+	for name := range dims {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,99 @@
+package modules
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/schema/go/easemlschema/dataset"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const conformanceSchemaIn = `{"nodes": {"x": {"singleton": true, "type": "tensor", "dim": ["n", 2]}}}`
+const conformanceSchemaOut = `{"nodes": {"y": {"singleton": true, "type": "category", "class": "labels"}}, "classes": {"labels": {"dim": "k"}}}`
+
+// fakeModel mimics a model which predicts random outputs for every input sample.
+func fakeModel(t *testing.T, failTrain bool) conformanceRunner {
+	return func(command []string) (string, error) {
+		args := map[string]string{}
+		for i := 1; i+1 < len(command); i += 2 {
+			args[command[i]] = strings.TrimPrefix(command[i+1], MntPrefix)
+		}
+		switch command[0] {
+		case "train":
+			if failTrain {
+				return "", errors.New("out of memory")
+			}
+			return "trained", nil
+		case "predict":
+			input, err := dataset.Load(filepath.Join(args["--data"], "input"), true, dataset.DefaultOpener{})
+			assert.Nil(t, err)
+			sampleNames := []string{}
+			for name, child := range input.Children {
+				if _, ok := child.(*dataset.Directory); ok {
+					sampleNames = append(sampleNames, name)
+				}
+			}
+			schemaOut, err := loadSchemaString(conformanceSchemaOut)
+			assert.Nil(t, err)
+			bound, err := schemaOut.Bind(nil, 3)
+			assert.Nil(t, err)
+			predictions, err := dataset.GenerateFromSchema("", bound, sampleNames, 1)
+			assert.Nil(t, err)
+			return "predicted", predictions.Dump(filepath.Join(args["--output"], "output"), dataset.DefaultOpener{})
+		}
+		return "", errors.New("unknown command")
+	}
+}
+
+func TestConformanceSuite(t *testing.T) {
+	assert := assert.New(t)
+
+	options := ConformanceOptions{NumDatasets: 2, MinDim: 1, MaxDim: 4, NumConfigs: 2, NumSamples: 3, NodeInstances: 2, Seed: 1}
+	configSpace := `{"depth": {".int": [1, 10]}}`
+
+	report, err := runConformanceSuite(fakeModel(t, false), conformanceSchemaIn, conformanceSchemaOut, configSpace, options)
+	assert.Nil(err)
+	assert.True(report.Passed)
+	assert.Equal(12, report.Tests)
+	assert.EqualValues(1, report.Seed)
+	assert.Len(report.Runs, 4)
+	assert.Nil(ConformanceReportError(report))
+	for _, r := range report.Runs {
+		assert.Contains(r.Dims, "n")
+		assert.Contains(r.Dims, "k")
+		var config map[string]interface{}
+		assert.Nil(json.Unmarshal([]byte(r.Config), &config))
+		assert.Contains(config, "depth")
+	}
+
+	// A model that fails to train has its predictions skipped.
+	report, err = runConformanceSuite(fakeModel(t, true), conformanceSchemaIn, conformanceSchemaOut, configSpace, options)
+	assert.Nil(err)
+	assert.False(report.Passed)
+	assert.Equal(4, report.Failures)
+	assert.Equal(8, report.Skipped)
+	assert.NotNil(ConformanceReportError(report))
+
+	// A model that produces no predictions fails the prediction checks.
+	noPredictions := func(command []string) (string, error) { return "", nil }
+	report, err = runConformanceSuite(noPredictions, conformanceSchemaIn, conformanceSchemaOut, configSpace, options)
+	assert.Nil(err)
+	assert.Equal(8, report.Failures)
+	assert.Equal(types.ConformancePassed, report.Runs[0].Cases[0].Status)
+	assert.Equal(types.ConformanceFailed, report.Runs[0].Cases[1].Status)
+
+	output, err := ConformanceReportJUnit(report)
+	assert.Nil(err)
+	var suites junitTestSuites
+	assert.Nil(xml.Unmarshal(output, &suites))
+	assert.Equal(12, suites.Tests)
+	assert.Equal(8, suites.Failures)
+	assert.Len(suites.Suites, 4)
+	assert.NotNil(suites.Suites[0].Cases[1].Failure)
+}
@@ -7,53 +7,41 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
-	"os/user"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ds3lab/easeml/engine/storage"
-	"github.com/ds3lab/easeml/schema/go/easemlschema/dataset"
-	"github.com/ds3lab/easeml/schema/go/easemlschema/schema"
 
-	"golang.org/x/net/context"
-
-	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/client"
 	"github.com/ghodss/yaml"
 	"github.com/mholt/archiver"
 	"github.com/pkg/errors"
 )
 
-// InferModuleProperties takes a module available on the local docker instance and tries to infer
-// its basic properties such as id, name and description.
-func InferModuleProperties(sourcePath string) (id, name, description, schemaIn, schemaOut, configSpace string, err error) {
+// InferModuleProperties takes a module image available to the runtime and tries to infer
+// its basic properties such as id, name and description. Objective modules can describe their
+// values with an objective metadata file.
+func InferModuleProperties(runtime Runtime, sourcePath string) (id, name, description, schemaIn, schemaOut, configSpace, objectiveMetadata string, err error) {
 
 	// Extract id from source path.
 	id = strings.Split(sourcePath, "@")[0] // Get rid of the digest.
+	id = strings.TrimRight(id, "/")        // Directories of the process runtime may end with a slash.
 	splits := strings.Split(id, "/")
 	id = splits[len(splits)-1]             // If there are more slash separated elements, take the last one.
 	id = strings.Replace(id, ".", "-", -1) // Replace dots with dashes.
 	id = strings.Replace(id, ":", "-", -1) // Replace colons with dashes.
 
 	// Extract all filenames from the image working dir.
-	var outReader io.ReadCloser
-	outReader, err = RunContainerAndCollectOutput(sourcePath, []string{"ls"}, []string{"--color=never", "."}, nil)
-	if err != nil {
-		err = errors.Wrap(err, "docker container start error")
-		return
-	}
-	defer outReader.Close()
-	var containerOutput []byte
-	containerOutput, err = ioutil.ReadAll(outReader)
+	var info ImageInfo
+	info, err = runtime.InspectImage(sourcePath)
 	if err != nil {
-		err = errors.Wrap(err, "docker container output read error")
+		err = errors.Wrap(err, "image inspect error")
 		return
 	}
-	workDirFiles := strings.Fields(string(containerOutput))
+	workDirFiles := info.Files
 
 	// Look for files we need to read from the working directory.
-	var readmeFileName, schemaInFileName, schemaOutFileName, configSpaceFilename string
+	var readmeFileName, schemaInFileName, schemaOutFileName, configSpaceFilename, objectiveMetadataFilename string
 	for i := range workDirFiles {
 
 		if readmeFileName == "" {
@@ -92,40 +80,37 @@ func InferModuleProperties(sourcePath string) (id, name, description, schemaIn,
 				configSpaceFilename = workDirFiles[i]
 			}
 		}
+		if objectiveMetadataFilename == "" {
+			match, err := filepath.Match("objective-metadata*", workDirFiles[i])
+			if err != nil {
+				panic(err) // This can only happen if the pattern is bad.
+			}
+			if match {
+				objectiveMetadataFilename = workDirFiles[i]
+			}
+		}
 	}
 
 	// If a README file was found, read it.
 	if readmeFileName != "" {
-		outReader, err = RunContainerAndCollectOutput(sourcePath, []string{"cat"}, []string{readmeFileName}, nil)
+		var content []byte
+		content, err = runtime.ReadImageFile(sourcePath, readmeFileName)
 		if err != nil {
-			err = errors.Wrap(err, "docker container start error")
+			err = errors.Wrap(err, "image file read error")
 			return
 		}
-		defer outReader.Close()
-		name, description = storage.ScanReadme(outReader)
+		name, description = storage.ScanReadme(bytes.NewReader(content))
 	}
 
 	// Look for the schema file.
 	if schemaInFileName != "" {
-		outReader, err = RunContainerAndCollectOutput(sourcePath, []string{"cat"}, []string{schemaInFileName}, nil)
-		if err != nil {
-			err = errors.Wrap(err, "docker container start error")
-			return
-		}
-		defer outReader.Close()
-		schemaIn, err = getJSONFromReader(schemaInFileName, outReader)
+		schemaIn, err = readImageJSON(runtime, sourcePath, schemaInFileName)
 		if err != nil {
 			return
 		}
 	}
 	if schemaOutFileName != "" {
-		outReader, err = RunContainerAndCollectOutput(sourcePath, []string{"cat"}, []string{schemaOutFileName}, nil)
-		if err != nil {
-			err = errors.Wrap(err, "docker container start error")
-			return
-		}
-		defer outReader.Close()
-		schemaOut, err = getJSONFromReader(schemaOutFileName, outReader)
+		schemaOut, err = readImageJSON(runtime, sourcePath, schemaOutFileName)
 		if err != nil {
 			return
 		}
@@ -140,13 +125,15 @@ func InferModuleProperties(sourcePath string) (id, name, description, schemaIn,
 
 	// Look for the config space file.
 	if configSpaceFilename != "" {
-		outReader, err = RunContainerAndCollectOutput(sourcePath, []string{"cat"}, []string{configSpaceFilename}, nil)
+		configSpace, err = readImageJSON(runtime, sourcePath, configSpaceFilename)
 		if err != nil {
-			err = errors.Wrap(err, "docker container start error")
 			return
 		}
-		defer outReader.Close()
-		configSpace, err = getJSONFromReader(configSpaceFilename, outReader)
+	}
+
+	// Look for the objective metadata file.
+	if objectiveMetadataFilename != "" {
+		objectiveMetadata, err = readImageJSON(runtime, sourcePath, objectiveMetadataFilename)
 		if err != nil {
 			return
 		}
@@ -162,6 +149,14 @@ func InferModuleProperties(sourcePath string) (id, name, description, schemaIn,
 	return
 }
 
+func readImageJSON(runtime Runtime, imageName, fileName string) (string, error) {
+	content, err := runtime.ReadImageFile(imageName, fileName)
+	if err != nil {
+		return "", errors.Wrap(err, "image file read error")
+	}
+	return getJSONFromReader(fileName, bytes.NewReader(content))
+}
+
 func getJSONFromReader(fileName string, reader io.Reader) (string, error) {
 
 	containerOutput, err := ioutil.ReadAll(reader)
@@ -192,378 +187,25 @@ func getJSONFromReader(fileName string, reader io.Reader) (string, error) {
 	return "", nil
 }
 
-// GetDockerClient returns an instance of the Docker client.
-func GetDockerClient() *client.Client {
-	// TODO: Get API version automatically.
-	// See: https://stackoverflow.com/a/48638182
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.37"))
-	if err != nil {
-		panic(err)
-	}
-	return cli
-}
-
-// MntPrefix must be placed before all command line arguments if they represent a local directory
-// or file which we want to mount to the image.
-const MntPrefix = "^^^"
-
-// RunContainerAndCollectOutput runs a given image name and returns the standard output reader.
-func RunContainerAndCollectOutput(imageName string, entrypoint, command []string, gpuDevices []string) (io.ReadCloser, error) {
-
-	// Go through all commands and see if any of them correspond to a file. If yes, mount it to
-	// the container and remap the command argument.
-	remappedCommand := make([]string, len(command))
-	bindsMap := map[string]interface{}{}
-	targetDirsMap := map[string]interface{}{}
-	binds := []string{}
-	for i := range command {
-
-		if strings.HasPrefix(command[i], MntPrefix) {
-
-			command[i] = strings.TrimPrefix(command[i], MntPrefix)
-
-			if stats, err := os.Stat(command[i]); err == nil {
-
-				// Get absolute path.
-				absPath, err := filepath.Abs(command[i])
-				if err != nil {
-					err = errors.Wrap(err, "absolute path inference failed")
-					return nil, err
-				}
-
-				// If it is a directory, we can immediately mount it. Otherwise we mount the parent.
-				var mapping string
-				if stats.IsDir() {
-					dirName := filepath.Base(absPath)
-
-					// If this dirname was already mounted, we rename it by appending underscores.
-					for {
-						if _, ok := targetDirsMap[dirName]; ok {
-							dirName = dirName + "_"
-						} else {
-							break
-						}
-					}
-					targetDirsMap[dirName] = nil
-
-					mountedPath := filepath.Join("/mnt", dirName)
-
-					remappedCommand[i] = mountedPath
-					mapping = fmt.Sprintf("%s:%s", absPath, mountedPath)
-
-				} else {
-					fileName := filepath.Base(absPath)
-					parentPath := filepath.Dir(absPath)
-					dirName := filepath.Base(parentPath)
-
-					// If this dirname was already mounted, we rename it by appending underscores.
-					for {
-						if _, ok := targetDirsMap[dirName]; ok {
-							dirName = dirName + "_"
-						} else {
-							break
-						}
-					}
-					targetDirsMap[dirName] = nil
-
-					mountedPath := filepath.Join("/mnt", dirName)
-
-					remappedCommand[i] = filepath.Join(mountedPath, fileName)
-					mapping = fmt.Sprintf("%s:%s", parentPath, mountedPath)
-
-				}
-
-				// Add to binds if it doesn't exist yet.
-				if _, ok := bindsMap[mapping]; ok == false {
-					bindsMap[mapping] = nil
-					binds = append(binds, mapping)
-				}
-
-				continue
-			}
-		}
-		// We consider it not to be a file path.
-		remappedCommand[i] = command[i]
-
-	}
-
-	// Assemble the host config with appropriate binds.
-	hostConfig := container.HostConfig{
-		Binds: binds,
-	}
-
-	// If GPU devices were specified, we need to add the appropriate device requests to the host config.
-	if gpuDevices != nil && len(gpuDevices) > 0 {
-
-		// We will convert the input slice to a map to prevent duplicates.
-		gpuDevicesMap := make(map[string]struct{}, len(gpuDevices))
-		for _, s := range gpuDevices {
-			gpuDevicesMap[s] = struct{}{}
-		}
-
-		// Check if -1 is in the list, which maps to all devices. Otherwise we list the specific device IDs.
-		_, ok := gpuDevicesMap["-1"]
-		var deviceRequest container.DeviceRequest
-		if ok {
-			deviceRequest = container.DeviceRequest{
-				Count:        -1,
-				Capabilities: [][]string{[]string{"gpu"}},
-			}
-		} else {
-			deviceIDs := make([]string, 0, len(gpuDevicesMap))
-			for id := range gpuDevicesMap {
-				deviceIDs = append(deviceIDs, id)
-			}
-			deviceRequest = container.DeviceRequest{
-				DeviceIDs:    deviceIDs,
-				Capabilities: [][]string{[]string{"gpu"}},
-			}
-		}
-
-		// Add the device requests to the host config.
-		hostConfig.Resources = container.Resources{DeviceRequests: []container.DeviceRequest{deviceRequest}}
-	}
-
-	// TODO Find a proper place to store the username, we should create username string once
-	currentUser, err := user.Current()
-	if err != nil {
-		panic(err)
-	}
-	username:= currentUser.Uid+":"+ currentUser.Gid
-
-	ctx := context.Background()
-	cli := GetDockerClient()
-	resp, err := cli.ContainerCreate(ctx, &container.Config{
-		Image:      imageName,
-		Entrypoint: entrypoint,
-		Cmd:        remappedCommand,
-		User:		username,
-		Tty:        true,
-	}, &hostConfig, nil, "")
-	if err != nil {
-		panic(err)
-	}
-
-	defer func() {
-		cli.ContainerRemove(
-			context.Background(),
-			resp.ID,
-			types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
-	}()
-
-	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		panic(err)
-	}
-
-	statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
-	select {
-	case err := <-errCh:
-		if err != nil {
-			panic(err)
-		}
-	case <-statusCh:
-	}
-
-	out, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true})
-	if err != nil {
-		panic(err)
-	}
-	return out, nil
-}
-
-// LoadImage loads a Docker image from a tar file.
-func LoadImage(imageFilePath string) (string, error) {
-
-	cli := GetDockerClient()
-	imageFile, err := os.Open(imageFilePath)
-	if err != nil {
-		return "", err
-	}
-	resp, err := cli.ImageLoad(context.Background(), imageFile, false)
-	if resp.JSON != true {
-		panic("expected JSON response")
-	}
-	defer resp.Body.Close()
-
-	type responseBodyType struct {
-		Stream string `json:"stream"`
-	}
-	var result responseBodyType
-	err = json.NewDecoder(resp.Body).Decode(&result)
-	if err != nil {
-		return "", err
-	}
-	imageName := strings.TrimSpace(strings.TrimPrefix(result.Stream, "Loaded image:"))
-
-	return imageName, nil
-}
-
 // ValidateModel takes a model image and the input and output schema, generates a random input data set,
-// runs train and predict on the model and verifies that the output data matches the output schema.
-func ValidateModel(modelImageName string, schemaStringIn, schemaStringOut, configSpace string, cleanup bool) (err error) {
-
-	// Parse schema JSON strings.
-	var schemaStructIn, schemaStructOut interface{}
-	err = json.Unmarshal([]byte(schemaStringIn), &schemaStructIn)
-	if err != nil {
-		err = errors.Wrap(err, "failed to decode input schema JSON")
-		return
-	}
-	err = json.Unmarshal([]byte(schemaStringOut), &schemaStructOut)
-	if err != nil {
-		err = errors.Wrap(err, "failed to decode output schema JSON")
-		return
-	}
-	schemaIn, err := schema.Load(schemaStructIn)
-	if err != nil {
-		err = errors.Wrap(err, "failed to load input schema")
-		return
-	}
-	schemaOut, err := schema.Load(schemaStructOut)
-	if err != nil {
-		err = errors.Wrap(err, "failed to load output schema")
-		return
-	}
-
-	// Generate temp directories.
-	tempDirName, err := ioutil.TempDir("", "easeml_model_val")
-	if err != nil {
-		err = errors.Wrap(err, "failed to generate temp directory")
-		return
-	}
-	err = os.MkdirAll(filepath.Join(tempDirName, "data", "input"), storage.DefaultFilePerm)
-	if err != nil {
-		err = errors.Wrap(err, "failed to generate temp data input directory")
-		return
-	}
-	err = os.MkdirAll(filepath.Join(tempDirName, "data", "output"), storage.DefaultFilePerm)
-	if err != nil {
-		err = errors.Wrap(err, "failed to generate temp data input directory")
-		return
-	}
-	err = os.MkdirAll(filepath.Join(tempDirName, "data", "predictions"), storage.DefaultFilePerm)
-	if err != nil {
-		err = errors.Wrap(err, "failed to generate temp data predictions directory")
-		return
-	}
-	err = os.MkdirAll(filepath.Join(tempDirName, "memory"), storage.DefaultFilePerm)
-	if err != nil {
-		err = errors.Wrap(err, "failed to generate temp memory directory")
-		return
-	}
-	if cleanup {
-		defer os.RemoveAll(tempDirName)
-	}
-
-	// Generate sample names.
-	sampleNames := make([]string, 10)
-	for i := range sampleNames {
-		sampleNames[i] = dataset.RandomString(10, "")
-	}
+// runs train and predict on the model and verifies that the output data matches the output schema. It runs
+// a single dataset and configuration of the conformance suite.
+func ValidateModel(runtime Runtime, modelImageName string, schemaStringIn, schemaStringOut, configSpace string, cleanup bool) (err error) {
+	options := DefaultConformanceOptions
+	options.NumDatasets = 1
+	options.NumConfigs = 1
+	options.Seed = time.Now().UnixNano()
+	options.KeepFiles = cleanup == false
 
-	// Generate random input and output data.
-	datasetIn, err := dataset.GenerateFromSchema("", schemaIn, sampleNames, 10)
+	report, err := RunConformanceSuite(runtime, modelImageName, schemaStringIn, schemaStringOut, configSpace, options)
 	if err != nil {
-		err = errors.Wrap(err, "failed to generate input dataset")
-		return
-	}
-	err = datasetIn.Dump(filepath.Join(tempDirName, "data", "input"), dataset.DefaultOpener{})
-	if err != nil {
-		err = errors.Wrap(err, "failed to save input dataset")
-		return
-	}
-	datasetOut, err := dataset.GenerateFromSchema("", schemaOut, sampleNames, 10)
-	if err != nil {
-		err = errors.Wrap(err, "failed to generate input dataset")
-		return
+		return err
 	}
-	err = datasetOut.Dump(filepath.Join(tempDirName, "data", "output"), dataset.DefaultOpener{})
-	if err != nil {
-		err = errors.Wrap(err, "failed to save output dataset")
-		return
-	}
-
-	// Generate temporary config.
-	var configStruct interface{}
-	err = json.Unmarshal([]byte(configSpace), &configStruct)
-	if err != nil {
-		err = errors.Wrap(err, "failed to load config space")
-		return
-	}
-	config, err := LoadConfig(configStruct)
-	if err != nil {
-		err = errors.Wrap(err, "failed to load config space")
-		return
-	}
-	configSample := config.Sample()
-	configJSON, err := json.Marshal(configSample.Dump())
-	if err != nil {
-		err = errors.Wrap(err, "failed to serialize config space")
-		return
-	}
-	configFilePath := filepath.Join(tempDirName, "config.json")
-	err = ioutil.WriteFile(configFilePath, []byte(configJSON), storage.DefaultFilePerm)
-	if err != nil {
-		err = errors.Wrap(err, "failed to write config file")
-		return
-	}
-
-	// Call train.
-	// Run the training.
-	command := []string{
-		"train",
-		"--data", MntPrefix + filepath.Join(tempDirName, "data"),
-		"--conf", MntPrefix + filepath.Join(tempDirName, "config.json"),
-		"--output", MntPrefix + filepath.Join(tempDirName, "memory"),
-		"--metadata", MntPrefix + filepath.Join(tempDirName, "metadata"),
-	}
-	outReader, err := RunContainerAndCollectOutput(modelImageName, nil, command, nil)
-	defer outReader.Close()
-	if err != nil {
-		err = errors.Wrap(err, "failed to run train")
-		return
-	}
-
-	// Call predict.
-	command = []string{
-		"predict",
-		"--data", MntPrefix + filepath.Join(tempDirName, "data"),
-		"--memory", MntPrefix + filepath.Join(tempDirName, "memory"),
-		"--output", MntPrefix + filepath.Join(tempDirName, "data", "predictions"),
-		"--metadata", MntPrefix + filepath.Join(tempDirName, "metadata"),
-	}
-	outReader, err = RunContainerAndCollectOutput(modelImageName, nil, command, nil)
-	defer outReader.Close()
-	if err != nil {
-		err = errors.Wrap(err, "failed to run train")
-		return
-	}
-
-	// Infer schema of output data.
-	predDataset, err := dataset.Load(filepath.Join(tempDirName, "data", "predictions", "output"), true, dataset.DefaultOpener{})
-	if err != nil {
-		err = errors.Wrap(err, "failed to load output dataset")
-		return
-	}
-	predSchema, err := predDataset.InferSchema()
-	if err != nil {
-		err = errors.Wrap(err, "failed to infer schema from output dataset")
-		return
-	}
-
-	// Check if schemas match.
-	match, _ := schemaOut.Match(predSchema, false)
-
-	if match == false {
-		err = errors.New("output dataset schema doesn't match the schema definition")
-		return
-	}
-
-	return nil
+	return ConformanceReportError(report)
 }
 
 // BuildModelImageWithMemory takes a model image, copies the memory content to it and builds a new image from that.
-func BuildModelImageWithMemory(baseImageName, memoryLocation, newImageTag string) (result io.ReadSeeker, err error) {
+func BuildModelImageWithMemory(runtime Runtime, baseImageName, memoryLocation, newImageTag string) (result io.ReadSeeker, err error) {
 	// Load image.
 
 	// Generate dockerfile and save it to a temp directory so that we can add it to the tar file.
@@ -591,41 +233,28 @@ func BuildModelImageWithMemory(baseImageName, memoryLocation, newImageTag string
 	}
 	var b bytes.Buffer
 	err = archiver.Tar.Write(&b, buildContextFiles)
-
-	// Build the image and return the tar file bytes.
-	imageTags := []string{newImageTag}
-	buildOptions := types.ImageBuildOptions{Tags: imageTags}
-	ctx := context.Background()
-	cli := GetDockerClient()
-	var buildResponse types.ImageBuildResponse
-	buildResponse, err = cli.ImageBuild(ctx, bytes.NewReader(b.Bytes()), buildOptions)
-	defer buildResponse.Body.Close()
-	_, err = io.Copy(os.Stdout, buildResponse.Body) // Use for debugging.
 	if err != nil {
-		err = errors.Wrap(err, "failed to build docker image")
+		err = errors.Wrap(err, "failed to write the build context")
 		return
 	}
 
-	// Get image as tar file.
-	reader, err := cli.ImageSave(ctx, imageTags)
+	// Build the image and return the tar file bytes.
+	err = runtime.BuildImage(bytes.NewReader(b.Bytes()), newImageTag, os.Stdout) // Use for debugging.
 	if err != nil {
-		err = errors.Wrap(err, "failed to save docker image")
 		return
 	}
-	defer reader.Close()
 
-	var data []byte
-	data, err = ioutil.ReadAll(reader)
+	// Get image as tar file.
+	var data bytes.Buffer
+	err = runtime.SaveImage(newImageTag, &data)
 	if err != nil {
-		err = errors.Wrap(err, "reader access error")
 		return
 	}
 	// TODO: Delete image.
-	_, err = cli.ImageRemove(ctx, imageTags[0], types.ImageRemoveOptions{Force: true})
+	err = runtime.RemoveImage(newImageTag)
 	if err != nil {
-		err = errors.Wrap(err, "failed to remove docker image")
 		return
 	}
 
-	return bytes.NewReader(data), nil
+	return bytes.NewReader(data.Bytes()), nil
 }
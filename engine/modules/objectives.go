@@ -0,0 +1,584 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/schema/go/easemlschema/dataset"
+	"github.com/ds3lab/easeml/schema/go/easemlschema/schema"
+
+	"github.com/pkg/errors"
+)
+
+// BuiltinObjectivePrefix is the prefix of the names of all built-in objectives. It keeps them apart
+// from objective modules that the root user uploads under the same name.
+const BuiltinObjectivePrefix = "builtin-"
+
+// objectiveNode and objectiveClass are the names of the singleton node and its class in the input
+// schemas of built-in objectives.
+const (
+	objectiveNode  = "s1"
+	objectiveClass = "cls"
+)
+
+// Input schemas of built-in objectives. Classification objectives compare categories and regression
+// objectives compare scalar tensors.
+const (
+	objectiveSchemaCategory = `{"nodes":{"s1":{"singleton":true,"type":"category","class":"cls"}},"classes":{"cls":{"dim":"n_classes"}}}`
+	objectiveSchemaScalar   = `{"nodes":{"s1":{"singleton":true,"type":"tensor","dim":[1]}}}`
+)
+
+// logLossEpsilon bounds the predicted probabilities away from 0 and 1 before taking their logarithm.
+const logLossEpsilon = 1e-15
+
+// BuiltinObjective is an objective function which the engine computes natively instead of running
+// a module container.
+type BuiltinObjective struct {
+	Name        string
+	Description string
+	SchemaIn    string
+	Metadata    types.ObjectiveMetadata
+
+	// sample computes the value reported for a single sample.
+	sample func(actual, predicted float64) float64
+
+	// score computes the quality from the actual and predicted values of all samples.
+	score func(actual, predicted []float64) float64
+
+	// perClass computes the value reported for a single class. It is nil for regression objectives.
+	perClass func(stats classStats, class float64) float64
+}
+
+// Metadata of built-in objectives. Classification objectives report the value of each class and
+// a confusion matrix.
+var (
+	zero, one = 0.0, 1.0
+
+	maximizeUnitMetadata = types.ObjectiveMetadata{
+		Direction: types.ObjectiveMaximize,
+		Min:       &zero,
+		Max:       &one,
+		Outputs:   []string{types.ObjectiveOutputPerSample},
+	}
+	minimizeErrorMetadata = types.ObjectiveMetadata{
+		Direction: types.ObjectiveMinimize,
+		Min:       &zero,
+		Outputs:   []string{types.ObjectiveOutputPerSample},
+	}
+	classificationMetadata = types.ObjectiveMetadata{
+		Direction: types.ObjectiveMaximize,
+		Min:       &zero,
+		Max:       &one,
+		Outputs:   []string{types.ObjectiveOutputPerSample, types.ObjectiveOutputPerClass, types.ObjectiveOutputConfusionMatrix},
+	}
+)
+
+// BuiltinObjectives contains all built-in objectives keyed by their module ID.
+var BuiltinObjectives = map[string]BuiltinObjective{
+	types.UserRoot + "/" + BuiltinObjectivePrefix + "accuracy": BuiltinObjective{
+		Name:        "Accuracy",
+		Description: "Fraction of samples whose predicted category matches the actual category.",
+		SchemaIn:    objectiveSchemaCategory,
+		Metadata:    classificationMetadata,
+		sample:      categoryMatch,
+		score:       accuracyScore,
+		perClass:    classStats.recall,
+	},
+	types.UserRoot + "/" + BuiltinObjectivePrefix + "f1-score": BuiltinObjective{
+		Name:        "F1 Score",
+		Description: "Harmonic mean of precision and recall, averaged over classes weighted by their support.",
+		SchemaIn:    objectiveSchemaCategory,
+		Metadata:    classificationMetadata,
+		sample:      categoryMatch,
+		score:       f1Score,
+		perClass:    classStats.f1,
+	},
+	types.UserRoot + "/" + BuiltinObjectivePrefix + "precision": BuiltinObjective{
+		Name:        "Precision",
+		Description: "Fraction of correct predictions of each class, averaged over classes weighted by their support.",
+		SchemaIn:    objectiveSchemaCategory,
+		Metadata:    classificationMetadata,
+		sample:      categoryMatch,
+		score:       precisionScore,
+		perClass:    classStats.precision,
+	},
+	types.UserRoot + "/" + BuiltinObjectivePrefix + "recall": BuiltinObjective{
+		Name:        "Recall",
+		Description: "Fraction of samples of each class that were predicted correctly, averaged over classes weighted by their support.",
+		SchemaIn:    objectiveSchemaCategory,
+		Metadata:    classificationMetadata,
+		sample:      categoryMatch,
+		score:       recallScore,
+		perClass:    classStats.recall,
+	},
+	types.UserRoot + "/" + BuiltinObjectivePrefix + "mean-abs-error": BuiltinObjective{
+		Name:        "Mean Absolute Error",
+		Description: "Mean absolute difference between the actual and predicted values.",
+		SchemaIn:    objectiveSchemaScalar,
+		Metadata:    minimizeErrorMetadata,
+		sample:      absError,
+		score:       meanAbsError,
+	},
+	types.UserRoot + "/" + BuiltinObjectivePrefix + "mean-squared-error": BuiltinObjective{
+		Name:        "Mean Squared Error",
+		Description: "Mean squared difference between the actual and predicted values.",
+		SchemaIn:    objectiveSchemaScalar,
+		Metadata:    minimizeErrorMetadata,
+		sample:      squaredError,
+		score:       meanSquaredError,
+	},
+	types.UserRoot + "/" + BuiltinObjectivePrefix + "r2-score": BuiltinObjective{
+		Name:        "R2 Score",
+		Description: "Coefficient of determination of the predicted values, capped to the range between 0 and 1.",
+		SchemaIn:    objectiveSchemaScalar,
+		Metadata:    maximizeUnitMetadata,
+		sample:      squaredError,
+		score:       r2Score,
+	},
+	types.UserRoot + "/" + BuiltinObjectivePrefix + "log-loss": BuiltinObjective{
+		Name:        "Log Loss",
+		Description: "Mean negative log-likelihood of binary labels given the predicted probabilities of the positive label.",
+		SchemaIn:    objectiveSchemaScalar,
+		Metadata:    minimizeErrorMetadata,
+		sample:      logLoss,
+		score:       meanLogLoss,
+	},
+}
+
+// BuiltinObjectiveAliases maps the IDs of the objective modules shipped with ease.ml to the built-in
+// objectives which compute the same values. Jobs which use these modules are evaluated natively.
+var BuiltinObjectiveAliases = map[string]string{
+	types.UserRoot + "/f1-score":       types.UserRoot + "/" + BuiltinObjectivePrefix + "f1-score",
+	types.UserRoot + "/mean-abs-error": types.UserRoot + "/" + BuiltinObjectivePrefix + "mean-abs-error",
+	types.UserRoot + "/r2-score":       types.UserRoot + "/" + BuiltinObjectivePrefix + "r2-score",
+}
+
+// resolveBuiltinObjective returns the ID of the built-in objective which the given module ID refers to.
+func resolveBuiltinObjective(id string) string {
+	if alias, ok := BuiltinObjectiveAliases[id]; ok {
+		return alias
+	}
+	return id
+}
+
+// IsBuiltinObjective returns true if the given module ID refers to a built-in objective or to one of
+// its aliases.
+func IsBuiltinObjective(id string) bool {
+	_, ok := BuiltinObjectives[resolveBuiltinObjective(id)]
+	return ok
+}
+
+// BuiltinObjectiveModules returns the module records of all built-in objectives sorted by ID.
+func BuiltinObjectiveModules() []types.Module {
+	result := make([]types.Module, 0, len(BuiltinObjectives))
+	for id, objective := range BuiltinObjectives {
+		metadata := objective.Metadata
+		result = append(result, types.Module{
+			ID:          id,
+			User:        types.UserRoot,
+			Type:        types.ModuleObjective,
+			Label:       id[len(types.UserRoot)+1:],
+			Name:        objective.Name,
+			Description: objective.Description,
+			SchemaIn:    objective.SchemaIn,
+			Source:      types.ModuleBuiltin,
+			Objective:   &metadata,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// EvaluateBuiltinObjective computes the built-in objective with the given ID or alias. The actual and predicted
+// directories have the same layout as the ones given to the eval command of objective modules. The returned
+// lines mirror the output of that command: one line per sample, the quality details of classification
+// objectives and the quality on the last line.
+func EvaluateBuiltinObjective(id, actualPath, predictedPath string) (lines []string, quality float64, err error) {
+	objective, ok := BuiltinObjectives[resolveBuiltinObjective(id)]
+	if ok == false {
+		return nil, 0, errors.Errorf("\"%s\" is not a built-in objective", id)
+	}
+
+	schemaIn, err := loadSchemaString(objective.SchemaIn)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	actual, err := loadObjectiveValues(schemaIn, actualPath)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to load actual values")
+	}
+	predicted, err := loadObjectiveValues(schemaIn, predictedPath)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to load predicted values")
+	}
+
+	// Categories are compared by their position in the class of the actual dataset. Predicted
+	// categories which are not in that class never match.
+	classIndex := map[string]float64{}
+	for i, category := range actual.classes {
+		classIndex[category] = float64(i)
+	}
+	value := func(values *objectiveValues, name string) float64 {
+		if values.labels == nil {
+			return values.values[name]
+		}
+		if index, ok := classIndex[values.labels[name]]; ok {
+			return index
+		}
+		return -1
+	}
+
+	names := []string{}
+	for name := range actual.samples {
+		if predicted.samples[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, 0, errors.New("no sample has both an actual and a predicted value")
+	}
+	sort.Strings(names)
+
+	actualValues := make([]float64, len(names))
+	predictedValues := make([]float64, len(names))
+	for i, name := range names {
+		actualValues[i] = value(actual, name)
+		predictedValues[i] = value(predicted, name)
+		lines = append(lines, fmt.Sprintf("%s | %s", name, formatObjectiveValue(objective.sample(actualValues[i], predictedValues[i]))))
+	}
+
+	if objective.perClass != nil {
+		details := classificationDetails(actualValues, predictedValues, actual.classes, objective.perClass)
+		detailsJSON, err := json.Marshal(details)
+		if err != nil {
+			panic(err) // This should never happen because the details contain only maps, slices and numbers.
+		}
+		lines = append(lines, string(detailsJSON))
+	}
+
+	quality = objective.score(actualValues, predictedValues)
+	lines = append(lines, formatObjectiveValue(quality))
+
+	return lines, quality, nil
+}
+
+// classificationDetails computes the value of each class which is present in the actual or the predicted
+// values and the confusion matrix of all classes. Predictions of categories which are not in the class
+// are left out of the confusion matrix.
+func classificationDetails(actual, predicted []float64, classes []string, perClass func(stats classStats, class float64) float64) *types.QualityDetails {
+	stats := computeClassStats(actual, predicted)
+	details := &types.QualityDetails{
+		PerClass:        map[string]float64{},
+		ConfusionMatrix: &types.ConfusionMatrix{Labels: classes, Counts: make([][]int, len(classes))},
+	}
+	for i := range classes {
+		class := float64(i)
+		if stats.support[class] > 0 || stats.predicted[class] > 0 {
+			details.PerClass[classes[i]] = perClass(stats, class)
+		}
+		details.ConfusionMatrix.Counts[i] = make([]int, len(classes))
+	}
+	for i := range actual {
+		if predicted[i] >= 0 {
+			details.ConfusionMatrix.Counts[int(actual[i])][int(predicted[i])]++
+		}
+	}
+	return details
+}
+
+func formatObjectiveValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// ParseObjectiveMetadata decodes and validates the JSON objective metadata of an objective module.
+// A missing direction defaults to maximization.
+func ParseObjectiveMetadata(data string) (*types.ObjectiveMetadata, error) {
+	var metadata types.ObjectiveMetadata
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode objective metadata")
+	}
+
+	if metadata.Direction == "" {
+		metadata.Direction = types.ObjectiveMaximize
+	}
+	if metadata.Direction != types.ObjectiveMaximize && metadata.Direction != types.ObjectiveMinimize {
+		return nil, errors.Errorf("objective direction can be \"%s\" or \"%s\", but found \"%s\"",
+			types.ObjectiveMaximize, types.ObjectiveMinimize, metadata.Direction)
+	}
+	if metadata.Min != nil && metadata.Max != nil && *metadata.Min > *metadata.Max {
+		return nil, errors.New("the lower bound of the objective is greater than its upper bound")
+	}
+	for _, output := range metadata.Outputs {
+		if output != types.ObjectiveOutputPerSample &&
+			output != types.ObjectiveOutputPerClass &&
+			output != types.ObjectiveOutputConfusionMatrix {
+			return nil, errors.Errorf("objective output can be \"%s\", \"%s\" or \"%s\", but found \"%s\"",
+				types.ObjectiveOutputPerSample, types.ObjectiveOutputPerClass, types.ObjectiveOutputConfusionMatrix, output)
+		}
+	}
+
+	return &metadata, nil
+}
+
+// ParseEvaluationOutput reads the quality from the last line of the output of an objective. A line which
+// holds a JSON object is decoded as the quality details. Only the details which are declared as outputs
+// in the objective metadata are returned and the quality must be within the declared bounds.
+func ParseEvaluationOutput(lines []string, metadata *types.ObjectiveMetadata) (quality float64, details *types.QualityDetails, err error) {
+	if len(lines) == 0 {
+		return 0, nil, errors.New("the objective output is empty")
+	}
+
+	quality, err = strconv.ParseFloat(strings.TrimSpace(lines[len(lines)-1]), 64)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to parse the quality")
+	}
+	if metadata != nil && ((metadata.Min != nil && quality < *metadata.Min) || (metadata.Max != nil && quality > *metadata.Max)) {
+		return 0, nil, errors.Errorf("the quality %s is out of the objective bounds", formatObjectiveValue(quality))
+	}
+
+	for _, line := range lines[:len(lines)-1] {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "{") == false {
+			continue
+		}
+		details = &types.QualityDetails{}
+		err = json.Unmarshal([]byte(line), details)
+		if err != nil {
+			return 0, nil, errors.Wrap(err, "failed to decode the quality details")
+		}
+	}
+	if details != nil {
+		if metadata.HasOutput(types.ObjectiveOutputPerClass) == false {
+			details.PerClass = nil
+		}
+		if metadata.HasOutput(types.ObjectiveOutputConfusionMatrix) == false {
+			details.ConfusionMatrix = nil
+		}
+		if details.PerClass == nil && details.ConfusionMatrix == nil {
+			details = nil
+		}
+	}
+
+	return quality, details, nil
+}
+
+// objectiveValues holds the value of the objective node of every sample in a dataset.
+type objectiveValues struct {
+	samples map[string]bool
+	values  map[string]float64
+	labels  map[string]string
+	classes []string
+}
+
+// loadObjectiveValues loads the output directory of a dataset and extracts the value of the node that
+// matches the objective node of the given schema from every sample.
+func loadObjectiveValues(schemaIn *schema.Schema, path string) (*objectiveValues, error) {
+	data, err := dataset.Load(filepath.Join(path, "output"), false, dataset.DefaultOpener{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load dataset")
+	}
+	dataSchema, err := data.InferSchema()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to infer dataset schema")
+	}
+	match, matching := schemaIn.Match(dataSchema, true)
+	if match == false {
+		return nil, errors.New("dataset schema doesn't match the objective input schema")
+	}
+	nodeName := matching.Nodes[objectiveNode].SrcName
+
+	result := &objectiveValues{samples: map[string]bool{}}
+	if class, ok := matching.Classes[objectiveClass]; ok {
+		classFile, ok := data.Children[class.SrcName].(*dataset.Class)
+		if ok == false {
+			return nil, errors.Errorf("class file \"%s\" not found", class.SrcName)
+		}
+		result.classes = classFile.Categories
+		result.labels = map[string]string{}
+	} else {
+		result.values = map[string]float64{}
+	}
+
+	for name, child := range data.Children {
+		directory, ok := child.(*dataset.Directory)
+		if ok == false {
+			continue
+		}
+		switch file := directory.Children[nodeName].(type) {
+		case *dataset.Category:
+			if len(file.Categories) != 1 {
+				return nil, errors.Errorf("sample \"%s\" must have exactly one category", name)
+			}
+			result.labels[name] = file.Categories[0]
+		case *dataset.Tensor:
+			value, err := tensorScalar(file)
+			if err != nil {
+				return nil, errors.Wrapf(err, "sample \"%s\"", name)
+			}
+			result.values[name] = value
+		default:
+			return nil, errors.Errorf("sample \"%s\" is missing the value of node \"%s\"", name, nodeName)
+		}
+		result.samples[name] = true
+	}
+
+	return result, nil
+}
+
+// tensorScalar returns the only element of a dense tensor.
+func tensorScalar(tensor *dataset.Tensor) (float64, error) {
+	values, err := tensorValues(tensor)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) != 1 {
+		return 0, errors.New("tensor must have exactly one element")
+	}
+	return values[0], nil
+}
+
+func categoryMatch(actual, predicted float64) float64 {
+	if actual == predicted {
+		return 1
+	}
+	return 0
+}
+
+func absError(actual, predicted float64) float64 {
+	return math.Abs(actual - predicted)
+}
+
+func squaredError(actual, predicted float64) float64 {
+	return (actual - predicted) * (actual - predicted)
+}
+
+func logLoss(actual, predicted float64) float64 {
+	p := math.Max(logLossEpsilon, math.Min(1-logLossEpsilon, predicted))
+	return -(actual*math.Log(p) + (1-actual)*math.Log(1-p))
+}
+
+func mean(actual, predicted []float64, f func(actual, predicted float64) float64) float64 {
+	sum := 0.0
+	for i := range actual {
+		sum += f(actual[i], predicted[i])
+	}
+	return sum / float64(len(actual))
+}
+
+func accuracyScore(actual, predicted []float64) float64 {
+	return mean(actual, predicted, categoryMatch)
+}
+
+func meanAbsError(actual, predicted []float64) float64 {
+	return mean(actual, predicted, absError)
+}
+
+func meanSquaredError(actual, predicted []float64) float64 {
+	return mean(actual, predicted, squaredError)
+}
+
+func meanLogLoss(actual, predicted []float64) float64 {
+	return mean(actual, predicted, logLoss)
+}
+
+// r2Score computes the coefficient of determination and caps it to [0, 1]. If all actual values are
+// equal, the score is 1 for perfect predictions and 0 otherwise.
+func r2Score(actual, predicted []float64) float64 {
+	actualMean := 0.0
+	for i := range actual {
+		actualMean += actual[i]
+	}
+	actualMean /= float64(len(actual))
+
+	var residual, total float64
+	for i := range actual {
+		residual += squaredError(actual[i], predicted[i])
+		total += squaredError(actual[i], actualMean)
+	}
+
+	score := 0.0
+	if total > 0 {
+		score = 1 - residual/total
+	} else if residual == 0 {
+		score = 1
+	}
+	return math.Max(0, math.Min(1, score))
+}
+
+// classStats holds the counts of true positives, predicted positives and actual positives (support)
+// of each class.
+type classStats struct {
+	truePositives map[float64]int
+	predicted     map[float64]int
+	support       map[float64]int
+}
+
+func computeClassStats(actual, predicted []float64) classStats {
+	stats := classStats{truePositives: map[float64]int{}, predicted: map[float64]int{}, support: map[float64]int{}}
+	for i := range actual {
+		stats.support[actual[i]]++
+		stats.predicted[predicted[i]]++
+		if actual[i] == predicted[i] {
+			stats.truePositives[actual[i]]++
+		}
+	}
+	return stats
+}
+
+// weightedAverage averages a per-class metric over all classes weighted by their support.
+func (stats classStats) weightedAverage(metric func(class float64) float64) float64 {
+	var sum float64
+	var total int
+	for class, support := range stats.support {
+		sum += float64(support) * metric(class)
+		total += support
+	}
+	return sum / float64(total)
+}
+
+func (stats classStats) precision(class float64) float64 {
+	if stats.predicted[class] == 0 {
+		return 0
+	}
+	return float64(stats.truePositives[class]) / float64(stats.predicted[class])
+}
+
+func (stats classStats) recall(class float64) float64 {
+	if stats.support[class] == 0 {
+		return 0
+	}
+	return float64(stats.truePositives[class]) / float64(stats.support[class])
+}
+
+func (stats classStats) f1(class float64) float64 {
+	precision, recall := stats.precision(class), stats.recall(class)
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
+
+func precisionScore(actual, predicted []float64) float64 {
+	stats := computeClassStats(actual, predicted)
+	return stats.weightedAverage(stats.precision)
+}
+
+func recallScore(actual, predicted []float64) float64 {
+	stats := computeClassStats(actual, predicted)
+	return stats.weightedAverage(stats.recall)
+}
+
+func f1Score(actual, predicted []float64) float64 {
+	stats := computeClassStats(actual, predicted)
+	return stats.weightedAverage(stats.f1)
+}
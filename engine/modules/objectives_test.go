@@ -0,0 +1,224 @@
+package modules
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/schema/go/easemlschema/dataset"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dumpObjectiveDataset writes a dataset with one sample per value into the output directory of root.
+// Values of type string become categories and values of type float64 become scalar tensors.
+func dumpObjectiveDataset(t *testing.T, root string, classes []string, values map[string]interface{}) {
+	children := map[string]dataset.File{}
+	if classes != nil {
+		children["labels"] = &dataset.Class{Name: "labels", Categories: classes}
+	}
+	for name, value := range values {
+		var file dataset.File
+		switch v := value.(type) {
+		case string:
+			file = &dataset.Category{Name: "y", Categories: []string{v}}
+		case float64:
+			file = &dataset.Tensor{Name: "y", Dimensions: []int{1}, Data: []float64{v}, Dtype: "float64"}
+		}
+		children[name] = &dataset.Directory{Name: name, Children: map[string]dataset.File{"y": file}}
+	}
+	data := dataset.Dataset{Directory: dataset.Directory{Children: children}}
+	assert.Nil(t, data.Dump(filepath.Join(root, "output"), dataset.DefaultOpener{}))
+}
+
+func evaluateObjective(t *testing.T, id string, classes []string, actual, predicted map[string]interface{}) ([]string, float64, error) {
+	root, err := ioutil.TempDir("", "easeml-objective")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	actualPath, predictedPath := filepath.Join(root, "actual"), filepath.Join(root, "predicted")
+	dumpObjectiveDataset(t, actualPath, classes, actual)
+	dumpObjectiveDataset(t, predictedPath, classes, predicted)
+	return EvaluateBuiltinObjective(id, actualPath, predictedPath)
+}
+
+func TestBuiltinClassificationObjectives(t *testing.T) {
+	assert := assert.New(t)
+
+	classes := []string{"a", "b", "c"}
+	actual := map[string]interface{}{"s1": "a", "s2": "a", "s3": "b", "s4": "b", "s5": "c"}
+	predicted := map[string]interface{}{"s1": "a", "s2": "b", "s3": "b", "s4": "b", "s5": "a"}
+
+	// Per class: a has precision 1/2 and recall 1/2, b has precision 2/3 and recall 1, c has neither.
+	expected := map[string]float64{
+		"root/builtin-accuracy":  0.6,
+		"root/builtin-precision": (2*0.5 + 2*2.0/3.0) / 5,
+		"root/builtin-recall":    (2*0.5 + 2*1.0) / 5,
+		"root/builtin-f1-score":  (2*0.5 + 2*0.8) / 5,
+	}
+	for id, value := range expected {
+		lines, quality, err := evaluateObjective(t, id, classes, actual, predicted)
+		assert.Nil(err, id)
+		assert.InDelta(value, quality, 1e-9, id)
+		assert.Equal([]string{"s1 | 1", "s2 | 0", "s3 | 1", "s4 | 1", "s5 | 0"}, lines[:5], id)
+		assert.Len(lines, 7, id)
+
+		// The details are reported in the same way as by objective modules.
+		metadata := BuiltinObjectives[id].Metadata
+		parsedQuality, details, err := ParseEvaluationOutput(lines, &metadata)
+		assert.Nil(err, id)
+		assert.Equal(quality, parsedQuality, id)
+		assert.Equal([][]int{{1, 1, 0}, {0, 2, 0}, {1, 0, 0}}, details.ConfusionMatrix.Counts, id)
+		assert.Equal(classes, details.ConfusionMatrix.Labels, id)
+		assert.Len(details.PerClass, 3, id)
+	}
+
+	lines, _, err := evaluateObjective(t, "root/builtin-f1-score", classes, actual, predicted)
+	assert.Nil(err)
+	metadata := BuiltinObjectives["root/builtin-f1-score"].Metadata
+	_, details, err := ParseEvaluationOutput(lines, &metadata)
+	assert.Nil(err)
+	assert.InDelta(0.5, details.PerClass["a"], 1e-9)
+	assert.InDelta(0.8, details.PerClass["b"], 1e-9)
+	assert.InDelta(0.0, details.PerClass["c"], 1e-9)
+}
+
+func TestBuiltinRegressionObjectives(t *testing.T) {
+	assert := assert.New(t)
+
+	actual := map[string]interface{}{"s1": 1.0, "s2": 2.0, "s3": 3.0, "s4": 4.0}
+	predicted := map[string]interface{}{"s1": 1.0, "s2": 3.0, "s3": 3.0, "s4": 2.0}
+
+	expected := map[string]float64{
+		"root/builtin-mean-abs-error":     0.75,
+		"root/builtin-mean-squared-error": 1.25,
+		"root/builtin-r2-score":           0,
+		"root/mean-abs-error":             0.75,
+	}
+	for id, value := range expected {
+		_, quality, err := evaluateObjective(t, id, nil, actual, predicted)
+		assert.Nil(err, id)
+		assert.InDelta(value, quality, 1e-9, id)
+	}
+
+	// R2 is 1 - 5/5 = 0 above, here it is 1 - 1/5.
+	predicted = map[string]interface{}{"s1": 1.0, "s2": 2.0, "s3": 3.0, "s4": 3.0}
+	_, quality, err := evaluateObjective(t, "root/builtin-r2-score", nil, actual, predicted)
+	assert.Nil(err)
+	assert.InDelta(0.8, quality, 1e-9)
+
+	// Log loss compares binary labels with predicted probabilities.
+	actual = map[string]interface{}{"s1": 1.0, "s2": 0.0}
+	predicted = map[string]interface{}{"s1": 0.8, "s2": 0.0}
+	lines, quality, err := evaluateObjective(t, "root/builtin-log-loss", nil, actual, predicted)
+	assert.Nil(err)
+	assert.InDelta(-math.Log(0.8)/2, quality, 1e-9)
+	assert.Len(lines, 3)
+}
+
+func TestBuiltinObjectiveErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	// Unknown objective.
+	_, _, err := EvaluateBuiltinObjective("root/accuracy", "", "")
+	assert.NotNil(err)
+	assert.False(IsBuiltinObjective("root/accuracy"))
+	assert.True(IsBuiltinObjective("root/builtin-f1-score"))
+
+	// The objective modules shipped with ease.ml are computed natively.
+	assert.True(IsBuiltinObjective("root/f1-score"))
+	for alias, id := range BuiltinObjectiveAliases {
+		_, ok := BuiltinObjectives[id]
+		assert.True(ok, alias)
+	}
+
+	// Categories given to a regression objective.
+	_, _, err = evaluateObjective(t, "root/builtin-mean-abs-error", []string{"a"},
+		map[string]interface{}{"s1": "a"}, map[string]interface{}{"s1": "a"})
+	assert.NotNil(err)
+
+	// No predictions for any of the samples.
+	_, _, err = evaluateObjective(t, "root/builtin-mean-abs-error", nil,
+		map[string]interface{}{"s1": 1.0}, map[string]interface{}{"s2": 1.0})
+	assert.NotNil(err)
+}
+
+func TestBuiltinObjectiveModules(t *testing.T) {
+	assert := assert.New(t)
+
+	modules := BuiltinObjectiveModules()
+	assert.Len(modules, len(BuiltinObjectives))
+	for _, module := range modules {
+		_, err := loadSchemaString(module.SchemaIn)
+		assert.Nil(err, module.ID)
+		assert.Equal("root/"+module.Label, module.ID)
+	}
+}
+
+func TestParseObjectiveMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	metadata, err := ParseObjectiveMetadata(`{"direction": "minimize", "min": 0, "outputs": ["per-sample", "per-class"]}`)
+	assert.Nil(err)
+	assert.True(metadata.IsMinimized())
+	assert.Equal(0.0, *metadata.Min)
+	assert.Nil(metadata.Max)
+	assert.True(metadata.HasOutput(types.ObjectiveOutputPerClass))
+	assert.False(metadata.HasOutput(types.ObjectiveOutputConfusionMatrix))
+
+	// Objectives are maximized by default.
+	metadata, err = ParseObjectiveMetadata(`{}`)
+	assert.Nil(err)
+	assert.Equal(types.ObjectiveMaximize, metadata.Direction)
+	assert.False(metadata.IsMinimized())
+
+	for _, data := range []string{
+		`{"direction": "up"}`,
+		`{"min": 1, "max": 0}`,
+		`{"outputs": ["histogram"]}`,
+		`{"directon": "minimize"}`,
+		`[]`,
+	} {
+		_, err = ParseObjectiveMetadata(data)
+		assert.NotNil(err, data)
+	}
+}
+
+func TestParseEvaluationOutput(t *testing.T) {
+	assert := assert.New(t)
+
+	lines := []string{
+		"s1 | 1",
+		`{"per-class": {"a": 0.5}, "confusion-matrix": {"labels": ["a"], "counts": [[1]]}}`,
+		"0.75",
+	}
+
+	// Without metadata only the quality is returned.
+	quality, details, err := ParseEvaluationOutput(lines, nil)
+	assert.Nil(err)
+	assert.Equal(0.75, quality)
+	assert.Nil(details)
+
+	// Only the declared outputs are kept.
+	metadata := &types.ObjectiveMetadata{Direction: types.ObjectiveMaximize, Outputs: []string{types.ObjectiveOutputPerClass}}
+	quality, details, err = ParseEvaluationOutput(lines, metadata)
+	assert.Nil(err)
+	assert.Equal(0.75, quality)
+	assert.Equal(map[string]float64{"a": 0.5}, details.PerClass)
+	assert.Nil(details.ConfusionMatrix)
+
+	// The quality must be within the bounds.
+	max := 0.5
+	_, _, err = ParseEvaluationOutput(lines, &types.ObjectiveMetadata{Max: &max})
+	assert.NotNil(err)
+
+	_, _, err = ParseEvaluationOutput([]string{"s1 | 1", "{broken", "0.5"}, metadata)
+	assert.NotNil(err)
+	_, _, err = ParseEvaluationOutput([]string{"s1 | 1"}, metadata)
+	assert.NotNil(err)
+	_, _, err = ParseEvaluationOutput(nil, metadata)
+	assert.NotNil(err)
+}
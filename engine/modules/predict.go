@@ -0,0 +1,216 @@
+package modules
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/ds3lab/easeml/schema/go/easemlschema/dataset"
+
+	"github.com/pkg/errors"
+)
+
+// RunModelPrediction runs a trained model on the input directory found in dataPath and returns the model output.
+// The model memory is read from memoryPath and the predictions are written to the output directory of outputPath.
+func RunModelPrediction(runtime Runtime, modelImageName, dataPath, memoryPath, outputPath, metadataPath string, gpuDevices []string) ([]byte, error) {
+	command := []string{
+		"predict",
+		"--data", MntPrefix + dataPath,
+		"--memory", MntPrefix + memoryPath,
+		"--output", MntPrefix + outputPath,
+		"--metadata", MntPrefix + metadataPath,
+	}
+	outReader, err := runtime.Run(modelImageName, nil, command, gpuDevices)
+	if err != nil {
+		return nil, errors.Wrap(err, "model container start error")
+	}
+	defer outReader.Close()
+
+	output, err := ioutil.ReadAll(outReader)
+	if err != nil {
+		return output, errors.Wrap(err, "model container output read error")
+	}
+	return output, nil
+}
+
+// CheckPredictionInput verifies that the input directory found in dataPath matches the input schema of a model.
+func CheckPredictionInput(schemaStringIn, dataPath string) error {
+	schemaIn, err := loadSchemaString(schemaStringIn)
+	if err != nil {
+		return errors.Wrap(err, "failed to load input schema")
+	}
+	input, err := dataset.Load(filepath.Join(dataPath, "input"), true, dataset.DefaultOpener{})
+	if err != nil {
+		return errors.Wrap(err, "failed to load input data")
+	}
+	inputSchema, err := input.InferSchema()
+	if err != nil {
+		return errors.Wrap(err, "failed to infer schema of input data")
+	}
+	if match, _ := schemaIn.Match(inputSchema, false); match == false {
+		return errors.New("schema of input data doesn't match the input schema of the model")
+	}
+	return nil
+}
+
+// CheckPredictionOutput verifies that the predictions written to outputPath match the output schema of
+// a model and cover all input samples found in dataPath.
+func CheckPredictionOutput(schemaStringOut, dataPath, outputPath string) error {
+	schemaOut, err := loadSchemaString(schemaStringOut)
+	if err != nil {
+		return errors.Wrap(err, "failed to load output schema")
+	}
+	return checkPredictions(schemaOut, filepath.Join(dataPath, "input"), filepath.Join(outputPath, "output"))
+}
+
+// DumpTensorSamples writes samples given as nested lists of numbers to the input directory of dataPath. Each
+// sample maps file names to tensors.
+func DumpTensorSamples(samples map[string]map[string]interface{}, dataPath string) error {
+	if len(samples) == 0 {
+		return errors.New("at least one sample is required")
+	}
+	children := map[string]dataset.File{}
+	for sampleName, files := range samples {
+		sampleChildren := map[string]dataset.File{}
+		for fileName, value := range files {
+			dimensions, data, err := flattenTensor(value)
+			if err != nil {
+				return errors.Wrapf(err, "sample \"%s\" file \"%s\"", sampleName, fileName)
+			}
+			sampleChildren[fileName] = &dataset.Tensor{Name: fileName, Dimensions: dimensions, Data: data, Dtype: "float64"}
+		}
+		children[sampleName] = &dataset.Directory{Name: sampleName, Children: sampleChildren}
+	}
+	data := dataset.Dataset{Directory: dataset.Directory{Children: children}}
+	return errors.Wrap(data.Dump(filepath.Join(dataPath, "input"), dataset.DefaultOpener{}), "failed to write samples")
+}
+
+// LoadPredictedSamples reads the predictions written to the output directory of outputPath. Tensors are
+// returned as nested lists of numbers and categories as lists of category names.
+func LoadPredictedSamples(outputPath string) (map[string]map[string]interface{}, error) {
+	predictions, err := dataset.Load(filepath.Join(outputPath, "output"), false, dataset.DefaultOpener{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load predictions")
+	}
+	result := map[string]map[string]interface{}{}
+	for sampleName, child := range predictions.Children {
+		directory, ok := child.(*dataset.Directory)
+		if ok == false {
+			continue
+		}
+		files := map[string]interface{}{}
+		for fileName, file := range directory.Children {
+			switch file := file.(type) {
+			case *dataset.Tensor:
+				values, err := tensorValues(file)
+				if err != nil {
+					return nil, errors.Wrapf(err, "sample \"%s\" file \"%s\"", sampleName, fileName)
+				}
+				files[fileName] = nestTensor(file.Dimensions, values)
+			case *dataset.Category:
+				categories := append([]string{}, file.Categories...)
+				sort.Strings(categories)
+				files[fileName] = categories
+			}
+		}
+		result[sampleName] = files
+	}
+	return result, nil
+}
+
+// flattenTensor returns the dimensions and the values in row-major order of a tensor given as nested lists.
+func flattenTensor(value interface{}) ([]int, []float64, error) {
+	switch value := value.(type) {
+	case float64:
+		return []int{1}, []float64{value}, nil
+	case []interface{}:
+		if len(value) == 0 {
+			return nil, nil, errors.New("tensor dimensions must be positive")
+		}
+		var dimensions []int
+		data := []float64{}
+		for i := range value {
+			var elemDimensions []int
+			var elemData []float64
+			if number, ok := value[i].(float64); ok {
+				elemData = []float64{number}
+			} else {
+				var err error
+				elemDimensions, elemData, err = flattenTensor(value[i])
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			if i == 0 {
+				dimensions = elemDimensions
+			} else if intsEqual(dimensions, elemDimensions) == false {
+				return nil, nil, errors.New("tensor must not be ragged")
+			}
+			data = append(data, elemData...)
+		}
+		return append([]int{len(value)}, dimensions...), data, nil
+	default:
+		return nil, nil, errors.Errorf("tensor must be a number or a list, found %T", value)
+	}
+}
+
+// nestTensor turns values in row-major order into nested lists given the dimensions of the tensor.
+func nestTensor(dimensions []int, values []float64) interface{} {
+	if len(dimensions) <= 1 {
+		return values
+	}
+	stride := len(values) / dimensions[0]
+	result := make([]interface{}, dimensions[0])
+	for i := range result {
+		result[i] = nestTensor(dimensions[1:], values[i*stride:(i+1)*stride])
+	}
+	return result
+}
+
+// tensorValues returns the elements of a dense tensor.
+func tensorValues(tensor *dataset.Tensor) ([]float64, error) {
+	var result []float64
+	switch data := tensor.Data.(type) {
+	case []float64:
+		result = append(result, data...)
+	case []float32:
+		for i := range data {
+			result = append(result, float64(data[i]))
+		}
+	case []int64:
+		for i := range data {
+			result = append(result, float64(data[i]))
+		}
+	case []int32:
+		for i := range data {
+			result = append(result, float64(data[i]))
+		}
+	case []int16:
+		for i := range data {
+			result = append(result, float64(data[i]))
+		}
+	case []int8:
+		for i := range data {
+			result = append(result, float64(data[i]))
+		}
+	case []uint8:
+		for i := range data {
+			result = append(result, float64(data[i]))
+		}
+	default:
+		return nil, errors.Errorf("unsupported tensor data of type %T", tensor.Data)
+	}
+	return result, nil
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
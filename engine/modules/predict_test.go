@@ -0,0 +1,78 @@
+package modules
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ds3lab/easeml/schema/go/easemlschema/dataset"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredictionSamples(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := ioutil.TempDir("", "easeml_predict_")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	// Samples arrive as decoded JSON.
+	var samples map[string]map[string]interface{}
+	assert.Nil(json.Unmarshal([]byte(`{"s1": {"x": [[1, 2], [3, 4], [5, 6]]}, "s2": {"x": [[0, 1], [2, 3], [4, 5]]}}`), &samples))
+	dataPath := filepath.Join(root, "data")
+	assert.Nil(DumpTensorSamples(samples, dataPath))
+	assert.Nil(CheckPredictionInput(conformanceSchemaIn, dataPath))
+
+	// The input must match the schema of the model.
+	samples = nil
+	assert.Nil(json.Unmarshal([]byte(`{"s1": {"x": [1, 2, 3]}}`), &samples))
+	otherPath := filepath.Join(root, "other")
+	assert.Nil(DumpTensorSamples(samples, otherPath))
+	assert.NotNil(CheckPredictionInput(conformanceSchemaIn, otherPath))
+
+	for _, data := range []string{`{}`, `{"s1": {"x": [[1, 2], [3]]}}`, `{"s1": {"x": []}}`, `{"s1": {"x": "a"}}`} {
+		samples = nil
+		assert.Nil(json.Unmarshal([]byte(data), &samples))
+		assert.NotNil(DumpTensorSamples(samples, filepath.Join(root, "bad")), data)
+	}
+
+	// Predictions are read back as nested lists and category names.
+	outputPath := filepath.Join(root, "predictions")
+	predictions := dataset.Dataset{Directory: dataset.Directory{Children: map[string]dataset.File{
+		"labels": &dataset.Class{Name: "labels", Categories: []string{"a", "b"}},
+		"s1": &dataset.Directory{Name: "s1", Children: map[string]dataset.File{
+			"y": &dataset.Category{Name: "y", Categories: []string{"b"}},
+		}},
+		"s2": &dataset.Directory{Name: "s2", Children: map[string]dataset.File{
+			"y": &dataset.Category{Name: "y", Categories: []string{"a"}},
+			"z": &dataset.Tensor{Name: "z", Dimensions: []int{2, 2}, Data: []float64{1, 2, 3, 4}, Dtype: "float64"},
+		}},
+	}}}
+	assert.Nil(predictions.Dump(filepath.Join(outputPath, "output"), dataset.DefaultOpener{}))
+	assert.NotNil(CheckPredictionOutput(conformanceSchemaOut, dataPath, outputPath))
+
+	result, err := LoadPredictedSamples(outputPath)
+	assert.Nil(err)
+	assert.Len(result, 2)
+	assert.Equal([]string{"b"}, result["s1"]["y"])
+	assert.Equal([]interface{}{[]float64{1, 2}, []float64{3, 4}}, result["s2"]["z"])
+}
+
+func TestRunModelPrediction(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := ioutil.TempDir("", "easeml_predict_")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	runtime, err := NewRuntime(RuntimeProcess, filepath.Join(root, "runtime"), "")
+	assert.Nil(err)
+	modulePath := writeProcessModule(t, root)
+
+	output, err := RunModelPrediction(runtime, modulePath+"/", "/data", "/memory", "/output", "/metadata", nil)
+	assert.Nil(err)
+	assert.Equal("predict --data /data --memory /memory --output /output --metadata /metadata", strings.TrimSpace(string(output)))
+}
@@ -0,0 +1,58 @@
+package modules
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultRegistryHost is the registry of image addresses which do not name a registry.
+const defaultRegistryHost = "docker.io"
+
+// RegistryAuth contains the credentials used to pull images from a private registry.
+type RegistryAuth struct {
+	Username string
+	Password string
+}
+
+// RegistryHost returns the registry host of an image address of the format [REGISTRY/]NAME[:TAG|@DIGEST].
+// As with Docker, the first component of the name is a registry host only if it contains a dot or a colon
+// or if it is localhost.
+func RegistryHost(address string) string {
+	parts := strings.SplitN(address, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+	return defaultRegistryHost
+}
+
+// imageRepository returns the repository of an image address without the tag and the digest. Repositories
+// of the default registry are returned in their short form, e.g. ubuntu instead of docker.io/library/ubuntu.
+func imageRepository(address string) string {
+	repository := strings.Split(address, "@")[0]
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	for _, prefix := range []string{"index.docker.io/", defaultRegistryHost + "/", "library/"} {
+		repository = strings.TrimPrefix(repository, prefix)
+	}
+	return repository
+}
+
+// PinImageDigest returns the digest of the form repository@sha256:hash by which an image pulled from the
+// given address can be pulled again without depending on mutable tags. If the address already contains a
+// digest, it must match the image.
+func PinImageDigest(address string, ref ImageRef) (string, error) {
+	repository := imageRepository(address)
+	for _, repoDigest := range ref.RepoDigests {
+		parts := strings.SplitN(repoDigest, "@", 2)
+		if len(parts) != 2 || imageRepository(parts[0]) != repository {
+			continue
+		}
+		if i := strings.Index(address, "@"); i >= 0 && address[i+1:] != parts[1] {
+			continue
+		}
+		return repository + "@" + parts[1], nil
+	}
+	return "", errors.Errorf("the image pulled from \"%s\" has no matching repository digest", address)
+}
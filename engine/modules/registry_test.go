@@ -0,0 +1,49 @@
+package modules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryHost(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("docker.io", RegistryHost("ubuntu"))
+	assert.Equal("docker.io", RegistryHost("easeml/logreg:latest"))
+	assert.Equal("registry.example.com", RegistryHost("registry.example.com/team/logreg:1.0"))
+	assert.Equal("localhost:5000", RegistryHost("localhost:5000/logreg"))
+	assert.Equal("localhost", RegistryHost("localhost/logreg"))
+}
+
+func TestPinImageDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	const digest = "sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1"
+	const otherDigest = "sha256:0f6f6f3fa7bd4e1c1e1f1c2ab5bb1f79a5d1d6b5e3a0e2c2b9d10b3fdc1a7e11"
+	ref := ImageRef{ID: "sha256:1234", RepoDigests: []string{
+		"registry.example.com/team/logreg@" + otherDigest,
+		"ubuntu@" + digest,
+	}}
+
+	pinned, err := PinImageDigest("ubuntu:18.04", ref)
+	assert.Nil(err)
+	assert.Equal("ubuntu@"+digest, pinned)
+	pinned, err = PinImageDigest("docker.io/library/ubuntu", ref)
+	assert.Nil(err)
+	assert.Equal("ubuntu@"+digest, pinned)
+	pinned, err = PinImageDigest("registry.example.com/team/logreg:latest", ref)
+	assert.Nil(err)
+	assert.Equal("registry.example.com/team/logreg@"+otherDigest, pinned)
+	pinned, err = PinImageDigest("ubuntu@"+digest, ref)
+	assert.Nil(err)
+	assert.Equal("ubuntu@"+digest, pinned)
+
+	// Digests of other repositories and digests which do not match the address are refused.
+	_, err = PinImageDigest("debian:buster", ref)
+	assert.NotNil(err)
+	_, err = PinImageDigest("ubuntu@"+otherDigest, ref)
+	assert.NotNil(err)
+	_, err = PinImageDigest("localhost:5000/logreg", ref)
+	assert.NotNil(err)
+}
@@ -0,0 +1,150 @@
+package modules
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// RuntimeDocker runs modules as containers on the local Docker daemon.
+	RuntimeDocker = "docker"
+
+	// RuntimeProcess runs modules as local processes from a directory.
+	RuntimeProcess = "process"
+
+	// RuntimePodman runs modules as containers through the Docker-compatible API socket of Podman.
+	RuntimePodman = "podman"
+
+	// DefaultRuntime is the runtime used if none is specified.
+	DefaultRuntime = RuntimeDocker
+)
+
+// MntPrefix must be placed before all command line arguments if they represent a local directory
+// or file which we want to mount to the image.
+const MntPrefix = "^^^"
+
+// Runtimes lists the names of all available runtimes.
+var Runtimes = []string{RuntimeDocker, RuntimeProcess, RuntimePodman}
+
+// Runtime loads, builds and runs module images. Images are referred to by the names returned by LoadImage
+// or the tags given to BuildImage.
+type Runtime interface {
+
+	// LoadImage loads an image from a tar file and returns its name.
+	LoadImage(imageFilePath string) (string, error)
+
+	// PullImage fetches an image from a registry given its address. The credentials are only used if they
+	// are given.
+	PullImage(address string, auth RegistryAuth) error
+
+	// SaveImage writes an image as a tar file which can be loaded with LoadImage.
+	SaveImage(imageName string, output io.Writer) error
+
+	// RemoveImage removes an image from the runtime.
+	RemoveImage(imageName string) error
+
+	// BuildImage builds an image from a tar build context which contains a Dockerfile and tags it with
+	// the given name. The build log is written to the output.
+	BuildImage(buildContext io.Reader, tag string, output io.Writer) error
+
+	// InspectImage returns information about an image.
+	InspectImage(imageName string) (ImageInfo, error)
+
+	// ResolveImage returns the content-addressed identifier of an image and the registry digests it is
+	// known by. Unlike names, the identifier cannot change.
+	ResolveImage(imageName string) (ImageRef, error)
+
+	// ReadImageFile returns the content of a file in the working directory of an image.
+	ReadImageFile(imageName, fileName string) ([]byte, error)
+
+	// Run runs an image with the given command and returns its output. Arguments prefixed with MntPrefix
+	// are local paths which are made accessible to the module.
+	Run(imageName string, entrypoint, command []string, gpuDevices []string) (io.ReadCloser, error)
+}
+
+// ImageInfo contains information about an image. The entrypoint is the command which is run with the
+// arguments given to Run.
+type ImageInfo struct {
+	ID         string
+	Files      []string
+	Entrypoint []string
+}
+
+// ImageRef contains the identifier of an image and its digests of the form repository@sha256:hash.
+type ImageRef struct {
+	ID          string
+	RepoDigests []string
+}
+
+// NewRuntime creates a runtime given its name. The root directory is where runtimes which need it keep
+// their images. The host is the address of the API socket of container runtimes. If it is empty, the
+// default socket of the runtime is used.
+func NewRuntime(name, root, host string) (Runtime, error) {
+	switch name {
+	case "", RuntimeDocker:
+		return DockerRuntime{Host: host}, nil
+	case RuntimeProcess:
+		return ProcessRuntime{Root: root}, nil
+	case RuntimePodman:
+		if host == "" {
+			host = DefaultPodmanHost()
+		}
+		return DockerRuntime{Host: host, Podman: true}, nil
+	}
+	return nil, errors.Errorf("unknown runtime \"%s\", expected one of: %s", name, strings.Join(Runtimes, ", "))
+}
+
+// dockerfileInstruction is a single instruction of a Dockerfile.
+type dockerfileInstruction struct {
+	Command string
+	Args    string
+}
+
+// readDockerfile reads the instructions of a Dockerfile. Continued lines are joined and comments are dropped.
+func readDockerfile(path string) ([]dockerfileInstruction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := []dockerfileInstruction{}
+	line := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		if strings.HasSuffix(text, "\\") {
+			line += strings.TrimSuffix(text, "\\") + " "
+			continue
+		}
+		line = strings.TrimSpace(line + text)
+		if line != "" {
+			fields := strings.SplitN(line, " ", 2)
+			instruction := dockerfileInstruction{Command: strings.ToUpper(fields[0])}
+			if len(fields) > 1 {
+				instruction.Args = strings.TrimSpace(fields[1])
+			}
+			result = append(result, instruction)
+		}
+		line = ""
+	}
+	return result, scanner.Err()
+}
+
+// dockerfileCommand parses the arguments of instructions such as ENTRYPOINT which can be given either in the
+// exec form as a JSON list or in the shell form.
+func dockerfileCommand(args string) []string {
+	var result []string
+	if err := json.Unmarshal([]byte(args), &result); err == nil {
+		return result
+	}
+	return []string{"/bin/sh", "-c", args}
+}
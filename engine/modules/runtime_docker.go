@@ -0,0 +1,394 @@
+package modules
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+// DockerRuntime runs modules as containers through the Docker API. It talks to the local Docker daemon
+// unless a different host is given.
+type DockerRuntime struct {
+
+	// Host is the address of the API socket, e.g. unix:///run/podman/podman.sock. The default socket
+	// of the Docker daemon is used if it is empty.
+	Host string
+
+	// Podman is set if the API is served by Podman. Containers are then created in a way which also
+	// works with rootless Podman.
+	Podman bool
+}
+
+// GetDockerClient returns an instance of the Docker client.
+func GetDockerClient() *client.Client {
+	return DockerRuntime{}.getClient()
+}
+
+// getClient returns an instance of the Docker client which talks to the host of the runtime.
+func (runtime DockerRuntime) getClient() *client.Client {
+	// TODO: Get API version automatically.
+	// See: https://stackoverflow.com/a/48638182
+	opts := []client.Opt{client.WithVersion("1.37")}
+	if runtime.Host != "" {
+		opts = append(opts, client.WithHost(runtime.Host))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cli
+}
+
+// Run runs a given image name and returns the standard output reader. Local paths are mounted to the container.
+func (runtime DockerRuntime) Run(imageName string, entrypoint, command []string, gpuDevices []string) (io.ReadCloser, error) {
+
+	// Go through all commands and see if any of them correspond to a file. If yes, mount it to
+	// the container and remap the command argument.
+	remappedCommand := make([]string, len(command))
+	bindsMap := map[string]interface{}{}
+	targetDirsMap := map[string]interface{}{}
+	binds := []string{}
+	for i := range command {
+
+		if strings.HasPrefix(command[i], MntPrefix) {
+
+			command[i] = strings.TrimPrefix(command[i], MntPrefix)
+
+			if stats, err := os.Stat(command[i]); err == nil {
+
+				// Get absolute path.
+				absPath, err := filepath.Abs(command[i])
+				if err != nil {
+					err = errors.Wrap(err, "absolute path inference failed")
+					return nil, err
+				}
+
+				// If it is a directory, we can immediately mount it. Otherwise we mount the parent.
+				var mapping string
+				if stats.IsDir() {
+					dirName := filepath.Base(absPath)
+
+					// If this dirname was already mounted, we rename it by appending underscores.
+					for {
+						if _, ok := targetDirsMap[dirName]; ok {
+							dirName = dirName + "_"
+						} else {
+							break
+						}
+					}
+					targetDirsMap[dirName] = nil
+
+					mountedPath := filepath.Join("/mnt", dirName)
+
+					remappedCommand[i] = mountedPath
+					mapping = fmt.Sprintf("%s:%s", absPath, mountedPath)
+
+				} else {
+					fileName := filepath.Base(absPath)
+					parentPath := filepath.Dir(absPath)
+					dirName := filepath.Base(parentPath)
+
+					// If this dirname was already mounted, we rename it by appending underscores.
+					for {
+						if _, ok := targetDirsMap[dirName]; ok {
+							dirName = dirName + "_"
+						} else {
+							break
+						}
+					}
+					targetDirsMap[dirName] = nil
+
+					mountedPath := filepath.Join("/mnt", dirName)
+
+					remappedCommand[i] = filepath.Join(mountedPath, fileName)
+					mapping = fmt.Sprintf("%s:%s", parentPath, mountedPath)
+
+				}
+
+				// Add to binds if it doesn't exist yet.
+				if _, ok := bindsMap[mapping]; ok == false {
+					bindsMap[mapping] = nil
+					binds = append(binds, mapping)
+				}
+
+				continue
+			}
+		}
+		// We consider it not to be a file path.
+		remappedCommand[i] = command[i]
+
+	}
+
+	// Assemble the host config with appropriate binds.
+	hostConfig := container.HostConfig{
+		Binds: binds,
+	}
+
+	// If GPU devices were specified, we need to add the appropriate device requests to the host config.
+	// Podman exposes GPUs as CDI devices instead.
+	if runtime.Podman {
+		hostConfig.Devices = podmanGPUDevices(gpuDevices)
+	} else if gpuDevices != nil && len(gpuDevices) > 0 {
+
+		// We will convert the input slice to a map to prevent duplicates.
+		gpuDevicesMap := make(map[string]struct{}, len(gpuDevices))
+		for _, s := range gpuDevices {
+			gpuDevicesMap[s] = struct{}{}
+		}
+
+		// Check if -1 is in the list, which maps to all devices. Otherwise we list the specific device IDs.
+		_, ok := gpuDevicesMap["-1"]
+		var deviceRequest container.DeviceRequest
+		if ok {
+			deviceRequest = container.DeviceRequest{
+				Count:        -1,
+				Capabilities: [][]string{[]string{"gpu"}},
+			}
+		} else {
+			deviceIDs := make([]string, 0, len(gpuDevicesMap))
+			for id := range gpuDevicesMap {
+				deviceIDs = append(deviceIDs, id)
+			}
+			deviceRequest = container.DeviceRequest{
+				DeviceIDs:    deviceIDs,
+				Capabilities: [][]string{[]string{"gpu"}},
+			}
+		}
+
+		// Add the device requests to the host config.
+		hostConfig.Resources = container.Resources{DeviceRequests: []container.DeviceRequest{deviceRequest}}
+	}
+
+	// TODO Find a proper place to store the username, we should create username string once
+	currentUser, err := user.Current()
+	if err != nil {
+		panic(err)
+	}
+	username := currentUser.Uid + ":" + currentUser.Gid
+
+	// Rootless Podman maps the container users to subordinate IDs of the user running it. Keeping the
+	// ID of the user makes the files written to mounted directories belong to the user.
+	if runtime.Podman && currentUser.Uid != "0" {
+		hostConfig.UsernsMode = "keep-id"
+	}
+
+	ctx := context.Background()
+	cli := runtime.getClient()
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:      imageName,
+		Entrypoint: entrypoint,
+		Cmd:        remappedCommand,
+		User:       username,
+		Tty:        true,
+	}, &hostConfig, nil, "")
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		cli.ContainerRemove(
+			context.Background(),
+			resp.ID,
+			types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+	}()
+
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		panic(err)
+	}
+
+	statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			panic(err)
+		}
+	case <-statusCh:
+	}
+
+	out, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true})
+	if err != nil {
+		panic(err)
+	}
+	return out, nil
+}
+
+// LoadImage loads a Docker image from a tar file.
+func (runtime DockerRuntime) LoadImage(imageFilePath string) (string, error) {
+
+	cli := runtime.getClient()
+	imageFile, err := os.Open(imageFilePath)
+	if err != nil {
+		return "", err
+	}
+	defer imageFile.Close()
+	resp, err := cli.ImageLoad(context.Background(), imageFile, false)
+	if err != nil {
+		return "", errors.Wrap(err, "docker image load error")
+	}
+	if resp.JSON != true {
+		panic("expected JSON response")
+	}
+	defer resp.Body.Close()
+
+	type responseBodyType struct {
+		Stream string `json:"stream"`
+	}
+	var result responseBodyType
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return "", err
+	}
+	// Podman reports all loaded names in a comma separated list. Images saved without a name are
+	// reported by their ID.
+	imageName := strings.TrimPrefix(result.Stream, "Loaded image:")
+	imageName = strings.TrimPrefix(imageName, "Loaded image(s):")
+	imageName = strings.TrimPrefix(imageName, "Loaded image ID:")
+	imageName = strings.TrimSpace(strings.Split(imageName, ",")[0])
+
+	return imageName, nil
+}
+
+// PullImage pulls an image from a Docker registry.
+func (runtime DockerRuntime) PullImage(address string, auth RegistryAuth) error {
+	options := types.ImagePullOptions{}
+	if auth.Username != "" {
+		authConfig, err := json.Marshal(types.AuthConfig{
+			Username:      auth.Username,
+			Password:      auth.Password,
+			ServerAddress: RegistryHost(address),
+		})
+		if err != nil {
+			return err
+		}
+		options.RegistryAuth = base64.URLEncoding.EncodeToString(authConfig)
+	}
+	cli := runtime.getClient()
+	resp, err := cli.ImagePull(context.Background(), address, options)
+	if err != nil {
+		return errors.Wrap(err, "docker image pull error")
+	}
+	defer resp.Close()
+	return readDockerMessages(resp, ioutil.Discard)
+}
+
+// SaveImage writes an image as a tar file.
+func (runtime DockerRuntime) SaveImage(imageName string, output io.Writer) error {
+	cli := runtime.getClient()
+	reader, err := cli.ImageSave(context.Background(), []string{imageName})
+	if err != nil {
+		return errors.Wrap(err, "failed to save docker image")
+	}
+	defer reader.Close()
+	_, err = io.Copy(output, reader)
+	return errors.Wrap(err, "failed to save docker image")
+}
+
+// RemoveImage removes an image from the Docker daemon.
+func (runtime DockerRuntime) RemoveImage(imageName string) error {
+	cli := runtime.getClient()
+	_, err := cli.ImageRemove(context.Background(), imageName, types.ImageRemoveOptions{Force: true})
+	return errors.Wrap(err, "failed to remove docker image")
+}
+
+// BuildImage builds an image from a tar build context.
+func (runtime DockerRuntime) BuildImage(buildContext io.Reader, tag string, output io.Writer) error {
+	cli := runtime.getClient()
+	buildOptions := types.ImageBuildOptions{Tags: []string{tag}, Remove: true}
+	resp, err := cli.ImageBuild(context.Background(), buildContext, buildOptions)
+	if err != nil {
+		return errors.Wrap(err, "failed to build docker image")
+	}
+	defer resp.Body.Close()
+	return errors.Wrap(readDockerMessages(resp.Body, output), "failed to build docker image")
+}
+
+// InspectImage returns the image ID and lists the working directory of the image.
+func (runtime DockerRuntime) InspectImage(imageName string) (ImageInfo, error) {
+	cli := runtime.getClient()
+	inspect, _, err := cli.ImageInspectWithRaw(context.Background(), imageName)
+	if err != nil {
+		return ImageInfo{}, errors.Wrap(err, "docker image inspect error")
+	}
+
+	outReader, err := runtime.Run(imageName, []string{"ls"}, []string{"--color=never", "."}, nil)
+	if err != nil {
+		return ImageInfo{}, errors.Wrap(err, "docker container start error")
+	}
+	defer outReader.Close()
+	containerOutput, err := ioutil.ReadAll(outReader)
+	if err != nil {
+		return ImageInfo{}, errors.Wrap(err, "docker container output read error")
+	}
+
+	info := ImageInfo{ID: inspect.ID, Files: strings.Fields(string(containerOutput))}
+	if inspect.Config != nil {
+		info.Entrypoint = inspect.Config.Entrypoint
+	}
+	return info, nil
+}
+
+// ResolveImage returns the image ID, which is the digest of the image configuration, and the repository
+// digests of the image.
+func (runtime DockerRuntime) ResolveImage(imageName string) (ImageRef, error) {
+	cli := runtime.getClient()
+	inspect, _, err := cli.ImageInspectWithRaw(context.Background(), imageName)
+	if err != nil {
+		return ImageRef{}, errors.Wrap(err, "docker image inspect error")
+	}
+	return ImageRef{ID: inspect.ID, RepoDigests: inspect.RepoDigests}, nil
+}
+
+// ReadImageFile reads a file from the working directory of the image.
+func (runtime DockerRuntime) ReadImageFile(imageName, fileName string) ([]byte, error) {
+	outReader, err := runtime.Run(imageName, []string{"cat"}, []string{fileName}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "docker container start error")
+	}
+	defer outReader.Close()
+	result, err := ioutil.ReadAll(outReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "docker container output read error")
+	}
+	return result, nil
+}
+
+// readDockerMessages reads the JSON message stream returned by image pulls and builds. The stream content
+// is written to the output and errors reported in the stream are returned.
+func readDockerMessages(reader io.Reader, output io.Writer) error {
+	type message struct {
+		Stream string `json:"stream"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	decoder := json.NewDecoder(reader)
+	for {
+		var m message
+		err := decoder.Decode(&m)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "docker message decode error")
+		}
+		if m.Error != "" {
+			return errors.New(m.Error)
+		}
+		if m.Stream != "" {
+			fmt.Fprint(output, m.Stream)
+		} else if m.Status != "" {
+			fmt.Fprintln(output, m.Status)
+		}
+	}
+}
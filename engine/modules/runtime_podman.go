@@ -0,0 +1,42 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// podmanGPUVendor is the CDI device kind of NVidia GPUs. The CDI specification is generated on the host
+// with the NVidia container toolkit (nvidia-ctk cdi generate).
+const podmanGPUVendor = "nvidia.com/gpu"
+
+// DefaultPodmanHost returns the address of the Podman API socket. The socket of the user is used for
+// rootless Podman and the system socket is used if the process runs as root.
+func DefaultPodmanHost() string {
+	if os.Getuid() != 0 {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+		}
+		return "unix://" + filepath.Join(runtimeDir, "podman", "podman.sock")
+	}
+	return "unix:///run/podman/podman.sock"
+}
+
+// podmanGPUDevices maps GPU device identifiers to CDI devices. If -1 is in the list, all devices are used.
+func podmanGPUDevices(gpuDevices []string) []container.DeviceMapping {
+	result := []container.DeviceMapping{}
+	seen := map[string]bool{}
+	for _, id := range gpuDevices {
+		if id == "-1" {
+			return []container.DeviceMapping{{PathOnHost: podmanGPUVendor + "=all"}}
+		}
+		if seen[id] == false {
+			seen[id] = true
+			result = append(result, container.DeviceMapping{PathOnHost: podmanGPUVendor + "=" + id})
+		}
+	}
+	return result
+}
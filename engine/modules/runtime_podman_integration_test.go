@@ -0,0 +1,72 @@
+//go:build podman
+// +build podman
+
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// These tests need a running Podman API socket (podman system service). They are run with:
+//
+//	go test -tags podman ./modules
+//
+// The socket is given by EASEML_PODMAN_HOST, otherwise the default socket is used. GPU devices are only
+// tested if EASEML_PODMAN_GPUS lists their identifiers, e.g. "0,1" or "-1" for all devices, in which case
+// the CDI specification of the host must be generated with nvidia-ctk cdi generate.
+
+const podmanTestImage = "docker.io/library/busybox:latest"
+
+func runPodmanTest(t *testing.T, runtime Runtime, command []string, gpuDevices []string) string {
+	outReader, err := runtime.Run(podmanTestImage, nil, command, gpuDevices)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer outReader.Close()
+	output, err := ioutil.ReadAll(outReader)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	return string(output)
+}
+
+func TestPodmanRuntimeIntegration(t *testing.T) {
+	assert := assert.New(t)
+
+	runtime, err := NewRuntime(RuntimePodman, "", os.Getenv("EASEML_PODMAN_HOST"))
+	assert.Nil(err)
+	if err := runtime.PullImage(podmanTestImage, RegistryAuth{}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	assert.Contains(runPodmanTest(t, runtime, []string{"echo", "hello"}, nil), "hello")
+
+	// Rootless Podman keeps the user ID so that mounted files belong to the user.
+	if os.Getuid() != 0 {
+		output := runPodmanTest(t, runtime, []string{"id", "-u"}, nil)
+		assert.Equal(strconv.Itoa(os.Getuid()), strings.TrimSpace(output))
+	}
+}
+
+func TestPodmanRuntimeGPUIntegration(t *testing.T) {
+	gpus := os.Getenv("EASEML_PODMAN_GPUS")
+	if gpus == "" {
+		t.Skip("EASEML_PODMAN_GPUS is not set")
+	}
+
+	runtime, err := NewRuntime(RuntimePodman, "", os.Getenv("EASEML_PODMAN_HOST"))
+	assert.Nil(t, err)
+	if err := runtime.PullImage(podmanTestImage, RegistryAuth{}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// The CDI devices given as device mappings must be resolved by Podman and appear in the container.
+	output := runPodmanTest(t, runtime, []string{"ls", "/dev"}, strings.Split(gpus, ","))
+	assert.Contains(t, output, "nvidia")
+}
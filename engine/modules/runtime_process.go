@@ -0,0 +1,339 @@
+package modules
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ds3lab/easeml/engine/storage"
+
+	"github.com/mholt/archiver"
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+)
+
+// processEntrypointFile stores the entrypoint of a process image. It is written when an image is built
+// from a Dockerfile with an ENTRYPOINT instruction.
+const processEntrypointFile = ".entrypoint"
+
+// ProcessRuntime runs modules as local processes. An image is a directory which plays the role of the
+// working directory of a container image. Images are kept in the root directory under their names, but any
+// local directory can be used as an image by giving its path as the image name.
+//
+// Modules are run without isolation so they need all their dependencies to be installed on the host. The
+// entrypoint is taken from the ENTRYPOINT instruction of the Dockerfile of the module if there is one.
+// Otherwise, the first command argument is an executable in the image directory or on the search path.
+type ProcessRuntime struct {
+	Root string
+}
+
+// imagePath returns the directory of an image.
+func (runtime ProcessRuntime) imagePath(imageName string) (string, error) {
+	if stats, err := os.Stat(imageName); err == nil && stats.IsDir() {
+		return filepath.Abs(imageName)
+	}
+	path := filepath.Join(runtime.Root, "images", processImageDirName(imageName))
+	if stats, err := os.Stat(path); err != nil || stats.IsDir() == false {
+		return "", errors.Errorf("image \"%s\" not found", imageName)
+	}
+	return path, nil
+}
+
+func processImageDirName(imageName string) string {
+	return strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(imageName)
+}
+
+// LoadImage extracts an image tar file to the root directory. The image is named after the hash of the tar
+// file so loading the same file twice is cheap. If the path is a directory, it is used as is.
+func (runtime ProcessRuntime) LoadImage(imageFilePath string) (string, error) {
+	if stats, err := os.Stat(imageFilePath); err != nil {
+		return "", err
+	} else if stats.IsDir() {
+		return filepath.Abs(imageFilePath)
+	}
+
+	f, err := os.Open(imageFilePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return "", errors.Wrap(err, "image file read error")
+	}
+	imageName := "sha256-" + hex.EncodeToString(hash.Sum(nil))[:16]
+	if _, err := runtime.imagePath(imageName); err == nil {
+		return imageName, nil
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return imageName, runtime.storeImage(imageName, func(dir string) error {
+		return archiver.Tar.Read(f, dir)
+	})
+}
+
+// storeImage creates an image directory by filling a temporary directory and moving it in place.
+func (runtime ProcessRuntime) storeImage(imageName string, fill func(dir string) error) error {
+	imagesPath := filepath.Join(runtime.Root, "images")
+	if err := os.MkdirAll(imagesPath, storage.DefaultFilePerm); err != nil {
+		return err
+	}
+	tempDir, err := ioutil.TempDir(imagesPath, ".tmp_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err = fill(tempDir); err != nil {
+		return err
+	}
+	path := filepath.Join(imagesPath, processImageDirName(imageName))
+	if err = os.RemoveAll(path); err != nil {
+		return err
+	}
+	return os.Rename(tempDir, path)
+}
+
+// PullImage is not supported by the process runtime.
+func (runtime ProcessRuntime) PullImage(address string, auth RegistryAuth) error {
+	return errors.Errorf("the process runtime cannot pull image \"%s\" from a registry", address)
+}
+
+// SaveImage writes the content of the image directory as a tar file.
+func (runtime ProcessRuntime) SaveImage(imageName string, output io.Writer) error {
+	path, err := runtime.imagePath(imageName)
+	if err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	filePaths := make([]string, len(files))
+	for i := range files {
+		filePaths[i] = filepath.Join(path, files[i].Name())
+	}
+	return errors.Wrap(archiver.Tar.Write(output, filePaths), "image archive error")
+}
+
+// RemoveImage removes an image from the root directory. Directories outside of it are never removed.
+func (runtime ProcessRuntime) RemoveImage(imageName string) error {
+	path := filepath.Join(runtime.Root, "images", processImageDirName(imageName))
+	if _, err := os.Stat(path); err != nil {
+		return errors.Errorf("image \"%s\" not found", imageName)
+	}
+	return os.RemoveAll(path)
+}
+
+// BuildImage builds an image by applying the Dockerfile of the build context to a directory. The FROM
+// instruction copies the base image if it is a process image, COPY and ADD copy files from the build context
+// and ENTRYPOINT sets the entrypoint. Other instructions, such as RUN, cannot be applied to the host and
+// are skipped. Destination paths are resolved relative to the image directory.
+func (runtime ProcessRuntime) BuildImage(buildContext io.Reader, tag string, output io.Writer) error {
+	contextDir, err := ioutil.TempDir("", "easeml_build_")
+	if err != nil {
+		return errors.Wrap(err, "failed to generate temp directory")
+	}
+	defer os.RemoveAll(contextDir)
+	if err = archiver.Tar.Read(buildContext, contextDir); err != nil {
+		return errors.Wrap(err, "build context read error")
+	}
+	instructions, err := readDockerfile(filepath.Join(contextDir, "Dockerfile"))
+	if err != nil {
+		return errors.Wrap(err, "dockerfile read error")
+	}
+
+	return runtime.storeImage(tag, func(dir string) error {
+		workDir := "/"
+		for step, instruction := range instructions {
+			fmt.Fprintf(output, "Step %d/%d : %s %s\n", step+1, len(instructions), instruction.Command, instruction.Args)
+			switch instruction.Command {
+			case "FROM":
+				if base, err := runtime.imagePath(strings.Fields(instruction.Args)[0]); err == nil {
+					if err = copy.Copy(base, dir); err != nil {
+						return errors.Wrap(err, "base image copy error")
+					}
+				}
+			case "WORKDIR":
+				workDir = filepath.Join(workDir, instruction.Args)
+			case "COPY", "ADD":
+				args := dockerfileCommand(instruction.Args)
+				if len(args) < 2 || args[0] == "/bin/sh" {
+					args = strings.Fields(instruction.Args)
+				}
+				if len(args) < 2 {
+					return errors.Errorf("step %d: %s needs a source and a destination", step+1, instruction.Command)
+				}
+				destination := args[len(args)-1]
+				if filepath.IsAbs(destination) == false {
+					destination = filepath.Join(workDir, destination)
+				}
+				if rel, err := filepath.Rel(workDir, destination); err == nil && strings.HasPrefix(rel, "..") == false {
+					destination = rel
+				}
+				destination = filepath.Join(dir, destination)
+				for _, source := range args[:len(args)-1] {
+					matches, err := filepath.Glob(filepath.Join(contextDir, source))
+					if err != nil {
+						return err
+					}
+					for _, match := range matches {
+						target := destination
+						if stats, err := os.Stat(match); err == nil && stats.IsDir() == false &&
+							(len(matches) > 1 || strings.HasSuffix(args[len(args)-1], "/")) {
+							target = filepath.Join(destination, filepath.Base(match))
+						}
+						if err = copy.Copy(match, target); err != nil {
+							return errors.Wrap(err, "build context copy error")
+						}
+					}
+				}
+			case "ENTRYPOINT":
+				entrypoint, err := json.Marshal(dockerfileCommand(instruction.Args))
+				if err != nil {
+					return err
+				}
+				err = ioutil.WriteFile(filepath.Join(dir, processEntrypointFile), entrypoint, storage.DefaultFilePerm)
+				if err != nil {
+					return err
+				}
+			default:
+				fmt.Fprintf(output, " ---> Skipping %s, it is not supported by the process runtime\n", instruction.Command)
+			}
+		}
+		fmt.Fprintf(output, "Successfully tagged %s\n", tag)
+		return nil
+	})
+}
+
+// InspectImage lists the files in the image directory.
+func (runtime ProcessRuntime) InspectImage(imageName string) (ImageInfo, error) {
+	path, err := runtime.imagePath(imageName)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	result := ImageInfo{ID: path, Files: []string{}, Entrypoint: runtime.entrypoint(path)}
+	for i := range files {
+		if files[i].Name() != processEntrypointFile {
+			result.Files = append(result.Files, files[i].Name())
+		}
+	}
+
+	// Executables in the image directory are given by their path since the entrypoint can be run by
+	// another program.
+	if len(result.Entrypoint) > 0 {
+		if stats, err := os.Stat(filepath.Join(path, result.Entrypoint[0])); err == nil && stats.IsDir() == false {
+			result.Entrypoint[0] = filepath.Join(path, result.Entrypoint[0])
+		}
+	}
+	return result, nil
+}
+
+// ResolveImage identifies the image by the hash of the content of its directory. Process images have no
+// repository digests.
+func (runtime ProcessRuntime) ResolveImage(imageName string) (ImageRef, error) {
+	path, err := runtime.imagePath(imageName)
+	if err != nil {
+		return ImageRef{}, err
+	}
+	hash, err := BuildContextHash(path)
+	if err != nil {
+		return ImageRef{}, err
+	}
+	return ImageRef{ID: "sha256:" + hash}, nil
+}
+
+// ReadImageFile reads a file from the image directory.
+func (runtime ProcessRuntime) ReadImageFile(imageName, fileName string) ([]byte, error) {
+	path, err := runtime.imagePath(imageName)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(filepath.Join(path, fileName))
+}
+
+// entrypoint returns the entrypoint stored in the image or the one given by its Dockerfile.
+func (runtime ProcessRuntime) entrypoint(path string) []string {
+	var result []string
+	if data, err := ioutil.ReadFile(filepath.Join(path, processEntrypointFile)); err == nil {
+		if json.Unmarshal(data, &result) == nil {
+			return result
+		}
+	}
+	if instructions, err := readDockerfile(filepath.Join(path, "Dockerfile")); err == nil {
+		for i := range instructions {
+			if instructions[i].Command == "ENTRYPOINT" {
+				result = dockerfileCommand(instructions[i].Args)
+			}
+		}
+	}
+	return result
+}
+
+// Run runs the image as a process in the image directory. Local paths are passed as absolute paths. As with
+// containers that have a terminal attached, the output combines the standard output and error streams and
+// the exit status is not checked. GPU devices are selected with the CUDA_VISIBLE_DEVICES variable.
+func (runtime ProcessRuntime) Run(imageName string, entrypoint, command []string, gpuDevices []string) (io.ReadCloser, error) {
+	path, err := runtime.imagePath(imageName)
+	if err != nil {
+		return nil, err
+	}
+	if entrypoint == nil {
+		entrypoint = runtime.entrypoint(path)
+	}
+
+	args := append([]string{}, entrypoint...)
+	for i := range command {
+		arg := command[i]
+		if strings.HasPrefix(arg, MntPrefix) {
+			arg, err = filepath.Abs(strings.TrimPrefix(arg, MntPrefix))
+			if err != nil {
+				return nil, errors.Wrap(err, "absolute path inference failed")
+			}
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return nil, errors.Errorf("image \"%s\" has no entrypoint and no command was given", imageName)
+	}
+
+	// Executables in the image directory take precedence over ones on the search path.
+	program := args[0]
+	if stats, err := os.Stat(filepath.Join(path, program)); err == nil && stats.IsDir() == false {
+		program = filepath.Join(path, program)
+	}
+
+	cmd := exec.Command(program, args[1:]...)
+	cmd.Dir = path
+	cmd.Env = os.Environ()
+	if len(gpuDevices) > 0 {
+		if containsString(gpuDevices, "-1") == false {
+			cmd.Env = append(cmd.Env, "CUDA_VISIBLE_DEVICES="+strings.Join(gpuDevices, ","))
+		}
+	} else {
+		cmd.Env = append(cmd.Env, "CUDA_VISIBLE_DEVICES=")
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err = cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "process start error")
+	}
+	cmd.Wait()
+
+	return ioutil.NopCloser(&output), nil
+}
@@ -0,0 +1,133 @@
+package modules
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mholt/archiver"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeProcessModule writes a model which prints its arguments and returns its directory.
+func writeProcessModule(t *testing.T, root string) string {
+	path := filepath.Join(root, "echo-model")
+	files := map[string]string{
+		"Dockerfile":        "FROM python:3.6\nWORKDIR /usr/src/app\nRUN pip install numpy\nCOPY . .\nENTRYPOINT [\"/bin/sh\", \"run.sh\"]\n",
+		"run.sh":            "echo \"$@\"\n",
+		"README.md":         "# Echo Model\n\nPrints its arguments.\n",
+		"schema-in.json":    conformanceSchemaIn,
+		"schema-out.json":   conformanceSchemaOut,
+		"config-space.json": `{"depth": {".int": [1, 10]}}`,
+	}
+	assert.Nil(t, os.MkdirAll(path, 0700))
+	for name, content := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(path, name), []byte(content), 0600))
+	}
+	return path
+}
+
+func TestProcessRuntime(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := ioutil.TempDir("", "easeml_runtime_")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	runtime, err := NewRuntime(RuntimeProcess, filepath.Join(root, "runtime"), "")
+	assert.Nil(err)
+	modulePath := writeProcessModule(t, root)
+
+	// A module directory can be used directly as an image.
+	id, name, description, schemaIn, schemaOut, configSpace, objectiveMetadata, err := InferModuleProperties(runtime, modulePath+"/")
+	assert.Nil(err)
+	assert.Equal("echo-model", id)
+	assert.Equal("Echo Model", name)
+	assert.Equal("Prints its arguments.", strings.TrimSpace(description))
+	assert.JSONEq(conformanceSchemaIn, schemaIn)
+	assert.JSONEq(conformanceSchemaOut, schemaOut)
+	assert.JSONEq(`{"depth": {".int": [1, 10]}}`, configSpace)
+	assert.Empty(objectiveMetadata)
+
+	// Building applies the Dockerfile and skips what cannot run on the host.
+	files, err := ioutil.ReadDir(modulePath)
+	assert.Nil(err)
+	filePaths := []string{}
+	for i := range files {
+		filePaths = append(filePaths, filepath.Join(modulePath, files[i].Name()))
+	}
+	var buildContext, buildLog bytes.Buffer
+	assert.Nil(archiver.Tar.Write(&buildContext, filePaths))
+	assert.Nil(runtime.BuildImage(&buildContext, "easeml/echo-model:latest", &buildLog))
+	assert.Contains(buildLog.String(), "Skipping RUN")
+
+	dataPath := filepath.Join(root, "data")
+	assert.Nil(os.Mkdir(dataPath, 0700))
+	reader, err := runtime.Run("easeml/echo-model:latest", nil, []string{"train", "--data", MntPrefix + dataPath}, nil)
+	assert.Nil(err)
+	output, err := ioutil.ReadAll(reader)
+	assert.Nil(err)
+	assert.Equal("train --data "+dataPath, strings.TrimSpace(string(output)))
+
+	// Saved images can be loaded under a new name.
+	var image bytes.Buffer
+	assert.Nil(runtime.SaveImage("easeml/echo-model:latest", &image))
+	imagePath := filepath.Join(root, "image.tar")
+	assert.Nil(ioutil.WriteFile(imagePath, image.Bytes(), 0600))
+	imageName, err := runtime.LoadImage(imagePath)
+	assert.Nil(err)
+	info, err := runtime.InspectImage(imageName)
+	assert.Nil(err)
+	assert.Contains(info.Files, "run.sh")
+	assert.NotContains(info.Files, processEntrypointFile)
+
+	// The image ID depends only on the content so it survives saving and loading.
+	ref, err := runtime.ResolveImage("easeml/echo-model:latest")
+	assert.Nil(err)
+	loadedRef, err := runtime.ResolveImage(imageName)
+	assert.Nil(err)
+	assert.Equal(ref.ID, loadedRef.ID)
+	assert.Empty(loadedRef.RepoDigests)
+	dirRef, err := runtime.ResolveImage(modulePath)
+	assert.Nil(err)
+	assert.NotEqual(ref.ID, dirRef.ID)
+
+	data, err := runtime.ReadImageFile(imageName, "config-space.json")
+	assert.Nil(err)
+	assert.JSONEq(`{"depth": {".int": [1, 10]}}`, string(data))
+
+	assert.Nil(runtime.RemoveImage("easeml/echo-model:latest"))
+	_, err = runtime.InspectImage("easeml/echo-model:latest")
+	assert.NotNil(err)
+	assert.NotNil(runtime.RemoveImage(modulePath))
+	assert.NotNil(runtime.PullImage("easeml/echo-model", RegistryAuth{}))
+
+	_, err = NewRuntime("vm", root, "")
+	assert.NotNil(err)
+}
+
+func TestPodmanRuntime(t *testing.T) {
+	assert := assert.New(t)
+
+	runtime, err := NewRuntime(RuntimePodman, "", "")
+	assert.Nil(err)
+	assert.Equal(DockerRuntime{Host: DefaultPodmanHost(), Podman: true}, runtime)
+	assert.True(strings.HasPrefix(DefaultPodmanHost(), "unix://"))
+	assert.True(strings.HasSuffix(DefaultPodmanHost(), "/podman/podman.sock"))
+
+	runtime, err = NewRuntime(RuntimePodman, "", "tcp://localhost:8080")
+	assert.Nil(err)
+	assert.Equal("tcp://localhost:8080", runtime.(DockerRuntime).Host)
+
+	// GPU devices are passed as CDI devices.
+	assert.Empty(podmanGPUDevices(nil))
+	devices := podmanGPUDevices([]string{"0", "2", "0"})
+	assert.Len(devices, 2)
+	assert.Equal("nvidia.com/gpu=0", devices[0].PathOnHost)
+	assert.Equal("nvidia.com/gpu=2", devices[1].PathOnHost)
+	devices = podmanGPUDevices([]string{"1", "-1"})
+	assert.Len(devices, 1)
+	assert.Equal("nvidia.com/gpu=all", devices[0].PathOnHost)
+}
@@ -0,0 +1,261 @@
+package modules
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/ds3lab/easeml/engine/database/model/types"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultSamplerSize is the size of Latin hypercube designs if no size is given.
+const DefaultSamplerSize = types.DefaultMaxTasks
+
+// Sampler generates a deterministic sequence of points in the unit hypercube.
+type Sampler interface {
+	Next() []float64
+}
+
+// SamplerOptions specifies how configurations are sampled from a config space. Samplers with the same
+// options always produce the same sequence of configurations.
+type SamplerOptions struct {
+	Name string
+	Seed int64
+	Size int
+}
+
+// NewSampler creates a sampler of the given name which generates points with the given number of
+// coordinates. The seed makes the sequence reproducible. The size is only used by Latin hypercube
+// sampling where it is the number of points which are stratified together.
+func NewSampler(name string, dims int, seed int64, size int) (Sampler, error) {
+	rng := rand.New(rand.NewSource(seed))
+	switch name {
+	case types.SamplerRandom:
+		return &randomSampler{rng: rng, dims: dims}, nil
+	case types.SamplerSobol:
+		return newSobolSampler(rng, dims)
+	case types.SamplerHalton:
+		return newHaltonSampler(rng, dims), nil
+	case types.SamplerLHS:
+		if size < 1 {
+			size = DefaultSamplerSize
+		}
+		return &lhsSampler{rng: rng, dims: dims, size: size}, nil
+	}
+	return nil, errors.Errorf("unknown sampler \"%s\"", name)
+}
+
+// SampleConfigs draws configurations from a config space with the given sampler. The first skip
+// configurations of the sequence are dropped, which allows continuing a sequence that has been partially
+// consumed. Points whose configurations violate constraints are rejected.
+func SampleConfigs(space ConfigElem, options SamplerOptions, skip, count int) ([]ConfigElem, error) {
+	skipped := 0
+	drop := func(ConfigElem) bool {
+		skipped++
+		return skipped <= skip
+	}
+	return sampleConfigs(space, options, count, drop, skip+maxConstraintAttempts)
+}
+
+// SampleNewConfigs draws the first count configurations of the sequence of the sampler for which exists
+// returns false. If the sampler keeps producing existing configurations it gives up and returns fewer.
+func SampleNewConfigs(space ConfigElem, options SamplerOptions, count int, exists func(ConfigElem) bool) ([]ConfigElem, error) {
+	return sampleConfigs(space, options, count, exists, maxConstraintAttempts)
+}
+
+func sampleConfigs(space ConfigElem, options SamplerOptions, count int, drop func(ConfigElem) bool, maxDropped int) ([]ConfigElem, error) {
+	sampler, err := NewSampler(options.Name, space.Dims(), options.Seed, options.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ConfigElem, 0, count)
+	for rejected, dropped := 0, 0; len(result) < count && dropped < maxDropped; {
+		sample, ok := space.SampleAt(sampler.Next())
+		if ok == false {
+			rejected++
+			if rejected >= maxConstraintAttempts {
+				return nil, errors.Errorf("no config sample satisfying all constraints was found in %d attempts", maxConstraintAttempts)
+			}
+			continue
+		}
+		rejected = 0
+		if drop(sample) {
+			dropped++
+			continue
+		}
+		dropped = 0
+		result = append(result, sample)
+	}
+	return result, nil
+}
+
+type randomSampler struct {
+	rng  *rand.Rand
+	dims int
+}
+
+func (s *randomSampler) Next() []float64 {
+	result := make([]float64, s.dims)
+	for i := range result {
+		result[i] = s.rng.Float64()
+	}
+	return result
+}
+
+// lhsSampler generates consecutive Latin hypercube designs. Within each design every coordinate has
+// exactly one point in each of the size equally wide strata.
+type lhsSampler struct {
+	rng    *rand.Rand
+	dims   int
+	size   int
+	design [][]float64
+}
+
+func (s *lhsSampler) Next() []float64 {
+	if len(s.design) == 0 {
+		s.design = make([][]float64, s.size)
+		for i := range s.design {
+			s.design[i] = make([]float64, s.dims)
+		}
+		for d := 0; d < s.dims; d++ {
+			for i, stratum := range s.rng.Perm(s.size) {
+				s.design[i][d] = (float64(stratum) + s.rng.Float64()) / float64(s.size)
+			}
+		}
+	}
+	result := s.design[0]
+	s.design = s.design[1:]
+	return result
+}
+
+// haltonSampler generates the Halton sequence where each coordinate is the radical inverse of the index
+// in a different prime base. The sequence is randomized by a random shift modulo 1 of each coordinate.
+type haltonSampler struct {
+	index int
+	bases []int
+	shift []float64
+}
+
+func newHaltonSampler(rng *rand.Rand, dims int) *haltonSampler {
+	s := &haltonSampler{bases: make([]int, 0, dims), shift: make([]float64, dims)}
+	for p := 2; len(s.bases) < dims; p++ {
+		prime := true
+		for _, b := range s.bases {
+			if p%b == 0 {
+				prime = false
+				break
+			}
+		}
+		if prime {
+			s.bases = append(s.bases, p)
+		}
+	}
+	for i := range s.shift {
+		s.shift[i] = rng.Float64()
+	}
+	return s
+}
+
+func (s *haltonSampler) Next() []float64 {
+	result := make([]float64, len(s.bases))
+	for i, base := range s.bases {
+		value, f := 0.0, 1.0
+		for n := s.index; n > 0; n /= base {
+			f /= float64(base)
+			value += f * float64(n%base)
+		}
+		_, result[i] = math.Modf(value + s.shift[i])
+	}
+	s.index++
+	return result
+}
+
+// sobolDirections holds the degree, the polynomial coefficients and the initial direction numbers of
+// the primitive polynomials of the Sobol sequence (Joe and Kuo, 2008). The first coordinate does not
+// need an entry.
+var sobolDirections = []struct {
+	s, a int
+	m    []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+	{3, 2, []uint32{1, 1, 1}},
+	{4, 1, []uint32{1, 1, 3, 3}},
+	{4, 4, []uint32{1, 3, 5, 13}},
+	{5, 2, []uint32{1, 1, 5, 5, 17}},
+	{5, 4, []uint32{1, 1, 5, 5, 5}},
+	{5, 7, []uint32{1, 1, 7, 11, 19}},
+	{5, 11, []uint32{1, 1, 5, 1, 1}},
+	{5, 13, []uint32{1, 1, 1, 3, 11}},
+	{5, 14, []uint32{1, 3, 5, 5, 31}},
+	{6, 1, []uint32{1, 3, 3, 9, 7, 49}},
+	{6, 13, []uint32{1, 1, 1, 15, 21, 21}},
+	{6, 16, []uint32{1, 3, 1, 13, 27, 49}},
+	{6, 19, []uint32{1, 1, 1, 15, 7, 5}},
+	{6, 22, []uint32{1, 3, 1, 15, 13, 25}},
+	{6, 25, []uint32{1, 1, 5, 5, 19, 61}},
+	{7, 1, []uint32{1, 3, 7, 11, 23, 15, 103}},
+	{7, 4, []uint32{1, 3, 7, 13, 13, 15, 69}},
+}
+
+// MaxSobolDims is the largest number of coordinates supported by the Sobol sampler.
+var MaxSobolDims = len(sobolDirections) + 1
+
+// sobolSampler generates the Sobol sequence in Gray code order. The sequence is randomized by a random
+// digital shift of each coordinate.
+type sobolSampler struct {
+	index      uint32
+	x          []uint32
+	directions [][32]uint32
+	shift      []uint32
+}
+
+func newSobolSampler(rng *rand.Rand, dims int) (*sobolSampler, error) {
+	if dims > MaxSobolDims {
+		return nil, errors.Errorf("the sobol sampler supports at most %d dimensions but the config space has %d", MaxSobolDims, dims)
+	}
+	s := &sobolSampler{x: make([]uint32, dims), directions: make([][32]uint32, dims), shift: make([]uint32, dims)}
+	for d := 0; d < dims; d++ {
+		v := &s.directions[d]
+		if d == 0 {
+			for i := range v {
+				v[i] = 1 << uint(31-i)
+			}
+		} else {
+			p := sobolDirections[d-1]
+			for i := 0; i < 32; i++ {
+				if i < p.s {
+					v[i] = p.m[i] << uint(31-i)
+					continue
+				}
+				v[i] = v[i-p.s] ^ (v[i-p.s] >> uint(p.s))
+				for k := 1; k < p.s; k++ {
+					v[i] ^= uint32((p.a>>uint(p.s-1-k))&1) * v[i-k]
+				}
+			}
+		}
+		s.shift[d] = rng.Uint32()
+	}
+	return s, nil
+}
+
+func (s *sobolSampler) Next() []float64 {
+	result := make([]float64, len(s.x))
+	for d := range s.x {
+		result[d] = float64(s.x[d]^s.shift[d]) / (1 << 32)
+	}
+
+	// The next point differs from the current one by the direction of the lowest zero bit of the index.
+	c := 0
+	for n := s.index; n&1 == 1; n >>= 1 {
+		c++
+	}
+	for d := range s.x {
+		s.x[d] ^= s.directions[d][c]
+	}
+	s.index++
+	return result
+}
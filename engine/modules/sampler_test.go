@@ -0,0 +1,127 @@
+package modules
+
+import (
+	"testing"
+
+	"github.com/ds3lab/easeml/engine/database/model/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplerStratification(t *testing.T) {
+	assert := assert.New(t)
+
+	// The first 16 points of each sampler have exactly one point in each sixteenth of every coordinate.
+	// The Halton sequence has this property only in the coordinate with base 2.
+	for _, name := range []string{types.SamplerSobol, types.SamplerHalton, types.SamplerLHS} {
+		sampler, err := NewSampler(name, MaxSobolDims, 42, 16)
+		assert.Nil(err)
+		strata := make([]map[int]bool, MaxSobolDims)
+		for i := range strata {
+			strata[i] = map[int]bool{}
+		}
+		for i := 0; i < 16; i++ {
+			point := sampler.Next()
+			assert.Len(point, MaxSobolDims)
+			for d, x := range point {
+				assert.True(x >= 0 && x < 1)
+				strata[d][int(x*16)] = true
+			}
+		}
+		for d := range strata {
+			if name == types.SamplerHalton && d > 0 {
+				break
+			}
+			assert.Len(strata[d], 16, "sampler %s, coordinate %d", name, d)
+		}
+	}
+
+	_, err := NewSampler(types.SamplerSobol, MaxSobolDims+1, 0, 0)
+	assert.NotNil(err)
+	_, err = NewSampler("grid", 1, 0, 0)
+	assert.NotNil(err)
+}
+
+func TestSampleConfigs(t *testing.T) {
+	assert := assert.New(t)
+
+	space, err := LoadConfig(map[string]interface{}{
+		"optimizer": map[string]interface{}{".choice": []interface{}{"sgd", "adam"}},
+		"momentum": map[string]interface{}{
+			".when":  map[string]interface{}{"optimizer": "sgd"},
+			".value": map[string]interface{}{".float": []interface{}{0.0, 1.0}},
+		},
+		"depth":        map[string]interface{}{".int": []interface{}{1.0, 8.0}},
+		"width":        map[string]interface{}{".int": []interface{}{1.0, 8.0}},
+		"rate":         map[string]interface{}{".normal": []interface{}{0.0, 1.0}},
+		".constraints": []interface{}{[]interface{}{"depth", "<=", "width"}},
+	})
+	assert.Nil(err)
+	assert.Equal(5, space.Dims())
+
+	for _, name := range types.Samplers {
+		options := SamplerOptions{Name: name, Seed: 7, Size: 10}
+		configs, err := SampleConfigs(space, options, 0, 20)
+		assert.Nil(err)
+		assert.Len(configs, 20)
+		for _, c := range configs {
+			params := c.(*ConfigMap).Params
+			assert.True(params["depth"].Dump().(int) <= params["width"].Dump().(int))
+			_, hasMomentum := params["momentum"]
+			assert.Equal(params["optimizer"].Dump() == "sgd", hasMomentum)
+		}
+
+		// The same options produce the same sequence which can be continued by skipping.
+		again, err := SampleConfigs(space, options, 15, 5)
+		assert.Nil(err)
+		for i := range again {
+			assert.Equal(configs[15+i].Dump(), again[i].Dump())
+		}
+
+		// A different seed produces a different sequence.
+		options.Seed = 8
+		other, err := SampleConfigs(space, options, 0, 20)
+		assert.Nil(err)
+		assert.NotEqual(configs[0].Dump(), other[0].Dump())
+	}
+
+	// Unsatisfiable constraints are reported as an error.
+	space, err = LoadConfig(map[string]interface{}{
+		"depth": map[string]interface{}{".int": []interface{}{1.0, 8.0}},
+	})
+	assert.Nil(err)
+	space.(*ConfigMap).Constraints = []ConfigConstraint{{Left: "depth", Op: ">", Right: 10.0}}
+	_, err = SampleConfigs(space, SamplerOptions{Name: types.SamplerRandom}, 0, 1)
+	assert.NotNil(err)
+}
+
+func TestSampleNewConfigs(t *testing.T) {
+	assert := assert.New(t)
+
+	space, err := LoadConfig(map[string]interface{}{
+		"depth": map[string]interface{}{".int": []interface{}{1.0, 4.0}},
+	})
+	assert.Nil(err)
+
+	// Existing configs are skipped until the space is exhausted.
+	existing := map[string]bool{}
+	exists := func(c ConfigElem) bool {
+		canonical, err := CanonicalConfig(c.Dump())
+		assert.Nil(err)
+		return existing[canonical]
+	}
+	for _, name := range types.Samplers {
+		existing = map[string]bool{}
+		for i := 0; i < 4; i++ {
+			configs, err := SampleNewConfigs(space, SamplerOptions{Name: name, Seed: 1, Size: 4}, 1, exists)
+			assert.Nil(err)
+			assert.Len(configs, 1)
+			canonical, err := CanonicalConfig(configs[0].Dump())
+			assert.Nil(err)
+			existing[canonical] = true
+		}
+		configs, err := SampleNewConfigs(space, SamplerOptions{Name: name, Seed: 1, Size: 4}, 1, exists)
+		assert.Nil(err)
+		assert.Len(configs, 0)
+	}
+}
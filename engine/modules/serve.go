@@ -0,0 +1,197 @@
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A model server runs the predict command of a model for every request put in its serving directory. It
+// is a single long-running container, so requests are handled one at a time and no container is started
+// per request. The serving directory contains these entries:
+//
+//	requests/<id>/        the data, predictions and metadata directories of a request
+//	requests/<id>.ready   created once a request has been written, removed once it has been handled
+//	requests/<id>/status  the exit status of the predict command, created once the request is handled
+//	requests/<id>/log     the output of the predict command
+//	requests/<id>/claimed created by whichever of the server and the caller is done with the request first
+//	started               created when the server is ready to take requests
+//	alive                 rewritten periodically by the owner of the server
+//	stop                  tells the server to exit
+const (
+	servingRequestsDir = "requests"
+	servingReadyExt    = ".ready"
+	servingStarted     = "started"
+	servingAlive       = "alive"
+	servingStop        = "stop"
+	servingStatus      = "status"
+	servingLog         = "log"
+	servingClaimed     = "claimed"
+)
+
+// MaxPendingRequests is the number of requests that can wait for a model server before new ones are refused.
+const MaxPendingRequests = 16
+
+// modelServerIdleLimit is the number of polls after which a server exits if its alive file has not changed.
+// With a poll every 0.2 seconds the server outlives its owner by about a minute.
+const modelServerIdleLimit = "300"
+
+// modelServerScript serves requests with the entrypoint of the model given after the serving directory and
+// the model memory. It only needs a POSIX shell in the model image.
+const modelServerScript = `
+serving="$1"; memory="$2"; shift 2
+last=""; idle=0
+touch "$serving/started"
+while [ ! -e "$serving/stop" ]; do
+	alive=$(cat "$serving/alive" 2>/dev/null)
+	if [ "$alive" = "$last" ]; then idle=$((idle + 1)); else idle=0; last="$alive"; fi
+	if [ "$idle" -gt ` + modelServerIdleLimit + ` ]; then echo "model server owner is gone"; exit 1; fi
+	handled=0
+	for ready in "$serving"/requests/*.ready; do
+		[ -e "$ready" ] || continue
+		request="${ready%.ready}"
+		if [ ! -e "$request/claimed" ]; then
+			"$@" predict --data "$request/data" --memory "$memory" --output "$request/predictions" --metadata "$request/metadata" > "$request/log" 2>&1
+			echo $? > "$request/status.tmp"
+			mv "$request/status.tmp" "$request/status"
+		fi
+		mkdir "$request/claimed" 2>/dev/null || rm -rf "$request"
+		rm -f "$ready"
+		handled=1
+	done
+	[ "$handled" = 1 ] || sleep 0.2 2>/dev/null || sleep 1
+done
+`
+
+// Errors returned when a request cannot be served.
+var (
+	ErrServerBusy    = errors.New("the model server has too many pending requests")
+	ErrServerTimeout = errors.New("the model server did not answer in time")
+)
+
+// PrepareServingDir empties the serving directory of a model server so that it can be started.
+func PrepareServingDir(servingPath string) error {
+	if err := os.RemoveAll(servingPath); err != nil {
+		return errors.Wrap(err, "serving directory removal failed")
+	}
+	return errors.Wrap(os.MkdirAll(filepath.Join(servingPath, servingRequestsDir), 0755), "serving directory creation failed")
+}
+
+// RunModelServer runs a model server on the given serving directory with the model memory found in
+// memoryPath. It blocks until the server is stopped with StopModelServer or its owner stops calling
+// KeepModelServerAlive, and returns the output of the server.
+func RunModelServer(runtime Runtime, modelImageName, servingPath, memoryPath string, gpuDevices []string) ([]byte, error) {
+	info, err := runtime.InspectImage(modelImageName)
+	if err != nil {
+		return nil, errors.Wrap(err, "model image inspect error")
+	}
+	entrypoint := []string{"/bin/sh", "-c", modelServerScript, "easeml-serve"}
+	command := append([]string{MntPrefix + servingPath, MntPrefix + memoryPath}, info.Entrypoint...)
+	outReader, err := runtime.Run(modelImageName, entrypoint, command, gpuDevices)
+	if err != nil {
+		return nil, errors.Wrap(err, "model server start error")
+	}
+	defer outReader.Close()
+
+	output, err := ioutil.ReadAll(outReader)
+	if err != nil {
+		return output, errors.Wrap(err, "model server output read error")
+	}
+	return output, nil
+}
+
+// ModelServerStarted checks if the model server is ready to take requests.
+func ModelServerStarted(servingPath string) bool {
+	_, err := os.Stat(filepath.Join(servingPath, servingStarted))
+	return err == nil
+}
+
+// KeepModelServerAlive tells the model server that its owner is still there. It must be called with a
+// different beat at least every few seconds.
+func KeepModelServerAlive(servingPath string, beat int) error {
+	return ioutil.WriteFile(filepath.Join(servingPath, servingAlive), []byte(strconv.Itoa(beat)), 0644)
+}
+
+// StopModelServer tells the model server to exit once it has handled the current request.
+func StopModelServer(servingPath string) error {
+	return ioutil.WriteFile(filepath.Join(servingPath, servingStop), nil, 0644)
+}
+
+// ServingRequestPath returns the directory of a request in the serving directory. The request is written
+// to the data directory inside it before it is submitted with ServeRequest.
+func ServingRequestPath(servingPath, requestID string) string {
+	return filepath.Join(servingPath, servingRequestsDir, requestID)
+}
+
+// submissionLocks make sure that the requests submitted to a model server by this process are counted
+// before the next one is submitted.
+var (
+	submissionLocksMutex sync.Mutex
+	submissionLocks      = map[string]*sync.Mutex{}
+)
+
+// submitRequest marks a request as ready to be handled by the model server unless too many requests
+// are already waiting.
+func submitRequest(servingPath, requestID string) error {
+	submissionLocksMutex.Lock()
+	lock, ok := submissionLocks[servingPath]
+	if ok == false {
+		lock = &sync.Mutex{}
+		submissionLocks[servingPath] = lock
+	}
+	submissionLocksMutex.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	pending, err := filepath.Glob(filepath.Join(servingPath, servingRequestsDir, "*"+servingReadyExt))
+	if err != nil {
+		return errors.Wrap(err, "serving directory read error")
+	}
+	if len(pending) >= MaxPendingRequests {
+		return ErrServerBusy
+	}
+	readyPath := ServingRequestPath(servingPath, requestID) + servingReadyExt
+	return errors.Wrap(ioutil.WriteFile(readyPath, nil, 0644), "request submission error")
+}
+
+// ServeRequest submits a request to the model server and waits until it has been handled. It returns the
+// output of the predict command of the model. If it returns ErrServerTimeout, the request directory must
+// be left in place because the server can still be using it. The server removes it once it is done.
+func ServeRequest(servingPath, requestID string, timeout time.Duration) ([]byte, error) {
+	if err := submitRequest(servingPath, requestID); err != nil {
+		return nil, err
+	}
+
+	requestPath := ServingRequestPath(servingPath, requestID)
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := ioutil.ReadFile(filepath.Join(requestPath, servingStatus))
+		if err == nil {
+			output, _ := ioutil.ReadFile(filepath.Join(requestPath, servingLog))
+			if code := strings.TrimSpace(string(status)); code != "0" {
+				return output, errors.Errorf("the predict command of the model failed with exit status %s", code)
+			}
+			return output, nil
+		} else if os.IsNotExist(err) == false {
+			return nil, errors.Wrap(err, "request status read error")
+		}
+		if time.Now().After(deadline) {
+			// If the server has claimed the request first, it has already written the status.
+			err := os.Mkdir(filepath.Join(requestPath, servingClaimed), 0755)
+			if err == nil {
+				return nil, ErrServerTimeout
+			} else if os.IsExist(err) == false {
+				return nil, errors.Wrap(err, "request claim error")
+			}
+			continue
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,138 @@
+package modules
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeServingModule writes a model whose predict command writes a file to its output directory. It fails
+// if the data directory contains a file named fail and takes a second if it contains a file named slow.
+func writeServingModule(t *testing.T, root string) string {
+	path := filepath.Join(root, "serving-model")
+	files := map[string]string{
+		"Dockerfile": "FROM python:3.6\nCOPY . .\nENTRYPOINT [\"/bin/sh\", \"run.sh\"]\n",
+		"run.sh": "[ \"$1\" = predict ] || exit 2\nshift\n" +
+			"while [ $# -gt 0 ]; do case \"$1\" in --data) data=\"$2\";; --output) out=\"$2\";; esac; shift 2; done\n" +
+			"[ -e \"$data/fail\" ] && exit 3\n[ -e \"$data/slow\" ] && sleep 1\nmkdir -p \"$out/output\" && echo done > \"$out/output/result.txt\"\necho predicted\n",
+	}
+	assert.Nil(t, os.MkdirAll(path, 0700))
+	for name, content := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(path, name), []byte(content), 0600))
+	}
+	return path
+}
+
+func TestModelServer(t *testing.T) {
+	assert := assert.New(t)
+
+	root, err := ioutil.TempDir("", "easeml_serve_")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	runtime, err := NewRuntime(RuntimeProcess, filepath.Join(root, "runtime"), "")
+	assert.Nil(err)
+	modulePath := writeServingModule(t, root)
+	servingPath := filepath.Join(root, "serving")
+	memoryPath := filepath.Join(root, "memory")
+	assert.Nil(os.MkdirAll(memoryPath, 0700))
+	assert.Nil(PrepareServingDir(servingPath))
+
+	// Without a server requests time out.
+	assert.Nil(os.MkdirAll(filepath.Join(ServingRequestPath(servingPath, "r0"), "data"), 0700))
+	_, err = ServeRequest(servingPath, "r0", 100*time.Millisecond)
+	assert.Equal(ErrServerTimeout, err)
+
+	// The owner of the server keeps it alive until it is stopped.
+	done := make(chan error)
+	go func() {
+		_, err := RunModelServer(runtime, modulePath+"/", servingPath, memoryPath, nil)
+		done <- err
+	}()
+	stopBeat := make(chan bool)
+	go func() {
+		for beat := 0; ; beat++ {
+			KeepModelServerAlive(servingPath, beat)
+			select {
+			case <-stopBeat:
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}()
+	defer close(stopBeat)
+	for i := 0; i < 100 && ModelServerStarted(servingPath) == false; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(ModelServerStarted(servingPath))
+
+	// Requests whose callers gave up are removed by the server.
+	waitRemoved := func(id string) {
+		for i := 0; i < 100; i++ {
+			if _, err := os.Stat(ServingRequestPath(servingPath, id)); os.IsNotExist(err) {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Errorf("Request %s was not removed.", id)
+	}
+	waitRemoved("r0")
+
+	for _, id := range []string{"r1", "r2"} {
+		requestPath := ServingRequestPath(servingPath, id)
+		assert.Nil(os.MkdirAll(filepath.Join(requestPath, "data"), 0700))
+		output, err := ServeRequest(servingPath, id, 10*time.Second)
+		assert.Nil(err)
+		assert.Equal("predicted", strings.TrimSpace(string(output)))
+		_, err = os.Stat(filepath.Join(requestPath, "predictions", "output", "result.txt"))
+		assert.Nil(err)
+	}
+
+	// Failures of the model are reported per request.
+	requestPath := ServingRequestPath(servingPath, "r3")
+	assert.Nil(os.MkdirAll(filepath.Join(requestPath, "data"), 0700))
+	assert.Nil(ioutil.WriteFile(filepath.Join(requestPath, "data", "fail"), nil, 0600))
+	_, err = ServeRequest(servingPath, "r3", 10*time.Second)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "exit status 3")
+	}
+
+	// A request which takes too long is left to the server which removes it once it is done.
+	requestPath = ServingRequestPath(servingPath, "r4")
+	assert.Nil(os.MkdirAll(filepath.Join(requestPath, "data"), 0700))
+	assert.Nil(ioutil.WriteFile(filepath.Join(requestPath, "data", "slow"), nil, 0600))
+	_, err = ServeRequest(servingPath, "r4", 100*time.Millisecond)
+	assert.Equal(ErrServerTimeout, err)
+	_, err = os.Stat(filepath.Join(requestPath, "data"))
+	assert.Nil(err)
+	waitRemoved("r4")
+
+	assert.Nil(StopModelServer(servingPath))
+	select {
+	case err := <-done:
+		assert.Nil(err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("The model server did not stop.")
+	}
+
+	// Requests are refused if too many are waiting, even if they are submitted at the same time.
+	results := make(chan error)
+	for i := 0; i < 2*MaxPendingRequests; i++ {
+		id := fmt.Sprintf("p%d", i)
+		assert.Nil(os.MkdirAll(filepath.Join(ServingRequestPath(servingPath, id), "data"), 0700))
+		go func() {
+			_, err := ServeRequest(servingPath, id, 500*time.Millisecond)
+			results <- err
+		}()
+	}
+	counts := map[error]int{}
+	for i := 0; i < 2*MaxPendingRequests; i++ {
+		counts[<-results]++
+	}
+	assert.Equal(map[error]int{ErrServerBusy: MaxPendingRequests, ErrServerTimeout: MaxPendingRequests}, counts)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ds3lab/easeml/engine/database/model"
 	"github.com/ds3lab/easeml/engine/database/model/types"
 	"github.com/ds3lab/easeml/engine/logger"
+	"github.com/ds3lab/easeml/engine/modules"
 	"github.com/ds3lab/easeml/engine/process"
 	"github.com/ds3lab/easeml/engine/storage"
 	"github.com/ds3lab/easeml/engine/workers"
@@ -34,12 +35,25 @@ func Start(context process.Context) {
 	}
 	defer modelContext.Session.Close()
 
+	runtime, err := context.GetRuntime()
+	if err != nil {
+		log.WriteFatal(fmt.Sprintf("fatal: %+v", err))
+	}
+
 	// Initialize the database.
 	err = modelContext.Initialize(context.DatabaseName)
 	if err != nil {
 		log.WriteFatal(fmt.Sprintf("fatal: %+v", err))
 	}
 
+	// Record the objectives which are computed by the engine itself.
+	for _, module := range modules.BuiltinObjectiveModules() {
+		_, err = modelContext.EnsureBuiltinModule(module)
+		if err != nil {
+			log.WriteFatal(fmt.Sprintf("fatal: %+v", err))
+		}
+	}
+
 	// Register the new process.
 	var process types.Process
 	process, err = modelContext.StartProcess(types.ProcController)
@@ -82,6 +96,12 @@ func Start(context process.Context) {
 		ProcessID:      process.ID,
 		Period:         context.ListenerPeriod,
 		Logger:         log,
+		GpuDevices:     context.GpuDevices,
+		Runtime:        runtime,
+		S3Endpoint:     context.S3Endpoint,
+		S3Region:       context.S3Region,
+
+		RequireConformance: context.RequireConformance,
 	}
 
 	// Process keepalive goroutine.
@@ -126,6 +146,13 @@ func Start(context process.Context) {
 		workersContextCopy.ModuleDownloadListener()
 	}()
 
+	// Module build worker.
+	go func() {
+		workersContextCopy := workersContext.Clone()
+		defer workersContextCopy.ModelContext.Session.Close()
+		workersContextCopy.ModuleBuildListener()
+	}()
+
 	// Module validate worker.
 	go func() {
 		workersContextCopy := workersContext.Clone()
@@ -147,6 +174,13 @@ func Start(context process.Context) {
 		workersContextCopy.TaskStatusMaintainerListener()
 	}()
 
+	// Deployment start worker.
+	go func() {
+		workersContextCopy := workersContext.Clone()
+		defer workersContextCopy.ModelContext.Session.Close()
+		workersContextCopy.DeploymentStartListener()
+	}()
+
 	// Start the HTTP server. We need to reconnect as an anonimous user.
 	// TODO: Start actual server and handle graceful shutdown.
 	anonContext, err := model.Connect(context.DatabaseAddress, context.DatabaseName, true)
@@ -156,7 +190,7 @@ func Start(context process.Context) {
 	defer anonContext.Session.Close()
 
 	// Initialize the API context and API router.
-	apiContext := api.Context{ModelContext: anonContext, StorageContext: storageContext, Logger: log}
+	apiContext := api.Context{ModelContext: anonContext, StorageContext: storageContext, Logger: log, Runtime: runtime}
 	apiRouter := router.New(apiContext)
 	http.Handle("/api/v1/", apiRouter)
 
@@ -2,6 +2,9 @@ package process
 
 import (
 	"time"
+
+	"github.com/ds3lab/easeml/engine/modules"
+	"github.com/ds3lab/easeml/engine/storage"
 )
 
 // Context contains all information needed to run processes.
@@ -16,6 +19,13 @@ type Context struct {
 	RootAPIKey      chan string
 	DebugLog        bool
 	GpuDevices      []string
+	Runtime         string
+	RuntimeHost     string
+	S3Endpoint      string
+	S3Region        string
+
+	// RequireConformance makes the controller reject models which fail the conformance suite.
+	RequireConformance bool
 }
 
 const (
@@ -34,3 +44,13 @@ const (
 	// ProcessTypeMongo is the process controlling the mongo daemon if it is run with the easeml engine.
 	ProcessTypeMongo = "mongo"
 )
+
+// GetRuntime creates the module runtime of the process.
+func (context Context) GetRuntime() (modules.Runtime, error) {
+	storageContext := storage.Context{WorkingDir: context.WorkingDir}
+	root, err := storageContext.GetRuntimePath(context.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	return modules.NewRuntime(context.Runtime, root, context.RuntimeHost)
+}
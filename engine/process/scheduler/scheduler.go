@@ -8,6 +8,7 @@ import (
 	"github.com/ds3lab/easeml/engine/database/model"
 	"github.com/ds3lab/easeml/engine/database/model/types"
 	"github.com/ds3lab/easeml/engine/logger"
+	"github.com/ds3lab/easeml/engine/modules"
 	"github.com/ds3lab/easeml/engine/process"
 	"github.com/ds3lab/easeml/engine/storage"
 	"github.com/ds3lab/easeml/engine/workers"
@@ -30,12 +31,23 @@ func Start(context process.Context) {
 	}
 	defer modelContext.Session.Close()
 
+	runtime, err := context.GetRuntime()
+	if err != nil {
+		log.WriteFatal(fmt.Sprintf("fatal: %+v", err))
+	}
+
 	// Initialize the database.
 	err = modelContext.Initialize(context.DatabaseName)
 	if err != nil {
 		log.WriteFatal(fmt.Sprintf("fatal: %+v", err))
 	}
 
+	// Tasks are told apart by their canonical configs so older tasks need to be brought to that form.
+	_, err = modelContext.CanonicalizeTaskConfigs(modules.CanonicalConfig)
+	if err != nil {
+		log.WriteFatal(fmt.Sprintf("fatal: %+v", err))
+	}
+
 	// Register the new process.
 	var process types.Process
 	process, err = modelContext.StartProcess(types.ProcScheduler)
@@ -77,6 +89,7 @@ func Start(context process.Context) {
 		ProcessID:      process.ID,
 		Period:         context.ListenerPeriod,
 		Logger:         log,
+		Runtime:        runtime,
 	}
 
 	// Process keepalive goroutine.
@@ -30,6 +30,11 @@ func Start(context process.Context) {
 	}
 	defer modelContext.Session.Close()
 
+	runtime, err := context.GetRuntime()
+	if err != nil {
+		log.WriteFatal(fmt.Sprintf("fatal: %+v", err))
+	}
+
 	// Initialize the database.
 	err = modelContext.Initialize(context.DatabaseName)
 	if err != nil {
@@ -77,6 +82,7 @@ func Start(context process.Context) {
 		ProcessID:      process.ID,
 		Period:         context.ListenerPeriod,
 		Logger:         log,
+		Runtime:        runtime,
 		GpuDevices:     context.GpuDevices,
 	}
 
@@ -1,12 +1,12 @@
 package storage
 
 import (
-	"archive/tar"
-	"archive/zip"
 	"bufio"
 	"bytes"
-	"compress/gzip"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"math/rand"
 	"os"
 	"path"
 	"path/filepath"
@@ -67,15 +67,7 @@ func InferDatasetProperties(sourcePath string) (id, name, description string, er
 			name, description = ScanReadme(file)
 		}
 	} else {
-		size := fileInfo.Size()
-		var datasetFile *os.File
-		datasetFile, err = os.Open(sourcePath)
-		if err != nil {
-			err = errors.Wrap(err, "dataset access error")
-			return
-		}
-
-		file := findFileInArchive(datasetFile, "README*", size)
+		file := findFileInArchive(sourcePath, "README*")
 		if file != nil {
 			name, description = ScanReadme(file)
 		}
@@ -84,37 +76,17 @@ func InferDatasetProperties(sourcePath string) (id, name, description string, er
 	return
 }
 
-func findFileInArchive(file *os.File, namePattern string, size int64) io.Reader {
-
-	var archiveReader io.Reader = file
+func findFileInArchive(archivePath string, namePattern string) io.Reader {
 
-	// Check if it is gzip.
-	if gzipReader, err := gzip.NewReader(file); err == nil {
-		archiveReader = gzipReader
-		defer gzipReader.Close()
-	} else {
-		// We seek to the beginning of the file. If we fail, then we musth abort.
-		_, err := file.Seek(0, 0)
-		if err != nil {
-			return nil
-		}
+	opener, err := ds.OpenArchiveOpener(archivePath)
+	if err != nil {
+		return nil
 	}
+	defer opener.Close()
 
-	// Check if it is tar. If yes, try to find the readme.
-	tarReader := tar.NewReader(archiveReader)
-	for {
-		header, err := tarReader.Next()
-
-		if err != nil {
-			// If get an EOF then we have reached the end of the tar.
-			if err == io.EOF {
-				return nil
-			}
-			// Otherwise we should try the zip.
-			break
-		}
+	for _, name := range opener.Files() {
 
-		match, err := path.Match(namePattern, header.Name)
+		match, err := path.Match(namePattern, name)
 		if err != nil {
 			// This can only happen if the pattern is bad.
 			panic(err)
@@ -122,28 +94,7 @@ func findFileInArchive(file *os.File, namePattern string, size int64) io.Reader
 
 		if match {
 			// We read the file.
-			buf := bytes.Buffer{}
-			if _, err := io.Copy(&buf, tarReader); err != nil {
-				return nil
-			}
-			return bytes.NewReader(buf.Bytes())
-		}
-	}
-
-	// Check if it is zip.
-	zipReader, err := zip.NewReader(file, size)
-	if err != nil {
-		return nil
-	}
-	for i := range zipReader.File {
-
-		match, err := path.Match(namePattern, zipReader.File[i].Name)
-		if err != nil {
-			// This can only happen if the pattern is bad.
-			panic(err)
-		}
-		if match {
-			reader, err := zipReader.File[i].Open()
+			reader, err := opener.GetFile("", name, true, false)
 			if err != nil {
 				return nil
 			}
@@ -193,7 +144,8 @@ func ScanReadme(r io.Reader) (name, description string) {
 	return
 }
 
-// InferDatasetSchema tries to infer the schema of a dataset.
+// InferDatasetSchema tries to infer the schema of a dataset. Datasets which only contain an input directory
+// in their root hold samples to make predictions on. They have no output schema so schemaOut is nil.
 func InferDatasetSchema(sourcePath string) (schemaIn, schemaOut *sch.Schema, err error) {
 
 	// First check if the dataset exists.
@@ -205,11 +157,15 @@ func InferDatasetSchema(sourcePath string) (schemaIn, schemaOut *sch.Schema, err
 	}
 
 	// If the dataset is a directory, then we read it with the default opener.
-	// Otherwise we will assume it's a tar or tar.gz archive.
+	// Otherwise we will assume it's a zip, tar, tar.gz or tar.zst archive.
 	var opener ds.Opener
 	var basePath string
 	if fileInfo.IsDir() {
 
+		if isInputOnlyLayout(ds.DefaultOpener{}, sourcePath) {
+			return inferInputSchema(ds.DefaultOpener{}, sourcePath)
+		}
+
 		// Each dataset must have a train and val directory. Each one of them must contain
 		// an input and output directory.
 		var exists bool
@@ -268,78 +224,26 @@ func InferDatasetSchema(sourcePath string) (schemaIn, schemaOut *sch.Schema, err
 
 	} else {
 
-		var datasetFile *os.File
-		datasetFile, err = os.Open(sourcePath)
+		// Archives are read in place without unpacking them.
+		var archiveOpener ds.ArchiveOpener
+		archiveOpener, err = ds.OpenArchiveOpener(sourcePath)
 		if err != nil {
-			err = errors.Wrap(err, "dataset access error")
+			err = errors.Wrap(err, "error while opening the dataset archive")
 			return nil, nil, err
 		}
+		defer archiveOpener.Close()
 
-		// Check if it is gzip.
-		var archiveReader io.Reader = datasetFile
-		if gzipReader, err := gzip.NewReader(datasetFile); err == nil {
-			archiveReader = gzipReader
-			defer gzipReader.Close()
-		} else {
-			// We seek to the beginning of the file. If we fail, then we musth abort.
-			_, err := datasetFile.Seek(0, 0)
-			if err != nil {
-				err = errors.Wrap(err, "dataset access error")
-				return nil, nil, err
-			}
+		if isInputOnlyLayout(archiveOpener, "") {
+			return inferInputSchema(archiveOpener, "")
 		}
 
-		// Try to open it as tar.
-		tarReader := tar.NewReader(archiveReader)
-		var tarOpener *ds.TarOpener
-		tarOpener, err = ds.LoadTarOpener(tarReader)
+		err = checkDatasetLayout(archiveOpener, "")
 		if err != nil {
-			err = errors.Wrap(err, "error while opening the tar archive")
-			return nil, nil, err
-		}
-
-		if trainChild, ok := tarOpener.Root["train"]; ok == true {
-			if trainDir, ok := trainChild.(ds.TarDir); ok == true {
-				if trainInChild, ok := trainDir["input"]; ok == true {
-					if _, ok := trainInChild.(ds.TarDir); ok == false {
-						err = errors.New("datset \"train\" directory must contain an \"input\" directory")
-						return nil, nil, err
-					}
-				} else {
-					err = errors.New("datset \"train\" directory must contain an \"input\" directory")
-					return nil, nil, err
-				}
-			} else {
-				err = errors.New("datset root must contain a \"train\" directory")
-				return nil, nil, err
-			}
-		} else {
-			err = errors.New("datset root must contain a \"train\" directory")
-			return nil, nil, err
-		}
-
-		if valChild, ok := tarOpener.Root["val"]; ok == true {
-			if valDir, ok := valChild.(ds.TarDir); ok == true {
-				if valInChild, ok := valDir["input"]; ok == true {
-					if _, ok := valInChild.(ds.TarDir); ok == false {
-						err = errors.New("datset \"val\" directory must contain an \"input\" directory")
-						return nil, nil, err
-					}
-				} else {
-					err = errors.New("datset \"val\" directory must contain an \"input\" directory")
-					return nil, nil, err
-				}
-			} else {
-				err = errors.New("datset root must contain a \"val\" directory")
-				return nil, nil, err
-			}
-		} else {
-			err = errors.New("datset root must contain a \"val\" directory")
 			return nil, nil, err
 		}
 
 		// If we are here, then we can set the opener and procede with reading the schemas.
-		opener = tarOpener
+		opener = archiveOpener
 		basePath = ""
 
 	}
@@ -380,7 +284,7 @@ func InferDatasetSchema(sourcePath string) (schemaIn, schemaOut *sch.Schema, err
 		err = errors.Wrap(err, "dataset input schema inference error")
 		return nil, nil, err
 	}
-	datasetValOut, err = ds.Load(filepath.Join(basePath, "train", "output"), true, opener)
+	datasetValOut, err = ds.Load(filepath.Join(basePath, "val", "output"), true, opener)
 	if err != nil {
 		err = errors.Wrap(err, "dataset load error")
 		return nil, nil, err
@@ -405,6 +309,198 @@ func InferDatasetSchema(sourcePath string) (schemaIn, schemaOut *sch.Schema, err
 	return schemaIn, schemaOut, nil
 }
 
+// isInputOnlyLayout checks if the dataset accessed through the opener has an input directory in its root
+// instead of the train and val directories.
+func isInputOnlyLayout(opener ds.Opener, basePath string) bool {
+	if _, err := opener.GetDir(basePath, "train", true); err == nil {
+		return false
+	}
+	_, err := opener.GetDir(basePath, "input", true)
+	return err == nil
+}
+
+// inferInputSchema infers the schema of the input directory in the root of the dataset.
+func inferInputSchema(opener ds.Opener, basePath string) (schemaIn, schemaOut *sch.Schema, err error) {
+	var datasetIn *ds.Dataset
+	datasetIn, err = ds.Load(filepath.Join(basePath, "input"), true, opener)
+	if err != nil {
+		err = errors.Wrap(err, "dataset load error")
+		return nil, nil, err
+	}
+	schemaIn, err = datasetIn.InferSchema()
+	if err != nil {
+		err = errors.Wrap(err, "dataset input schema inference error")
+		return nil, nil, err
+	}
+	return schemaIn, nil, nil
+}
+
+// checkDatasetLayout checks that the dataset accessed through the opener contains the train and val
+// directories, each with an input and output directory.
+func checkDatasetLayout(opener ds.Opener, basePath string) error {
+	for _, split := range []string{"train", "val"} {
+		if _, err := opener.GetDir(basePath, split, true); err != nil {
+			return errors.Errorf("datset root must contain a \"%s\" directory", split)
+		}
+		for _, child := range []string{"input", "output"} {
+			if _, err := opener.GetDir(basePath, path.Join(split, child), true); err != nil {
+				return errors.Errorf("datset \"%s\" directory must contain an \"%s\" directory", split, child)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateDatasetArchive checks that the archive at the given path can be unpacked safely. All entries
+// are read to verify the integrity of the archive and entries with paths outside of the archive root
+// are rejected.
+func ValidateDatasetArchive(archivePath string) error {
+	opener, err := ds.OpenArchiveOpener(archivePath)
+	if err != nil {
+		return errors.Wrap(err, "invalid dataset archive")
+	}
+	defer opener.Close()
+
+	err = opener.Walk(func(name string, r io.Reader) error {
+		_, err := io.Copy(ioutil.Discard, r)
+		return err
+	})
+	return errors.Wrap(err, "invalid dataset archive")
+}
+
+// UnpackDatasetArchive validates the archive at the given path and extracts it to the destination
+// directory. Supported formats are zip, tar, tar.gz and tar.zst.
+func UnpackDatasetArchive(archivePath, destination string) error {
+	err := ValidateDatasetArchive(archivePath)
+	if err != nil {
+		return err
+	}
+
+	opener, err := ds.OpenArchiveOpener(archivePath)
+	if err != nil {
+		return errors.Wrap(err, "invalid dataset archive")
+	}
+	defer opener.Close()
+
+	return opener.Walk(func(name string, r io.Reader) error {
+		targetPath := filepath.Join(destination, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(targetPath), DefaultFilePerm)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		f, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, DefaultFilePerm)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		_, err = io.Copy(f, r)
+		return errors.WithStack(err)
+	})
+}
+
+// GenerateDatasetOptions controls the size and contents of a generated dataset.
+type GenerateDatasetOptions struct {
+	// TrainSamples and ValSamples are the number of samples in the train and val splits.
+	TrainSamples int
+	ValSamples   int
+
+	// NodeInstances is the number of instances of each non-singleton node in a sample. It also
+	// bounds the number of outgoing links of each instance.
+	NodeInstances int
+
+	// Dims holds values of dimension variables. Variables which are missing take DefaultDim.
+	Dims       map[string]int
+	DefaultDim int
+
+	// Seed initializes the random generator so that the same options produce the same dataset.
+	Seed int64
+}
+
+// GenerateDataset generates a random dataset with train and val splits which conforms to the given
+// input and output schemas. Variable dimensions are bound according to the options. The dataset is
+// written to the destination directory, or to a tar archive if the destination ends with ".tar".
+// The destination must not exist.
+func GenerateDataset(schemaIn, schemaOut *sch.Schema, destination string, options GenerateDatasetOptions) error {
+
+	if options.TrainSamples < 1 || options.ValSamples < 1 || options.NodeInstances < 1 {
+		return errors.New("the number of samples and node instances must be positive")
+	}
+	if _, err := os.Stat(destination); err == nil {
+		return errors.Errorf("destination \"%s\" already exists", destination)
+	} else if os.IsNotExist(err) == false {
+		return errors.Wrap(err, "destination access error")
+	}
+
+	boundIn, err := schemaIn.Bind(options.Dims, options.DefaultDim)
+	if err != nil {
+		return errors.Wrap(err, "failed to bind input schema dimensions")
+	}
+	boundOut, err := schemaOut.Bind(options.Dims, options.DefaultDim)
+	if err != nil {
+		return errors.Wrap(err, "failed to bind output schema dimensions")
+	}
+
+	var opener ds.Opener
+	var tarOpener *ds.TarOpener
+	root := destination
+	if strings.HasSuffix(destination, ".tar") {
+		tarOpener = ds.NewTarOpener()
+		opener = tarOpener
+		root = ""
+	} else {
+		opener = ds.DefaultOpener{}
+	}
+
+	rnd := rand.New(rand.NewSource(options.Seed))
+
+	splits := []struct {
+		name       string
+		numSamples int
+	}{{"train", options.TrainSamples}, {"val", options.ValSamples}}
+	for _, split := range splits {
+
+		// Input and output samples are paired by their names.
+		width := len(fmt.Sprint(split.numSamples))
+		sampleNames := make([]string, split.numSamples)
+		for i := range sampleNames {
+			sampleNames[i] = fmt.Sprintf("sample_%0*d", width, i+1)
+		}
+
+		datasetIn, err := ds.GenerateFromSchemaWithRand("", boundIn, sampleNames, options.NodeInstances, rnd)
+		if err != nil {
+			return errors.Wrap(err, "failed to generate input dataset")
+		}
+		err = datasetIn.Dump(path.Join(root, split.name, "input"), opener)
+		if err != nil {
+			return errors.Wrap(err, "failed to save input dataset")
+		}
+		datasetOut, err := ds.GenerateFromSchemaWithRand("", boundOut, sampleNames, options.NodeInstances, rnd)
+		if err != nil {
+			return errors.Wrap(err, "failed to generate output dataset")
+		}
+		err = datasetOut.Dump(path.Join(root, split.name, "output"), opener)
+		if err != nil {
+			return errors.Wrap(err, "failed to save output dataset")
+		}
+	}
+
+	if tarOpener != nil {
+		reader, err := ds.DumpTarOpener(tarOpener)
+		if err != nil {
+			return errors.Wrap(err, "failed to build dataset archive")
+		}
+		f, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_EXCL, DefaultFilePerm)
+		if err != nil {
+			return errors.Wrap(err, "failed to create dataset archive")
+		}
+		defer f.Close()
+		_, err = io.Copy(f, reader)
+		return errors.Wrap(err, "failed to write dataset archive")
+	}
+
+	return nil
+}
+
 func directoryEsists(dirpath string) (bool, error) {
 	trainDir, err := os.Stat(dirpath)
 	if err != nil {
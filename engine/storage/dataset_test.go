@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sch "github.com/ds3lab/easeml/schema/go/easemlschema/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const schemaTestExamplesPath = "../../schema/test-examples"
+
+// writeDatasetZip creates a zip archive with a train and val split which both use the given example
+// dataset as input and output. If the example path is empty only the additional entries are added.
+func writeDatasetZip(t *testing.T, archivePath string, examplePath string, extra map[string]string) {
+	f, err := os.Create(archivePath)
+	assert.Nil(t, err)
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	walk := func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(examplePath, p)
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		for _, split := range []string{"train", "val"} {
+			for _, side := range []string{"input", "output"} {
+				w, err := zw.Create(filepath.ToSlash(filepath.Join(split, side, rel)))
+				if err != nil {
+					return err
+				}
+				w.Write(content)
+			}
+		}
+		return nil
+	}
+	if examplePath != "" {
+		assert.Nil(t, filepath.Walk(examplePath, walk))
+	}
+	for name, content := range extra {
+		w, err := zw.Create(name)
+		assert.Nil(t, err)
+		w.Write([]byte(content))
+	}
+	assert.Nil(t, zw.Close())
+}
+
+func TestDatasetArchive(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "easeml-dataset")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	examplePath := filepath.Join(schemaTestExamplesPath, "dataset", "infer", "positive", "combined-001")
+	archivePath := filepath.Join(dir, "iris.zip")
+	writeDatasetZip(t, archivePath, examplePath, map[string]string{"README.md": "# Iris\n\nThe iris dataset.\n"})
+
+	id, name, description, err := InferDatasetProperties(archivePath)
+	assert.Nil(err)
+	assert.Equal("iris", id)
+	assert.Equal("Iris", name)
+	assert.Equal("The iris dataset.\n", description)
+
+	schemaIn, schemaOut, err := InferDatasetSchema(archivePath)
+	assert.Nil(err)
+	assert.NotNil(schemaIn)
+	assert.NotNil(schemaOut)
+
+	destination := filepath.Join(dir, "unpacked")
+	err = UnpackDatasetArchive(archivePath, destination)
+	assert.Nil(err)
+	_, err = os.Stat(filepath.Join(destination, "val", "output"))
+	assert.Nil(err)
+
+	// Archives without the expected layout are rejected before inference.
+	emptyPath := filepath.Join(dir, "empty.zip")
+	writeDatasetZip(t, emptyPath, "", map[string]string{"README.md": ""})
+	_, _, err = InferDatasetSchema(emptyPath)
+	assert.NotNil(err)
+
+	// Archives with entries outside of the root are not unpacked.
+	evilPath := filepath.Join(dir, "evil.zip")
+	writeDatasetZip(t, evilPath, "", map[string]string{"../evil.txt": "evil"})
+	assert.NotNil(ValidateDatasetArchive(evilPath))
+	assert.NotNil(UnpackDatasetArchive(evilPath, filepath.Join(dir, "evil")))
+	_, err = os.Stat(filepath.Join(dir, "evil.txt"))
+	assert.True(os.IsNotExist(err))
+}
+
+func loadTestSchema(t *testing.T, src string) *sch.Schema {
+	var input interface{}
+	assert.Nil(t, json.Unmarshal([]byte(src), &input))
+	schema, err := sch.Load(input)
+	assert.Nil(t, err)
+	return schema
+}
+
+func TestGenerateDataset(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "easeml-dataset")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	schemaIn := loadTestSchema(t, `{"nodes": {
+		"image": {"singleton": true, "type": "tensor", "dim": ["h", "w", 3]},
+		"regions": {"fields": {"box": {"type": "tensor", "dim": [4]}}, "links": {"regions": 1}}
+	}}`)
+	schemaOut := loadTestSchema(t, `{"nodes": {
+		"label": {"singleton": true, "type": "category", "class": "labels"}
+	}, "classes": {"labels": {"dim": "k"}}}`)
+
+	options := GenerateDatasetOptions{
+		TrainSamples:  5,
+		ValSamples:    2,
+		NodeInstances: 3,
+		Dims:          map[string]int{"h": 8, "k": 4},
+		DefaultDim:    6,
+		Seed:          42,
+	}
+
+	for _, name := range []string{"generated", "generated.tar"} {
+		destination := filepath.Join(dir, name)
+		err = GenerateDataset(schemaIn, schemaOut, destination, options)
+		assert.Nil(err)
+
+		inferredIn, inferredOut, err := InferDatasetSchema(destination)
+		assert.Nil(err, name)
+		if err != nil {
+			continue
+		}
+		match, _ := schemaIn.Match(inferredIn, false)
+		assert.True(match, name)
+		match, _ = schemaOut.Match(inferredOut, false)
+		assert.True(match, name)
+
+		dim := inferredIn.Nodes["image"].Fields["field"].(*sch.Tensor).Dim
+		assert.Equal(8, dim[0].(*sch.ConstDim).Value)
+		assert.Equal(6, dim[1].(*sch.ConstDim).Value)
+		assert.Equal(4, inferredOut.Classes["labels"].Dim.(*sch.ConstDim).Value)
+
+		// An existing destination is never overwritten.
+		assert.NotNil(GenerateDataset(schemaIn, schemaOut, destination, options))
+	}
+
+	// The same seed generates the same dataset.
+	again := filepath.Join(dir, "again")
+	assert.Nil(GenerateDataset(schemaIn, schemaOut, again, options))
+	filepath.Walk(again, func(p string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() == false {
+			rel, _ := filepath.Rel(again, p)
+			expected, _ := ioutil.ReadFile(filepath.Join(dir, "generated", rel))
+			actual, _ := ioutil.ReadFile(p)
+			assert.Equal(expected, actual, rel)
+		}
+		return err
+	})
+}
+
+func TestInferInputOnlyDataset(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "easeml-dataset")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	schemaIn := loadTestSchema(t, `{"nodes": {"x": {"singleton": true, "type": "tensor", "dim": [2, 3]}}}`)
+	schemaOut := loadTestSchema(t, `{"nodes": {"y": {"singleton": true, "type": "tensor", "dim": [1]}}}`)
+	generated := filepath.Join(dir, "generated")
+	err = GenerateDataset(schemaIn, schemaOut, generated, GenerateDatasetOptions{TrainSamples: 3, ValSamples: 1, NodeInstances: 1, Seed: 1})
+	assert.Nil(err)
+
+	// Datasets to make predictions on only contain inputs.
+	inputOnly := filepath.Join(dir, "input-only")
+	assert.Nil(os.MkdirAll(inputOnly, DefaultFilePerm))
+	assert.Nil(os.Rename(filepath.Join(generated, "train", "input"), filepath.Join(inputOnly, "input")))
+
+	inferredIn, inferredOut, err := InferDatasetSchema(inputOnly)
+	assert.Nil(err)
+	assert.Nil(inferredOut)
+	if assert.NotNil(inferredIn) {
+		match, _ := schemaIn.Match(inferredIn, false)
+		assert.True(match)
+	}
+
+	// Datasets with a train directory must have the full layout.
+	_, _, err = InferDatasetSchema(generated)
+	assert.NotNil(err)
+}
@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultS3Endpoint is the endpoint used when no S3-compatible endpoint is specified.
+const DefaultS3Endpoint = "https://s3.amazonaws.com"
+
+// DefaultS3Region is the region used for signing requests when no region is specified.
+const DefaultS3Region = "us-east-1"
+
+const s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Client is a minimal client for S3-compatible object stores (e.g. AWS S3 or MinIO). It uses path-style
+// addressing and signs requests with AWS Signature Version 4. Anonymous requests are made if no credentials
+// are given.
+type S3Client struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	HTTPClient      *http.Client
+}
+
+// S3Object describes a single object in a bucket.
+type S3Object struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+type s3ListBucketResult struct {
+	Contents              []S3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+type s3ErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// ParseS3Address splits an address of the form bucket/prefix into the bucket and the prefix. The prefix
+// can be empty, in which case the address refers to the whole bucket.
+func ParseS3Address(address string) (bucket, prefix string, err error) {
+	address = strings.TrimPrefix(address, "s3://")
+	address = strings.TrimLeft(address, "/")
+	parts := strings.SplitN(address, "/", 2)
+	bucket = parts[0]
+	if bucket == "" {
+		err = errors.New("the s3 address must be of the format bucket/prefix")
+		return
+	}
+	if len(parts) == 2 {
+		prefix = parts[1]
+	}
+	return
+}
+
+// ParseS3Credentials splits an access key of the form access-key-id:secret-access-key into its parts.
+// An empty access key results in empty credentials.
+func ParseS3Credentials(accessKey string) (accessKeyID, secretAccessKey string, err error) {
+	if accessKey == "" {
+		return
+	}
+	parts := strings.SplitN(accessKey, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		err = errors.New("the s3 access key must be of the format access-key-id:secret-access-key")
+		return
+	}
+	return parts[0], parts[1], nil
+}
+
+// ListObjects returns all objects in the bucket whose keys start with the given prefix.
+func (client S3Client) ListObjects(bucket, prefix string) (objects []S3Object, err error) {
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		var resp *http.Response
+		resp, err = client.do("GET", "/"+bucket, query)
+		if err != nil {
+			return
+		}
+
+		var result s3ListBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			err = errors.Wrap(err, "s3 list response decode error")
+			return
+		}
+		objects = append(objects, result.Contents...)
+
+		if result.IsTruncated == false || result.NextContinuationToken == "" {
+			break
+		}
+		continuationToken = result.NextContinuationToken
+	}
+	return
+}
+
+// GetObject writes the content of the object with the given key to the writer.
+func (client S3Client) GetObject(bucket, key string, w io.Writer) (err error) {
+	resp, err := client.do("GET", "/"+bucket+"/"+key, nil)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return errors.WithStack(err)
+}
+
+// DownloadObject stores the object with the given key to the given file path. All missing parent
+// directories are created.
+func (client S3Client) DownloadObject(bucket, key, path string) (err error) {
+	err = os.MkdirAll(filepath.Dir(path), DefaultFilePerm)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, DefaultFilePerm)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	return client.GetObject(bucket, key, f)
+}
+
+// DownloadPrefix stores all objects under the given prefix in the destination directory. The directory
+// structure is taken from the object keys relative to the prefix. Keys ending with a slash are treated as
+// directory markers and skipped.
+func (client S3Client) DownloadPrefix(bucket, prefix, destination string) (numObjects int, err error) {
+	if prefix != "" && strings.HasSuffix(prefix, "/") == false {
+		prefix += "/"
+	}
+	objects, err := client.ListObjects(bucket, prefix)
+	if err != nil {
+		return
+	}
+	for _, object := range objects {
+		relativePath := strings.TrimPrefix(object.Key, prefix)
+		if relativePath == "" || strings.HasSuffix(relativePath, "/") {
+			continue
+		}
+		targetPath := filepath.Join(destination, filepath.FromSlash(relativePath))
+
+		// Prevent keys such as "../x" from escaping the destination directory.
+		if strings.HasPrefix(targetPath, filepath.Clean(destination)+string(os.PathSeparator)) == false {
+			err = errors.Errorf("invalid object key \"%s\"", object.Key)
+			return
+		}
+		err = client.DownloadObject(bucket, object.Key, targetPath)
+		if err != nil {
+			return
+		}
+		numObjects++
+	}
+	return
+}
+
+func (client S3Client) do(method, path string, query url.Values) (resp *http.Response, err error) {
+	endpoint := client.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultS3Endpoint
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		err = errors.Wrap(err, "invalid s3 endpoint")
+		return
+	}
+	u.RawPath = s3EscapePath(u.Path + path)
+	u.Path = u.Path + path
+	if query != nil {
+		u.RawQuery = s3CanonicalQuery(query)
+	}
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	client.sign(req, time.Now().UTC())
+
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err = httpClient.Do(req)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		var s3Err s3ErrorResponse
+		body, _ := ioutil.ReadAll(resp.Body)
+		if xml.Unmarshal(body, &s3Err) == nil && s3Err.Code != "" {
+			err = errors.Errorf("s3 request %s %s failed with status %d: %s: %s", method, path, resp.StatusCode, s3Err.Code, s3Err.Message)
+		} else {
+			err = errors.Errorf("s3 request %s %s failed with status %d", method, path, resp.StatusCode)
+		}
+		resp = nil
+	}
+	return
+}
+
+// sign adds the AWS Signature Version 4 authorization headers to the request.
+func (client S3Client) sign(req *http.Request, now time.Time) {
+	req.Header.Set("x-amz-content-sha256", s3EmptyPayloadHash)
+	if client.AccessKeyID == "" {
+		return
+	}
+
+	region := client.Region
+	if region == "" {
+		region = DefaultS3Region
+	}
+	amzDate := now.Format("20060102T150405Z")
+	shortDate := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	headerValues := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": s3EmptyPayloadHash,
+		"x-amz-date":           amzDate,
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		canonicalHeaders.WriteString(h + ":" + headerValues[h] + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		s3EmptyPayloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, region, "s3", "aws4_request"}, "/")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	key := s3HMAC([]byte("AWS4"+client.SecretAccessKey), shortDate)
+	key = s3HMAC(key, region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+	signature := hex.EncodeToString(s3HMAC(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		client.AccessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Escape performs the URI encoding required by the S3 signature algorithm.
+func s3Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		segments[i] = s3Escape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := []string{}
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
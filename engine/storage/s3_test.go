@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newS3StandIn returns a server which mimics the subset of the MinIO API that is used by S3Client.
+func newS3StandIn(bucket string, objects map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") == false {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "<Error><Code>AccessDenied</Code><Message>Access Denied.</Message></Error>")
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		if path == bucket {
+			prefix := r.URL.Query().Get("prefix")
+			keys := []string{}
+			for k := range objects {
+				if strings.HasPrefix(k, prefix) {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			fmt.Fprint(w, "<ListBucketResult>")
+			for _, k := range keys {
+				fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", k, len(objects[k]))
+			}
+			fmt.Fprint(w, "<IsTruncated>false</IsTruncated></ListBucketResult>")
+			return
+		}
+		content, ok := objects[strings.TrimPrefix(path, bucket+"/")]
+		if ok == false {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>")
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+}
+
+func TestParseS3Address(t *testing.T) {
+	assert := assert.New(t)
+
+	bucket, prefix, err := ParseS3Address("data/sets/mnist.tar")
+	assert.Nil(err)
+	assert.Equal("data", bucket)
+	assert.Equal("sets/mnist.tar", prefix)
+
+	bucket, prefix, err = ParseS3Address("s3://data")
+	assert.Nil(err)
+	assert.Equal("data", bucket)
+	assert.Equal("", prefix)
+
+	_, _, err = ParseS3Address("")
+	assert.NotNil(err)
+
+	_, _, err = ParseS3Credentials("no-secret")
+	assert.NotNil(err)
+}
+
+func TestS3DownloadPrefix(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newS3StandIn("data", map[string]string{
+		"sets/mnist.tar":               "archive",
+		"sets/iris/train/input/x.ten":  "1 2 3",
+		"sets/iris/train/output/y.cat": "a",
+		"sets/iris/val/":               "",
+	})
+	defer server.Close()
+
+	client := S3Client{Endpoint: server.URL, AccessKeyID: "minio", SecretAccessKey: "minio123"}
+
+	dir, err := ioutil.TempDir("", "easeml-s3")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	err = client.DownloadObject("data", "sets/mnist.tar", filepath.Join(dir, "file.bin"))
+	assert.Nil(err)
+	content, err := ioutil.ReadFile(filepath.Join(dir, "file.bin"))
+	assert.Nil(err)
+	assert.Equal("archive", string(content))
+
+	numObjects, err := client.DownloadPrefix("data", "sets/iris", filepath.Join(dir, "iris"))
+	assert.Nil(err)
+	assert.Equal(2, numObjects)
+	content, err = ioutil.ReadFile(filepath.Join(dir, "iris", "train", "input", "x.ten"))
+	assert.Nil(err)
+	assert.Equal("1 2 3", string(content))
+
+	err = client.DownloadObject("data", "sets/missing", filepath.Join(dir, "missing"))
+	assert.NotNil(err)
+
+	client.AccessKeyID = ""
+	_, err = client.ListObjects("data", "")
+	assert.NotNil(err)
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// secretKeySize is the size of the AES-256 key used to encrypt secrets.
+const secretKeySize = 32
+
+// GetSecretKey returns the key used to encrypt secrets such as registry credentials before they are stored
+// in the database. The key is generated the first time it is needed and kept in the working directory so
+// that it is shared by all processes but never stored together with the secrets.
+func (context Context) GetSecretKey() (key []byte, err error) {
+	path := filepath.FromSlash(context.WorkingDir + secretKeyPathTemplate)
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return
+	}
+
+	// The key is written to a temporary file which is then linked into place. Linking fails if the key file
+	// already exists so only one process can create it and the others never see a partially written key.
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		key = make([]byte, secretKeySize)
+		if _, err = io.ReadFull(rand.Reader, key); err != nil {
+			return nil, errors.Wrap(err, "secret key generation failed")
+		}
+		var f *os.File
+		f, err = ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+		if err != nil {
+			return nil, errors.Wrap(err, "secret key write failed")
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write(key)
+		if err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "secret key write failed")
+		}
+		err = os.Link(f.Name(), path)
+		if err == nil {
+			return
+		} else if os.IsExist(err) == false {
+			return nil, errors.Wrap(err, "secret key write failed")
+		}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "secret key access failed")
+	}
+
+	key, err = ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "secret key read failed")
+	}
+	if len(key) != secretKeySize {
+		return nil, errors.Errorf("the secret key file \"%s\" is corrupt", path)
+	}
+	return
+}
+
+// EncryptSecret encrypts a secret with AES-GCM and returns it encoded as base64.
+func EncryptSecret(key []byte, secret string) (string, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "nonce generation failed")
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts a secret encrypted with EncryptSecret.
+func DecryptSecret(key []byte, encrypted string) (string, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", errors.Wrap(err, "secret decode failed")
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("secret too short")
+	}
+	secret, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.Wrap(err, "secret decryption failed")
+	}
+	return string(secret), nil
+}
+
+func newSecretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "secret cipher creation failed")
+	}
+	return cipher.NewGCM(block)
+}
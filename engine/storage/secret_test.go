@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecrets(t *testing.T) {
+	assert := assert.New(t)
+
+	workingDir, err := ioutil.TempDir("", "easeml_secret_")
+	assert.Nil(err)
+	defer os.RemoveAll(workingDir)
+	context := Context{WorkingDir: workingDir}
+
+	// The key is generated once and then reused.
+	key, err := context.GetSecretKey()
+	assert.Nil(err)
+	assert.Len(key, secretKeySize)
+	again, err := context.GetSecretKey()
+	assert.Nil(err)
+	assert.Equal(key, again)
+
+	encrypted, err := EncryptSecret(key, "hunter2")
+	assert.Nil(err)
+	assert.NotContains(encrypted, "hunter2")
+	other, err := EncryptSecret(key, "hunter2")
+	assert.Nil(err)
+	assert.NotEqual(encrypted, other)
+
+	secret, err := DecryptSecret(key, encrypted)
+	assert.Nil(err)
+	assert.Equal("hunter2", secret)
+
+	// Secrets cannot be decrypted with a different key or after tampering.
+	otherContext := Context{WorkingDir: workingDir + "/other"}
+	otherKey, err := otherContext.GetSecretKey()
+	assert.Nil(err)
+	_, err = DecryptSecret(otherKey, encrypted)
+	assert.NotNil(err)
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	assert.Nil(err)
+	sealed[len(sealed)-1] ^= 1
+	_, err = DecryptSecret(key, base64.StdEncoding.EncodeToString(sealed))
+	assert.NotNil(err)
+}
+
+func TestSecretKeyConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	workingDir, err := ioutil.TempDir("", "easeml_secret_")
+	assert.Nil(err)
+	defer os.RemoveAll(workingDir)
+	context := Context{WorkingDir: workingDir}
+
+	// Processes which generate the key at the same time all end up with the same key.
+	keys := make([][]byte, 16)
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i := range keys {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			keys[i], errs[i] = context.GetSecretKey()
+		}(i)
+	}
+	wg.Wait()
+	for i := range keys {
+		assert.Nil(errs[i])
+		assert.Equal(keys[0], keys[i])
+	}
+}
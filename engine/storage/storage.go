@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ds3lab/easeml/engine/database/model/types"
 )
@@ -27,9 +28,15 @@ const (
 	// Pattern: shared/data/stable/{user-id}/{datased-id}
 	datasetPathTemplate = "/shared/data/stable/%s/%s"
 
+	// Pattern: /shared/jobs/{job-id}
+	jobPathTemplate = "/shared/jobs/%s"
+
 	// Pattern: /shared/jobs/{job-id}/{task-id}
 	taskPathTemplate = "/shared/jobs/%s/%s"
 
+	// Pattern: /shared/deployments/{deployment-id}
+	deploymentPathTemplate = "/shared/deployments/%s"
+
 	// Pattern: scheduling/input
 	schedulingInputPathTemplate = "/shared/scheduling/input"
 
@@ -44,8 +51,17 @@ const (
 
 	// Pattern: /shared/processes/{process-id}
 	processPathTemplate = "/shared/processes/%s"
+
+	// Pattern: /shared/runtime/{runtime}
+	runtimePathTemplate = "/shared/runtime/%s"
+
+	// Pattern: /shared/secrets/secret.key
+	secretKeyPathTemplate = "/shared/secrets/secret.key"
 )
 
+// DeploymentLogFile is the name of the file in the logs directory of a deployment which collects its events.
+const DeploymentLogFile = "deployment.log"
+
 // DefaultFilePerm is the default file mode to be used when creating directories.
 const DefaultFilePerm = os.FileMode(0755)
 
@@ -111,6 +127,13 @@ func (context Context) GetModulePath(id string, moduleType string, subdir string
 	return
 }
 
+// GetRuntimePath returns the path where a module runtime keeps its images.
+func (context Context) GetRuntimePath(runtime string) (path string, err error) {
+	path = filepath.FromSlash(context.WorkingDir + fmt.Sprintf(runtimePathTemplate, runtime))
+	err = os.MkdirAll(path, DefaultFilePerm)
+	return
+}
+
 // GetSchedulingInputPath returns the path which holds temp data
 func (context Context) GetSchedulingInputPath(subdir string) (path string, err error) {
 	path = filepath.FromSlash(context.WorkingDir + schedulingInputPathTemplate)
@@ -121,6 +144,18 @@ func (context Context) GetSchedulingInputPath(subdir string) (path string, err e
 	return
 }
 
+// GetJobPath constructs the path for a given job, ensures it exists and returns the path string. Tasks of
+// the job are stored in this path as well.
+func (context Context) GetJobPath(id string, subdir string) (path string, err error) {
+	path = filepath.FromSlash(context.WorkingDir + fmt.Sprintf(jobPathTemplate, id))
+	if subdir != "" {
+		path = filepath.Join(path, subdir)
+	}
+
+	err = os.MkdirAll(path, DefaultFilePerm)
+	return
+}
+
 // GetTaskPath constructs the path for a given dataset, ensures it exists and returns the path string.
 func (context Context) GetTaskPath(id string, subdir string) (path string, err error) {
 
@@ -137,6 +172,33 @@ func (context Context) GetTaskPath(id string, subdir string) (path string, err e
 	return
 }
 
+// GetDeploymentPath constructs the path for a given deployment, ensures it exists and returns the path string.
+func (context Context) GetDeploymentPath(id string, subdir string) (path string, err error) {
+	path = filepath.FromSlash(context.WorkingDir + fmt.Sprintf(deploymentPathTemplate, id))
+	if subdir != "" {
+		path = filepath.Join(path, subdir)
+	}
+
+	err = os.MkdirAll(path, DefaultFilePerm)
+	return
+}
+
+// AppendDeploymentLog appends a timestamped message to the log of a given deployment.
+func (context Context) AppendDeploymentLog(id string, message string) (err error) {
+	logsPath, err := context.GetDeploymentPath(id, "logs")
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(filepath.Join(logsPath, DeploymentLogFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, DefaultFilePerm)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s %s\n", time.Now().UTC().Format(time.RFC3339), strings.TrimSpace(message))
+	return
+}
+
 // GetAllTaskPaths constructs and returns all paths during storage execution.
 func (context Context) GetAllTaskPaths(id string) (paths TaskPaths, err error) {
 	if paths.Parameters, err = context.GetTaskPath(id, "parameters"); err != nil {
@@ -1,6 +1,8 @@
 package workers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/ds3lab/easeml/engine/database/model"
 	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/storage"
 	"github.com/ds3lab/easeml/engine/utils"
 
 	"github.com/otiai10/copy"
@@ -19,6 +22,15 @@ import (
 
 const downloadFilename = "file.bin"
 
+// downloadMaxAttempts is the number of times a download is attempted before giving up.
+const downloadMaxAttempts = 5
+
+// downloadRetryTimeout is the time to wait before the first retry. It doubles after every attempt.
+const downloadRetryTimeout = 2 * time.Second
+
+// downloadProgressPeriod is the time between two transfer progress updates.
+const downloadProgressPeriod = time.Second
+
 // DatasetDownloadListener periodically checks if there are any datasets which have been created
 // with source set to "download" but the download hasn't been successfully performed yet.
 func (context Context) DatasetDownloadListener() {
@@ -39,7 +51,13 @@ func (context Context) DatasetDownloadListener() {
 			panic(err)
 		}
 
-
+		// If the file source is an S3-compatible object store it should fetch the archive object or the whole prefix.
+		dataset, err = context.ModelContext.LockDataset(model.F{"source": types.DatasetS3, "status": types.DatasetCreated}, context.ProcessID, "", "")
+		if err == nil {
+			go context.DatasetS3Worker(dataset)
+		} else if errors.Cause(err) != model.ErrNotFound {
+			panic(err)
+		}
 
 		// If the local file source is a directory, then in this step we will copy it to our repository to ensure stability.
 		dataset, err = context.ModelContext.LockDataset(model.F{"source": types.DatasetLocal, "status": types.DatasetCreated}, context.ProcessID, "", "")
@@ -72,7 +90,7 @@ func (context Context) DatasetDownloadWorker(dataset types.Dataset) {
 	).WriteInfo("DATASET TRANSFER STARTED")
 
 	// Perform download.
-	resp, err := grab.Get(filepath.Join(path, downloadFilename), dataset.SourceAddress)
+	resp, err := context.downloadDataset(dataset, filepath.Join(path, downloadFilename))
 	if err != nil {
 
 		err = errors.WithStack(err)
@@ -106,6 +124,77 @@ func (context Context) DatasetDownloadWorker(dataset types.Dataset) {
 	).WriteInfo("DATASET TRANSFER COMPLETED")
 }
 
+// downloadDataset downloads the dataset to the given file path. Interrupted transfers are retried with an
+// exponential backoff and resumed from the partially downloaded file if the server supports it. The transfer
+// progress is periodically written to the dataset record. If the dataset has a checksum, the downloaded file
+// is verified against it.
+func (context Context) downloadDataset(dataset types.Dataset, filename string) (resp *grab.Response, err error) {
+
+	backoff := downloadRetryTimeout
+	for attempt := 1; ; attempt++ {
+
+		var req *grab.Request
+		req, err = grab.NewRequest(filename, dataset.SourceAddress)
+		if err != nil {
+			return
+		}
+		if dataset.Checksum != "" {
+			var sum []byte
+			sum, err = hex.DecodeString(dataset.Checksum)
+			if err != nil {
+				return
+			}
+			req.SetChecksum(sha256.New(), sum, true)
+		}
+
+		resp = grab.DefaultClient.Do(req)
+		context.watchDownloadProgress(dataset, resp)
+		err = resp.Err()
+
+		// Bad checksums and client errors will not be fixed by retrying.
+		if err == nil || err == grab.ErrBadChecksum || err == grab.ErrBadLength ||
+			(resp.HTTPResponse != nil && resp.HTTPResponse.StatusCode >= 400 && resp.HTTPResponse.StatusCode < 500) {
+			return
+		}
+		if attempt >= downloadMaxAttempts {
+			err = errors.Wrapf(err, "download failed after %d attempts", attempt)
+			return
+		}
+
+		context.Logger.WithFields(
+			"dataset-id", dataset.ID,
+			"source-address", dataset.SourceAddress,
+			"attempt", attempt,
+			"timeout", backoff,
+			"transferred-bytes", resp.BytesComplete(),
+		).WithError(err).WriteInfo("DATASET TRANSFER INTERRUPTED, RETRYING")
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// watchDownloadProgress blocks until the transfer completes and periodically records its progress.
+func (context Context) watchDownloadProgress(dataset types.Dataset, resp *grab.Response) {
+	ticker := time.NewTicker(downloadProgressPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Progress reporting is best effort so we ignore errors.
+			context.ModelContext.UpdateDatasetTransferProgress(dataset.ID, resp.BytesComplete(), resp.Size)
+		case <-resp.Done:
+			if resp.Err() == nil {
+				context.repeatUntilSuccess(func() error {
+					return context.ModelContext.UpdateDatasetTransferProgress(dataset.ID, resp.BytesComplete(), resp.Size)
+				})
+			}
+			return
+		}
+	}
+}
+
 // DatasetGitWorker performs the actual dataset fetch from a git lfs repository.
 func (context Context) DatasetGitWorker(dataset types.Dataset) {
 
@@ -224,6 +313,106 @@ func (context Context) DatasetGitWorker(dataset types.Dataset) {
 	).WriteInfo("DATASET GIT TRANSFER COMPLETED")
 }
 
+// DatasetS3Worker fetches the dataset from an S3-compatible object store. If the prefix in the source address
+// matches a single object, it is treated as an archive and unpacked later. Otherwise, all objects under the
+// prefix are copied to the dataset directory.
+func (context Context) DatasetS3Worker(dataset types.Dataset) {
+
+	context.Logger.WithFields(
+		"dataset-id", dataset.ID,
+		"source", dataset.Source,
+		"source-address", dataset.SourceAddress,
+		"s3-endpoint", context.S3Endpoint,
+	).WriteInfo("DATASET S3 TRANSFER STARTED")
+
+	destinationPath, err := context.transferS3Dataset(dataset)
+
+	// Flush used accessKey
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.FlushDatasetAccessKey(dataset.ID)
+	})
+	dataset.AccessKey = ""
+
+	if err != nil {
+		err = errors.WithStack(err)
+		context.Logger.WithFields(
+			"dataset-id", dataset.ID,
+			"source", dataset.Source,
+			"source-address", dataset.SourceAddress,
+		).WithStack(err).WithError(err).WriteError("DATASET S3 TRANSFER ERROR")
+
+		context.repeatUntilSuccess(func() error {
+			return context.ModelContext.UpdateDatasetStatus(dataset.ID, types.DatasetError, err.Error())
+		})
+
+		return
+	}
+
+	// Unlock the dataset and update the status.
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UpdateDatasetStatus(dataset.ID, types.DatasetTransferred, "")
+	})
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UnlockDataset(dataset.ID, context.ProcessID)
+	})
+
+	// Log task completion.
+	context.Logger.WithFields(
+		"dataset-id", dataset.ID,
+		"source", dataset.Source,
+		"source-address", dataset.SourceAddress,
+		"destination-path", destinationPath,
+	).WriteInfo("DATASET S3 TRANSFER COMPLETED")
+}
+
+func (context Context) transferS3Dataset(dataset types.Dataset) (destinationPath string, err error) {
+
+	bucket, prefix, err := storage.ParseS3Address(dataset.SourceAddress)
+	if err != nil {
+		return
+	}
+	accessKeyID, secretAccessKey, err := storage.ParseS3Credentials(dataset.AccessKey)
+	if err != nil {
+		return
+	}
+	client := storage.S3Client{
+		Endpoint:        context.S3Endpoint,
+		Region:          context.S3Region,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+	}
+
+	objects, err := client.ListObjects(bucket, prefix)
+	if err != nil {
+		return
+	}
+
+	// A prefix that names an object exactly is an archive which will be unpacked later.
+	for i := range objects {
+		if prefix != "" && objects[i].Key == prefix {
+			downloadPath, err := context.StorageContext.GetDatasetPath(dataset.ID, ".download")
+			if err != nil {
+				// This means that we cannot access the file system, so we need to panic.
+				panic(err)
+			}
+			destinationPath = filepath.Join(downloadPath, downloadFilename)
+			return destinationPath, client.DownloadObject(bucket, prefix, destinationPath)
+		}
+	}
+
+	// Otherwise the prefix is treated as a directory.
+	destinationPath, err = context.StorageContext.GetDatasetPath(dataset.ID, "")
+	if err != nil {
+		// This means that we cannot access the file system, so we need to panic.
+		panic(err)
+	}
+	numObjects, err := client.DownloadPrefix(bucket, prefix, destinationPath)
+	if err == nil && numObjects == 0 {
+		err = errors.Errorf("no objects found in bucket \"%s\" under prefix \"%s\"", bucket, prefix)
+	}
+	return
+}
+
 // DatasetLocalCopyWorker copies the local dataset if it is a directory.
 func (context Context) DatasetLocalCopyWorker(dataset types.Dataset) {
 
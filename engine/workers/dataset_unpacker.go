@@ -7,6 +7,8 @@ import (
 
 	"github.com/ds3lab/easeml/engine/database/model"
 	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/storage"
+	ds "github.com/ds3lab/easeml/schema/go/easemlschema/dataset"
 
 	"github.com/mholt/archiver"
 	"github.com/pkg/errors"
@@ -57,6 +59,15 @@ func (context Context) DatasetUnpackWorker(dataset types.Dataset) {
 		// Find all uploaded files and their target paths.
 		sourceFilePaths, destinationFilePaths = getUploadedFilesDestinationPaths(datasetPath, "")
 
+	} else if dataset.Source == types.DatasetS3 {
+
+		// Only archive objects need unpacking, directory prefixes were copied directly to the dataset path.
+		archivePath := filepath.Join(datasetPath, ".download", downloadFilename)
+		if _, err := os.Stat(archivePath); err == nil {
+			sourceFilePaths = []string{archivePath}
+			destinationFilePaths = []string{datasetPath}
+		}
+
 	} else if dataset.Source == types.DatasetLocal {
 
 		// Check if the source address points to a file.
@@ -86,6 +97,27 @@ func (context Context) DatasetUnpackWorker(dataset types.Dataset) {
 
 	// Unpack all the source files.
 	for i := range sourceFilePaths {
+
+		// Zip and tar archives are validated before they are unpacked. Other formats are left to the archiver.
+		format, err := ds.DetectArchiveFormat(sourceFilePaths[i])
+		if err == nil && format != "" {
+			err = storage.UnpackDatasetArchive(sourceFilePaths[i], destinationFilePaths[i])
+			if err != nil {
+				context.Logger.WithFields(
+					"dataset-id", dataset.ID,
+					"source", dataset.Source,
+					"source-address", dataset.SourceAddress,
+				).WithStack(err).WithError(err).WriteError("DATASET UNPACK FAILED")
+
+				context.repeatUntilSuccess(func() error {
+					return context.ModelContext.UpdateDatasetStatus(dataset.ID, types.DatasetError, err.Error())
+				})
+
+				return
+			}
+			continue
+		}
+
 		arch := archiver.MatchingFormat(sourceFilePaths[i])
 		if arch == nil {
 			err = errors.New("unknown format")
@@ -122,7 +154,7 @@ func (context Context) DatasetUnpackWorker(dataset types.Dataset) {
 
 	// Delete the temp directories.
 	//TODO Check if it is dangerous to share the same folder by Gitlfs
-	if dataset.Source == types.DatasetDownload || dataset.Source == types.DatasetGit {
+	if dataset.Source == types.DatasetDownload || dataset.Source == types.DatasetGit || dataset.Source == types.DatasetS3 {
 		err = os.RemoveAll(filepath.Join(datasetPath, ".download"))
 		if err != nil {
 			panic(err)
@@ -39,12 +39,13 @@ func (context Context) DatasetValidatorkWorker(dataset types.Dataset) {
 		panic(err) // This means that we cannot access the file system, so we need to panic.
 	}
 
-	// Check if we can infer the schema.
+	// Check if we can infer the schema. Datasets which only hold samples to make predictions on have no
+	// output schema.
 	schemaIn, schemaOut, err := storage.InferDatasetSchema(datasetPath)
-	if err != nil || schemaIn == nil || schemaOut == nil {
+	if err != nil || schemaIn == nil {
 
 		if err == nil {
-			err = errors.New("every dataset must have an input and output schema")
+			err = errors.New("every dataset must have an input schema")
 		}
 
 		err = errors.WithStack(err)
@@ -65,9 +66,12 @@ func (context Context) DatasetValidatorkWorker(dataset types.Dataset) {
 	if err != nil {
 		panic(err) // This should never happen.
 	}
-	jsonSchemaOut, err := json.Marshal(schemaOut.Dump())
-	if err != nil {
-		panic(err) // This should never happen.
+	var jsonSchemaOut []byte
+	if schemaOut != nil {
+		jsonSchemaOut, err = json.Marshal(schemaOut.Dump())
+		if err != nil {
+			panic(err) // This should never happen.
+		}
 	}
 
 	// Update the dataset with the schema.
@@ -0,0 +1,154 @@
+package workers
+
+import (
+	"io/ioutil"
+	"log"
+	"time"
+
+	"github.com/ds3lab/easeml/engine/database/model"
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/modules"
+
+	"github.com/pkg/errors"
+)
+
+// DeploymentStartListener periodically checks if there are any deployments which need to be started. The
+// process which starts a deployment keeps it locked for as long as it is serving predictions.
+func (context Context) DeploymentStartListener() {
+
+	for {
+		deployment, err := context.ModelContext.LockDeployment(model.F{"status": types.DeploymentStarting}, context.ProcessID, "", "")
+		if err == nil {
+			log.Printf("DEPLOYMENT FOUND FOR STARTING")
+			go context.DeploymentStartWorker(deployment)
+		} else if errors.Cause(err) == model.ErrNotFound {
+			time.Sleep(context.Period)
+		} else {
+			panic(err)
+		}
+	}
+
+}
+
+// DeploymentStartWorker loads the model image of a deployment, makes sure the parameters of its task are
+// available and runs the model server of the deployment. The deployment is running and accepts prediction
+// requests once the server has started. The worker returns when the deployment is stopped or the server fails.
+func (context Context) DeploymentStartWorker(deployment types.Deployment) {
+
+	id := deployment.ID.Hex()
+	context.StorageContext.AppendDeploymentLog(id, "starting deployment of task "+deployment.Task)
+
+	// The trained parameters of the task must be there.
+	paths, err := context.StorageContext.GetAllTaskPaths(deployment.Task)
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+	files, err := ioutil.ReadDir(paths.Parameters)
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+	if len(files) == 0 {
+		err = errors.Errorf("the task \"%s\" has no trained parameters", deployment.Task)
+		context.deploymentStartError(err, deployment)
+		return
+	}
+
+	// Load the model image.
+	imageName, err := context.loadModuleImage(deployment.Model, types.ModuleModel)
+	if err != nil {
+		err = errors.WithStack(err)
+		context.deploymentStartError(err, deployment)
+		return
+	}
+
+	context.repeatUntilSuccess(func() error {
+		_, err := context.ModelContext.UpdateDeployment(deployment.ID, model.F{"image": imageName})
+		return err
+	})
+
+	// The model server takes prediction requests from the serving directory of the deployment.
+	servingPath, err := context.StorageContext.GetDeploymentPath(id, "serving")
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+	err = modules.PrepareServingDir(servingPath)
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+
+	// Watch the deployment while the model server is running. It is stopped as soon as the deployment
+	// is no longer ours to serve.
+	serverDone := make(chan bool)
+	stopRequested := make(chan bool, 1)
+	go func() {
+		running := false
+		for beat := 0; ; beat++ {
+			modules.KeepModelServerAlive(servingPath, beat)
+			select {
+			case <-serverDone:
+				return
+			case <-time.After(context.Period):
+			}
+
+			if running == false && modules.ModelServerStarted(servingPath) {
+				err := context.ModelContext.UpdateDeploymentStatus(deployment.ID, types.DeploymentRunning, "")
+				if err == nil {
+					running = true
+					context.StorageContext.AppendDeploymentLog(id, "deployment running with image "+imageName)
+					context.Logger.WithFields(
+						"deployment-id", id,
+						"task-id", deployment.Task,
+						"model", deployment.Model,
+					).WriteInfo("DEPLOYMENT STARTED")
+				} else if errors.Cause(err) != model.ErrBadInput {
+					continue // We try again with the next beat.
+				}
+			}
+
+			current, err := context.ModelContext.GetDeploymentByID(deployment.ID)
+			if err != nil && errors.Cause(err) != model.ErrNotFound {
+				continue
+			}
+			if err != nil || current.Process != context.ProcessID ||
+				(current.Status != types.DeploymentStarting && current.Status != types.DeploymentRunning) {
+				select {
+				case stopRequested <- true:
+				default:
+				}
+				modules.StopModelServer(servingPath)
+			}
+		}
+	}()
+
+	// Run the model server until the deployment is stopped.
+	output, err := modules.RunModelServer(context.Runtime, imageName, servingPath, paths.Parameters, context.GpuDevices)
+	close(serverDone)
+
+	select {
+	case <-stopRequested:
+		context.StorageContext.AppendDeploymentLog(id, "deployment stopped")
+		context.Logger.WithFields(
+			"deployment-id", id,
+			"task-id", deployment.Task,
+		).WriteInfo("DEPLOYMENT STOPPED")
+	default:
+		if err == nil {
+			err = errors.New("the model server exited unexpectedly")
+		}
+		context.StorageContext.AppendDeploymentLog(id, string(output))
+		context.deploymentStartError(errors.WithStack(err), deployment)
+	}
+}
+
+func (context Context) deploymentStartError(err error, deployment types.Deployment) {
+	context.Logger.WithFields(
+		"deployment-id", deployment.ID.Hex(),
+		"task-id", deployment.Task,
+		"model", deployment.Model,
+	).WithStack(err).WithError(err).WriteError("DEPLOYMENT START ERROR")
+
+	context.StorageContext.AppendDeploymentLog(deployment.ID.Hex(), "deployment failed to start: "+err.Error())
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UpdateDeploymentStatus(deployment.ID, types.DeploymentError, err.Error())
+	})
+}
@@ -0,0 +1,172 @@
+package workers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ds3lab/easeml/engine/database/model"
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/modules"
+	"github.com/ds3lab/easeml/engine/storage"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+)
+
+// JobPredictWorker runs the model of the task of a prediction job on the inputs of its dataset. The inputs
+// and the predictions are stored in the predictions dataset of the job which is then ready to be downloaded.
+func (context Context) JobPredictWorker(job types.Job) {
+
+	// Mark the job as running. The job could have been terminated in the meantime.
+	_, err := context.ModelContext.UpdateJob(job.ID, model.F{"status": types.JobRunning})
+	if errors.Cause(err) == model.ErrBadInput {
+		context.repeatUntilSuccess(func() error {
+			return context.ModelContext.UnlockJob(job.ID, context.ProcessID)
+		})
+		return
+	} else if err != nil {
+		context.repeatUntilSuccess(func() error {
+			_, err := context.ModelContext.UpdateJob(job.ID, model.F{"status": types.JobRunning})
+			return err
+		})
+	}
+
+	context.Logger.WithFields(
+		"job-id", job.ID.Hex(),
+		"task-id", job.Task,
+		"dataset", job.Dataset,
+	).WriteInfo("PREDICTION JOB STARTED")
+
+	var task types.Task
+	context.repeatUntilSuccess(func() (err error) {
+		task, err = context.ModelContext.GetTaskByID(job.Task)
+		return err
+	})
+	var module types.Module
+	context.repeatUntilSuccess(func() (err error) {
+		module, err = context.ModelContext.GetModuleByID(task.Model)
+		return err
+	})
+	var dataset types.Dataset
+	context.repeatUntilSuccess(func() (err error) {
+		dataset, err = context.ModelContext.GetDatasetByID(job.Dataset)
+		return err
+	})
+
+	// Dataset, parameters and predictions paths. Files left by an interrupted run are removed.
+	datasetPath, err := context.StorageContext.GetDatasetPath(job.Dataset, "")
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+	taskPaths, err := context.StorageContext.GetAllTaskPaths(job.Task)
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+	logsPath, err := context.StorageContext.GetJobPath(job.ID.Hex(), "logs")
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+	metadataPath, err := context.StorageContext.GetJobPath(job.ID.Hex(), "metadata")
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+	predictionsPath, err := context.StorageContext.GetDatasetPath(job.Predictions, "")
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+	err = storage.ClearDirectory(predictionsPath)
+	if err != nil {
+		panic(err) // This means that we cannot access the file system.
+	}
+
+	// Ensure the model is loaded.
+	imageName, err := context.loadModuleImage(task.Model, types.ModuleModel)
+	if err != nil {
+		context.jobPredictError(errors.WithStack(err), job)
+		return
+	}
+
+	// Make the predictions and check that they match the model.
+	modelOutput, err := modules.RunModelPrediction(context.Runtime, imageName, datasetPath, taskPaths.Parameters, predictionsPath, metadataPath, context.GpuDevices)
+	ioutil.WriteFile(filepath.Join(logsPath, "predict.log"), modelOutput, storage.DefaultFilePerm)
+	if err == nil {
+		err = modules.CheckPredictionOutput(module.SchemaOut, datasetPath, predictionsPath)
+	}
+	if err != nil {
+		context.jobPredictError(errors.WithStack(err), job)
+		return
+	}
+
+	// The predictions are stored together with the inputs they were made for.
+	err = copy.Copy(filepath.Join(datasetPath, "input"), filepath.Join(predictionsPath, "input"))
+	if err != nil {
+		context.jobPredictError(errors.WithStack(err), job)
+		return
+	}
+
+	// If the job was terminated in the meantime, the predictions are discarded.
+	var status string
+	context.repeatUntilSuccess(func() error {
+		currentJob, err := context.ModelContext.GetJobByID(job.ID)
+		status = currentJob.Status
+		return err
+	})
+	if status != types.JobRunning {
+		os.RemoveAll(predictionsPath)
+		context.repeatUntilSuccess(func() error {
+			return context.ModelContext.UnlockJob(job.ID, context.ProcessID)
+		})
+		context.Logger.WithFields(
+			"job-id", job.ID.Hex(),
+			"task-id", job.Task,
+			"dataset", job.Dataset,
+		).WriteInfo("PREDICTION JOB DISCARDED")
+		return
+	}
+
+	// Make the predictions dataset available.
+	context.repeatUntilSuccess(func() error {
+		updates := model.F{"schema-in": dataset.SchemaIn, "schema-out": module.SchemaOut}
+		_, err := context.ModelContext.UpdateDataset(job.Predictions, updates)
+		return err
+	})
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UpdateDatasetStatus(job.Predictions, types.DatasetValidated, "")
+	})
+
+	// Mark the job as completed and unlock it.
+	context.repeatUntilSuccess(func() error {
+		_, err := context.ModelContext.UpdateJob(job.ID, model.F{"status": types.JobCompleted})
+		return err
+	})
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UnlockJob(job.ID, context.ProcessID)
+	})
+
+	context.Logger.WithFields(
+		"job-id", job.ID.Hex(),
+		"task-id", job.Task,
+		"dataset", job.Dataset,
+		"predictions", job.Predictions,
+	).WriteInfo("PREDICTION JOB COMPLETED")
+}
+
+func (context Context) jobPredictError(err error, job types.Job) {
+	context.Logger.WithFields(
+		"job-id", job.ID.Hex(),
+		"task-id", job.Task,
+		"dataset", job.Dataset,
+	).WithStack(err).WithError(err).WriteError("PREDICTION JOB ERROR")
+
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UpdateDatasetStatus(job.Predictions, types.DatasetError, err.Error())
+	})
+	context.repeatUntilSuccess(func() error {
+		_, err := context.ModelContext.UpdateJob(job.ID, model.F{"status": types.JobError, "status-message": err.Error()})
+		return err
+	})
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UnlockJob(job.ID, context.ProcessID)
+	})
+}
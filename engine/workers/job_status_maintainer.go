@@ -96,6 +96,13 @@ func (context Context) JobTerminatingWorker(job types.Job) {
 		return context.ModelContext.TerminateRunningTasks(job.ID)
 	})
 
+	// The predictions of a terminated prediction job are never made.
+	if job.IsPredict() {
+		context.repeatUntilSuccess(func() error {
+			return context.ModelContext.UpdateDatasetStatus(job.Predictions, types.DatasetError, "the prediction job was terminated")
+		})
+	}
+
 	// Mark job as terminated.
 	context.repeatUntilSuccess(func() (err error) {
 		_, err = context.ModelContext.UpdateJob(job.ID, model.F{"status": types.JobTerminated})
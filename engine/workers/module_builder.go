@@ -0,0 +1,135 @@
+package workers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ds3lab/easeml/engine/database/model"
+	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/modules"
+	"github.com/ds3lab/easeml/engine/storage"
+
+	"github.com/pkg/errors"
+)
+
+// maxBuildLogSize is the size of the tail of the build log which is kept with the module.
+const maxBuildLogSize = 64 * 1024
+
+// ModuleBuildListener periodically checks if there are any modules which have been created
+// with source set to "build" but their image hasn't been built yet.
+func (context Context) ModuleBuildListener() {
+
+	for {
+		module, err := context.ModelContext.LockModule(model.F{"source": types.ModuleBuild, "status": types.ModuleCreated}, context.ProcessID, "", "")
+		if err == nil {
+			go context.ModuleBuildWorker(module)
+		} else if errors.Cause(err) == model.ErrNotFound {
+			time.Sleep(context.Period)
+		} else {
+			panic(err)
+		}
+	}
+
+}
+
+// ModuleBuildWorker fetches the build source of the module, builds its image and saves it as the module
+// image. The image is tagged with the hash of the build context. The build log is stored with the module.
+func (context Context) ModuleBuildWorker(module types.Module) {
+
+	// Get the build directory and make sure it is empty.
+	buildPath, err := context.StorageContext.GetModulePath(module.ID, module.Type, ".build")
+	if err != nil {
+		// This means that we cannot access the file system, so we need to panic.
+		panic(err)
+	}
+	if err = storage.ClearDirectory(buildPath); err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(buildPath)
+
+	var buildLog bytes.Buffer
+	contextDir, err := modules.FetchBuildSource(module.SourceAddress, filepath.Join(buildPath, "context"), &buildLog)
+	if err != nil {
+		context.moduleBuildError(errors.WithStack(err), module, buildLog.String())
+		return
+	}
+	contextHash, err := modules.BuildContextHash(contextDir)
+	if err != nil {
+		context.moduleBuildError(errors.WithStack(err), module, buildLog.String())
+		return
+	}
+	tag := modules.ModuleImageTag(module.ID, contextHash)
+
+	context.Logger.WithFields(
+		"module-id", module.ID,
+		"source-address", module.SourceAddress,
+		"build-tag", tag,
+	).WriteInfo("MODULE BUILD STARTED")
+
+	err = modules.BuildModuleImage(context.Runtime, contextDir, tag, &buildLog)
+	if err != nil {
+		context.moduleBuildError(errors.WithStack(err), module, buildLog.String())
+		return
+	}
+
+	// Save the image as a TAR so that it goes through the same validation as the transferred images.
+	path, err := context.StorageContext.GetModulePath(module.ID, module.Type, "")
+	if err != nil {
+		panic(err)
+	}
+	f, err := os.Create(filepath.Join(path, "module.tar"))
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	err = context.Runtime.SaveImage(tag, f)
+	if err != nil {
+		context.moduleBuildError(errors.WithStack(err), module, buildLog.String())
+		return
+	}
+
+	// Unlock the module and update the status.
+	context.repeatUntilSuccess(func() (err error) {
+		_, err = context.ModelContext.UpdateModule(module.ID, model.F{"build-tag": tag, "build-log": truncateBuildLog(buildLog.String())})
+		return
+	})
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UpdateModuleStatus(module.ID, types.ModuleTransferred, "")
+	})
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UnlockModule(module.ID, context.ProcessID)
+	})
+
+	// Log build completion.
+	context.Logger.WithFields(
+		"module-id", module.ID,
+		"source-address", module.SourceAddress,
+		"build-tag", tag,
+	).WriteInfo("MODULE BUILD COMPLETED")
+}
+
+func (context Context) moduleBuildError(err error, module types.Module, buildLog string) {
+	context.Logger.WithFields(
+		"module-id", module.ID,
+		"source", module.Source,
+		"source-address", module.SourceAddress,
+	).WithStack(err).WithError(err).WriteError("MODULE BUILD ERROR")
+
+	context.repeatUntilSuccess(func() (err error) {
+		_, err = context.ModelContext.UpdateModule(module.ID, model.F{"build-log": truncateBuildLog(buildLog)})
+		return
+	})
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UpdateModuleStatus(module.ID, types.ModuleError, err.Error())
+	})
+}
+
+// truncateBuildLog keeps the end of the build log since that is where build errors are reported.
+func truncateBuildLog(buildLog string) string {
+	if len(buildLog) > maxBuildLogSize {
+		return "...\n" + buildLog[len(buildLog)-maxBuildLogSize:]
+	}
+	return buildLog
+}
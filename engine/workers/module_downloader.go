@@ -1,20 +1,16 @@
 package workers
 
 import (
-	ctx "context"
-	"io"
-	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/ds3lab/easeml/engine/database/model"
 	"github.com/ds3lab/easeml/engine/database/model/types"
+	"github.com/ds3lab/easeml/engine/modules"
+	"github.com/ds3lab/easeml/engine/storage"
 
 	"github.com/cavaliercoder/grab"
-	dockertypes "github.com/docker/docker/api/types"
-	"github.com/docker/docker/client"
 	"github.com/otiai10/copy"
 	"github.com/pkg/errors"
 )
@@ -162,28 +158,40 @@ func (context Context) ModuleLocalCopyWorker(module types.Module) {
 	).WriteInfo("MODULE TRANSFER COMPLETED")
 }
 
-// ModuleRegistryPullWorker copies the local module if it is a directory.
+// ModuleRegistryPullWorker pulls the module image from a registry. The image is pinned to its repository
+// digest and its ID is recorded so that workers can verify the image before running it.
 func (context Context) ModuleRegistryPullWorker(module types.Module) {
 
-	// TODO: Get API version automatically.
-	// See: https://stackoverflow.com/a/48638182
-	cli, err := client.NewClientWithOpts(client.WithVersion("1.37"))
-	if err != nil {
-		panic(err)
+	// Decrypt the registry credentials if the module has them.
+	auth := modules.RegistryAuth{Username: module.RegistryUsername}
+	if module.RegistryPassword != "" {
+		key, err := context.StorageContext.GetSecretKey()
+		if err != nil {
+			panic(err)
+		}
+		auth.Password, err = storage.DecryptSecret(key, module.RegistryPassword)
+		if err != nil {
+			context.moduleTransferError(errors.WithStack(err), module)
+			return
+		}
 	}
 
-	// Pull image from registry.
-	resp, err := cli.ImagePull(ctx.Background(), module.SourceAddress, dockertypes.ImagePullOptions{})
-	defer resp.Close()
+	// Pull image from registry and resolve the digest of what was pulled.
+	err := context.Runtime.PullImage(module.SourceAddress, auth)
 	if err != nil {
-		panic(err)
+		context.moduleTransferError(errors.WithStack(err), module)
+		return
 	}
-	body, err := ioutil.ReadAll(resp)
+	ref, err := context.Runtime.ResolveImage(module.SourceAddress)
 	if err != nil {
-		panic(err)
+		context.moduleTransferError(errors.WithStack(err), module)
+		return
+	}
+	digest, err := modules.PinImageDigest(module.SourceAddress, ref)
+	if err != nil {
+		context.moduleTransferError(errors.WithStack(err), module)
+		return
 	}
-	// TODO: Maybe this is not needed. Maybe save it as log. Or maybe just discard.
-	log.Printf(string(body))
 
 	// Get the download target directory.
 	path, err := context.StorageContext.GetModulePath(module.ID, module.Type, "")
@@ -192,25 +200,24 @@ func (context Context) ModuleRegistryPullWorker(module types.Module) {
 		panic(err)
 	}
 
-	// Save the image as a TAR.
-	reader, err := cli.ImageSave(ctx.Background(), []string{module.SourceAddress})
-	if err != nil {
-		panic(err)
-	}
-	defer reader.Close()
-
+	// Save the image as a TAR. The tag may have been moved by another pull since so the image is saved by its ID.
 	f, err := os.Create(filepath.Join(path, "module.tar"))
 	if err != nil {
 		panic(err)
 	}
 	defer f.Close()
 
-	_, err = io.Copy(f, reader)
+	err = context.Runtime.SaveImage(ref.ID, f)
 	if err != nil {
-		panic(err)
+		context.moduleTransferError(errors.WithStack(err), module)
+		return
 	}
 
 	// Unlock the module and update the status.
+	context.repeatUntilSuccess(func() (err error) {
+		_, err = context.ModelContext.UpdateModule(module.ID, model.F{"image-digest": digest, "image-id": ref.ID})
+		return
+	})
 	context.repeatUntilSuccess(func() error {
 		return context.ModelContext.UpdateModuleStatus(module.ID, types.ModuleTransferred, "")
 	})
@@ -223,5 +230,18 @@ func (context Context) ModuleRegistryPullWorker(module types.Module) {
 		"module-id", module.ID,
 		"source", module.Source,
 		"source-address", module.SourceAddress,
+		"image-digest", digest,
 	).WriteInfo("MODULE TRANSFER COMPLETED")
 }
+
+func (context Context) moduleTransferError(err error, module types.Module) {
+	context.Logger.WithFields(
+		"module-id", module.ID,
+		"source", module.Source,
+		"source-address", module.SourceAddress,
+	).WithStack(err).WithError(err).WriteError("MODULE TRANSFER ERROR")
+
+	context.repeatUntilSuccess(func() error {
+		return context.ModelContext.UpdateModuleStatus(module.ID, types.ModuleError, err.Error())
+	})
+}
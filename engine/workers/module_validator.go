@@ -59,8 +59,7 @@ func (context Context) ModuleValidateWorker(module types.Module) {
 	}
 
 	// Load image and get name.
-	imageFilePath := context.getModuleImagePath(module.ID, module.Type)
-	imageName, err := modules.LoadImage(imageFilePath)
+	imageName, err := context.loadModuleImage(module.ID, module.Type)
 	if err != nil {
 		err = errors.WithStack(err)
 		context.moduleValidationError(err, module)
@@ -68,7 +67,7 @@ func (context Context) ModuleValidateWorker(module types.Module) {
 	}
 
 	// Extract image information.
-	_, name, description, jsonSchemaIn, jsonSchemaOut, configSpace, err := modules.InferModuleProperties(imageName)
+	_, name, description, jsonSchemaIn, jsonSchemaOut, configSpace, jsonObjectiveMetadata, err := modules.InferModuleProperties(context.Runtime, imageName)
 	var schemaIn, schemaOut *sch.Schema
 
 	// Unmarshal image schemas if they were found.
@@ -118,12 +117,55 @@ func (context Context) ModuleValidateWorker(module types.Module) {
 		return
 	}
 
+	// The config space is checked upfront so that its errors are reported precisely.
+	if configSpace != "" {
+		var structConfigSpace interface{}
+		err = json.Unmarshal([]byte(configSpace), &structConfigSpace)
+		if err == nil {
+			_, err = modules.LoadConfig(structConfigSpace)
+		}
+		if err != nil {
+			err = errors.WithStack(err)
+			context.moduleValidationError(err, module)
+			return
+		}
+	}
+
+	// The objective metadata tells how the values of the objective are compared.
+	var objectiveMetadata *types.ObjectiveMetadata
+	if module.Type == types.ModuleObjective && jsonObjectiveMetadata != "" {
+		objectiveMetadata, err = modules.ParseObjectiveMetadata(jsonObjectiveMetadata)
+		if err != nil {
+			err = errors.WithStack(err)
+			context.moduleValidationError(err, module)
+			return
+		}
+	}
+
+	// Models are trained and applied to generated data. The full suite can be run with the validate
+	// model command. The report is stored even if the model fails.
+	var conformance *types.ConformanceReport
+	if module.Type == types.ModuleModel {
+		conformance, err = modules.RunConformanceSuite(context.Runtime, imageName, jsonSchemaIn, jsonSchemaOut, configSpace, modules.SmokeConformanceOptions)
+		if err != nil {
+			err = errors.WithStack(err)
+			context.moduleValidationError(err, module)
+			return
+		}
+	}
+
 	// Update the module.
 	var updates = map[string]interface{}{
 		"schema-in":    jsonSchemaIn,
 		"schema-out":   jsonSchemaOut,
 		"config-space": configSpace,
 	}
+	if conformance != nil {
+		updates["conformance"] = conformance
+	}
+	if objectiveMetadata != nil {
+		updates["objective-metadata"] = objectiveMetadata
+	}
 	if module.Name == "" {
 		updates["name"] = name
 	}
@@ -136,6 +178,11 @@ func (context Context) ModuleValidateWorker(module types.Module) {
 		context.moduleValidationError(err, module)
 		return
 	}
+	if context.RequireConformance && conformance != nil && conformance.Passed == false {
+		err = errors.WithStack(modules.ConformanceReportError(conformance))
+		context.moduleValidationError(err, module)
+		return
+	}
 
 	// Update the status.
 	context.repeatUntilSuccess(func() error {
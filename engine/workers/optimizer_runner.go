@@ -26,7 +26,7 @@ func (context Context) OptimizerRunListener(optimizerID string) {
 		// Optimization is triggered when the number of tasks that are scheduled but not running is below
 		// the number of running workers. Of course, we need to have running jobs to even consider scheduling tasks.
 
-		numJobs, err := context.ModelContext.CountJobs(model.F{"status": types.JobRunning})
+		numJobs, err := context.ModelContext.CountJobs(model.F{"kind": types.JobTrain, "status": types.JobRunning})
 		if err != nil {
 			panic(err)
 		}
@@ -76,17 +76,17 @@ func (context Context) OptimizerRunListener(optimizerID string) {
 func (context Context) OptimizerRunWorker(optimizerID string, numProcesses, numTasks int) {
 
 	// Get optimizer image.
-	imageFilePath := context.getModuleImagePath(optimizerID, types.ModuleOptimizer)
-	imageName, err := modules.LoadImage(imageFilePath)
+	imageName, err := context.loadModuleImage(optimizerID, types.ModuleOptimizer)
 	if err != nil {
 		err = errors.WithStack(err)
 		context.Logger.WithFields(
 			"module-id", optimizerID,
 		).WithStack(err).WithError(err).WriteError("OPTIMIZER LOAD ERROR")
+		return
 	}
 
 	// Get all running jobs.
-	jobs, _, err := context.ModelContext.GetJobs(model.F{"status": types.JobRunning}, 0, "", "", "")
+	jobs, _, err := context.ModelContext.GetJobs(model.F{"kind": types.JobTrain, "status": types.JobRunning}, 0, "", "", "")
 	if err != nil {
 		panic(err)
 	}
@@ -106,11 +106,39 @@ func (context Context) OptimizerRunWorker(optimizerID string, numProcesses, numT
 		panic(err) // This means that we cannot access the file system, so we need to panic.
 	}
 
-	jobsDict := map[string]*types.Job{}
+	// Jobs whose config space is exhausted get no new tasks.
+	activeJobs := []*types.Job{}
 	for i := range jobs {
-		jobsDict[jobs[i].ID.Hex()] = &jobs[i]
-		filename := filepath.Join(confPath, jobs[i].ID.Hex()+".json")
-		ioutil.WriteFile(filename, []byte(jobs[i].ConfigSpace), storage.DefaultFilePerm)
+		if context.jobSpaceExhausted(jobs[i]) == false {
+			activeJobs = append(activeJobs, &jobs[i])
+		}
+	}
+	if len(activeJobs) == 0 {
+		return
+	}
+
+	// New tasks are divided among the active jobs. Jobs with a sampler get their share directly from it,
+	// the rest are left to the optimizer.
+	numNewTasks := numProcesses*2 - numTasks
+	numJobTasks := (numNewTasks + len(activeJobs) - 1) / len(activeJobs)
+	optimizerJobs := 0
+	jobsDict := map[string]*types.Job{}
+	for _, job := range activeJobs {
+		if job.Sampler != "" {
+			count := numJobTasks
+			if count > numNewTasks {
+				count = numNewTasks
+			}
+			if count > 0 {
+				context.SampleJobTasks(*job, count)
+				numNewTasks -= count
+			}
+			continue
+		}
+		optimizerJobs++
+		jobsDict[job.ID.Hex()] = job
+		filename := filepath.Join(confPath, job.ID.Hex()+".json")
+		ioutil.WriteFile(filename, []byte(job.ConfigSpace), storage.DefaultFilePerm)
 	}
 
 	// TODO: Dump all tasks of all jobs to history dir.
@@ -120,15 +148,18 @@ func (context Context) OptimizerRunWorker(optimizerID string, numProcesses, numT
 		panic(err)
 	}
 
+	if optimizerJobs == 0 || numNewTasks <= 0 {
+		return
+	}
+
 	// Call optimizer.
-	numNewTasks := numProcesses*2 - numTasks
 	command := []string{
 		"suggest",
 		"--space", modules.MntPrefix + confPath,
 		"--history", modules.MntPrefix + histPath,
 		"--num-tasks", strconv.Itoa(numNewTasks),
 	}
-	outReader, err := modules.RunContainerAndCollectOutput(imageName, nil, command, nil)
+	outReader, err := context.Runtime.Run(imageName, nil, command, nil)
 	if err != nil {
 		err = errors.Wrap(err, "docker container start error")
 		context.Logger.WithFields(
@@ -158,30 +189,210 @@ func (context Context) OptimizerRunWorker(optimizerID string, numProcesses, numT
 		if err != nil {
 			panic(err)
 		}
-		job := jobsDict[conf.ID]
-		modelID := conf.Model.ID
+		job, ok := jobsDict[conf.ID]
+		if ok == false {
+			continue
+		}
+		context.createJobTask(*job, conf.Model.ID, conf.Model.Config, "")
+	}
+}
+
+// SampleJobTasks creates new tasks of a job by drawing configurations from its config space with the
+// sampler of the job. Configurations of existing tasks are skipped in the sequence of the sampler so the
+// tasks of a job do not depend on how they were scheduled.
+func (context Context) SampleJobTasks(job types.Job, numTasks int) {
+
+	tasks, _, err := context.ModelContext.GetTasks(model.F{"job": job.ID}, 0, "", "", "")
+	if err != nil {
+		panic(err)
+	}
+	if job.MaxTasks > 0 && uint64(len(tasks)+numTasks) > job.MaxTasks {
+		numTasks = int(job.MaxTasks) - len(tasks)
+	}
+	if numTasks <= 0 {
+		return
+	}
+	existing := map[string]bool{}
+	for i := range tasks {
+		existing[tasks[i].Model+" "+tasks[i].Config] = true
+	}
+
+	// Configs of models without an active version are skipped as if they existed.
+	taskKey := context.jobTaskKey()
+	exists := func(config modules.ConfigElem) bool {
+		key, ok := taskKey(config)
+		return ok == false || existing[key]
+	}
+
+	space, err := loadJobConfigSpace(job)
+	if err == nil {
+		options := modules.SamplerOptions{Name: job.Sampler, Seed: job.SamplerSeed, Size: int(job.MaxTasks)}
+		var configs []modules.ConfigElem
+		configs, err = modules.SampleNewConfigs(space, options, numTasks, exists)
+		for i := range configs {
+			modelID, modelConfig := splitJobConfig(configs[i])
+			context.createJobTask(job, modelID, modelConfig, job.Sampler)
+		}
+	}
+	if err != nil {
+		err = errors.WithStack(err)
+		context.Logger.WithFields(
+			"job-id", job.ID.Hex(),
+			"sampler", job.Sampler,
+		).WithStack(err).WithError(err).WriteError("SAMPLER ERROR")
+	}
+}
 
-		modelConfig, err := json.Marshal(conf.Model.Config)
+// jobTaskKey returns a function which maps a config of a job to the model version and canonical config of
+// its task. Tasks record the model version they run so the models of the job are resolved to their versions.
+// Configs of older versions do not count for models which follow the latest version. The function returns
+// false for configs of models without an active version.
+func (context Context) jobTaskKey() func(config modules.ConfigElem) (string, bool) {
+	versions := map[string]string{}
+	return func(config modules.ConfigElem) (string, bool) {
+		modelID, modelConfig := splitJobConfig(config)
+		version, ok := versions[modelID]
+		if ok == false {
+			module, err := context.ModelContext.ResolveModuleVersion(modelID)
+			if err == nil && module.Status == types.ModuleActive {
+				version = module.ID
+			}
+			versions[modelID] = version
+		}
+		if version == "" {
+			return "", false
+		}
+		canonicalConfig, err := modules.CanonicalConfig(modelConfig)
 		if err != nil {
-			panic(err)
+			return "", false
 		}
+		return version + " " + canonicalConfig, true
+	}
+}
+
+// createJobTask creates a task given its model and config. The config is stored in canonical form so that
+// tasks which duplicate an existing task of the job are refused. The model is given as it is referenced by
+// the job and the task records the model version it resolves to.
+func (context Context) createJobTask(job types.Job, modelID string, config interface{}, sampler string) {
+
+	canonicalConfig, err := modules.CanonicalConfig(config)
+	if err != nil {
+		panic(err)
+	}
+
+	module, err := context.ModelContext.ResolveModuleVersion(modelID)
+	if errors.Cause(err) == model.ErrNotFound || errors.Cause(err) == model.ErrBadInput || (err == nil && module.Status != types.ModuleActive) {
+		context.Logger.WithFields(
+			"job-id", job.ID.Hex(),
+			"model", modelID,
+		).WriteInfo("NO ACTIVE MODEL VERSION, TASK SKIPPED")
+		return
+	} else if err != nil {
+		panic(err)
+	}
+
+	// Define new task.
+	task := types.Task{
+		Job:    job.ID,
+		Model:  module.ID,
+		Config: canonicalConfig,
+	}
+	task, err = context.ModelContext.CreateTask(task)
+	if errors.Cause(err) == model.ErrDuplicate {
+		context.Logger.WithFields(
+			"job-id", job.ID.Hex(),
+			"model", module.ID,
+			"config", canonicalConfig,
+		).WriteInfo("DUPLICATE TASK SKIPPED")
+		return
+	} else if err != nil {
+		panic(err)
+	}
+
+	context.Logger.WithFields(
+		"task-id", task.ID,
+		"model", task.Model,
+		"dataset", task.Dataset,
+		"objective", task.Objective,
+		"sampler", sampler,
+	).WriteInfo("SCHEDULED NEW TASK")
+}
+
+// jobSpaceExhausted checks if a job has a task for every config of its config space. Only tasks of the
+// active model versions count and configs of models without an active version are not schedulable. Such
+// jobs get no new tasks and are completed once all their tasks have finished.
+func (context Context) jobSpaceExhausted(job types.Job) bool {
 
-		// Define new task.
-		task := types.Task{
-			Job:    job.ID,
-			Model:  modelID,
-			Config: string(modelConfig),
+	space, err := loadJobConfigSpace(job)
+	if err != nil {
+		return false
+	}
+	configs, finite := modules.EnumerateConfigs(space, maxEnumeratedConfigs)
+	if finite == false {
+		return false
+	}
+	tasks, _, err := context.ModelContext.GetTasks(model.F{"job": job.ID}, 0, "", "", "")
+	if err != nil {
+		panic(err)
+	}
+	existing := map[string]bool{}
+	for i := range tasks {
+		existing[tasks[i].Model+" "+tasks[i].Config] = true
+	}
+	taskKey := context.jobTaskKey()
+	for i := range configs {
+		if key, ok := taskKey(configs[i]); ok && existing[key] == false {
+			return false
 		}
-		task, err = context.ModelContext.CreateTask(task)
+	}
+
+	if job.StatusMessage != types.JobSpaceExhausted {
+		context.repeatUntilSuccess(func() error {
+			_, err := context.ModelContext.UpdateJob(job.ID, model.F{"status-message": types.JobSpaceExhausted})
+			return err
+		})
+	}
+
+	numUnfinished := 0
+	for _, status := range []string{types.TaskScheduled, types.TaskRunning, types.TaskPausing, types.TaskPaused} {
+		count, err := context.ModelContext.CountTasks(model.F{"job": job.ID, "status": status})
 		if err != nil {
 			panic(err)
 		}
-
+		numUnfinished += count
+	}
+	if numUnfinished == 0 {
+		context.repeatUntilSuccess(func() error {
+			_, err := context.ModelContext.UpdateJob(job.ID, model.F{"status": types.JobCompleted})
+			return err
+		})
 		context.Logger.WithFields(
-			"task-id", task.ID,
-			"model", task.Model,
-			"dataset", task.Dataset,
-			"objective", task.Objective,
-		).WriteInfo("SCHEDULED NEW TASK")
+			"job-id", job.ID.Hex(),
+			"user", job.User,
+			"dataset", job.Dataset,
+			"objective", job.Objective,
+			"reason", types.JobSpaceExhausted,
+		).WriteInfo("JOB COMPLETED")
+	}
+	return true
+}
+
+// maxEnumeratedConfigs is the size above which config spaces are treated as infinite when checking
+// whether they are exhausted.
+const maxEnumeratedConfigs = 10000
+
+func loadJobConfigSpace(job types.Job) (modules.ConfigElem, error) {
+	var configSpace interface{}
+	err := json.Unmarshal([]byte(job.ConfigSpace), &configSpace)
+	if err != nil {
+		return nil, errors.Wrap(err, "job config space decode error")
 	}
+	return modules.LoadConfig(configSpace)
+}
+
+// splitJobConfig takes a sample of a job config space, which is a choice of models with their config
+// spaces, and returns the model and its config.
+func splitJobConfig(config modules.ConfigElem) (string, interface{}) {
+	sample := config.Dump().(map[string]interface{})["model"].(map[string]interface{})
+	return sample["id"].(string), sample["config"]
 }
@@ -5,7 +5,6 @@ import (
 	"io/ioutil"
 	"log"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
@@ -18,9 +17,33 @@ import (
 )
 
 // TaskRunListener periodically checks if there are any tasks which are in the "scheduled" state
-// which means they are ready to run.
+// which means they are ready to run. Scheduled prediction jobs are run before the tasks as they
+// only need a single run of a trained model.
 func (context Context) TaskRunListener() {
 	for {
+		job, err := context.ModelContext.LockJob(model.F{"kind": types.JobPredict, "status": types.JobScheduled}, context.ProcessID, "", "")
+		if err == nil {
+
+			// Mark the process as working.
+			context.repeatUntilSuccess(func() (err error) {
+				_, err = context.ModelContext.SetProcessStatus(context.ProcessID, types.ProcWorking)
+				return
+			})
+
+			log.Printf("PREDICTION JOB FOUND FOR EXECUTION")
+			context.JobPredictWorker(job)
+
+			// Mark the process as idle.
+			context.repeatUntilSuccess(func() (err error) {
+				_, err = context.ModelContext.SetProcessStatus(context.ProcessID, types.ProcIdle)
+				return
+			})
+			continue
+
+		} else if errors.Cause(err) != model.ErrNotFound {
+			panic(err)
+		}
+
 		task, err := context.ModelContext.LockTask(model.F{"status": types.TaskScheduled}, context.ProcessID, "", "")
 		if err == nil {
 
@@ -70,9 +93,8 @@ func (context Context) TaskRunWorker(task types.Task) {
 	// Ensure task model is loaded. Only needed if the task didn't arrive to the evaluation stage.
 	var modelImageName string
 	if task.Stage != types.TaskStageEvaluating {
-		modelImageFilePath := context.getModuleImagePath(task.Model, types.ModuleModel)
 		var err error
-		modelImageName, err = modules.LoadImage(modelImageFilePath)
+		modelImageName, err = context.loadModuleImage(task.Model, types.ModuleModel)
 		if err != nil {
 			err = errors.WithStack(err)
 			context.Logger.WithFields(
@@ -205,9 +227,12 @@ func (context Context) TaskRunWorker(task types.Task) {
 				"objective", task.Objective,
 			).WriteInfo("MODEL EVALUATING STARTED")
 
-			// Ensure task objective is loaded.
-			objectiveImageFilePath := context.getModuleImagePath(task.Objective, types.ModuleObjective)
-			objectiveImageName, err := modules.LoadImage(objectiveImageFilePath)
+			// Ensure task objective is loaded. Built-in objectives have no image.
+			var objectiveImageName string
+			objective, err := context.ModelContext.GetModuleByID(task.Objective)
+			if err == nil && modules.IsBuiltinObjective(task.Objective) == false {
+				objectiveImageName, err = context.loadModuleImage(task.Objective, types.ModuleObjective)
+			}
 			if err != nil {
 				err = errors.WithStack(err)
 				context.Logger.WithFields(
@@ -222,15 +247,16 @@ func (context Context) TaskRunWorker(task types.Task) {
 			}
 
 			var trainQuality, valQuality float64
+			var valDetails *types.QualityDetails
 
 			// Predict the training set.
-			trainQuality, err = context.runModelEvaluationAndGetQuality(&task, objectiveImageName, paths, datasetPath, "train")
+			trainQuality, _, err = context.runModelEvaluationAndGetQuality(&task, objective.Objective, objectiveImageName, paths, datasetPath, "train")
 			if err != nil {
 				return
 			}
 
 			// Predict the training set.
-			valQuality, err = context.runModelEvaluationAndGetQuality(&task, objectiveImageName, paths, datasetPath, "val")
+			valQuality, valDetails, err = context.runModelEvaluationAndGetQuality(&task, objective.Objective, objectiveImageName, paths, datasetPath, "val")
 			if err != nil {
 				return
 			}
@@ -238,6 +264,9 @@ func (context Context) TaskRunWorker(task types.Task) {
 			// Update task quality.
 			context.repeatUntilSuccess(func() error {
 				updates := model.F{"quality": valQuality, "quality-train": trainQuality}
+				if valDetails != nil {
+					updates["quality-details"] = valDetails
+				}
 				_, err := context.ModelContext.UpdateTask(task.ID, updates)
 				return err
 			})
@@ -380,7 +409,7 @@ func (context Context) runModelTraining(task *types.Task, modelImageName string,
 		"--output", modules.MntPrefix + paths.Parameters,
 		"--metadata", modules.MntPrefix + paths.Metadata,
 	}
-	outReader, err := modules.RunContainerAndCollectOutput(modelImageName, nil, command, context.GpuDevices)
+	outReader, err := context.Runtime.Run(modelImageName, nil, command, context.GpuDevices)
 	if err != nil {
 		err = errors.WithStack(err)
 		context.Logger.WithFields(
@@ -426,7 +455,7 @@ func (context Context) runModelPrediction(task *types.Task, modelImageName strin
 		"--output", modules.MntPrefix + valOutputPath,
 		"--metadata", modules.MntPrefix + paths.Metadata,
 	}
-	outReader, err := modules.RunContainerAndCollectOutput(modelImageName, nil, command, context.GpuDevices)
+	outReader, err := context.Runtime.Run(modelImageName, nil, command, context.GpuDevices)
 	if err != nil {
 		err = errors.WithStack(err)
 		context.Logger.WithFields(
@@ -460,17 +489,59 @@ func (context Context) runModelPrediction(task *types.Task, modelImageName strin
 	return nil
 }
 
-func (context Context) runModelEvaluationAndGetQuality(task *types.Task, objectiveImageName string, paths storage.TaskPaths, datasetPath string, subdir string) (float64, error) {
+// runModelEvaluationAndGetQuality runs the objective on the predictions of a dataset split, dumps its output
+// to the evaluation log and returns the quality and its details.
+func (context Context) runModelEvaluationAndGetQuality(task *types.Task, metadata *types.ObjectiveMetadata, objectiveImageName string, paths storage.TaskPaths, datasetPath string, subdir string) (float64, *types.QualityDetails, error) {
 
 	// Run the evaluation.
 	valDatasetPath := filepath.Join(datasetPath, subdir)
 	valOutputPath := filepath.Join(paths.Predictions, subdir)
+	var lines []string
+	var err error
+	if modules.IsBuiltinObjective(task.Objective) {
+		lines, err = context.runBuiltinEvaluation(task, valDatasetPath, valOutputPath)
+	} else {
+		lines, err = context.runObjectiveEvaluation(task, objectiveImageName, valDatasetPath, valOutputPath)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Dump evaluation lines.
+	evalFileName := filepath.Join(paths.Evaluations, "evals."+subdir+".log")
+	evalLines := strings.Join(lines, "\n")
+	err = ioutil.WriteFile(evalFileName, []byte(evalLines), storage.DefaultFilePerm)
+	if err != nil {
+		panic(err)
+	}
+
+	// The last line should contain the quality.
+	quality, details, err := modules.ParseEvaluationOutput(lines, metadata)
+	if err != nil {
+		err = errors.WithStack(err)
+		context.Logger.WithFields(
+			"module-id", task.Objective,
+			"task-id", task.ID,
+		).WithStack(err).WithError(err).WriteError("OBJECTIVE QUALITY PARSE ERROR")
+
+		context.repeatUntilSuccess(func() error {
+			return context.ModelContext.UpdateTaskStatus(task.ID, types.TaskError, err.Error())
+		})
+		return 0, nil, err
+	}
+
+	return quality, details, nil
+}
+
+// runObjectiveEvaluation runs the eval command of an objective module and returns its output lines.
+func (context Context) runObjectiveEvaluation(task *types.Task, objectiveImageName string, valDatasetPath, valOutputPath string) ([]string, error) {
+
 	command := []string{
 		"eval",
 		"--actual", modules.MntPrefix + valDatasetPath,
 		"--predicted", modules.MntPrefix + valOutputPath,
 	}
-	outReader, err := modules.RunContainerAndCollectOutput(objectiveImageName, nil, command, context.GpuDevices)
+	outReader, err := context.Runtime.Run(objectiveImageName, nil, command, context.GpuDevices)
 	if err != nil {
 		err = errors.WithStack(err)
 		context.Logger.WithFields(
@@ -481,7 +552,7 @@ func (context Context) runModelEvaluationAndGetQuality(task *types.Task, objecti
 		context.repeatUntilSuccess(func() error {
 			return context.ModelContext.UpdateTaskStatus(task.ID, types.TaskError, err.Error())
 		})
-		return 0, err
+		return nil, err
 	}
 	defer outReader.Close()
 
@@ -492,29 +563,26 @@ func (context Context) runModelEvaluationAndGetQuality(task *types.Task, objecti
 		lines = append(lines, scanner.Text())
 	}
 
-	// Dump evaluation lines.
-	evalFileName := filepath.Join(paths.Evaluations, "evals."+subdir+".log")
-	evalLines := strings.Join(lines, "\n")
-	err = ioutil.WriteFile(evalFileName, []byte(evalLines), storage.DefaultFilePerm)
-	if err != nil {
-		panic(err)
-	}
+	return lines, nil
+}
 
-	// The last line should contain the quality.
-	qualityString := strings.TrimSpace(lines[len(lines)-1])
-	quality, err := strconv.ParseFloat(qualityString, 64)
+// runBuiltinEvaluation computes a built-in objective without starting a container and returns the same
+// output lines as objective modules.
+func (context Context) runBuiltinEvaluation(task *types.Task, valDatasetPath, valOutputPath string) ([]string, error) {
+
+	lines, _, err := modules.EvaluateBuiltinObjective(task.Objective, valDatasetPath, valOutputPath)
 	if err != nil {
 		err = errors.WithStack(err)
 		context.Logger.WithFields(
 			"module-id", task.Objective,
 			"task-id", task.ID,
-		).WithStack(err).WithError(err).WriteError("OBJECTIVE QUALITY PARSE ERROR")
+		).WithStack(err).WithError(err).WriteError("BUILTIN OBJECTIVE EVALUATION ERROR")
 
 		context.repeatUntilSuccess(func() error {
 			return context.ModelContext.UpdateTaskStatus(task.ID, types.TaskError, err.Error())
 		})
-		return 0, err
+		return nil, err
 	}
 
-	return quality, nil
+	return lines, nil
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ds3lab/easeml/engine/database/model"
 	"github.com/ds3lab/easeml/engine/logger"
+	"github.com/ds3lab/easeml/engine/modules"
 	"github.com/ds3lab/easeml/engine/storage"
 
 	"github.com/globalsign/mgo/bson"
@@ -24,6 +25,12 @@ type Context struct {
 	Period         time.Duration
 	Logger         logger.Logger
 	GpuDevices     []string
+	Runtime        modules.Runtime
+	S3Endpoint     string
+	S3Region       string
+
+	// RequireConformance makes the module validator reject models which fail the conformance suite.
+	RequireConformance bool
 }
 
 // Clone makes a copy of the mongo session.
@@ -73,6 +80,30 @@ func (context Context) getModuleImagePath(moduleID, moduleType string) string {
 	return matches[0]
 }
 
+// loadModuleImage loads the image of a module and returns its name. If the ID of the image was recorded
+// when it was pulled, the loaded image must have the same ID.
+func (context Context) loadModuleImage(moduleID, moduleType string) (string, error) {
+	imageName, err := context.Runtime.LoadImage(context.getModuleImagePath(moduleID, moduleType))
+	if err != nil {
+		return "", err
+	}
+	module, err := context.ModelContext.GetModuleByID(moduleID)
+	if err != nil {
+		return "", err
+	}
+	if module.ImageID != "" {
+		ref, err := context.Runtime.ResolveImage(imageName)
+		if err != nil {
+			return "", err
+		}
+		if ref.ID != module.ImageID {
+			return "", errors.Errorf("the image of module \"%s\" has the ID \"%s\" but \"%s\" was expected",
+				moduleID, ref.ID, module.ImageID)
+		}
+	}
+	return imageName, nil
+}
+
 func getUploadedFilesDestinationPaths(modulePath string, defaultFilename string) (sourceFilePaths []string, destinationFilePaths []string) {
 
 	sourceFilePaths = []string{}
@@ -43,7 +43,7 @@ func loadClass(root string, relPath string, name string, opener Opener, metadata
 }
 
 func (f *Class) dump(root string, relPath string, name string, opener Opener) error {
-	path := path.Join(relPath, name) + TypeExtensions["category"][f.Subtype()]
+	path := path.Join(relPath, name) + TypeExtensions["class"][f.Subtype()]
 	file, err := opener.GetFile(root, path, false, false)
 	if err != nil {
 		return err
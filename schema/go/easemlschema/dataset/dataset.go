@@ -2,8 +2,12 @@ package dataset // import "github.com/ds3lab/easeml/schema/go/easemlschema/datas
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
 
 	sch "github.com/ds3lab/easeml/schema/go/easemlschema/schema"
 )
@@ -25,10 +29,14 @@ type LoaderFunc func(string, string, string, Opener, bool, string) (File, error)
 
 // TypeExtensions is.
 var TypeExtensions = map[string]map[string]string{
-	"tensor":   map[string]string{"default": ".ten.npy", "csv": ".ten.csv"},
+	"tensor":   map[string]string{"default": ".ten.npy", "npz": ".ten.npz", "coo": ".ten.coo.npz", "csv": ".ten.csv"},
 	"category": map[string]string{"default": ".cat.txt"},
 	"class":    map[string]string{"default": ".class.txt"},
 	"links":    map[string]string{"default": ".links.csv"},
+	"image":    map[string]string{"png": ".png", "jpeg": ".jpeg", "jpg": ".jpg"},
+	"text":     map[string]string{"default": ".text.txt"},
+	"ordinal":  map[string]string{"default": ".ord.txt"},
+	"datetime": map[string]string{"default": ".time.txt"},
 }
 
 // LoaderFunctions is.
@@ -37,6 +45,10 @@ var LoaderFunctions = map[string]LoaderFunc{
 	"category": loadCategory,
 	"class":    loadClass,
 	"links":    loadLinks,
+	"image":    loadImage,
+	"text":     loadText,
+	"ordinal":  loadOrdinal,
+	"datetime": loadDatetime,
 }
 
 // Load is.
@@ -94,6 +106,9 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 	schFanin := false
 	schUndirected := true
 
+	// Images are inferred as tensors. Their height and width may vary between samples.
+	imageNodes := map[string]bool{}
+
 	// Go through all data samples.
 	for sampleName, sample := range samples {
 
@@ -101,6 +116,7 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 		sampleLinks := map[string]*Links{}
 		sampleTensors := map[string]*Tensor{}
 		sampleCategories := map[string]*Category{}
+		sampleValues := map[string]File{}
 		sampleDirectories := map[string]*Directory{}
 		sampleNodes := map[string]interface{}{}
 
@@ -110,10 +126,21 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 				sampleTensors[childName] = tensor
 				sampleNodes[childName] = nil
 
+			} else if image, ok := child.(*Image); ok {
+				sampleTensors[childName] = &Tensor{Name: image.Name, Dimensions: image.Dimensions(), Dtype: "uint8"}
+				sampleNodes[childName] = nil
+				if firstSample {
+					imageNodes[childName] = true
+				}
+
 			} else if category, ok := child.(*Category); ok {
 				sampleCategories[childName] = category
 				sampleNodes[childName] = nil
 
+			} else if isValueFile(child) {
+				sampleValues[childName] = child
+				sampleNodes[childName] = nil
+
 			} else if links, ok := child.(*Links); ok {
 				sampleLinks[childName] = links
 				if firstSample {
@@ -182,7 +209,7 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 				for i := range child.Dimensions {
 					dimensions[i] = &sch.ConstDim{Value: child.Dimensions[i]}
 				}
-				tensor := &sch.Tensor{Dim: dimensions}
+				tensor := &sch.Tensor{Dim: dimensions, Dtype: child.Dtype, Sparse: child.IsSparse()}
 				schNodes[childName] = &sch.Node{IsSingleton: true, Fields: map[string]sch.Field{"field": tensor}}
 
 			} else {
@@ -195,10 +222,20 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 					return nil, err
 
 				}
-				missmatch := len(child.Dimensions) != len(node.Fields["field"].(*sch.Tensor).Dim)
+				tensorField := node.Fields["field"].(*sch.Tensor)
+				missmatch := len(child.Dimensions) != len(tensorField.Dim)
 				if missmatch == false {
 					for i := range child.Dimensions {
-						if node.Fields["field"].(*sch.Tensor).Dim[i].(*sch.ConstDim).Value != child.Dimensions[i] {
+						constDim, ok := tensorField.Dim[i].(*sch.ConstDim)
+						if ok == false {
+							// Only image height and width can be variable.
+							continue
+						}
+						if constDim.Value != child.Dimensions[i] {
+							if imageNodes[childName] && i < 2 {
+								tensorField.Dim[i] = &sch.VarDim{Value: imageDimName(childName, i)}
+								continue
+							}
 							missmatch = true
 							break
 						}
@@ -210,6 +247,18 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 					err := &datasetError{err: msg, path: pth}
 					return nil, err
 				}
+				if node.Fields["field"].(*sch.Tensor).Dtype != child.Dtype {
+					msg := "Tensor dtype mismatch."
+					pth := strings.Join([]string{"", sampleName, childName}, "/")
+					err := &datasetError{err: msg, path: pth}
+					return nil, err
+				}
+				if node.Fields["field"].(*sch.Tensor).Sparse != child.IsSparse() {
+					msg := "Tensor sparsity mismatch."
+					pth := strings.Join([]string{"", sampleName, childName}, "/")
+					err := &datasetError{err: msg, path: pth}
+					return nil, err
+				}
 			}
 		}
 
@@ -252,6 +301,35 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 			}
 		}
 
+		// Handle text, ordinal and datetime singleton nodes.
+		for childName, child := range sampleValues {
+			field, _, msg := inferValueField(child, classSets)
+			if field == nil {
+				pth := strings.Join([]string{"", sampleName, childName}, "/")
+				err := &datasetError{err: msg, path: pth}
+				return nil, err
+			}
+
+			if firstSample {
+				schNodes[childName] = &sch.Node{IsSingleton: true, Fields: map[string]sch.Field{"field": field}}
+			} else {
+				// Verify that the node is the same.
+				node := schNodes[childName]
+				if node.IsSingleton == false || len(node.Fields) > 1 || node.Fields["field"].Type() != field.Type() {
+					msg := fmt.Sprintf("Node '%s' not the same type in all samples.", childName)
+					pth := strings.Join([]string{"", sampleName}, "/")
+					err := &datasetError{err: msg, path: pth}
+					return nil, err
+				}
+				if ordinal, ok := field.(*sch.Ordinal); ok && node.Fields["field"].(*sch.Ordinal).Class != ordinal.Class {
+					msg := "Ordinal class mismatch."
+					pth := strings.Join([]string{"", sampleName, childName}, "/")
+					err := &datasetError{err: msg, path: pth}
+					return nil, err
+				}
+			}
+		}
+
 		// This counts how many instances each node has. It is used to validate link targets.
 		nodeInstanceCount := map[string]int{}
 
@@ -329,7 +407,7 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 						for i := 0; i < len(tensorNodeChild.Dimensions)-1; i++ {
 							dimensions[i] = &sch.ConstDim{Value: tensorNodeChild.Dimensions[i+1]}
 						}
-						fields[nodeChildName] = &sch.Tensor{Dim: dimensions}
+						fields[nodeChildName] = &sch.Tensor{Dim: dimensions, Dtype: tensorNodeChild.Dtype, Sparse: tensorNodeChild.IsSparse()}
 
 					} else {
 						// Verify that the node is the same.
@@ -356,8 +434,26 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 							err := &datasetError{err: msg, path: pth}
 							return nil, err
 						}
+						if tensorField.Dtype != tensorNodeChild.Dtype {
+							msg := "Tensor dtype mismatch."
+							pth := strings.Join([]string{"", sampleName, childName, nodeChildName}, "/")
+							err := &datasetError{err: msg, path: pth}
+							return nil, err
+						}
+						if tensorField.Sparse != tensorNodeChild.IsSparse() {
+							msg := "Tensor sparsity mismatch."
+							pth := strings.Join([]string{"", sampleName, childName, nodeChildName}, "/")
+							err := &datasetError{err: msg, path: pth}
+							return nil, err
+						}
 					}
 
+				} else if _, ok := nodeChild.(*Image); ok {
+					msg := "Images are only supported as singleton nodes."
+					pth := strings.Join([]string{"", sampleName, childName, nodeChildName}, "/")
+					err := &datasetError{err: msg, path: pth}
+					return nil, err
+
 				} else if categoryNodeChild, ok := nodeChild.(*Category); ok {
 
 					// Infer class by finding first class to which the node belongs.
@@ -405,6 +501,43 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 						}
 					}
 
+				} else if isValueFile(nodeChild) {
+
+					field, childCount, msg := inferValueField(nodeChild, classSets)
+					if field == nil {
+						pth := strings.Join([]string{"", sampleName, childName, nodeChildName}, "/")
+						err := &datasetError{err: msg, path: pth}
+						return nil, err
+					}
+
+					// Verify that all node fields have the same number of instances.
+					if count, ok := nodeInstanceCount[childName]; ok && count != childCount {
+						msg := fmt.Sprintf("%s instance count mismatch.", strings.Title(field.Type()))
+						pth := strings.Join([]string{"", sampleName, childName, nodeChildName}, "/")
+						err := &datasetError{err: msg, path: pth}
+						return nil, err
+					}
+					nodeInstanceCount[childName] = childCount
+
+					if firstSample {
+						fields[nodeChildName] = field
+					} else {
+						// Verify that the node is the same.
+						existing := fields[nodeChildName]
+						if existing == nil || existing.Type() != field.Type() {
+							msg := fmt.Sprintf("Node '%s' not the same type in all samples.", childName)
+							pth := strings.Join([]string{"", sampleName, childName, nodeChildName}, "/")
+							err := &datasetError{err: msg, path: pth}
+							return nil, err
+						}
+						if ordinal, ok := field.(*sch.Ordinal); ok && existing.(*sch.Ordinal).Class != ordinal.Class {
+							msg := "Ordinal class mismatch."
+							pth := strings.Join([]string{"", sampleName, childName}, "/")
+							err := &datasetError{err: msg, path: pth}
+							return nil, err
+						}
+					}
+
 				} else {
 					msg := fmt.Sprintf("Files of type '%s' are unexpected in dataset root.", nodeChild.Type())
 					pth := strings.Join([]string{"", sampleName, childName, nodeChildName}, "/")
@@ -538,6 +671,52 @@ func (d *Dataset) InferSchema() (*sch.Schema, Error) {
 	return result, nil
 }
 
+// isValueFile returns true for text, ordinal and datetime files.
+func isValueFile(file File) bool {
+	switch file.(type) {
+	case *Text, *Ordinal, *Datetime:
+		return true
+	}
+	return false
+}
+
+// inferValueField returns the schema field of a text, ordinal or datetime file and the number of values it
+// holds. Ordinals belong to the first class which contains all their values. If no field can be inferred, the
+// returned field is nil and the message explains why.
+func inferValueField(file File, classSets map[string]map[string]interface{}) (field sch.Field, count int, msg string) {
+	switch f := file.(type) {
+	case *Text:
+		return &sch.Text{}, len(f.Texts), ""
+	case *Datetime:
+		return &sch.Datetime{}, len(f.Times), ""
+	case *Ordinal:
+		for className, categorySet := range classSets {
+			if f.belongsToSet(categorySet) {
+				return &sch.Ordinal{Class: className}, len(f.Values), ""
+			}
+		}
+		return nil, 0, "Ordinal file does not match any class."
+	}
+	return nil, 0, fmt.Sprintf("Files of type '%s' are unexpected.", file.Type())
+}
+
+// imageDimName returns the name of the variable height or width dimension of an image node.
+func imageDimName(nodeName string, index int) string {
+	name := []byte(strings.ToLower(nodeName))
+	for i := range name {
+		if (name[i] < 'a' || name[i] > 'z') && (name[i] < '0' || name[i] > '9') {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') {
+		name = append([]byte("img_"), name...)
+	}
+	if index == 0 {
+		return string(name) + "_height"
+	}
+	return string(name) + "_width"
+}
+
 func isSuperset(set1, set2 map[string]interface{}) bool {
 	for k := range set1 {
 		if _, ok := set2[k]; ok == false {
@@ -551,39 +730,194 @@ const defaultRandomStringChars = "abcdefghijklmnopqrstuvwxyz0123456789"
 
 // RandomString returns a random string of given length from a given set of characters.
 func RandomString(size int, chars string) string {
+	return randomString(globalRand, size, chars)
+}
+
+func randomString(rnd *rand.Rand, size int, chars string) string {
 	if chars == "" {
 		chars = defaultRandomStringChars
 	}
 	b := make([]byte, size)
 	for i := range b {
-		b[i] = chars[rand.Intn(len(chars))]
+		b[i] = chars[rnd.Intn(len(chars))]
 	}
 	return string(b)
 }
 
-func randomVector(dimensions []int) []float64 {
-	size := 1
-	for i := range dimensions {
-		size = size * dimensions[i]
-	}
+// globalSource is a random source which draws from the global source of the math/rand package.
+type globalSource struct{}
+
+func (globalSource) Int63() int64 { return rand.Int63() }
+func (globalSource) Seed(int64)   {}
+
+// globalRand draws from the global source and is safe for concurrent use.
+var globalRand = rand.New(globalSource{})
+
+func randomVector(rnd *rand.Rand, dimensions []int) []float64 {
+	size := numElements(dimensions)
 
 	result := make([]float64, size)
 	for i := range result {
-		result[i] = rand.Float64()
+		result[i] = rnd.Float64()
+	}
+	return result
+}
+
+// randomTensorData returns random tensor data of the given schema dtype. The default dtype is float64.
+func randomTensorData(rnd *rand.Rand, dimensions []int, dtype string) interface{} {
+	size := numElements(dimensions)
+
+	switch dtype {
+	case "float32", "float16":
+		result := make([]float32, size)
+		for i := range result {
+			result[i] = rnd.Float32()
+		}
+		return result
+	case "int64":
+		result := make([]int64, size)
+		for i := range result {
+			result[i] = rnd.Int63n(math.MaxInt32)
+		}
+		return result
+	case "int32":
+		result := make([]int32, size)
+		for i := range result {
+			result[i] = rnd.Int31()
+		}
+		return result
+	case "int16":
+		result := make([]int16, size)
+		for i := range result {
+			result[i] = int16(rnd.Intn(math.MaxInt16))
+		}
+		return result
+	case "int8":
+		result := make([]int8, size)
+		for i := range result {
+			result[i] = int8(rnd.Intn(math.MaxInt8))
+		}
+		return result
+	case "uint8":
+		result := make([]uint8, size)
+		for i := range result {
+			result[i] = uint8(rnd.Intn(math.MaxUint8 + 1))
+		}
+		return result
+	default:
+		return randomVector(rnd, dimensions)
+	}
+}
+
+// sparseDensity is the fraction of non-zero elements in generated sparse tensors.
+const sparseDensity = 0.1
+
+// randomTensor returns a tensor with random data which follows the dtype and sparsity of the schema field.
+func randomTensor(rnd *rand.Rand, name string, dimensions []int, field *sch.Tensor) *Tensor {
+	if field.Sparse == false {
+		data := randomTensorData(rnd, dimensions, field.Dtype)
+		return &Tensor{Name: name, Dimensions: dimensions, Data: data, Dtype: tensorDtype(field.Dtype)}
 	}
+
+	// Pick distinct random positions of the non-zero elements.
+	size := numElements(dimensions)
+	nnz := int(float64(size) * sparseDensity)
+	if nnz < 1 {
+		nnz = 1
+	}
+	positions := rnd.Perm(size)[:nnz]
+	sort.Ints(positions)
+
+	coords := make([]int64, nnz*len(dimensions))
+	for i, position := range positions {
+		for j := len(dimensions) - 1; j >= 0; j-- {
+			coords[i*len(dimensions)+j] = int64(position % dimensions[j])
+			position /= dimensions[j]
+		}
+	}
+	values := randomTensorData(rnd, []int{nnz}, field.Dtype)
+	data := &SparseData{Coords: coords, Values: values}
+	return &Tensor{Name: name, Dimensions: dimensions, Data: data, Dtype: tensorDtype(field.Dtype), subtype: "coo"}
+}
+
+func tensorDtype(dtype string) string {
+	if dtype == "" {
+		return "float64"
+	}
+	return dtype
+}
+
+// randomValueFile returns a text, ordinal or datetime file with the given number of random values. For other
+// fields it returns nil.
+func randomValueFile(rnd *rand.Rand, name string, field sch.Field, classes map[string]*Class, count int) File {
+	switch f := field.(type) {
+	case *sch.Text:
+		texts := make([]string, count)
+		for i := range texts {
+			words := make([]string, rnd.Intn(maxRandomTextWords)+1)
+			for j := range words {
+				words[j] = randomString(rnd, rnd.Intn(8)+1, "abcdefghijklmnopqrstuvwxyz")
+			}
+			texts[i] = strings.Join(words, " ")
+		}
+		return &Text{Name: name, Texts: texts}
+
+	case *sch.Ordinal:
+		values := make([]string, count)
+		classCategories := classes[f.Class].Categories
+		for i := range values {
+			values[i] = classCategories[rnd.Intn(len(classCategories))]
+		}
+		return &Ordinal{Name: name, Values: values}
+
+	case *sch.Datetime:
+		times := make([]time.Time, count)
+		for i := range times {
+			times[i] = randomTimeStart.Add(time.Duration(rnd.Int63n(int64(randomTimeRange)))).Truncate(time.Second)
+		}
+		return &Datetime{Name: name, Times: times}
+	}
+	return nil
+}
+
+// maxRandomTextWords is the maximum number of words in generated texts.
+const maxRandomTextWords = 20
+
+// Generated timestamps are picked uniformly at random from a ten year range. They are truncated to seconds
+// to survive the round trip through the RFC 3339 format.
+var randomTimeStart = time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const randomTimeRange = 10 * 365 * 24 * time.Hour
+
+// sortedKeys returns the sorted keys of a map with string keys. Iterating maps in a fixed order makes the
+// generated datasets reproducible given the seed of the random number generator.
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	result := make([]string, len(keys))
+	for i := range keys {
+		result[i] = keys[i].String()
+	}
+	sort.Strings(result)
 	return result
 }
 
 // GenerateFromSchema is.
 func GenerateFromSchema(root string, schema *sch.Schema, sampleNames []string, numNodeInstances int) (*Dataset, error) {
+	return GenerateFromSchemaWithRand(root, schema, sampleNames, numNodeInstances, globalRand)
+}
+
+// GenerateFromSchemaWithRand generates a dataset like GenerateFromSchema but draws all random values from
+// the given generator. Given a seeded generator the generated dataset is reproducible.
+func GenerateFromSchemaWithRand(root string, schema *sch.Schema, sampleNames []string, numNodeInstances int, rnd *rand.Rand) (*Dataset, error) {
 
 	// Generate classes.
 	classes := map[string]*Class{}
-	for className, class := range schema.Classes {
+	for _, className := range sortedKeys(schema.Classes) {
+		class := schema.Classes[className]
 		dim := class.Dim.(*sch.ConstDim).Value
 		categories := make([]string, dim)
 		for i := 0; i < dim; i++ {
-			categories[i] = RandomString(16, defaultRandomStringChars)
+			categories[i] = randomString(rnd, 16, defaultRandomStringChars)
 		}
 		classes[className] = &Class{Categories: categories}
 	}
@@ -594,7 +928,8 @@ func GenerateFromSchema(root string, schema *sch.Schema, sampleNames []string, n
 		nodes := map[string]File{}
 
 		// Generate nodes.
-		for nodeName, node := range schema.Nodes {
+		for _, nodeName := range sortedKeys(schema.Nodes) {
+			node := schema.Nodes[nodeName]
 
 			if node.IsSingleton {
 
@@ -611,22 +946,26 @@ func GenerateFromSchema(root string, schema *sch.Schema, sampleNames []string, n
 						dim := tensorField.Dim[i].(*sch.ConstDim)
 						dimensions[i] = dim.Value
 					}
-					data := randomVector(dimensions)
-					nodes[nodeName] = &Tensor{Name: nodeName, Dimensions: dimensions, Data: data}
+					nodes[nodeName] = randomTensor(rnd, nodeName, dimensions, tensorField)
 
 				} else if categoryField, ok := field.(*sch.Category); ok {
 					// Generate singleton category.
 					categories := make([]string, 1)
 					classCategories := classes[categoryField.Class].Categories
-					categories[0] = classCategories[rand.Intn(len(classCategories))]
+					categories[0] = classCategories[rnd.Intn(len(classCategories))]
 					nodes[nodeName] = &Category{Name: nodeName, Categories: categories}
+
+				} else if value := randomValueFile(rnd, nodeName, field, classes, 1); value != nil {
+					// Generate singleton text, ordinal or datetime.
+					nodes[nodeName] = value
 				}
 
 			} else {
 
 				nodeChildren := map[string]File{}
 
-				for fieldName, field := range node.Fields {
+				for _, fieldName := range sortedKeys(node.Fields) {
+					field := node.Fields[fieldName]
 
 					if tensorField, ok := field.(*sch.Tensor); ok {
 						// Generate non-singleton tensor.
@@ -636,17 +975,20 @@ func GenerateFromSchema(root string, schema *sch.Schema, sampleNames []string, n
 							dim := tensorField.Dim[i].(*sch.ConstDim)
 							dimensions[i+1] = dim.Value
 						}
-						data := randomVector(dimensions)
-						nodeChildren[fieldName] = &Tensor{Name: fieldName, Dimensions: dimensions, Data: data}
+						nodeChildren[fieldName] = randomTensor(rnd, fieldName, dimensions, tensorField)
 
 					} else if categoryField, ok := field.(*sch.Category); ok {
 						// Generate non-singleton category.
 						categories := make([]string, numNodeInstances)
 						classCategories := classes[categoryField.Class].Categories
 						for i := range categories {
-							categories[i] = classCategories[rand.Intn(len(classCategories))]
+							categories[i] = classCategories[rnd.Intn(len(classCategories))]
 						}
 						nodeChildren[fieldName] = &Category{Name: fieldName, Categories: categories}
+
+					} else if value := randomValueFile(rnd, fieldName, field, classes, numNodeInstances); value != nil {
+						// Generate non-singleton text, ordinal or datetime.
+						nodeChildren[fieldName] = value
 					}
 				}
 
@@ -659,15 +1001,15 @@ func GenerateFromSchema(root string, schema *sch.Schema, sampleNames []string, n
 		links := map[Link]interface{}{}
 		allInstances := map[string][]int{}
 		countIn, countOut := map[InstanceID]int{}, map[InstanceID]int{}
-		for nodeName, node := range schema.Nodes {
-			if node.IsSingleton == false {
+		for _, nodeName := range sortedKeys(schema.Nodes) {
+			if schema.Nodes[nodeName].IsSingleton == false {
 				// Generate a random permutation of indices.
 				randIndices := make([]int, numNodeInstances)
 				for i := range randIndices {
 					randIndices[i] = i
 				}
 				for i := range randIndices {
-					j := rand.Intn(len(randIndices))
+					j := rnd.Intn(len(randIndices))
 					t := randIndices[j]
 					randIndices[j] = randIndices[i]
 					randIndices[i] = t
@@ -677,16 +1019,17 @@ func GenerateFromSchema(root string, schema *sch.Schema, sampleNames []string, n
 		}
 		maxIdxIn := map[destCountKey]int{}
 
-		for nodeName, instances := range allInstances {
-			for i := range instances {
-				for targetName, link := range schema.Nodes[nodeName].Links {
+		for _, nodeName := range sortedKeys(allInstances) {
+			for i := range allInstances[nodeName] {
+				for _, targetName := range sortedKeys(schema.Nodes[nodeName].Links) {
+					link := schema.Nodes[nodeName].Links[targetName]
 
 					lBound := link.LBound
 					uBound := link.UBound
 					if uBound > numNodeInstances || uBound <= 0 {
 						uBound = numNodeInstances
 					}
-					count := rand.Intn(uBound-lBound+1) + lBound - countOut[InstanceID{Node: nodeName, Index: i}]
+					count := rnd.Intn(uBound-lBound+1) + lBound - countOut[InstanceID{Node: nodeName, Index: i}]
 
 					// If there are no links to create, simply skip.
 					if count <= 0 {
@@ -2,9 +2,14 @@ package dataset
 
 import (
 	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
 	"io/ioutil"
 	"log"
+	"os"
 	"path"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -190,3 +195,277 @@ func testDatasetGenerate(exampleFile string) func(*testing.T) {
 		}
 	}
 }
+
+func TestTensorDtypes(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "easeml-dataset")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tensors := map[string]*Tensor{
+		"f8": &Tensor{Dimensions: []int{2, 2}, Data: []float64{1, 2, 3, 4}, Dtype: "float64"},
+		"f4": &Tensor{Dimensions: []int{2, 2}, Data: []float32{1, 2, 3, 4}, Dtype: "float32"},
+		"f2": &Tensor{Dimensions: []int{2, 2}, Data: []float32{0.5, -2, 1024, 0}, Dtype: "float16"},
+		"i4": &Tensor{Dimensions: []int{2, 2}, Data: []int32{1, -2, 3, -4}, Dtype: "int32"},
+		"u1": &Tensor{Dimensions: []int{2, 2}, Data: []uint8{0, 1, 128, 255}, Dtype: "uint8"},
+		"z4": &Tensor{Dimensions: []int{2, 2}, Data: []float32{1, 2, 3, 4}, Dtype: "float32", subtype: "npz"},
+	}
+	children := map[string]File{}
+	for k, v := range tensors {
+		children[k] = v
+	}
+	dataset := &Dataset{Root: dir, Directory: Directory{Children: map[string]File{
+		"sample1": &Directory{Name: "sample1", Children: children},
+	}}}
+	err = dataset.Dump(dir, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(dir, false, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := loaded.Children["sample1"].(*Directory)
+	for k, v := range tensors {
+		tensor, ok := sample.Children[k].(*Tensor)
+		if ok == false {
+			t.Errorf("Tensor '%s' not loaded.", k)
+			continue
+		}
+		if tensor.Dtype != v.Dtype || tensor.Subtype() != v.Subtype() {
+			t.Errorf("Tensor '%s' loaded as %s/%s.", k, tensor.Dtype, tensor.Subtype())
+		}
+		if reflect.DeepEqual(tensor.Data, v.Data) == false {
+			t.Errorf("Tensor '%s' data mismatch: %v", k, tensor.Data)
+		}
+	}
+
+	schema, err := loaded.InferSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dtype := schema.Nodes["u1"].Fields["field"].(*sch.Tensor).Dtype; dtype != "uint8" {
+		t.Errorf("Inferred dtype '%s' instead of 'uint8'.", dtype)
+	}
+}
+
+func TestImageInfer(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "easeml-dataset")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Images with a fixed shape and images whose height and width vary between samples.
+	newRGB := func(h, w int) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{10, 20, 30, 255}), image.Point{}, draw.Src)
+		return img
+	}
+	dataset := &Dataset{Root: dir, Directory: Directory{Children: map[string]File{
+		"sample1": &Directory{Children: map[string]File{
+			"photo": &Image{Data: newRGB(4, 6), subtype: "jpg"},
+			"mask":  &Image{Data: image.NewGray(image.Rect(0, 0, 8, 8))},
+		}},
+		"sample2": &Directory{Children: map[string]File{
+			"photo": &Image{Data: newRGB(5, 7), subtype: "jpg"},
+			"mask":  &Image{Data: image.NewGray(image.Rect(0, 0, 8, 8))},
+		}},
+	}}}
+	err = dataset.Dump(dir, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(dir, true, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask := loaded.Children["sample1"].(*Directory).Children["mask"].(*Image)
+	if reflect.DeepEqual(mask.Dimensions(), []int{8, 8, 1}) == false {
+		t.Errorf("Mask loaded with shape %v.", mask.Dimensions())
+	}
+
+	srcSchema, err := loaded.InferSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dst map[string]interface{}
+	err = json.Unmarshal([]byte(`{"nodes": {
+		"photo": {"singleton": true, "type": "tensor", "dim": ["h", "w", 3], "dtype": "uint8"},
+		"mask": {"singleton": true, "type": "tensor", "dim": [8, 8, 1]}
+	}}`), &dst)
+	if err != nil {
+		panic(err)
+	}
+	dstSchema, err := sch.Load(dst)
+	if err != nil {
+		panic(err)
+	}
+	if match, _ := dstSchema.Match(srcSchema, false); match == false {
+		t.Error("Schema match failed.")
+	}
+	photo := srcSchema.Nodes["photo"].Fields["field"].(*sch.Tensor)
+	if photo.Dim[0].IsVariable() == false || photo.Dim[1].IsVariable() == false || photo.Dim[2].IsVariable() {
+		t.Error("Expected variable image height and width and a constant channel count.")
+	}
+}
+
+func TestSparseTensor(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "easeml-dataset")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Generate a dataset with a sparse tensor field and write it out.
+	var input map[string]interface{}
+	err = json.Unmarshal([]byte(`{"nodes": {
+		"words": {"singleton": true, "type": "tensor", "dim": [100], "sparse": true},
+		"ratings": {"singleton": true, "type": "tensor", "dim": [10, 20], "dtype": "float32", "sparse": true}
+	}}`), &input)
+	if err != nil {
+		panic(err)
+	}
+	schema, err := sch.Load(input)
+	if err != nil {
+		panic(err)
+	}
+	generated, err := GenerateFromSchema(dir, schema, []string{"s1", "s2"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = generated.Dump(dir, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Load it back and compare.
+	loaded, err := Load(dir, false, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sample := range []string{"s1", "s2"} {
+		for _, node := range []string{"words", "ratings"} {
+			expected := generated.Children[sample].(*Directory).Children[node].(*Tensor)
+			actual := loaded.Children[sample].(*Directory).Children[node].(*Tensor)
+			if actual.IsSparse() == false || reflect.DeepEqual(expected.Data, actual.Data) == false {
+				t.Errorf("Sparse tensor '%s/%s' was not preserved.", sample, node)
+			}
+		}
+	}
+
+	// The inferred schema must record the sparsity.
+	inferred, err := loaded.InferSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match, _ := schema.Match(inferred, false); match == false {
+		t.Error("Schema match failed.")
+	}
+	if inferred.Nodes["words"].Fields["field"].(*sch.Tensor).Sparse == false {
+		t.Error("Inferred tensor is not sparse.")
+	}
+}
+
+func TestValueFields(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "easeml-dataset")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Generate a dataset with text, ordinal and datetime fields and write it out.
+	var input map[string]interface{}
+	err = json.Unmarshal([]byte(`{"nodes": {
+		"review": {"singleton": true, "type": "text"},
+		"rating": {"singleton": true, "type": "ordinal", "class": "stars"},
+		"events": {"fields": {
+			"time": {"type": "datetime"},
+			"note": {"type": "text"},
+			"level": {"type": "ordinal", "class": "stars"}
+		}, "links": {"events": 1}}
+	}, "classes": {"stars": {"dim": 5}}}`), &input)
+	if err != nil {
+		panic(err)
+	}
+	schema, err := sch.Load(input)
+	if err != nil {
+		panic(err)
+	}
+	generated, err := GenerateFromSchema(dir, schema, []string{"s1", "s2"}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = generated.Dump(dir, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Load it back and compare.
+	loaded, err := Load(dir, false, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sample := range []string{"s1", "s2"} {
+		expected := generated.Children[sample].(*Directory)
+		actual := loaded.Children[sample].(*Directory)
+		if reflect.DeepEqual(expected.Children["review"], actual.Children["review"]) == false {
+			t.Errorf("Text '%s/review' was not preserved.", sample)
+		}
+		if reflect.DeepEqual(expected.Children["rating"], actual.Children["rating"]) == false {
+			t.Errorf("Ordinal '%s/rating' was not preserved.", sample)
+		}
+		expectedTimes := expected.Children["events"].(*Directory).Children["time"].(*Datetime).Times
+		actualTimes := actual.Children["events"].(*Directory).Children["time"].(*Datetime).Times
+		for i := range expectedTimes {
+			if expectedTimes[i].Equal(actualTimes[i]) == false {
+				t.Errorf("Datetime '%s/events/time' was not preserved.", sample)
+			}
+		}
+	}
+
+	// The inferred schema must match the original one.
+	inferred, err := loaded.InferSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match, _ := schema.Match(inferred, false); match == false {
+		t.Error("Schema match failed.")
+	}
+	if _, ok := inferred.Nodes["events"].Fields["level"].(*sch.Ordinal); ok == false {
+		t.Error("Inferred field is not an ordinal.")
+	}
+
+	// Timestamps must follow RFC 3339.
+	err = ioutil.WriteFile(path.Join(dir, "s1", "events", "time.time.txt"), []byte("yesterday\n"), 0644)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := Load(dir, false, DefaultOpener{}); err == nil {
+		t.Error("Expected an error for an invalid timestamp.")
+	}
+
+	// Lines which are too long are not truncated.
+	long := strings.Repeat("x", maxValueLength+1) + "\n"
+	err = ioutil.WriteFile(path.Join(dir, "s1", "rating.ord.txt"), []byte(long), 0644)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := loadOrdinal(dir, "s1", "rating", DefaultOpener{}, false, "default"); err == nil {
+		t.Error("Expected an error for an ordinal line which is too long.")
+	}
+	err = ioutil.WriteFile(path.Join(dir, "s1", "events", "time.time.txt"), []byte(long), 0644)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := loadDatetime(dir, "s1/events", "time", DefaultOpener{}, false, "default"); err == nil {
+		t.Error("Expected an error for a datetime line which is too long.")
+	}
+}
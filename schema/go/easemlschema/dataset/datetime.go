@@ -0,0 +1,62 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Datetime is a file holding one RFC 3339 timestamp per line.
+type Datetime struct {
+	Name  string
+	Times []time.Time
+}
+
+// Type is.
+func (f Datetime) Type() string { return "datetime" }
+
+// Subtype is.
+func (f Datetime) Subtype() string { return "default" }
+
+func loadDatetime(root string, relPath string, name string, opener Opener, metadataOnly bool, subtype string) (File, error) {
+	path := path.Join(relPath, name+TypeExtensions["datetime"][subtype])
+	file, err := opener.GetFile(root, path, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	times := []time.Time{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 4096), maxValueLength)
+	for line := 1; scanner.Scan(); line++ {
+		value, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(scanner.Text()))
+		if err != nil {
+			msg := fmt.Sprintf("Line %d of the datetime file is not an RFC 3339 timestamp.", line)
+			return nil, &datasetError{err: msg, path: path}
+		}
+		times = append(times, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &datasetError{err: "Datetime file could not be read. " + err.Error(), path: path}
+	}
+
+	return &Datetime{Name: name, Times: times}, nil
+}
+
+func (f *Datetime) dump(root string, relPath string, name string, opener Opener) error {
+	path := path.Join(relPath, name) + TypeExtensions["datetime"][f.Subtype()]
+	file, err := opener.GetFile(root, path, false, false)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for i := range f.Times {
+		fmt.Fprintln(writer, f.Times[i].Format(time.RFC3339Nano))
+	}
+	return writer.Flush()
+}
@@ -75,6 +75,14 @@ func (f *Directory) dump(root string, relPath string, name string, opener Opener
 			err = category.dump(root, path, k, opener)
 		} else if links, ok := v.(*Links); ok {
 			err = links.dump(root, path, k, opener)
+		} else if image, ok := v.(*Image); ok {
+			err = image.dump(root, path, k, opener)
+		} else if text, ok := v.(*Text); ok {
+			err = text.dump(root, path, k, opener)
+		} else if ordinal, ok := v.(*Ordinal); ok {
+			err = ordinal.dump(root, path, k, opener)
+		} else if datetime, ok := v.(*Datetime); ok {
+			err = datetime.dump(root, path, k, opener)
 		} else if class, ok := v.(*Class); ok {
 			err = class.dump(root, path, k, opener)
 		} else if directory, ok := v.(*Directory); ok {
@@ -82,6 +90,9 @@ func (f *Directory) dump(root string, relPath string, name string, opener Opener
 		} else {
 			panic("unexpected child type")
 		}
+		if err != nil {
+			return
+		}
 	}
 
 	return
@@ -0,0 +1,102 @@
+package dataset
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"path"
+)
+
+// Image is.
+type Image struct {
+	Name     string
+	Height   int
+	Width    int
+	Channels int
+	Data     image.Image
+	subtype  string
+}
+
+// Type is.
+func (f Image) Type() string { return "image" }
+
+// Subtype is.
+func (f Image) Subtype() string {
+	if f.subtype == "" {
+		return "png"
+	}
+	return f.subtype
+}
+
+// Dimensions returns the shape of the image as (height, width, channels).
+func (f Image) Dimensions() []int {
+	return []int{f.Height, f.Width, f.Channels}
+}
+
+func loadImage(root string, relPath string, name string, opener Opener, metadataOnly bool, subtype string) (File, error) {
+	path := path.Join(relPath, name+TypeExtensions["image"][subtype])
+	file, err := opener.GetFile(root, path, true, true)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// In metadata only mode we only read the image header.
+	if metadataOnly {
+		config, format, err := image.DecodeConfig(file)
+		if err != nil || (format == "png") != (subtype == "png") {
+			return nil, &datasetError{err: "Image file is not a valid PNG or JPEG image.", path: path}
+		}
+		channels := imageChannels(config.ColorModel)
+		return &Image{Name: name, Height: config.Height, Width: config.Width, Channels: channels, subtype: subtype}, nil
+	}
+
+	data, format, err := image.Decode(file)
+	if err != nil || (format == "png") != (subtype == "png") {
+		return nil, &datasetError{err: "Image file is not a valid PNG or JPEG image.", path: path}
+	}
+	bounds := data.Bounds()
+	channels := imageChannels(data.ColorModel())
+	return &Image{Name: name, Height: bounds.Dy(), Width: bounds.Dx(), Channels: channels, Data: data, subtype: subtype}, nil
+}
+
+func (f *Image) dump(root string, relPath string, name string, opener Opener) error {
+	path := path.Join(relPath, name) + TypeExtensions["image"][f.Subtype()]
+	if f.Data == nil {
+		return &datasetError{err: "Cannot write image without data.", path: path}
+	}
+	file, err := opener.GetFile(root, path, false, true)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if f.Subtype() == "png" {
+		return png.Encode(file, f.Data)
+	}
+	return jpeg.Encode(file, f.Data, nil)
+}
+
+// imageChannels returns the number of channels of images with the given color model. The PNG decoder
+// reports opaque truecolor images with the RGBA model and images with an alpha channel with the NRGBA model.
+func imageChannels(model color.Model) int {
+	switch model {
+	case color.GrayModel, color.Gray16Model:
+		return 1
+	case color.RGBAModel, color.RGBA64Model, color.YCbCrModel:
+		return 3
+	case color.NRGBAModel, color.NRGBA64Model, color.CMYKModel:
+		return 4
+	}
+
+	// Paletted images have an alpha channel only if some palette color is transparent.
+	if palette, ok := model.(color.Palette); ok {
+		for i := range palette {
+			if _, _, _, a := palette[i].RGBA(); a != 0xffff {
+				return 4
+			}
+		}
+	}
+	return 3
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -113,9 +114,16 @@ func (f *Links) dump(root string, relPath string, name string, opener Opener) er
 	}
 	defer file.Close()
 
-	writer := bufio.NewWriter(file)
+	// Links are written in a fixed order so that dumps of the same links are identical.
+	lines := make([]string, 0, len(f.Links))
 	for l := range f.Links {
-		fmt.Fprintln(writer, l.dump())
+		lines = append(lines, l.dump())
+	}
+	sort.Strings(lines)
+
+	writer := bufio.NewWriter(file)
+	for i := range lines {
+		fmt.Fprintln(writer, lines[i])
 	}
 	return writer.Flush()
 }
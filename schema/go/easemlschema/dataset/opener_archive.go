@@ -0,0 +1,472 @@
+package dataset
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveOpener is a read only opener which reads dataset files directly from an archive. Entries are
+// read lazily, the archive is never extracted or loaded into memory as a whole.
+type ArchiveOpener interface {
+	Opener
+
+	// Files returns the paths of all regular files in the archive.
+	Files() []string
+
+	// Walk calls the given function for each regular file in the archive in the order in which the
+	// files are stored. The reader is only valid until the function returns.
+	Walk(fn func(name string, r io.Reader) error) error
+
+	// Close releases all resources held by the opener.
+	Close() error
+}
+
+// Decompressor wraps a compressed stream into a reader which returns the decompressed data.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+// GzipDecompressor decompresses gzip streams.
+func GzipDecompressor(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// ZstdDecompressor decompresses zstd streams.
+func ZstdDecompressor(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
+
+// DetectArchiveFormat returns the format of the archive at the given path based on its content. The
+// result is one of "zip", "tar", "tar.gz" and "tar.zst", or an empty string if the format is not supported.
+func DetectArchiveFormat(archivePath string) (string, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	header := make([]byte, 265)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")) || bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return "zip", nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return "tar.gz", nil
+	case bytes.HasPrefix(header, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return "tar.zst", nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return "tar", nil
+	}
+	return "", nil
+}
+
+// OpenArchiveOpener opens the archive at the given path. The format is detected with DetectArchiveFormat.
+func OpenArchiveOpener(archivePath string) (ArchiveOpener, error) {
+	format, err := DetectArchiveFormat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "zip":
+		return OpenZipOpener(archivePath)
+	case "tar":
+		return OpenCompressedTarOpener(archivePath, nil)
+	case "tar.gz":
+		return OpenCompressedTarOpener(archivePath, GzipDecompressor)
+	case "tar.zst":
+		return OpenCompressedTarOpener(archivePath, ZstdDecompressor)
+	}
+	return nil, errors.New("unsupported archive format: " + archivePath)
+}
+
+// archiveIndex keeps the directory structure of an archive without any of the file contents. Files are
+// mapped to the position of their entry in the archive and directories list their children in the order
+// in which they first appear in the archive.
+type archiveIndex struct {
+	files map[string]int
+	dirs  map[string][]string
+}
+
+func newArchiveIndex() archiveIndex {
+	return archiveIndex{files: map[string]int{}, dirs: map[string][]string{"": {}}}
+}
+
+// cleanEntryName normalizes the name of an archive entry. Names which could escape the extraction
+// directory are rejected.
+func cleanEntryName(name string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+name), "/")
+	if path.IsAbs(name) || strings.HasPrefix(path.Clean(name), "../") || path.Clean(name) == ".." {
+		return "", errors.New("unsafe archive entry path: " + name)
+	}
+	return cleaned, nil
+}
+
+func (index archiveIndex) addDir(dirpath string) error {
+	if _, ok := index.files[dirpath]; ok {
+		return errors.New("name conflict: " + dirpath)
+	}
+	if _, ok := index.dirs[dirpath]; ok {
+		return nil
+	}
+	index.dirs[dirpath] = []string{}
+	parent, name := path.Split(dirpath)
+	parent = strings.TrimSuffix(parent, "/")
+	if err := index.addDir(parent); err != nil {
+		return err
+	}
+	index.dirs[parent] = append(index.dirs[parent], name)
+	return nil
+}
+
+func (index archiveIndex) addFile(filepath string, position int) error {
+	_, isDir := index.dirs[filepath]
+	if _, isFile := index.files[filepath]; isDir || isFile {
+		return errors.New("name conflict: " + filepath)
+	}
+	parent, name := path.Split(filepath)
+	parent = strings.TrimSuffix(parent, "/")
+	if err := index.addDir(parent); err != nil {
+		return err
+	}
+	index.files[filepath] = position
+	index.dirs[parent] = append(index.dirs[parent], name)
+	return nil
+}
+
+func (index archiveIndex) getDir(root string, relPath string, readOnly bool) ([]string, error) {
+	if readOnly == false {
+		return nil, errors.New("archive openers are read only")
+	}
+	fullPath := strings.TrimPrefix(path.Clean("/"+path.Join(root, relPath)), "/")
+	dir, ok := index.dirs[fullPath]
+	if ok == false {
+		return nil, errors.New("directory not found: " + fullPath)
+	}
+	return append([]string{}, dir...), nil
+}
+
+func (index archiveIndex) getFilePath(root string, relPath string, readOnly bool) (string, error) {
+	if readOnly == false {
+		return "", errors.New("archive openers are read only")
+	}
+	fullPath := strings.TrimPrefix(path.Clean("/"+path.Join(root, relPath)), "/")
+	if _, ok := index.files[fullPath]; ok == false {
+		return "", errors.New("file not found: " + fullPath)
+	}
+	return fullPath, nil
+}
+
+func (index archiveIndex) sortedFiles() []string {
+	result := make([]string, 0, len(index.files))
+	for k := range index.files {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// entryReader exposes a single archive entry as a read only io.ReadWriteCloser.
+type entryReader struct {
+	io.Reader
+	close func() error
+}
+
+func (r *entryReader) Write(p []byte) (n int, err error) {
+	return 0, errors.New("archive openers are read only")
+}
+
+func (r *entryReader) Close() error {
+	if r.close != nil {
+		return r.close()
+	}
+	return nil
+}
+
+// ZipOpener reads dataset files from a zip archive. Entries are decompressed on access.
+type ZipOpener struct {
+	file   *os.File
+	reader *zip.Reader
+	index  archiveIndex
+	// entries maps cleaned entry names to the corresponding zip records.
+	entries map[string]*zip.File
+}
+
+// OpenZipOpener opens the zip archive at the given path and indexes its entries.
+func OpenZipOpener(archivePath string) (*ZipOpener, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	reader, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	opener := &ZipOpener{file: file, reader: reader, index: newArchiveIndex(), entries: map[string]*zip.File{}}
+	for i, f := range reader.File {
+		name, err := cleanEntryName(f.Name)
+		if err == nil {
+			if f.FileInfo().IsDir() {
+				err = opener.index.addDir(name)
+			} else if f.Mode().IsRegular() {
+				err = opener.index.addFile(name, i)
+				opener.entries[name] = f
+			}
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return opener, nil
+}
+
+// GetFile opens a file in the archive. Only read only access is supported.
+func (opener *ZipOpener) GetFile(root string, relPath string, readOnly bool, binary bool) (io.ReadWriteCloser, error) {
+	name, err := opener.index.getFilePath(root, relPath, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := opener.entries[name].Open()
+	if err != nil {
+		return nil, err
+	}
+	return &entryReader{Reader: reader, close: reader.Close}, nil
+}
+
+// GetDir lists a directory in the archive. Only read only access is supported.
+func (opener *ZipOpener) GetDir(root string, relPath string, readOnly bool) ([]string, error) {
+	return opener.index.getDir(root, relPath, readOnly)
+}
+
+// Files returns the paths of all regular files in the archive.
+func (opener *ZipOpener) Files() []string {
+	return opener.index.sortedFiles()
+}
+
+// Walk calls the given function for each regular file in the archive. The checksum of each entry is
+// verified once it has been read completely.
+func (opener *ZipOpener) Walk(fn func(name string, r io.Reader) error) error {
+	for _, f := range opener.reader.File {
+		name, _ := cleanEntryName(f.Name)
+		if opener.entries[name] != f {
+			continue
+		}
+		reader, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = fn(name, reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying archive file.
+func (opener *ZipOpener) Close() error {
+	return opener.file.Close()
+}
+
+// CompressedTarOpener reads dataset files from a tar archive which is optionally compressed. Compressed
+// tar archives cannot be accessed randomly, so the opener keeps the archive open at the entry after the
+// last file that was read and only starts from the beginning when an earlier file is requested. Directories
+// are listed in the order of the archive, which lets a dataset be loaded in a single pass over the archive.
+// Only the archive structure is kept in memory. A file is only valid until the next file is opened and the
+// opener must not be used concurrently.
+type CompressedTarOpener struct {
+	path       string
+	decompress Decompressor
+	index      archiveIndex
+	cursor     *tarCursor
+}
+
+// tarCursor is an open sequential reader of a tar archive.
+type tarCursor struct {
+	reader  *tar.Reader
+	stream  io.Reader
+	closers multiCloser
+	// next is the position of the entry which is returned by the next call to reader.Next.
+	next int
+}
+
+// OpenCompressedTarOpener opens the tar archive at the given path and indexes its entries. If the
+// decompressor is nil the archive is assumed to be uncompressed.
+func OpenCompressedTarOpener(archivePath string, decompress Decompressor) (*CompressedTarOpener, error) {
+	opener := &CompressedTarOpener{path: archivePath, decompress: decompress, index: newArchiveIndex()}
+	err := opener.scan(func(position int, name string, header *tar.Header, r io.Reader) error {
+		switch header.Typeflag {
+		case tar.TypeDir:
+			return opener.index.addDir(name)
+		case tar.TypeReg, tar.TypeRegA:
+			return opener.index.addFile(name, position)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return opener, nil
+}
+
+// open starts reading the archive from the beginning.
+func (opener *CompressedTarOpener) open() (*tarCursor, error) {
+	file, err := os.Open(opener.path)
+	if err != nil {
+		return nil, err
+	}
+	cursor := &tarCursor{closers: multiCloser{file}, stream: bufio.NewReader(file)}
+	if opener.decompress != nil {
+		decompressed, err := opener.decompress(cursor.stream)
+		if err != nil {
+			cursor.closers.Close()
+			return nil, err
+		}
+		cursor.closers = append(multiCloser{decompressed}, cursor.closers...)
+		cursor.stream = decompressed
+	}
+	cursor.reader = tar.NewReader(cursor.stream)
+	return cursor, nil
+}
+
+// advance moves the cursor to the next entry and returns its header and cleaned name. It returns io.EOF
+// at the end of the archive.
+func (cursor *tarCursor) advance() (string, *tar.Header, error) {
+	header, err := cursor.reader.Next()
+	if err != nil {
+		return "", nil, err
+	}
+	cursor.next++
+	name, err := cleanEntryName(header.Name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, header, nil
+}
+
+// scan reads the whole archive once and calls the given function for each entry with its position.
+func (opener *CompressedTarOpener) scan(fn func(position int, name string, header *tar.Header, r io.Reader) error) error {
+	cursor, err := opener.open()
+	if err != nil {
+		return err
+	}
+	defer cursor.closers.Close()
+
+	for {
+		name, header, err := cursor.advance()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(cursor.next-1, name, header, cursor.reader); err != nil {
+			return err
+		}
+	}
+
+	// Drain the stream so that trailing corruption is detected by the decompressor.
+	_, err = io.Copy(ioutil.Discard, cursor.stream)
+	return err
+}
+
+// GetFile opens a file in the archive. Only read only access is supported. The file is valid until the
+// next file is opened.
+func (opener *CompressedTarOpener) GetFile(root string, relPath string, readOnly bool, binary bool) (io.ReadWriteCloser, error) {
+	name, err := opener.index.getFilePath(root, relPath, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	position := opener.index.files[name]
+
+	// Files that precede the cursor can only be reached by reading the archive again.
+	if opener.cursor != nil && opener.cursor.next > position {
+		opener.cursor.closers.Close()
+		opener.cursor = nil
+	}
+	if opener.cursor == nil {
+		if opener.cursor, err = opener.open(); err != nil {
+			return nil, err
+		}
+	}
+	for opener.cursor.next <= position {
+		if _, _, err = opener.cursor.advance(); err != nil {
+			opener.cursor.closers.Close()
+			opener.cursor = nil
+			if err == io.EOF {
+				err = errors.New("file not found: " + name)
+			}
+			return nil, err
+		}
+	}
+	return &entryReader{Reader: opener.cursor.reader}, nil
+}
+
+// GetDir lists a directory in the archive. Only read only access is supported.
+func (opener *CompressedTarOpener) GetDir(root string, relPath string, readOnly bool) ([]string, error) {
+	return opener.index.getDir(root, relPath, readOnly)
+}
+
+// Files returns the paths of all regular files in the archive.
+func (opener *CompressedTarOpener) Files() []string {
+	return opener.index.sortedFiles()
+}
+
+// Walk calls the given function for each regular file in the archive. The archive is read once.
+func (opener *CompressedTarOpener) Walk(fn func(name string, r io.Reader) error) error {
+	return opener.scan(func(position int, name string, header *tar.Header, r io.Reader) error {
+		if header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA {
+			return fn(name, r)
+		}
+		return nil
+	})
+}
+
+// Close releases the archive if a file has been opened.
+func (opener *CompressedTarOpener) Close() error {
+	if opener.cursor == nil {
+		return nil
+	}
+	err := opener.cursor.closers.Close()
+	opener.cursor = nil
+	return err
+}
+
+// multiCloser closes a list of closers in order and returns the first error.
+type multiCloser []io.Closer
+
+func (closers multiCloser) Close() error {
+	var result error
+	for _, c := range closers {
+		if err := c.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
@@ -0,0 +1,252 @@
+package dataset
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// writeTestArchive packs the given files into an archive of the given format and returns its path.
+func writeTestArchive(t *testing.T, dir string, format string, files map[string][]byte) string {
+	archivePath := filepath.Join(dir, "dataset."+format)
+	var buf bytes.Buffer
+
+	if format == "zip" {
+		zw := zip.NewWriter(&buf)
+		for name, content := range files {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(content)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		// Entries are written depth first like archiving tools do.
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var tarBuf bytes.Buffer
+		tw := tar.NewWriter(&tarBuf)
+		for _, name := range names {
+			content := files[name]
+			header := &tar.Header{Name: name, Size: int64(len(content)), Mode: 0600, Typeflag: tar.TypeReg}
+			if err := tw.WriteHeader(header); err != nil {
+				t.Fatal(err)
+			}
+			tw.Write(content)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		switch format {
+		case "tar":
+			buf = tarBuf
+		case "tar.gz":
+			gw := gzip.NewWriter(&buf)
+			gw.Write(tarBuf.Bytes())
+			gw.Close()
+		case "tar.zst":
+			zw, err := zstd.NewWriter(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			zw.Write(tarBuf.Bytes())
+			zw.Close()
+		}
+	}
+
+	if err := ioutil.WriteFile(archivePath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+// readDirFiles reads all files under the given directory keyed by their slash separated relative path.
+func readDirFiles(t *testing.T, root string) map[string][]byte {
+	files := map[string][]byte{}
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		files[filepath.ToSlash(rel)], err = ioutil.ReadFile(p)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestArchiveOpener(t *testing.T) {
+	sourcePath := filepath.Join(relTestPath, "dataset", "infer", "positive", "combined-001")
+	files := readDirFiles(t, sourcePath)
+
+	srcDataset, err := Load(sourcePath, true, DefaultOpener{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcSchema, err := srcDataset.InferSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "easeml-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, format := range []string{"zip", "tar", "tar.gz", "tar.zst"} {
+		t.Run(format, func(t *testing.T) {
+			opener, err := OpenArchiveOpener(writeTestArchive(t, dir, format, files))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer opener.Close()
+
+			if len(opener.Files()) != len(files) {
+				t.Fatalf("Expected %d files, found: %v", len(files), opener.Files())
+			}
+
+			// Files are read lazily through the opener.
+			for name, content := range files {
+				rw, err := opener.GetFile("", name, true, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := ioutil.ReadAll(rw)
+				rw.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if bytes.Equal(data, content) == false {
+					t.Fatalf("Unexpected content of file %s.", name)
+				}
+			}
+
+			// Walk visits every file once.
+			visited := 0
+			err = opener.Walk(func(name string, r io.Reader) error {
+				visited++
+				_, err := io.Copy(ioutil.Discard, r)
+				return err
+			})
+			if err != nil || visited != len(files) {
+				t.Fatalf("Expected to walk %d files, walked %d (error: %v).", len(files), visited, err)
+			}
+
+			dataset, err := Load("", true, opener)
+			if err != nil {
+				t.Fatal(err)
+			}
+			schema, err := dataset.InferSchema()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match, _ := schema.Match(srcSchema, false); match == false {
+				t.Fatal("Schema inferred from the archive does not match the source schema.")
+			}
+
+			if _, err := opener.GetFile("", "missing.txt", true, false); err == nil {
+				t.Fatal("Expected an error when opening a missing file.")
+			}
+			if _, err := opener.GetDir("", "new-dir", false); err == nil {
+				t.Fatal("Expected an error when writing to an archive.")
+			}
+		})
+	}
+}
+
+func TestCompressedTarOpenerSinglePass(t *testing.T) {
+	sourcePath := filepath.Join(relTestPath, "dataset", "infer", "positive", "combined-001")
+	files := readDirFiles(t, sourcePath)
+
+	dir, err := ioutil.TempDir("", "easeml-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Count how many times the archive is decompressed from the start.
+	passes := 0
+	decompress := func(r io.Reader) (io.ReadCloser, error) {
+		passes++
+		return GzipDecompressor(r)
+	}
+	opener, err := OpenCompressedTarOpener(writeTestArchive(t, dir, "tar.gz", files), decompress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer opener.Close()
+
+	// Indexing takes one pass and loading the dataset another one.
+	if _, err := Load("", false, opener); err != nil {
+		t.Fatal(err)
+	}
+	if passes != 2 {
+		t.Fatalf("Expected the archive to be read twice, it was read %d times.", passes)
+	}
+
+	// Going back to an earlier file starts a new pass.
+	names := opener.Files()
+	for _, name := range []string{names[len(names)-1], names[0]} {
+		rw, err := opener.GetFile("", name, true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(rw)
+		rw.Close()
+		if err != nil || bytes.Equal(data, files[name]) == false {
+			t.Fatalf("Unexpected content of file %s (error: %v).", name, err)
+		}
+	}
+	if passes != 4 {
+		t.Fatalf("Expected the archive to be read 4 times, it was read %d times.", passes)
+	}
+}
+
+func TestArchiveOpenerInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "easeml-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Entries escaping the archive root are rejected.
+	for _, format := range []string{"zip", "tar.gz"} {
+		archivePath := writeTestArchive(t, dir, format, map[string][]byte{"../evil.txt": []byte("evil")})
+		if _, err := OpenArchiveOpener(archivePath); err == nil || strings.Contains(err.Error(), "unsafe") == false {
+			t.Fatalf("Expected unsafe path error for %s, found: %v", format, err)
+		}
+	}
+
+	// Truncated archives are rejected.
+	archivePath := writeTestArchive(t, dir, "tar.gz", map[string][]byte{"train/input/a.txt": bytes.Repeat([]byte("a"), 4096)})
+	data, err := ioutil.ReadFile(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(archivePath, data[:len(data)-8], 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenArchiveOpener(archivePath); err == nil {
+		t.Fatal("Expected an error for a truncated archive.")
+	}
+}
@@ -123,6 +123,10 @@ func DumpTarOpener(opener *TarOpener) (io.Reader, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
 
 	return bytes.NewReader(buf.Bytes()), nil
 }
@@ -0,0 +1,69 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// maxValueLength is the maximum length of a line in an ordinal or datetime file in bytes.
+const maxValueLength = 64 * 1024
+
+// Ordinal is a file holding one ordered category per line. The order of categories is given by the order
+// of lines in the class file.
+type Ordinal struct {
+	Name   string
+	Values []string
+}
+
+// Type is.
+func (f Ordinal) Type() string { return "ordinal" }
+
+// Subtype is.
+func (f Ordinal) Subtype() string { return "default" }
+
+func loadOrdinal(root string, relPath string, name string, opener Opener, metadataOnly bool, subtype string) (File, error) {
+	path := path.Join(relPath, name+TypeExtensions["ordinal"][subtype])
+	file, err := opener.GetFile(root, path, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := []string{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 4096), maxValueLength)
+	for scanner.Scan() {
+		values = append(values, strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &datasetError{err: "Ordinal file could not be read. " + err.Error(), path: path}
+	}
+
+	return &Ordinal{Name: name, Values: values}, nil
+}
+
+func (f *Ordinal) dump(root string, relPath string, name string, opener Opener) error {
+	path := path.Join(relPath, name) + TypeExtensions["ordinal"][f.Subtype()]
+	file, err := opener.GetFile(root, path, false, false)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for i := range f.Values {
+		fmt.Fprintln(writer, f.Values[i])
+	}
+	return writer.Flush()
+}
+
+func (f *Ordinal) belongsToSet(categorySet map[string]interface{}) bool {
+	for i := range f.Values {
+		if _, ok := categorySet[f.Values[i]]; ok == false {
+			return false
+		}
+	}
+	return true
+}
@@ -1,26 +1,62 @@
 package dataset
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
 	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"path"
 	"strconv"
+	"strings"
 
 	"github.com/kshedden/gonpy"
 )
 
+// NpyDtypes maps numpy dtype codes to the dtype names used in schemas.
+var NpyDtypes = map[string]string{
+	"f8": "float64",
+	"f4": "float32",
+	"f2": "float16",
+	"i8": "int64",
+	"i4": "int32",
+	"i2": "int16",
+	"i1": "int8",
+	"u1": "uint8",
+}
+
 // Tensor is.
 type Tensor struct {
 	Name       string
 	Dimensions []int
 	Data       interface{}
+	Dtype      string
 	subtype    string
 }
 
+// SparseData holds the non-zero elements of a sparse tensor in coordinate (COO) format. Coords is a flattened
+// matrix with one row of indices per element, and Values holds the element values.
+type SparseData struct {
+	Coords []int64
+	Values interface{}
+}
+
 // Type is.
 func (f Tensor) Type() string { return "tensor" }
 
+// IsSparse returns true if the tensor is stored in the sparse coordinate format.
+func (f Tensor) IsSparse() bool { return f.Subtype() == "coo" }
+
 // Subtype is.
-func (f Tensor) Subtype() string { return f.subtype }
+func (f Tensor) Subtype() string {
+	if f.subtype == "" {
+		return "default"
+	}
+	return f.subtype
+}
 
 func loadTensor(root string, relPath string, name string, opener Opener, metadataOnly bool, subtype string) (File, error) {
 	path := path.Join(relPath, name+TypeExtensions["tensor"][subtype])
@@ -32,31 +68,108 @@ func loadTensor(root string, relPath string, name string, opener Opener, metadat
 
 	if subtype == "default" {
 
-		reader, err := gonpy.NewReader(file)
-		var data interface{}
+		tensor, err := readNpy(file, path, metadataOnly)
+		if err != nil {
+			return nil, err
+		}
+		tensor.Name = name
+		tensor.subtype = subtype
+		return tensor, nil
+
+	} else if subtype == "npz" {
+
+		// Zip archives need random access so we read the whole file.
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, &datasetError{err: "Tensor file is not a valid NPZ archive.", path: path}
+		}
+		if len(archive.File) != 1 || strings.HasSuffix(archive.File[0].Name, ".npy") == false {
+			return nil, &datasetError{err: "NPZ tensor file must contain exactly one array.", path: path}
+		}
+		member, err := archive.File[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		defer member.Close()
+
+		tensor, err := readNpy(member, path, metadataOnly)
+		if err != nil {
+			return nil, err
+		}
+		tensor.Name = name
+		tensor.subtype = subtype
+		return tensor, nil
+
+	} else if subtype == "coo" {
 
-		if reader.Dtype != "f8" {
-			return nil, &datasetError{err: "Tensor datatype must be float64.", path: path}
+		// The archive holds the tensor shape, the element coordinates and the element values.
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, &datasetError{err: "Tensor file is not a valid NPZ archive.", path: path}
+		}
+		members := map[string]*zip.File{}
+		for i := range archive.File {
+			members[archive.File[i].Name] = archive.File[i]
+		}
+		readMember := func(memberName string, metadataOnly bool) (*Tensor, error) {
+			zipFile, ok := members[memberName]
+			if ok == false {
+				return nil, &datasetError{err: "Sparse tensor file must contain the '" + memberName + "' array.", path: path}
+			}
+			member, err := zipFile.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer member.Close()
+			return readNpy(member, path, metadataOnly)
 		}
 
+		shape, err := readMember("shape.npy", false)
+		if err != nil {
+			return nil, err
+		}
+		shapeData, ok := shape.Data.([]int64)
+		if ok == false || len(shapeData) == 0 {
+			return nil, &datasetError{err: "Sparse tensor shape must be a non-empty int64 array.", path: path}
+		}
+		dimensions := make([]int, len(shapeData))
+		for i := range shapeData {
+			dimensions[i] = int(shapeData[i])
+		}
+
+		values, err := readMember("values.npy", metadataOnly)
+		if err != nil {
+			return nil, err
+		}
+		coords, err := readMember("coords.npy", metadataOnly)
+		if err != nil {
+			return nil, err
+		}
+		if coords.Dtype != "int64" || len(coords.Dimensions) != 2 || coords.Dimensions[1] != len(dimensions) ||
+			len(values.Dimensions) != 1 || values.Dimensions[0] != coords.Dimensions[0] {
+			return nil, &datasetError{err: "Sparse tensor coordinates must be an int64 matrix with one row per value.", path: path}
+		}
+
+		var data interface{}
 		if metadataOnly == false {
-			switch reader.Dtype {
-			case "f8":
-				data, err = reader.GetFloat64()
-				if err != nil {
-					return nil, err
+			coordsData := coords.Data.([]int64)
+			for i := range coordsData {
+				if coordsData[i] < 0 || coordsData[i] >= shapeData[i%len(shapeData)] {
+					return nil, &datasetError{err: "Sparse tensor coordinate out of bounds.", path: path}
 				}
-			case "f4":
-				data, err = reader.GetFloat32()
-				if err != nil {
-					return nil, err
-				}
-			default:
-				return nil, &datasetError{err: "Tensor datatype must be float64.", path: path}
 			}
+			data = &SparseData{Coords: coordsData, Values: values.Data}
 		}
 
-		return &Tensor{Name: name, Dimensions: reader.Shape, Data: data, subtype: subtype}, nil
+		return &Tensor{Name: name, Dimensions: dimensions, Data: data, Dtype: values.Dtype, subtype: subtype}, nil
 
 	} else if subtype == "csv" {
 
@@ -90,13 +203,63 @@ func loadTensor(root string, relPath string, name string, opener Opener, metadat
 			}
 		}
 
-		return &Tensor{Name: name, Dimensions: shape, Data: data, subtype: subtype}, nil
+		return &Tensor{Name: name, Dimensions: shape, Data: data, Dtype: "float64", subtype: subtype}, nil
 
 	} else {
 		return nil, &datasetError{err: "Unknown tensor subtype '" + subtype + "'.", path: path}
 	}
 }
 
+func readNpy(file io.Reader, path string, metadataOnly bool) (*Tensor, error) {
+
+	reader, err := gonpy.NewReader(file)
+	if err != nil {
+		return nil, &datasetError{err: "Tensor file is not a valid NPY file.", path: path}
+	}
+
+	dtype, ok := NpyDtypes[reader.Dtype]
+	if ok == false {
+		msg := fmt.Sprintf("Tensor datatype '%s' is not supported.", reader.Dtype)
+		return nil, &datasetError{err: msg, path: path}
+	}
+
+	var data interface{}
+	if metadataOnly == false {
+		switch reader.Dtype {
+		case "f8":
+			data, err = reader.GetFloat64()
+		case "f4":
+			data, err = reader.GetFloat32()
+		case "f2":
+			// The numpy reader does not support half precision so we read the raw values which follow the header.
+			raw := make([]uint16, numElements(reader.Shape))
+			err = binary.Read(file, reader.Endian, &raw)
+			if err == nil {
+				float32data := make([]float32, len(raw))
+				for i := range raw {
+					float32data[i] = float16ToFloat32(raw[i])
+				}
+				data = float32data
+			}
+		case "i8":
+			data, err = reader.GetInt64()
+		case "i4":
+			data, err = reader.GetInt32()
+		case "i2":
+			data, err = reader.GetInt16()
+		case "i1":
+			data, err = reader.GetInt8()
+		case "u1":
+			data, err = reader.GetUint8()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Tensor{Dimensions: reader.Shape, Data: data, Dtype: dtype}, nil
+}
+
 func (f *Tensor) dump(root string, relPath string, name string, opener Opener) error {
 	path := path.Join(relPath, name) + TypeExtensions["tensor"][f.Subtype()]
 	file, err := opener.GetFile(root, path, false, false)
@@ -107,29 +270,61 @@ func (f *Tensor) dump(root string, relPath string, name string, opener Opener) e
 
 	if f.Subtype() == "default" {
 
-		writer, err := gonpy.NewWriter(file)
+		err := f.writeNpy(file)
 		if err != nil {
 			return err
 		}
-		writer.Shape = f.Dimensions
 
-		if f.Data == nil {
-			err := writer.WriteFloat64([]float64{})
-			if err != nil {
-				return err
-			}
-		} else if float64data, ok := f.Data.([]float64); ok {
-			err := writer.WriteFloat64(float64data)
+	} else if f.Subtype() == "npz" {
+
+		archive := zip.NewWriter(file)
+		member, err := archive.CreateHeader(&zip.FileHeader{Name: "arr_0.npy", Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		err = f.writeNpy(nopCloser{member})
+		if err != nil {
+			return err
+		}
+		err = archive.Close()
+		if err != nil {
+			return err
+		}
+
+	} else if f.Subtype() == "coo" {
+
+		sparseData, ok := f.Data.(*SparseData)
+		if ok == false {
+			panic("Unknown data")
+		}
+		shape := make([]int64, len(f.Dimensions))
+		for i := range f.Dimensions {
+			shape[i] = int64(f.Dimensions[i])
+		}
+		nnz := len(sparseData.Coords) / len(f.Dimensions)
+		members := []struct {
+			name   string
+			tensor *Tensor
+		}{
+			{"shape.npy", &Tensor{Dimensions: []int{len(shape)}, Data: shape}},
+			{"coords.npy", &Tensor{Dimensions: []int{nnz, len(f.Dimensions)}, Data: sparseData.Coords}},
+			{"values.npy", &Tensor{Dimensions: []int{nnz}, Data: sparseData.Values, Dtype: f.Dtype}},
+		}
+
+		archive := zip.NewWriter(file)
+		for i := range members {
+			member, err := archive.CreateHeader(&zip.FileHeader{Name: members[i].name, Method: zip.Deflate})
 			if err != nil {
 				return err
 			}
-		} else if float32data, ok := f.Data.([]float32); ok {
-			err := writer.WriteFloat32(float32data)
+			err = members[i].tensor.writeNpy(nopCloser{member})
 			if err != nil {
 				return err
 			}
-		} else {
-			panic("Unknown data")
+		}
+		err = archive.Close()
+		if err != nil {
+			return err
 		}
 
 	} else if f.Subtype() == "csv" {
@@ -167,3 +362,134 @@ func (f *Tensor) dump(root string, relPath string, name string, opener Opener) e
 
 	return nil
 }
+
+func (f *Tensor) writeNpy(file io.WriteCloser) error {
+
+	// Half precision data is kept as float32 in memory so the dtype decides how it is written.
+	if f.Dtype == "float16" {
+		float32data, ok := f.Data.([]float32)
+		if ok == false && f.Data != nil {
+			panic("Unknown data")
+		}
+		return writeNpyFloat16(file, f.Dimensions, float32data)
+	}
+
+	writer, err := gonpy.NewWriter(file)
+	if err != nil {
+		return err
+	}
+	writer.Shape = f.Dimensions
+
+	switch data := f.Data.(type) {
+	case nil:
+		return writer.WriteFloat64([]float64{})
+	case []float64:
+		return writer.WriteFloat64(data)
+	case []float32:
+		return writer.WriteFloat32(data)
+	case []int64:
+		return writer.WriteInt64(data)
+	case []int32:
+		return writer.WriteInt32(data)
+	case []int16:
+		return writer.WriteInt16(data)
+	case []int8:
+		return writer.WriteInt8(data)
+	case []uint8:
+		return writer.WriteUint8(data)
+	default:
+		panic("Unknown data")
+	}
+}
+
+// writeNpyFloat16 writes a version 1.0 NPY file with little endian half precision values.
+func writeNpyFloat16(file io.WriteCloser, shape []int, data []float32) error {
+	defer file.Close()
+
+	var shapeString string
+	for _, v := range shape {
+		shapeString += fmt.Sprintf("%d,", v)
+	}
+	header := fmt.Sprintf("{'descr': '<f2', 'fortran_order': False, 'shape': (%s),}", shapeString)
+	header += strings.Repeat(" ", 16-((10+len(header))%16))
+
+	_, err := file.Write([]byte("\x93NUMPY\x01\x00"))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, uint16(len(header)))
+	if err != nil {
+		return err
+	}
+	_, err = file.Write([]byte(header))
+	if err != nil {
+		return err
+	}
+
+	raw := make([]uint16, len(data))
+	for i := range data {
+		raw[i] = float32ToFloat16(data[i])
+	}
+	return binary.Write(file, binary.LittleEndian, raw)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func numElements(dimensions []int) int {
+	size := 1
+	for i := range dimensions {
+		size = size * dimensions[i]
+	}
+	return size
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h) & 0x3ff
+
+	switch {
+	case exp == 0 && mant == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// Subnormal numbers.
+		return float32(math.Ldexp(float64(mant), -24)) * float32(1-2*int(h>>15))
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	default:
+		return math.Float32frombits(sign | (exp+112)<<23 | mant<<13)
+	}
+}
+
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int((bits>>23)&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+
+	switch {
+	case (bits>>23)&0xff == 0xff:
+		// Infinity and NaN.
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp >= 0x1f:
+		// Overflow to infinity.
+		return sign | 0x7c00
+	case exp <= 0:
+		// Subnormal numbers or underflow to zero.
+		if exp < -10 {
+			return sign
+		}
+		mant = (mant | 0x800000) >> uint(1-exp)
+		return sign | uint16((mant+0x1000)>>13)
+	default:
+		// Round to nearest.
+		return sign | (uint16(exp)<<10 + uint16((mant+0x1000)>>13))
+	}
+}
@@ -0,0 +1,62 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// maxTextLength is the maximum length of a single text in bytes.
+const maxTextLength = 16 * 1024 * 1024
+
+// Text is a file holding one string per line. Each line corresponds to one node instance.
+type Text struct {
+	Name  string
+	Texts []string
+}
+
+// Type is.
+func (f Text) Type() string { return "text" }
+
+// Subtype is.
+func (f Text) Subtype() string { return "default" }
+
+func loadText(root string, relPath string, name string, opener Opener, metadataOnly bool, subtype string) (File, error) {
+	path := path.Join(relPath, name+TypeExtensions["text"][subtype])
+	file, err := opener.GetFile(root, path, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	texts := []string{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxTextLength)
+	for scanner.Scan() {
+		texts = append(texts, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &datasetError{err: "Text file could not be read. " + err.Error(), path: path}
+	}
+
+	return &Text{Name: name, Texts: texts}, nil
+}
+
+func (f *Text) dump(root string, relPath string, name string, opener Opener) error {
+	path := path.Join(relPath, name) + TypeExtensions["text"][f.Subtype()]
+	file, err := opener.GetFile(root, path, false, false)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for i := range f.Texts {
+		if strings.ContainsAny(f.Texts[i], "\r\n") {
+			return &datasetError{err: "Texts cannot contain line breaks.", path: path}
+		}
+		fmt.Fprintln(writer, f.Texts[i])
+	}
+	return writer.Flush()
+}
@@ -3,6 +3,7 @@ module github.com/ds3lab/easeml/schema/go/easemlschema
 go 1.12
 
 require (
+	github.com/klauspost/compress v1.12.3
 	github.com/kshedden/gonpy v0.0.0-20181015193932-4db3805e76d5
 	github.com/robertkrimen/godocdown v0.0.0-20130622164427-0bfa04905481 // indirect
 )
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bind returns a copy of the schema where all variable dimensions are replaced with constants. The values
+// of dimension variables are looked up in the given map and default to the given value if missing. Wildcard
+// dimensions are expanded to a single occurrence.
+func (s *Schema) Bind(dims map[string]int, defaultDim int) (*Schema, Error) {
+
+	result, loadErr := Load(s.Dump())
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	var err *schemaError
+
+	bind := func(d Dim, path string) (Dim, *schemaError) {
+		v, ok := d.(*VarDim)
+		if ok == false {
+			return d, nil
+		}
+		name := strings.TrimRight(v.Value, "?+*")
+		value, ok := dims[name]
+		if ok == false {
+			value = defaultDim
+		}
+		if value < 1 {
+			return nil, &schemaError{err: fmt.Sprintf("Dimension '%s' must be bound to a positive integer.", name), path: path}
+		}
+		return &ConstDim{Value: value}, nil
+	}
+
+	for nodeName, node := range result.Nodes {
+		for fieldName, field := range node.Fields {
+			if tensor, ok := field.(*Tensor); ok {
+				for i := range tensor.Dim {
+					path := fmt.Sprintf("nodes.%s.fields.%s.dim", nodeName, fieldName)
+					tensor.Dim[i], err = bind(tensor.Dim[i], path)
+					if err != nil {
+						return nil, err
+					}
+				}
+				tensor.SrcDim = tensor.Dim
+			}
+		}
+	}
+	for className, class := range result.Classes {
+		class.Dim, err = bind(class.Dim, "classes."+className+".dim")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
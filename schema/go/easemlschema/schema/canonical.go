@@ -0,0 +1,266 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxCanonicalPermutations bounds the number of orderings of structurally indistinguishable nodes that are
+// tried when computing the canonical form. Beyond it, such nodes are ordered by their names.
+const maxCanonicalPermutations = 720
+
+// Canonical returns a copy of the schema in canonical form. Nodes, fields, classes and dimension variables
+// are renamed based on the structure of the schema only, so schemas which differ only in naming have the
+// same canonical form. Source names and source dimensions are dropped. Fields of a node which are
+// structurally identical up to the naming of their dimensions are ordered by their names.
+func (s *Schema) Canonical() *Schema {
+
+	// Nodes are ordered by structural signatures. Nodes with equal signatures are indistinguishable
+	// so we try all their orderings and pick the one with the smallest serialization.
+	names := sortedNodeNames(s.Nodes)
+	signatures := s.nodeSignatures()
+	sort.SliceStable(names, func(i, j int) bool { return signatures[names[i]] < signatures[names[j]] })
+
+	groups := [][]string{}
+	for i, name := range names {
+		if i > 0 && signatures[name] == signatures[names[i-1]] {
+			groups[len(groups)-1] = append(groups[len(groups)-1], name)
+		} else {
+			groups = append(groups, []string{name})
+		}
+	}
+
+	numPermutations := 1
+	for _, g := range groups {
+		for i := 2; i <= len(g) && numPermutations <= maxCanonicalPermutations; i++ {
+			numPermutations *= i
+		}
+	}
+	if numPermutations > maxCanonicalPermutations {
+		return s.renderCanonical(names)
+	}
+
+	var best *Schema
+	var bestKey string
+	var permute func(group int, order []string)
+	permute = func(group int, order []string) {
+		if group == len(groups) {
+			candidate := s.renderCanonical(order)
+			key := canonicalKey(candidate)
+			if best == nil || key < bestKey {
+				best, bestKey = candidate, key
+			}
+			return
+		}
+		orig := getRange(len(groups[group]))
+		for p := make([]int, len(orig)); p[0] < len(p); nextPerm(p) {
+			perm := getPerm(orig, p)
+			groupOrder := make([]string, len(perm))
+			for i := range perm {
+				groupOrder[i] = groups[group][perm[i]]
+			}
+			permute(group+1, append(append([]string{}, order...), groupOrder...))
+		}
+	}
+	permute(0, []string{})
+	return best
+}
+
+// Hash returns a structural hash of the schema computed from its canonical form. It is invariant to the
+// naming of nodes, classes and dimension variables.
+func (s *Schema) Hash() string {
+	hash := sha256.Sum256([]byte(canonicalKey(s.Canonical())))
+	return hex.EncodeToString(hash[:])
+}
+
+func canonicalKey(s *Schema) string {
+	result, err := json.Marshal(s.Dump())
+	if err != nil {
+		panic(err)
+	}
+	return string(result)
+}
+
+// nodeSignatures computes a name independent signature of each node. Signatures are refined iteratively
+// with the signatures of linked nodes until the partition of nodes stops changing.
+func (s *Schema) nodeSignatures() map[string]string {
+	signatures := map[string]string{}
+	for name, node := range s.Nodes {
+		fields := []string{}
+		for _, f := range node.Fields {
+			fields = append(fields, s.fieldSignature(f))
+		}
+		sort.Strings(fields)
+		signatures[name] = fmt.Sprintf("%v|%s", node.IsSingleton, strings.Join(fields, ","))
+	}
+
+	numClasses := countDistinct(signatures)
+	for round := 0; round < len(s.Nodes); round++ {
+		refined := map[string]string{}
+		for name, node := range s.Nodes {
+			links := []string{}
+			for target, l := range node.Links {
+				links = append(links, formatLink(l)+">"+signatures[target])
+			}
+			sort.Strings(links)
+			hash := sha256.Sum256([]byte(signatures[name] + "|" + strings.Join(links, ",")))
+			refined[name] = hex.EncodeToString(hash[:])
+		}
+		signatures = refined
+		if n := countDistinct(signatures); n == numClasses {
+			break
+		} else {
+			numClasses = n
+		}
+	}
+	return signatures
+}
+
+// fieldSignature describes a field without the names of its dimension variables and classes.
+func (s *Schema) fieldSignature(f Field) string {
+	switch field := f.(type) {
+	case *Tensor:
+		dims := make([]string, len(field.Dim))
+		for i := range field.Dim {
+			dims[i] = dimSignature(field.Dim[i])
+		}
+		return fmt.Sprintf("tensor|%s|%v|%s", field.Dtype, field.Sparse, strings.Join(dims, " "))
+	case *Category:
+		if class, ok := s.Classes[field.Class]; ok {
+			return "category|" + dimSignature(class.Dim)
+		}
+		return "category|"
+	case *Ordinal:
+		if class, ok := s.Classes[field.Class]; ok {
+			return "ordinal|" + dimSignature(class.Dim)
+		}
+		return "ordinal|"
+	}
+	return f.Type()
+}
+
+func dimSignature(d Dim) string {
+	if v, ok := d.(*VarDim); ok {
+		return "v" + strings.TrimLeft(v.Value, "abcdefghijklmnopqrstuvwxyz0123456789_")
+	}
+	return formatDim(d, nil)
+}
+
+// renderCanonical builds the canonical schema for the given node order. Fields are ordered by their
+// signatures and dimension variables and classes are named in order of their first occurrence.
+func (s *Schema) renderCanonical(nodeOrder []string) *Schema {
+	nodeNames := map[string]string{}
+	for i, name := range nodeOrder {
+		nodeNames[name] = fmt.Sprintf("n%d", i)
+	}
+	dimNames := map[string]string{}
+	renameDim := func(d Dim) Dim {
+		v, ok := d.(*VarDim)
+		if ok == false {
+			return &ConstDim{Value: d.(*ConstDim).Value}
+		}
+		base := strings.TrimRight(v.Value, "?+*")
+		if _, ok := dimNames[base]; ok == false {
+			dimNames[base] = fmt.Sprintf("d%d", len(dimNames))
+		}
+		return &VarDim{Value: dimNames[base] + v.Value[len(base):]}
+	}
+	classNames := map[string]string{}
+	classes := map[string]*Class{}
+	renameClass := func(name string) string {
+		if _, ok := classNames[name]; ok == false {
+			classNames[name] = fmt.Sprintf("c%d", len(classNames))
+			if class, ok := s.Classes[name]; ok {
+				classes[classNames[name]] = &Class{Dim: renameDim(class.Dim)}
+			}
+		}
+		return classNames[name]
+	}
+
+	result := &Schema{
+		Nodes:        map[string]*Node{},
+		Classes:      classes,
+		SrcDims:      map[string]Dim{},
+		IsUndirected: s.IsUndirected,
+		IsCyclic:     s.IsCyclic,
+		IsFanIn:      s.IsFanIn,
+	}
+
+	for _, name := range nodeOrder {
+		node := s.Nodes[name]
+		fieldOrder := make([]string, 0, len(node.Fields))
+		for k := range node.Fields {
+			fieldOrder = append(fieldOrder, k)
+		}
+		sort.Slice(fieldOrder, func(i, j int) bool {
+			si, sj := s.fieldSignature(node.Fields[fieldOrder[i]]), s.fieldSignature(node.Fields[fieldOrder[j]])
+			if si != sj {
+				return si < sj
+			}
+			return fieldOrder[i] < fieldOrder[j]
+		})
+
+		canonicalNode := &Node{IsSingleton: node.IsSingleton, Fields: map[string]Field{}}
+		for i, k := range fieldOrder {
+			fieldName := fmt.Sprintf("f%d", i)
+			if node.IsSingleton {
+				fieldName = "field"
+			}
+			switch field := node.Fields[k].(type) {
+			case *Tensor:
+				dims := make([]Dim, len(field.Dim))
+				for j := range field.Dim {
+					dims[j] = renameDim(field.Dim[j])
+				}
+				canonicalNode.Fields[fieldName] = &Tensor{Dim: dims, SrcDim: dims, Dtype: field.Dtype, Sparse: field.Sparse}
+			case *Category:
+				canonicalNode.Fields[fieldName] = &Category{Class: renameClass(field.Class)}
+			case *Ordinal:
+				canonicalNode.Fields[fieldName] = &Ordinal{Class: renameClass(field.Class)}
+			case *Text:
+				canonicalNode.Fields[fieldName] = &Text{}
+			case *Datetime:
+				canonicalNode.Fields[fieldName] = &Datetime{}
+			}
+		}
+		if len(node.Links) > 0 {
+			canonicalNode.Links = map[string]*Link{}
+			for target, l := range node.Links {
+				canonicalNode.Links[nodeNames[target]] = &Link{LBound: l.LBound, UBound: l.UBound}
+			}
+		}
+		result.Nodes[nodeNames[name]] = canonicalNode
+	}
+
+	// Classes which are not used by any field are appended in order of their dimensions.
+	unused := []string{}
+	for name := range s.Classes {
+		if _, ok := classNames[name]; ok == false {
+			unused = append(unused, name)
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		di, dj := dimSignature(s.Classes[unused[i]].Dim), dimSignature(s.Classes[unused[j]].Dim)
+		if di != dj {
+			return di < dj
+		}
+		return unused[i] < unused[j]
+	})
+	for _, name := range unused {
+		renameClass(name)
+	}
+
+	return result
+}
+
+func countDistinct(values map[string]string) int {
+	distinct := map[string]bool{}
+	for _, v := range values {
+		distinct[v] = true
+	}
+	return len(distinct)
+}
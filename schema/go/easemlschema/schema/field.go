@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Field is.
@@ -10,11 +11,16 @@ type Field interface {
 	dump() interface{}
 }
 
+// TensorDtypes is the list of element types a tensor can declare.
+var TensorDtypes = []string{"float64", "float32", "float16", "int64", "int32", "int16", "int8", "uint8"}
+
 // Tensor is.
 type Tensor struct {
 	Dim     []Dim
 	SrcDim  []Dim
 	SrcName string
+	Dtype   string
+	Sparse  bool
 }
 
 // Category is.
@@ -23,6 +29,22 @@ type Category struct {
 	SrcName string
 }
 
+// Ordinal is a category whose values are ordered. The order is given by the order of categories in the class.
+type Ordinal struct {
+	Class   string
+	SrcName string
+}
+
+// Text is a field holding raw strings of arbitrary length.
+type Text struct {
+	SrcName string
+}
+
+// Datetime is a field holding points in time.
+type Datetime struct {
+	SrcName string
+}
+
 func loadField(input interface{}) (result Field, err *schemaError) {
 
 	if field, ok := input.(map[string]interface{}); ok {
@@ -37,6 +59,12 @@ func loadField(input interface{}) (result Field, err *schemaError) {
 			return loadTensor(field)
 		case "category":
 			return loadCategory(field)
+		case "ordinal":
+			return loadOrdinal(field)
+		case "text":
+			return loadText(field)
+		case "datetime":
+			return loadDatetime(field)
 		default:
 			err = &schemaError{err: fmt.Sprintf("Unknown field type '%s'.", fieldType)}
 			return
@@ -56,6 +84,14 @@ func (f *Tensor) match(source *Tensor, dimMap map[string]Dim) (result bool, dimM
 	if source == nil {
 		return false, map[string]Dim{}
 	}
+	// A tensor without a dtype accepts any dtype.
+	if f.Dtype != "" && source.Dtype != "" && f.Dtype != source.Dtype {
+		return false, map[string]Dim{}
+	}
+	// A sparse tensor accepts dense tensors as well, but a dense tensor does not accept sparse ones.
+	if source.Sparse && f.Sparse == false {
+		return false, map[string]Dim{}
+	}
 	return matchDimList(f.Dim, source.Dim, dimMap)
 }
 
@@ -81,6 +117,14 @@ func (f *Tensor) dump() interface{} {
 		result["src-name"] = f.SrcName
 	}
 
+	if f.Dtype != "" {
+		result["dtype"] = f.Dtype
+	}
+
+	if f.Sparse {
+		result["sparse"] = true
+	}
+
 	return result
 }
 
@@ -142,6 +186,33 @@ func loadTensor(input map[string]interface{}) (result *Tensor, err *schemaError)
 		}
 	}
 
+	if dtype, ok := input["dtype"]; ok {
+		result.Dtype, ok = dtype.(string)
+		if ok == false {
+			err = &schemaError{err: "Tensor dtype must be a string."}
+			return nil, err
+		}
+		valid := false
+		for i := range TensorDtypes {
+			if result.Dtype == TensorDtypes[i] {
+				valid = true
+				break
+			}
+		}
+		if valid == false {
+			err = &schemaError{err: fmt.Sprintf("Tensor dtype must be one of: %s.", strings.Join(TensorDtypes, ", "))}
+			return nil, err
+		}
+	}
+
+	if sparse, ok := input["sparse"]; ok {
+		result.Sparse, ok = sparse.(bool)
+		if ok == false {
+			err = &schemaError{err: "Tensor sparse field must be a boolean."}
+			return nil, err
+		}
+	}
+
 	foundWildcard := false
 	for i := range result.Dim {
 		if result.Dim[i].IsWildcard() {
@@ -181,16 +252,32 @@ func (f *Category) match(
 		return false, emptyDimMap, emptyClassNameMap
 	}
 
+	return matchClasses(f.Class, source.Class, dimMap, classNameMap, selfClasses, sourceClasses)
+}
+
+// matchClasses matches the classes of two fields given the mapping of classes that have been matched so far.
+func matchClasses(
+	selfClassName string,
+	sourceClassName string,
+	dimMap map[string]Dim,
+	classNameMap map[string]string,
+	selfClasses map[string]*Class,
+	sourceClasses map[string]*Class,
+) (result bool, dimMapUpdate map[string]Dim, classNameMapUpdate map[string]string) {
+
+	emptyDimMap := map[string]Dim{}
+	emptyClassNameMap := map[string]string{}
+
 	// If the class has already been mapped then we simply compare.
-	if mappedClass, ok := classNameMap[f.Class]; ok {
-		return mappedClass == source.Class, emptyDimMap, emptyClassNameMap
+	if mappedClass, ok := classNameMap[selfClassName]; ok {
+		return mappedClass == sourceClassName, emptyDimMap, emptyClassNameMap
 	}
 
-	selfClass := selfClasses[f.Class]
-	sourceClass := sourceClasses[source.Class]
+	selfClass := selfClasses[selfClassName]
+	sourceClass := sourceClasses[sourceClassName]
 	match, dimMapUpdate := selfClass.match(sourceClass, dimMap)
 	if match {
-		emptyClassNameMap[f.Class] = source.Class
+		emptyClassNameMap[selfClassName] = sourceClassName
 		return true, dimMapUpdate, emptyClassNameMap
 	}
 
@@ -211,33 +298,153 @@ func loadCategory(input map[string]interface{}) (result *Category, err *schemaEr
 
 	result = &Category{}
 
-	categoryClass, ok := input["class"]
-	if ok == false {
-		err = &schemaError{err: "Category must have a 'class' field."}
+	result.Class, err = loadFieldClass(input, "Category")
+	if err != nil {
 		return nil, err
 	}
-	result.Class, ok = categoryClass.(string)
-	if ok == false {
-		err = &schemaError{err: "Category class must be a string."}
+
+	result.SrcName, err = loadFieldSrcName(input)
+	if err != nil {
 		return nil, err
 	}
-	if checkNameFormat(result.Class) == false {
-		err = &schemaError{err: "Category class may contain lowercase letters, numbers and underscores. They must start with a letter."}
+
+	return
+}
+
+// Type is.
+func (f *Ordinal) Type() string {
+	return "ordinal"
+}
+
+func (f *Ordinal) match(
+	source *Ordinal,
+	dimMap map[string]Dim,
+	classNameMap map[string]string,
+	selfClasses map[string]*Class,
+	sourceClasses map[string]*Class,
+) (result bool, dimMapUpdate map[string]Dim, classNameMapUpdate map[string]string) {
+
+	if source == nil {
+		return false, map[string]Dim{}, map[string]string{}
+	}
+
+	return matchClasses(f.Class, source.Class, dimMap, classNameMap, selfClasses, sourceClasses)
+}
+
+func (f *Ordinal) dump() interface{} {
+	result := map[string]interface{}{}
+	result["type"] = "ordinal"
+	result["class"] = f.Class
+	if f.SrcName != "" {
+		result["src-name"] = f.SrcName
+	}
+	return result
+}
+
+func loadOrdinal(input map[string]interface{}) (result *Ordinal, err *schemaError) {
+
+	result = &Ordinal{}
+
+	result.Class, err = loadFieldClass(input, "Ordinal")
+	if err != nil {
 		return nil, err
 	}
 
-	categorySrcName, ok := input["src-name"]
+	result.SrcName, err = loadFieldSrcName(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Type is.
+func (f *Text) Type() string {
+	return "text"
+}
+
+func (f *Text) dump() interface{} {
+	result := map[string]interface{}{}
+	result["type"] = "text"
+	if f.SrcName != "" {
+		result["src-name"] = f.SrcName
+	}
+	return result
+}
+
+func loadText(input map[string]interface{}) (result *Text, err *schemaError) {
+
+	result = &Text{}
+
+	result.SrcName, err = loadFieldSrcName(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Type is.
+func (f *Datetime) Type() string {
+	return "datetime"
+}
+
+func (f *Datetime) dump() interface{} {
+	result := map[string]interface{}{}
+	result["type"] = "datetime"
+	if f.SrcName != "" {
+		result["src-name"] = f.SrcName
+	}
+	return result
+}
+
+func loadDatetime(input map[string]interface{}) (result *Datetime, err *schemaError) {
+
+	result = &Datetime{}
+
+	result.SrcName, err = loadFieldSrcName(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// loadFieldClass loads the class reference of a category or an ordinal field.
+func loadFieldClass(input map[string]interface{}, fieldKind string) (class string, err *schemaError) {
+
+	fieldClass, ok := input["class"]
+	if ok == false {
+		err = &schemaError{err: fieldKind + " must have a 'class' field."}
+		return "", err
+	}
+	class, ok = fieldClass.(string)
+	if ok == false {
+		err = &schemaError{err: fieldKind + " class must be a string."}
+		return "", err
+	}
+	if checkNameFormat(class) == false {
+		err = &schemaError{err: fieldKind + " class may contain lowercase letters, numbers and underscores. They must start with a letter."}
+		return "", err
+	}
+
+	return class, nil
+}
+
+func loadFieldSrcName(input map[string]interface{}) (srcName string, err *schemaError) {
+
+	fieldSrcName, ok := input["src-name"]
 	if ok {
-		result.SrcName, ok = categorySrcName.(string)
+		srcName, ok = fieldSrcName.(string)
 		if ok == false {
 			err = &schemaError{err: "Source name must be a string."}
-			return nil, err
+			return "", err
 		}
-		if checkNameFormat(result.SrcName) == false {
+		if checkNameFormat(srcName) == false {
 			err = &schemaError{err: "Source name may contain lowercase letters, numbers and underscores. They must start with a letter."}
-			return nil, err
+			return "", err
 		}
 	}
 
-	return
+	return srcName, nil
 }
@@ -1,5 +1,7 @@
 package schema
 
+import "sort"
+
 // Node is.
 type Node struct {
 	IsSingleton bool
@@ -18,32 +20,47 @@ func (n *Node) match(source *Node, dimMap map[string]Dim, classNameMap map[strin
 		return false, nil, dimMapUpdates, classNameMapUpdates
 	}
 
-	// Split fields by type.
+	// Split fields by type. Categories and ordinals are both matched based on their classes. Text and
+	// datetime fields have no properties so they are matched by type only.
 	selfTensorNames := []string{}
 	sourceTensorNames := []string{}
 	selfCategoryNames := []string{}
 	sourceCategoryNames := []string{}
+	selfPlainNames := []string{}
+	sourcePlainNames := []string{}
+	typeCounts := map[string]int{}
 
 	for k, v := range n.Fields {
 		switch v.Type() {
 		case "tensor":
 			selfTensorNames = append(selfTensorNames, k)
-		case "category":
+		case "category", "ordinal":
 			selfCategoryNames = append(selfCategoryNames, k)
+		case "text", "datetime":
+			selfPlainNames = append(selfPlainNames, k)
 		}
+		typeCounts[v.Type()]++
 	}
 
 	for k, v := range source.Fields {
 		switch v.Type() {
 		case "tensor":
 			sourceTensorNames = append(sourceTensorNames, k)
-		case "category":
+		case "category", "ordinal":
 			sourceCategoryNames = append(sourceCategoryNames, k)
+		case "text", "datetime":
+			sourcePlainNames = append(sourcePlainNames, k)
 		}
+		typeCounts[v.Type()]--
 	}
 
 	// Simply dismiss in case the counts don't match.
-	if len(selfTensorNames) != len(sourceTensorNames) || len(selfCategoryNames) != len(sourceCategoryNames) || len(n.Links) != len(source.Links) {
+	for _, count := range typeCounts {
+		if count != 0 {
+			return false, nil, dimMapUpdates, classNameMapUpdates
+		}
+	}
+	if len(n.Links) != len(source.Links) {
 		return false, nil, dimMapUpdates, classNameMapUpdates
 	}
 
@@ -99,6 +116,7 @@ func (n *Node) match(source *Node, dimMap map[string]Dim, classNameMap map[strin
 					sourceFieldName := sourceTensorNames[perm[i]]
 					fieldNameMap[selfFieldName] = sourceFieldName
 				}
+				break
 			}
 		}
 	}
@@ -130,12 +148,16 @@ func (n *Node) match(source *Node, dimMap map[string]Dim, classNameMap map[strin
 			}
 
 			for i := 0; i < len(perm); i++ {
-				selfField := n.Fields[selfCategoryNames[i]].(*Category)
-				sourceField := source.Fields[sourceCategoryNames[perm[i]]].(*Category)
-
 				var dimMapUpdatesNew map[string]Dim
 				var classNameMapUpdatesNew map[string]string
-				result, dimMapUpdatesNew, classNameMapUpdatesNew = selfField.match(sourceField, dimMapIter, classNameMapIter, selfClasses, sourceClasses)
+				switch selfField := n.Fields[selfCategoryNames[i]].(type) {
+				case *Category:
+					sourceField, _ := source.Fields[sourceCategoryNames[perm[i]]].(*Category)
+					result, dimMapUpdatesNew, classNameMapUpdatesNew = selfField.match(sourceField, dimMapIter, classNameMapIter, selfClasses, sourceClasses)
+				case *Ordinal:
+					sourceField, _ := source.Fields[sourceCategoryNames[perm[i]]].(*Ordinal)
+					result, dimMapUpdatesNew, classNameMapUpdatesNew = selfField.match(sourceField, dimMapIter, classNameMapIter, selfClasses, sourceClasses)
+				}
 
 				if result {
 					// If there was a match, we update the dimension and class mappings.
@@ -167,6 +189,7 @@ func (n *Node) match(source *Node, dimMap map[string]Dim, classNameMap map[strin
 					sourceFieldName := sourceCategoryNames[perm[i]]
 					fieldNameMap[selfFieldName] = sourceFieldName
 				}
+				break
 			}
 		}
 	}
@@ -176,12 +199,37 @@ func (n *Node) match(source *Node, dimMap map[string]Dim, classNameMap map[strin
 		return false, nil, dimMapUpdates, classNameMapUpdates
 	}
 
+	// Fields without properties are interchangeable so we pair them up by type in the order of their names.
+	sort.Strings(selfPlainNames)
+	sort.Strings(sourcePlainNames)
+	for _, fieldType := range []string{"text", "datetime"} {
+		j := 0
+		for _, selfFieldName := range selfPlainNames {
+			if n.Fields[selfFieldName].Type() != fieldType {
+				continue
+			}
+			for source.Fields[sourcePlainNames[j]].Type() != fieldType {
+				j++
+			}
+			fieldNameMap[selfFieldName] = sourcePlainNames[j]
+			j++
+		}
+	}
+
 	// If a matching was found, build a resulting matching node if needed.
 	if buildMatching {
 		matching, err := loadNode(n.dump())
 		if err != nil {
 			panic(err)
 		}
+		// Singleton nodes are dumped without their field name, so we restore it.
+		if n.IsSingleton {
+			for k := range n.Fields {
+				for _, v := range matching.Fields {
+					matching.Fields = map[string]Field{k: v}
+				}
+			}
+		}
 		for k, v := range matching.Fields {
 			switch v.(type) {
 			case *Tensor:
@@ -199,6 +247,15 @@ func (n *Node) match(source *Node, dimMap map[string]Dim, classNameMap map[strin
 			case *Category:
 				// Assign the source name.
 				v.(*Category).SrcName = fieldNameMap[k]
+
+			case *Ordinal:
+				v.(*Ordinal).SrcName = fieldNameMap[k]
+
+			case *Text:
+				v.(*Text).SrcName = fieldNameMap[k]
+
+			case *Datetime:
+				v.(*Datetime).SrcName = fieldNameMap[k]
 			}
 		}
 		return true, matching, dimMapUpdates, classNameMapUpdates
@@ -0,0 +1,491 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Constraint kinds reported in match failures.
+const (
+	ConstraintNodeCount  = "node-count"
+	ConstraintSingleton  = "singleton"
+	ConstraintClassCount = "class-count"
+	ConstraintRefs       = "ref-constraints"
+	ConstraintFieldCount = "field-count"
+	ConstraintLink       = "link"
+	ConstraintDim        = "dim"
+	ConstraintDtype      = "dtype"
+	ConstraintSparse     = "sparse"
+	ConstraintClass      = "class"
+	ConstraintMatching   = "matching"
+)
+
+// MatchFailure describes a single constraint of the destination schema that the source schema violates.
+// The path points to the constraint in the destination schema.
+type MatchFailure struct {
+	Path       string `json:"path"`
+	Constraint string `json:"constraint"`
+	Expected   string `json:"expected"`
+	Found      string `json:"found"`
+	Message    string `json:"message"`
+}
+
+// MatchReport is the result of explaining a schema match. If the schemas do not match, the node mapping
+// is the closest candidate mapping from destination nodes to source nodes and the failures list all
+// constraints violated under that mapping.
+type MatchReport struct {
+	Match       bool              `json:"match"`
+	NodeMapping map[string]string `json:"node-mapping"`
+	Failures    []MatchFailure    `json:"failures"`
+	Suggestions []string          `json:"suggestions"`
+}
+
+// Explain matches the source schema against this schema like Match does, but returns a report which
+// explains why the schemas do not match.
+func (s *Schema) Explain(source *Schema) *MatchReport {
+	report := &MatchReport{NodeMapping: map[string]string{}, Failures: []MatchFailure{}, Suggestions: []string{}}
+
+	if source == nil {
+		report.Failures = append(report.Failures, MatchFailure{
+			Constraint: ConstraintMatching,
+			Expected:   "schema",
+			Found:      "none",
+			Message:    "The source has no schema.",
+		})
+		report.Suggestions = suggestFixes(report.Failures)
+		return report
+	}
+
+	if match, matching := s.Match(source, true); match {
+		report.Match = true
+		for k, v := range matching.Nodes {
+			report.NodeMapping[k] = v.SrcName
+		}
+		return report
+	}
+
+	e := &explainer{self: s, source: source, dimMap: map[string]Dim{}, classNameMap: map[string]string{}}
+	e.explainSchema()
+	report.NodeMapping = e.nodeMapping
+	report.Failures = e.failures
+
+	// The matching can fail even if every node matches on its own, since dimension and class bindings
+	// must be consistent across all nodes at once.
+	if len(report.Failures) == 0 {
+		report.Failures = append(report.Failures, MatchFailure{
+			Constraint: ConstraintMatching,
+			Expected:   "consistent matching",
+			Found:      "none",
+			Message:    "Each node can be matched on its own, but no assignment of nodes, fields and dimensions satisfies all constraints at once.",
+		})
+	}
+	report.Suggestions = suggestFixes(report.Failures)
+	return report
+}
+
+// explainer collects match failures between a destination (self) and a source schema.
+type explainer struct {
+	self         *Schema
+	source       *Schema
+	dimMap       map[string]Dim
+	classNameMap map[string]string
+	nodeMapping  map[string]string
+	failures     []MatchFailure
+}
+
+func (e *explainer) fail(path, constraint, expected, found, message string) {
+	e.failures = append(e.failures, MatchFailure{Path: path, Constraint: constraint, Expected: expected, Found: found, Message: message})
+}
+
+func (e *explainer) explainSchema() {
+
+	if len(e.self.Classes) != len(e.source.Classes) {
+		e.fail("classes", ConstraintClassCount, fmt.Sprintf("%d classes", len(e.self.Classes)), fmt.Sprintf("%d classes", len(e.source.Classes)),
+			"The number of category classes differs.")
+	}
+
+	selfHasGraph, sourceHasGraph := false, false
+	for _, v := range e.self.Nodes {
+		selfHasGraph = selfHasGraph || v.IsSingleton == false
+	}
+	for _, v := range e.source.Nodes {
+		sourceHasGraph = sourceHasGraph || v.IsSingleton == false
+	}
+	if selfHasGraph && sourceHasGraph {
+		if e.source.IsCyclic && !e.self.IsCyclic {
+			e.fail("ref-constraints.cyclic", ConstraintRefs, "false", "true", "A cyclic graph cannot be accepted by an acyclic destination.")
+		}
+		if !e.source.IsUndirected && e.self.IsUndirected {
+			e.fail("ref-constraints.undirected", ConstraintRefs, "true", "false", "A directed graph cannot be accepted by an undirected destination.")
+		}
+		if e.source.IsFanIn && !e.self.IsFanIn {
+			e.fail("ref-constraints.fan-in", ConstraintRefs, "false", "true", "A graph with fan-in cannot be accepted by a destination that forbids it.")
+		}
+	}
+
+	e.nodeMapping = e.closestNodeMapping()
+
+	// Explain matched nodes in the same order as Match does, singletons first.
+	selfNames := sortedNodeNames(e.self.Nodes)
+	sort.SliceStable(selfNames, func(i, j int) bool {
+		return e.self.Nodes[selfNames[i]].IsSingleton && !e.self.Nodes[selfNames[j]].IsSingleton
+	})
+	mappedSource := map[string]bool{}
+	for _, name := range selfNames {
+		sourceName, ok := e.nodeMapping[name]
+		if ok == false {
+			e.fail("nodes."+name, ConstraintNodeCount, "node "+name, "none", fmt.Sprintf("No source node is left to match node '%s'.", name))
+			continue
+		}
+		mappedSource[sourceName] = true
+		e.failures = append(e.failures, e.explainNode(name, sourceName, e.dimMap, e.classNameMap, true)...)
+	}
+	for _, name := range sortedNodeNames(e.source.Nodes) {
+		if mappedSource[name] == false {
+			e.fail("nodes", ConstraintNodeCount, "no node", "node "+name, fmt.Sprintf("Source node '%s' has no counterpart.", name))
+		}
+	}
+}
+
+// closestNodeMapping greedily pairs destination and source nodes, preferring pairs with the fewest
+// failures. Ties are resolved in favor of nodes with equal names.
+func (e *explainer) closestNodeMapping() map[string]string {
+	type candidate struct {
+		self, source string
+		cost         int
+	}
+	candidates := []candidate{}
+	for _, selfName := range sortedNodeNames(e.self.Nodes) {
+		for _, sourceName := range sortedNodeNames(e.source.Nodes) {
+			dimMap := copyDimMap(e.dimMap)
+			classNameMap := copyClassNameMap(e.classNameMap)
+			cost := len(e.explainNode(selfName, sourceName, dimMap, classNameMap, false))
+			candidates = append(candidates, candidate{self: selfName, source: sourceName, cost: cost})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].cost != candidates[j].cost {
+			return candidates[i].cost < candidates[j].cost
+		}
+		return candidates[i].self == candidates[i].source && candidates[j].self != candidates[j].source
+	})
+
+	mapping := map[string]string{}
+	usedSource := map[string]bool{}
+	for _, c := range candidates {
+		if _, ok := mapping[c.self]; ok || usedSource[c.source] {
+			continue
+		}
+		mapping[c.self] = c.source
+		usedSource[c.source] = true
+	}
+	return mapping
+}
+
+// explainNode returns the failures of matching the source node against the destination node. Links are
+// only checked if requested because they depend on the complete node mapping. The dimension and class
+// maps are updated with the bindings of all matched fields.
+func (e *explainer) explainNode(selfName, sourceName string, dimMap map[string]Dim, classNameMap map[string]string, checkLinks bool) []MatchFailure {
+	self := e.self.Nodes[selfName]
+	source := e.source.Nodes[sourceName]
+	path := "nodes." + selfName
+	failures := []MatchFailure{}
+
+	if self.IsSingleton != source.IsSingleton {
+		failures = append(failures, MatchFailure{
+			Path:       path + ".singleton",
+			Constraint: ConstraintSingleton,
+			Expected:   fmt.Sprint(self.IsSingleton),
+			Found:      fmt.Sprint(source.IsSingleton),
+			Message:    fmt.Sprintf("Node '%s' and source node '%s' differ in whether they are singletons.", selfName, sourceName),
+		})
+	}
+
+	for _, fieldType := range []string{"tensor", "category", "ordinal", "text", "datetime"} {
+		selfFields := sortedFieldNames(self.Fields, fieldType)
+		sourceFields := sortedFieldNames(source.Fields, fieldType)
+
+		// Pair fields greedily, each time taking the source field with the fewest failures.
+		used := map[string]bool{}
+		for _, selfField := range selfFields {
+			best, bestFailures := "", []MatchFailure(nil)
+			for _, sourceField := range sourceFields {
+				if used[sourceField] {
+					continue
+				}
+				fieldFailures := e.explainField(path+".fields."+selfField, self.Fields[selfField], source.Fields[sourceField],
+					copyDimMap(dimMap), copyClassNameMap(classNameMap))
+				if best == "" || len(fieldFailures) < len(bestFailures) {
+					best, bestFailures = sourceField, fieldFailures
+				}
+			}
+			if best == "" {
+				failures = append(failures, MatchFailure{
+					Path:       path + ".fields." + selfField,
+					Constraint: ConstraintFieldCount,
+					Expected:   fieldType + " field " + selfField,
+					Found:      "none",
+					Message:    fmt.Sprintf("Source node '%s' has no %s field left to match field '%s'.", sourceName, fieldType, selfField),
+				})
+				continue
+			}
+			used[best] = true
+			failures = append(failures, e.explainField(path+".fields."+selfField, self.Fields[selfField], source.Fields[best], dimMap, classNameMap)...)
+		}
+		for _, sourceField := range sourceFields {
+			if used[sourceField] == false {
+				failures = append(failures, MatchFailure{
+					Path:       path + ".fields",
+					Constraint: ConstraintFieldCount,
+					Expected:   "no field",
+					Found:      fieldType + " field " + sourceField,
+					Message:    fmt.Sprintf("The %s field '%s' of source node '%s' has no counterpart.", fieldType, sourceField, sourceName),
+				})
+			}
+		}
+	}
+
+	if checkLinks {
+		covered := map[string]bool{}
+		for _, target := range sortedLinkNames(self.Links) {
+			link := self.Links[target]
+			sourceTarget := e.nodeMapping[target]
+			sourceLink := source.Links[sourceTarget]
+			covered[sourceTarget] = true
+			if sourceLink == nil {
+				failures = append(failures, MatchFailure{
+					Path:       path + ".links." + target,
+					Constraint: ConstraintLink,
+					Expected:   formatLink(link),
+					Found:      "none",
+					Message:    fmt.Sprintf("Source node '%s' has no link to node '%s'.", sourceName, sourceTarget),
+				})
+			} else if link.match(sourceLink) == false {
+				failures = append(failures, MatchFailure{
+					Path:       path + ".links." + target,
+					Constraint: ConstraintLink,
+					Expected:   formatLink(link),
+					Found:      formatLink(sourceLink),
+					Message:    fmt.Sprintf("The bounds of the link to '%s' do not contain the bounds of the source link.", target),
+				})
+			}
+		}
+		for _, sourceTarget := range sortedLinkNames(source.Links) {
+			if covered[sourceTarget] == false {
+				failures = append(failures, MatchFailure{
+					Path:       path + ".links",
+					Constraint: ConstraintLink,
+					Expected:   "no link",
+					Found:      "link to " + sourceTarget,
+					Message:    fmt.Sprintf("The link from source node '%s' to '%s' has no counterpart.", sourceName, sourceTarget),
+				})
+			}
+		}
+	}
+
+	return failures
+}
+
+// explainField returns the failures of matching two fields and updates the maps with the new bindings.
+func (e *explainer) explainField(path string, self, source Field, dimMap map[string]Dim, classNameMap map[string]string) []MatchFailure {
+	failures := []MatchFailure{}
+
+	switch selfField := self.(type) {
+	case *Tensor:
+		sourceField := source.(*Tensor)
+		if selfField.Dtype != "" && sourceField.Dtype != "" && selfField.Dtype != sourceField.Dtype {
+			failures = append(failures, MatchFailure{Path: path + ".dtype", Constraint: ConstraintDtype, Expected: selfField.Dtype, Found: sourceField.Dtype,
+				Message: fmt.Sprintf("Tensor elements must be of type %s.", selfField.Dtype)})
+		}
+		if sourceField.Sparse && selfField.Sparse == false {
+			failures = append(failures, MatchFailure{Path: path + ".sparse", Constraint: ConstraintSparse, Expected: "false", Found: "true",
+				Message: "A dense tensor cannot accept a sparse tensor."})
+		}
+		failures = append(failures, explainDims(path+".dim", selfField.Dim, sourceField.Dim, dimMap)...)
+
+	case *Category:
+		failures = append(failures, e.explainClass(path, selfField.Class, source.(*Category).Class, dimMap, classNameMap)...)
+
+	case *Ordinal:
+		failures = append(failures, e.explainClass(path, selfField.Class, source.(*Ordinal).Class, dimMap, classNameMap)...)
+	}
+
+	return failures
+}
+
+// explainClass returns the failures of matching the classes of two fields and updates the maps with the new bindings.
+func (e *explainer) explainClass(path, selfClassName, sourceClassName string, dimMap map[string]Dim, classNameMap map[string]string) []MatchFailure {
+	failures := []MatchFailure{}
+
+	if mappedClass, ok := classNameMap[selfClassName]; ok {
+		if mappedClass != sourceClassName {
+			failures = append(failures, MatchFailure{Path: path + ".class", Constraint: ConstraintClass, Expected: mappedClass, Found: sourceClassName,
+				Message: fmt.Sprintf("Class '%s' is already matched to source class '%s'.", selfClassName, mappedClass)})
+		}
+		return failures
+	}
+	selfClass := e.self.Classes[selfClassName]
+	sourceClass := e.source.Classes[sourceClassName]
+	if selfClass == nil || sourceClass == nil {
+		failures = append(failures, MatchFailure{Path: path + ".class", Constraint: ConstraintClass, Expected: selfClassName, Found: sourceClassName,
+			Message: "The category class is not defined."})
+		return failures
+	}
+	match, dimMapUpdate := selfClass.match(sourceClass, dimMap)
+	if match == false {
+		failures = append(failures, MatchFailure{Path: "classes." + selfClassName + ".dim", Constraint: ConstraintClass,
+			Expected: formatDim(selfClass.Dim, dimMap), Found: formatDim(sourceClass.Dim, nil),
+			Message: fmt.Sprintf("Class '%s' and source class '%s' have a different number of categories.", selfClassName, sourceClassName)})
+		return failures
+	}
+	for k, v := range dimMapUpdate {
+		dimMap[k] = v
+	}
+	classNameMap[selfClassName] = sourceClassName
+
+	return failures
+}
+
+// explainDims returns the failures of matching two dimension lists and updates the dimension map with the
+// new bindings. Mismatches are attributed to individual dimensions if no wildcards are involved.
+func explainDims(path string, self, source []Dim, dimMap map[string]Dim) []MatchFailure {
+	if match, dimMapUpdate := matchDimList(self, source, dimMap); match {
+		for k, v := range dimMapUpdate {
+			dimMap[k] = v
+		}
+		return []MatchFailure{}
+	}
+
+	hasWildcards := false
+	for _, d := range append(append([]Dim{}, self...), source...) {
+		hasWildcards = hasWildcards || d.IsWildcard()
+	}
+	if hasWildcards || len(self) != len(source) {
+		return []MatchFailure{{Path: path, Constraint: ConstraintDim, Expected: formatDims(self, dimMap), Found: formatDims(source, nil),
+			Message: "The tensor shape does not match."}}
+	}
+
+	failures := []MatchFailure{}
+	for i := range self {
+		expected := formatDim(self[i], dimMap)
+		match, dimMapUpdate := self[i].Match(source[i], dimMap)
+		if match == false {
+			failures = append(failures, MatchFailure{Path: fmt.Sprintf("%s[%d]", path, i), Constraint: ConstraintDim, Expected: expected,
+				Found: formatDim(source[i], nil), Message: fmt.Sprintf("Dimension %d of the tensor does not match.", i)})
+			continue
+		}
+		for k, v := range dimMapUpdate {
+			dimMap[k] = v
+		}
+	}
+	return failures
+}
+
+// suggestFixes derives human readable suggestions from match failures.
+func suggestFixes(failures []MatchFailure) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, f := range failures {
+		var s string
+		switch f.Constraint {
+		case ConstraintDim:
+			s = fmt.Sprintf("Reshape the source tensor at %s from %s to %s.", f.Path, f.Found, f.Expected)
+		case ConstraintDtype:
+			s = fmt.Sprintf("Convert the source tensor at %s to %s.", strings.TrimSuffix(f.Path, ".dtype"), f.Expected)
+		case ConstraintSparse:
+			s = fmt.Sprintf("Store the source tensor at %s as a dense tensor.", strings.TrimSuffix(f.Path, ".sparse"))
+		case ConstraintClass, ConstraintClassCount:
+			s = "Check that the source categories use the same classes with the same number of categories."
+		case ConstraintSingleton:
+			s = fmt.Sprintf("Make the source node matched to %s a singleton if and only if the destination node is one.", strings.TrimSuffix(f.Path, ".singleton"))
+		case ConstraintLink:
+			s = fmt.Sprintf("Adjust the links of the source node to fit the bounds %s at %s.", f.Expected, f.Path)
+		case ConstraintRefs:
+			s = "Use a destination that accepts the graph structure of the source or restructure the source graph."
+		case ConstraintNodeCount, ConstraintFieldCount:
+			s = "Add or remove source nodes and fields so that they correspond one to one to the destination."
+		default:
+			s = "Compare the source schema with the destination schema."
+		}
+		if seen[s] == false {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func formatDim(d Dim, dimMap map[string]Dim) string {
+	switch dim := d.(type) {
+	case *ConstDim:
+		return fmt.Sprint(dim.Value)
+	case *VarDim:
+		name := strings.TrimRight(dim.Value, "?+*")
+		if bound, ok := dimMap[name]; ok {
+			return fmt.Sprintf("%s=%s", dim.Value, formatDim(bound, nil))
+		}
+		return dim.Value
+	}
+	return ""
+}
+
+func formatDims(dims []Dim, dimMap map[string]Dim) string {
+	parts := make([]string, len(dims))
+	for i := range dims {
+		parts[i] = formatDim(dims[i], dimMap)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func formatLink(l *Link) string {
+	if l.IsUnbounded() {
+		return fmt.Sprintf("[%d, inf]", l.LBound)
+	}
+	return fmt.Sprintf("[%d, %d]", l.LBound, l.UBound)
+}
+
+func sortedNodeNames(nodes map[string]*Node) []string {
+	result := make([]string, 0, len(nodes))
+	for k := range nodes {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortedFieldNames(fields map[string]Field, fieldType string) []string {
+	result := []string{}
+	for k, v := range fields {
+		if v.Type() == fieldType {
+			result = append(result, k)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortedLinkNames(links map[string]*Link) []string {
+	result := make([]string, 0, len(links))
+	for k := range links {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func copyDimMap(dimMap map[string]Dim) map[string]Dim {
+	result := make(map[string]Dim, len(dimMap))
+	for k, v := range dimMap {
+		result[k] = v
+	}
+	return result
+}
+
+func copyClassNameMap(classNameMap map[string]string) map[string]string {
+	result := make(map[string]string, len(classNameMap))
+	for k, v := range classNameMap {
+		result[k] = v
+	}
+	return result
+}
@@ -138,15 +138,22 @@ func Load(input interface{}) (*Schema, Error) {
 				}
 			}
 
-			// Check category field classes.
+			// Check category and ordinal field classes.
 			for f := range v.Fields {
-				if category, ok := v.Fields[f].(*Category); ok {
-					if _, ok := result.Classes[category.Class]; ok == false {
-						err = &schemaError{err: "Field category class undefined."}
-						return nil, err
-					}
-					delete(orphanClasses, category.Class)
+				var class string
+				switch field := v.Fields[f].(type) {
+				case *Category:
+					class = field.Class
+				case *Ordinal:
+					class = field.Class
+				default:
+					continue
+				}
+				if _, ok := result.Classes[class]; ok == false {
+					err = &schemaError{err: "Field category class undefined."}
+					return nil, err
 				}
+				delete(orphanClasses, class)
 			}
 
 			if result.IsUndirected && !result.IsFanIn && linkCount > 2 {
@@ -155,7 +162,7 @@ func Load(input interface{}) (*Schema, Error) {
 			}
 		}
 		if len(orphanClasses) > 0 {
-			err = &schemaError{err: "Every declared class must be referenced in a category."}
+			err = &schemaError{err: "Every declared class must be referenced in a category or an ordinal."}
 			return nil, err
 		}
 
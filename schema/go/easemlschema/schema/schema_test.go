@@ -58,8 +58,25 @@ func testSchemaValidate(exampleFile string, positiveExample bool) func(*testing.
 		}
 
 		// Load the schema.
-		_, err = Load(src)
-		if positiveExample && err != nil {
+		schema, err := Load(src)
+		if positiveExample && err == nil {
+			// The canonical form must be a valid schema equivalent to the original.
+			canonical, err := Load(schema.Canonical().Dump())
+			if err != nil {
+				t.Fatalf("Canonical form is not a valid schema: %s", err.Error())
+			}
+			if match, _ := schema.Match(schema, false); match {
+				if match, _ := schema.Match(canonical, false); match == false {
+					t.Error("Schema should match its canonical form.")
+				}
+				if match, _ := canonical.Match(schema, false); match == false {
+					t.Error("Canonical form should match the schema.")
+				}
+			}
+			if canonical.Hash() != schema.Hash() {
+				t.Error("Canonical form should have the same hash as the schema.")
+			}
+		} else if positiveExample && err != nil {
 			schErr, ok := err.(Error)
 			if ok == false {
 				t.Error("Resulting error should be a schema.Error instance.")
@@ -176,5 +193,309 @@ func testSchemaMatch(exampleFileSrc, exampleFileDst string, positiveExample bool
 			t.Error("Expected a failure but got a match.")
 
 		}
+
+		// The match report must agree with the match and explain every failure.
+		report := dstSchema.Explain(srcSchema)
+		if report.Match != match {
+			t.Errorf("Match report returned %v but match returned %v.", report.Match, match)
+		}
+		if report.Match == false && (len(report.Failures) == 0 || len(report.Suggestions) == 0) {
+			t.Error("Match report of a failed match has no failures or suggestions.")
+		}
+	}
+}
+
+func TestTensorDtype(t *testing.T) {
+	load := func(src string) (*Schema, Error) {
+		var input map[string]interface{}
+		if err := json.Unmarshal([]byte(src), &input); err != nil {
+			panic(err)
+		}
+		return Load(input)
+	}
+
+	any, err := load(`{"nodes": {"x": {"singleton": true, "type": "tensor", "dim": [4]}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f4, err := load(`{"nodes": {"x": {"singleton": true, "type": "tensor", "dim": [4], "dtype": "float32"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u1, err := load(`{"nodes": {"x": {"singleton": true, "type": "tensor", "dim": [4], "dtype": "uint8"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := load(`{"nodes": {"x": {"singleton": true, "type": "tensor", "dim": [4], "dtype": "complex"}}}`); err == nil {
+		t.Error("Expected an unknown dtype to be rejected.")
+	}
+
+	// The dtype must survive a dump and load round trip.
+	dumped, err := Load(f4.Dump())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dumped.Nodes["x"].Fields["field"].(*Tensor).Dtype != "float32" {
+		t.Error("Tensor dtype was not preserved through dump.")
+	}
+
+	if match, _ := any.Match(u1, false); match == false {
+		t.Error("Tensor without dtype should match any dtype.")
+	}
+	if match, _ := f4.Match(f4, false); match == false {
+		t.Error("Tensors with the same dtype should match.")
+	}
+	if match, _ := f4.Match(u1, false); match == true {
+		t.Error("Tensors with different dtypes should not match.")
+	}
+}
+
+func TestTensorSparse(t *testing.T) {
+	load := func(src string) *Schema {
+		var input map[string]interface{}
+		if err := json.Unmarshal([]byte(src), &input); err != nil {
+			panic(err)
+		}
+		result, err := Load(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	dense := load(`{"nodes": {"x": {"singleton": true, "type": "tensor", "dim": [4]}}}`)
+	sparse := load(`{"nodes": {"x": {"singleton": true, "type": "tensor", "dim": [4], "sparse": true}}}`)
+
+	dumped, err := Load(sparse.Dump())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dumped.Nodes["x"].Fields["field"].(*Tensor).Sparse == false {
+		t.Error("Tensor sparsity was not preserved through dump.")
+	}
+	if match, _ := sparse.Match(dense, false); match == false {
+		t.Error("Sparse tensors should accept dense tensors.")
+	}
+	if match, _ := sparse.Match(sparse, false); match == false {
+		t.Error("Sparse tensors should accept sparse tensors.")
+	}
+	if match, _ := dense.Match(sparse, false); match == true {
+		t.Error("Dense tensors should not accept sparse tensors.")
+	}
+}
+
+func TestSchemaExplain(t *testing.T) {
+	load := func(src string) *Schema {
+		var input map[string]interface{}
+		if err := json.Unmarshal([]byte(src), &input); err != nil {
+			panic(err)
+		}
+		result, err := Load(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	dst := load(`{"nodes": {
+		"image": {"singleton": true, "fields": {"pixels": {"type": "tensor", "dim": [28, 28], "dtype": "uint8"}}},
+		"label": {"singleton": true, "fields": {"digit": {"type": "category", "class": "digits"}}}
+	}, "classes": {"digits": {"dim": 10}}}`)
+	src := load(`{"nodes": {
+		"img": {"singleton": true, "fields": {"px": {"type": "tensor", "dim": [28, 32], "dtype": "float32"}}},
+		"lbl": {"singleton": true, "fields": {"d": {"type": "category", "class": "numbers"}}}
+	}, "classes": {"numbers": {"dim": 10}}}`)
+
+	report := dst.Explain(src)
+	if report.Match {
+		t.Fatal("Expected the schemas not to match.")
+	}
+	if report.NodeMapping["image"] != "img" || report.NodeMapping["label"] != "lbl" {
+		t.Errorf("Unexpected closest node mapping: %v", report.NodeMapping)
+	}
+	failures := map[string]MatchFailure{}
+	for _, f := range report.Failures {
+		failures[f.Path] = f
+	}
+	if len(failures) != 2 {
+		t.Errorf("Expected 2 failures, found: %v", report.Failures)
+	}
+	if f := failures["nodes.image.fields.pixels.dim[1]"]; f.Constraint != ConstraintDim || f.Expected != "28" || f.Found != "32" {
+		t.Errorf("Expected a dimension failure, found: %v", f)
+	}
+	if f := failures["nodes.image.fields.pixels.dtype"]; f.Constraint != ConstraintDtype || f.Expected != "uint8" || f.Found != "float32" {
+		t.Errorf("Expected a dtype failure, found: %v", f)
+	}
+	if len(report.Suggestions) != 2 {
+		t.Errorf("Expected 2 suggestions, found: %v", report.Suggestions)
+	}
+
+	report = dst.Explain(dst)
+	if report.Match == false || len(report.Failures) != 0 || report.NodeMapping["image"] != "image" {
+		t.Errorf("Expected a schema to match itself, found: %v", report)
+	}
+}
+
+func TestSchemaHash(t *testing.T) {
+	load := func(src string) *Schema {
+		var input map[string]interface{}
+		if err := json.Unmarshal([]byte(src), &input); err != nil {
+			panic(err)
+		}
+		result, err := Load(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	original := load(`{"nodes": {
+		"root": {"fields": {"x": {"type": "tensor", "dim": ["n", 3]}}, "links": {"leaf": [1, 2]}},
+		"leaf": {"fields": {"y": {"type": "tensor", "dim": ["n"]}, "c": {"type": "category", "class": "labels"}}},
+		"label": {"singleton": true, "type": "category", "class": "labels"}
+	}, "classes": {"labels": {"dim": "k"}}}`)
+	renamed := load(`{"nodes": {
+		"a": {"singleton": true, "type": "category", "class": "cls"},
+		"b": {"fields": {"v": {"type": "tensor", "dim": ["m"]}, "w": {"type": "category", "class": "cls"}}},
+		"c": {"fields": {"u": {"type": "tensor", "dim": ["m", 3]}}, "links": {"b": [1, 2]}}
+	}, "classes": {"cls": {"dim": "q"}}}`)
+	different := load(`{"nodes": {
+		"root": {"fields": {"x": {"type": "tensor", "dim": ["n", 3]}}, "links": {"leaf": [1, 2]}},
+		"leaf": {"fields": {"y": {"type": "tensor", "dim": ["p"]}, "c": {"type": "category", "class": "labels"}}},
+		"label": {"singleton": true, "type": "category", "class": "labels"}
+	}, "classes": {"labels": {"dim": "k"}}}`)
+
+	if original.Hash() != renamed.Hash() {
+		t.Error("Schemas which differ only in naming should have the same hash.")
+	}
+	if original.Hash() == different.Hash() {
+		t.Error("Schemas with different dimension bindings should have different hashes.")
+	}
+
+	// Structurally identical nodes must be canonicalized independently of their names.
+	first := load(`{"nodes": {
+		"a": {"fields": {"x": {"type": "tensor", "dim": [2]}}, "links": {"b": 1}},
+		"b": {"fields": {"x": {"type": "tensor", "dim": [2]}}}
+	}}`)
+	second := load(`{"nodes": {
+		"b": {"fields": {"x": {"type": "tensor", "dim": [2]}}, "links": {"a": 1}},
+		"a": {"fields": {"x": {"type": "tensor", "dim": [2]}}}
+	}}`)
+	if first.Hash() != second.Hash() {
+		t.Error("Schemas with permuted node names should have the same hash.")
+	}
+}
+
+func TestValueFields(t *testing.T) {
+	load := func(src string) *Schema {
+		var input map[string]interface{}
+		if err := json.Unmarshal([]byte(src), &input); err != nil {
+			panic(err)
+		}
+		result, err := Load(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	dst := load(`{"nodes": {
+		"review": {"singleton": true, "type": "text"},
+		"posts": {"fields": {
+			"body": {"type": "text"},
+			"time": {"type": "datetime"},
+			"rating": {"type": "ordinal", "class": "stars"}
+		}, "links": {"posts": [0, 1]}}
+	}, "classes": {"stars": {"dim": "k"}}}`)
+	src := load(`{"nodes": {
+		"comment": {"singleton": true, "type": "text"},
+		"items": {"fields": {
+			"created": {"type": "datetime"},
+			"content": {"type": "text"},
+			"score": {"type": "ordinal", "class": "levels"}
+		}, "links": {"items": [1, 1]}}
+	}, "classes": {"levels": {"dim": 5}}}`)
+
+	dumped, err := Load(dst.Dump())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dumped.Nodes["posts"].Fields["rating"].(*Ordinal); ok == false {
+		t.Error("Ordinal field was not preserved through dump.")
+	}
+
+	match, matching := dst.Match(src, true)
+	if match == false {
+		t.Fatal("Schemas with text, datetime and ordinal fields should match.")
+	}
+	if matching.Nodes["posts"].Fields["body"].(*Text).SrcName != "content" {
+		t.Error("Text field was not matched to the text source field.")
+	}
+	if matching.Nodes["posts"].Fields["time"].(*Datetime).SrcName != "created" {
+		t.Error("Datetime field was not matched to the datetime source field.")
+	}
+	if matching.Nodes["posts"].Fields["rating"].(*Ordinal).SrcName != "score" {
+		t.Error("Ordinal field was not matched to the ordinal source field.")
+	}
+
+	// Ordered and unordered categories are not interchangeable.
+	category := load(`{"nodes": {
+		"review": {"singleton": true, "type": "text"},
+		"posts": {"fields": {
+			"body": {"type": "text"},
+			"time": {"type": "datetime"},
+			"rating": {"type": "category", "class": "stars"}
+		}, "links": {"posts": [0, 1]}}
+	}, "classes": {"stars": {"dim": 5}}}`)
+	if match, _ := dst.Match(category, false); match == true {
+		t.Error("Ordinal fields should not accept category fields.")
+	}
+	if dst.Explain(category).Match == true {
+		t.Error("Explain should agree with Match on ordinal fields.")
+	}
+	if dst.Hash() == category.Hash() {
+		t.Error("Ordinal and category fields should have different hashes.")
+	}
+
+	text := load(`{"nodes": {"field": {"singleton": true, "type": "text"}}}`)
+	datetime := load(`{"nodes": {"field": {"singleton": true, "type": "datetime"}}}`)
+	if match, _ := text.Match(datetime, false); match == true {
+		t.Error("Text fields should not accept datetime fields.")
+	}
+}
+
+func TestSchemaBind(t *testing.T) {
+	var input map[string]interface{}
+	err := json.Unmarshal([]byte(`{"nodes": {
+		"image": {"singleton": true, "type": "tensor", "dim": ["h", "w+", 3]},
+		"label": {"singleton": true, "type": "category", "class": "labels"}
+	}, "classes": {"labels": {"dim": "k"}}}`), &input)
+	if err != nil {
+		panic(err)
+	}
+	schema, err := Load(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bound, err := schema.Bind(map[string]int{"h": 8, "k": 4}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if formatted := formatDims(bound.Nodes["image"].Fields["field"].(*Tensor).Dim, nil); formatted != "[8, 2, 3]" {
+		t.Errorf("Bound tensor has dimensions %s, expected [8, 2, 3].", formatted)
+	}
+	if bound.Classes["labels"].Dim.(*ConstDim).Value != 4 {
+		t.Error("Class dimension was not bound.")
+	}
+	if match, _ := schema.Match(bound, false); match == false {
+		t.Error("Schema should match its bound copy.")
+	}
+	if _, ok := schema.Classes["labels"].Dim.(*VarDim); ok == false {
+		t.Error("Binding should not modify the original schema.")
+	}
+
+	if _, err := schema.Bind(nil, 0); err == nil {
+		t.Error("Binding to a non-positive dimension should fail.")
 	}
 }
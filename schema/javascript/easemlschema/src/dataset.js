@@ -3,6 +3,7 @@
 import assert from 'assert'
 import ReaderWriterCloser from './reader-writer-closer'
 import jsnpy from './util/jsnpy'
+import jsnpz from './util/jsnpz'
 import path from 'path'
 import sch from './schema'
 
@@ -18,16 +19,56 @@ const FILE_TYPES = {
   'tensor': Tensor,
   'category': Category,
   'links': Links,
-  'class': Class
+  'class': Class,
+  'image': Image,
+  'text': Text,
+  'ordinal': Ordinal,
+  'datetime': Datetime
 }
 
+// File types which hold one value per node instance and are inferred as text, ordinal or datetime fields.
+const VALUE_FILE_TYPES = ['text', 'ordinal', 'datetime']
+
 const TYPE_EXTENSIONS = {
-  'tensor': { 'default': '.ten.npy', 'csv': '.ten.csv' },
+  'tensor': { 'default': '.ten.npy', 'npz': '.ten.npz', 'coo': '.ten.coo.npz', 'csv': '.ten.csv' },
   'category': { 'default': '.cat.txt' },
   'class': { 'default': '.class.txt' },
-  'links': { 'default': '.links.csv' }
+  'links': { 'default': '.links.csv' },
+  'image': { 'png': '.png', 'jpeg': '.jpeg', 'jpg': '.jpg' },
+  'text': { 'default': '.text.txt' },
+  'ordinal': { 'default': '.ord.txt' },
+  'datetime': { 'default': '.time.txt' }
+}
+
+// Maps numpy dtype codes to the dtype names used in schemas.
+const NPY_DTYPES = {
+  'f8': 'float64',
+  'f4': 'float32',
+  'f2': 'float16',
+  'i8': 'int64',
+  'i4': 'int32',
+  'i2': 'int16',
+  'i1': 'int8',
+  'u1': 'uint8'
 }
 
+// The fraction of non-zero elements in generated sparse tensors.
+const SPARSE_DENSITY = 0.1
+
+// The maximum length of a single text and of a line in an ordinal or datetime file in bytes.
+const MAX_TEXT_LENGTH = 16 * 1024 * 1024
+const MAX_VALUE_LENGTH = 64 * 1024
+
+const DATETIME_FORMAT = new RegExp('^(\\d{4})-(\\d{2})-(\\d{2})T(\\d{2}):(\\d{2}):(\\d{2})(\\.\\d+)?(Z|[+-]\\d{2}:\\d{2})$')
+
+// The maximum number of words in generated texts.
+const MAX_RANDOM_TEXT_WORDS = 20
+
+// Generated timestamps are picked uniformly at random from a ten year range. They are whole seconds to
+// survive the round trip through the RFC 3339 format.
+const RANDOM_TIME_START = Date.UTC(2010, 0, 1)
+const RANDOM_TIME_RANGE = 10 * 365 * 24 * 60 * 60
+
 const NODE_SOURCE = 'SOURCE'
 const NODE_SINK = 'SINK'
 
@@ -63,7 +104,11 @@ function loadDirectory (root, relPath, name, opener, metadataOnly = false, subty
       'tensor': loadTensor,
       'category': loadCategory,
       'class': loadClass,
-      'links': loadLinks
+      'links': loadLinks,
+      'image': loadImage,
+      'text': loadText,
+      'ordinal': loadOrdinal,
+      'datetime': loadDatetime
     }
 
     for (let fileType in TYPE_EXTENSIONS) {
@@ -108,6 +153,22 @@ function dumpDirectory (self, root, relPath, name, opener) {
         dumpLinks(child, root, dirPath, childName, opener)
         break
 
+      case 'image':
+        dumpImage(child, root, dirPath, childName, opener)
+        break
+
+      case 'text':
+        dumpText(child, root, dirPath, childName, opener)
+        break
+
+      case 'ordinal':
+        dumpOrdinal(child, root, dirPath, childName, opener)
+        break
+
+      case 'datetime':
+        dumpDatetime(child, root, dirPath, childName, opener)
+        break
+
       case 'directory':
         dumpDirectory(child, root, dirPath, childName, opener)
         break
@@ -132,15 +193,29 @@ function dumpDataset (self, root, opener) {
   dumpDirectory(self.directory, root, '', '', opener)
 }
 
-function Tensor (name, dimensions, data = null) {
-  File.call(this, name, 'tensor')
+function Tensor (name, dimensions, data = null, dtype = 'float64', subtype = 'default') {
+  File.call(this, name, 'tensor', subtype)
+  assert(sch.TENSOR_DTYPES.indexOf(dtype) >= 0)
   this.dimensions = dimensions
   this.data = data
+  this.dtype = dtype
 }
 
 Tensor.prototype = Object.create(File.prototype)
 Tensor.prototype.constructor = Tensor
 
+// Returns true if the tensor is stored in the sparse coordinate format.
+Tensor.prototype.isSparse = function () {
+  return this.subtype === 'coo'
+}
+
+// SparseData holds the non-zero elements of a sparse tensor in coordinate (COO) format. The coords are a
+// flattened matrix with one row of indices per element, and the values hold the element values.
+function SparseData (coords, values) {
+  this.coords = coords
+  this.values = values
+}
+
 function isSuperset (set, subset) {
   for (var elem of subset) {
     if (!set.has(elem)) {
@@ -172,6 +247,35 @@ function arraysEqual (a, b) {
   return true
 }
 
+function matchTensorDim (field, dimensions, imageName = null) {
+  if (field.dim.length !== dimensions.length) {
+    return false
+  }
+  for (let i = 0; i < dimensions.length; i++) {
+    if (Number.isInteger(field.dim[i]) === false) {
+      // Only image height and width can be variable.
+      continue
+    }
+    if (field.dim[i] !== dimensions[i]) {
+      if (imageName !== null && i < 2) {
+        field.dim[i] = imageDimName(imageName, i)
+        continue
+      }
+      return false
+    }
+  }
+  return true
+}
+
+// Returns the name of the variable height or width dimension of an image node.
+function imageDimName (nodeName, index) {
+  let name = nodeName.toLowerCase().replace(/[^a-z0-9]/g, '_')
+  if (name.length === 0 || (name[0] >= '0' && name[0] <= '9')) {
+    name = 'img_' + name
+  }
+  return name + (index === 0 ? '_height' : '_width')
+}
+
 Dataset.prototype.inferSchema = function () {
   let categoryClasses = {}
   let categoryClassSets = {}
@@ -202,6 +306,9 @@ Dataset.prototype.inferSchema = function () {
   let schFanin = false
   let schUndirected = true
 
+  // Images are inferred as tensors. Their height and width may vary between samples.
+  let imageNodes = new Set()
+
   // Go through all data samples.
   for (let sampleName in samples) {
     let sample = samples[sampleName]
@@ -251,20 +358,34 @@ Dataset.prototype.inferSchema = function () {
       }
     }
 
+    let sampleTensors = Object.assign({}, sampleChildren['tensor'])
+    for (let childName in sampleChildren['image']) {
+      let child = sampleChildren['image'][childName]
+      sampleTensors[childName] = new Tensor(childName, child.dimensions(), null, 'uint8')
+      if (firstSample) {
+        imageNodes.add(childName)
+      }
+    }
+
     // Handle tensor singleton nodes.
-    for (let childName in sampleChildren['tensor']) {
-      let child = sampleChildren['tensor'][childName]
+    for (let childName in sampleTensors) {
+      let child = sampleTensors[childName]
 
       if (firstSample) {
-        let field = new sch.Tensor(child.dimensions)
+        let field = new sch.Tensor(child.dimensions.slice(), null, null, child.dtype, child.isSparse())
         schNodes[childName] = new sch.Node(true, { 'field': field })
       } else {
         // Verify that the node is the same.
         let node = schNodes[childName]
+        let imageName = imageNodes.has(childName) ? childName : null
         if (node.isSingleton === false || Object.keys(node.fields).length > 1 || node.fields['field'].fieldType !== 'tensor') {
           throw new DatasetException("Node '" + childName + "' not the same type in all samples.", ['', sampleName].join('/'))
-        } else if (arraysEqual(node.fields['field'].dim, child.dimensions) === false) {
+        } else if (matchTensorDim(node.fields['field'], child.dimensions, imageName) === false) {
           throw new DatasetException('Tensor dimensions mismatch.', ['', sampleName, childName].join('/'))
+        } else if (node.fields['field'].dtype !== child.dtype) {
+          throw new DatasetException('Tensor dtype mismatch.', ['', sampleName, childName].join('/'))
+        } else if (node.fields['field'].sparse !== child.isSparse()) {
+          throw new DatasetException('Tensor sparsity mismatch.', ['', sampleName, childName].join('/'))
         }
       }
     }
@@ -300,6 +421,30 @@ Dataset.prototype.inferSchema = function () {
       }
     }
 
+    // Handle text, ordinal and datetime singleton nodes.
+    let sampleValues = {}
+    for (let i = 0; i < VALUE_FILE_TYPES.length; i++) {
+      Object.assign(sampleValues, sampleChildren[VALUE_FILE_TYPES[i]])
+    }
+    for (let childName in sampleValues) {
+      let [field, , message] = inferValueField(sampleValues[childName], categoryClassSets)
+      if (field === null) {
+        throw new DatasetException(message, ['', sampleName, childName].join('/'))
+      }
+
+      if (firstSample) {
+        schNodes[childName] = new sch.Node(true, { 'field': field })
+      } else {
+        // Verify that the node is the same.
+        let node = schNodes[childName]
+        if (node.isSingleton === false || Object.keys(node.fields).length > 1 || node.fields['field'].fieldType !== field.fieldType) {
+          throw new DatasetException("Node '" + childName + "' not the same type in all samples.", ['', sampleName].join('/'))
+        } else if (field.fieldType === 'ordinal' && node.fields['field'].categoryClass !== field.categoryClass) {
+          throw new DatasetException('Ordinal class mismatch.', ['', sampleName, childName].join('/'))
+        }
+      }
+    }
+
     // This counts how many instances each node has. It is used to validate link targets.
     let nodeInstanceCount = {}
 
@@ -352,7 +497,7 @@ Dataset.prototype.inferSchema = function () {
           nodeInstanceCount[childName] = previousCount
 
           if (firstSample) {
-            fields[nodeChildName] = new sch.Tensor(nodeChild.dimensions.slice(1))
+            fields[nodeChildName] = new sch.Tensor(nodeChild.dimensions.slice(1), null, null, nodeChild.dtype, nodeChild.isSparse())
           } else {
             // Verify that the node is the same.
             let field = fields[nodeChildName]
@@ -362,6 +507,12 @@ Dataset.prototype.inferSchema = function () {
             if (arraysEqual(field.dim, nodeChild.dimensions.slice(1)) === false) {
               throw new DatasetException('Tensor dimensions mismatch.', ['', sampleName, childName, nodeChildName].join('/'))
             }
+            if (field.dtype !== nodeChild.dtype) {
+              throw new DatasetException('Tensor dtype mismatch.', ['', sampleName, childName, nodeChildName].join('/'))
+            }
+            if (field.sparse !== nodeChild.isSparse()) {
+              throw new DatasetException('Tensor sparsity mismatch.', ['', sampleName, childName, nodeChildName].join('/'))
+            }
           }
         } else if (nodeChild.fileType === 'category') {
           // Infer class by finding first class to which the node belongs.
@@ -397,6 +548,34 @@ Dataset.prototype.inferSchema = function () {
               throw new DatasetException('Category class mismatch.', ['', sampleName, childName, nodeChildName].join('/'))
             }
           }
+        } else if (VALUE_FILE_TYPES.indexOf(nodeChild.fileType) >= 0) {
+          let [field, count, message] = inferValueField(nodeChild, categoryClassSets)
+          if (field === null) {
+            throw new DatasetException(message, ['', sampleName, childName, nodeChildName].join('/'))
+          }
+
+          // Verify that all node fields have the same number of instances.
+          let previousCount = nodeInstanceCount[childName] || count
+          if (previousCount !== count) {
+            let kind = field.fieldType.charAt(0).toUpperCase() + field.fieldType.slice(1)
+            throw new DatasetException(kind + ' instance count mismatch.', ['', sampleName, childName, nodeChildName].join('/'))
+          }
+          nodeInstanceCount[childName] = previousCount
+
+          if (firstSample) {
+            fields[nodeChildName] = field
+          } else {
+            // Verify that the node is the same.
+            let existing = fields[nodeChildName]
+            if (existing.fieldType !== field.fieldType) {
+              throw new DatasetException("Node '" + childName + "' not the same type in all samples.", ['', sampleName, childName, nodeChildName].join('/'))
+            }
+            if (field.fieldType === 'ordinal' && existing.categoryClass !== field.categoryClass) {
+              throw new DatasetException('Ordinal class mismatch.', ['', sampleName, childName, nodeChildName].join('/'))
+            }
+          }
+        } else if (nodeChild.fileType === 'image') {
+          throw new DatasetException('Images are only supported as singleton nodes.', ['', sampleName, childName, nodeChildName].join('/'))
         } else {
           // We forbid any other file type in the node directory. Maybe we should just ignore.
           throw new DatasetException("Files of type '" + nodeChild.fileType + "' are unexpected in node directory.", ['', sampleName, childName, nodeChildName].join('/'))
@@ -506,6 +685,73 @@ function randomString (size, chars = 'abcdefghijklmnopqrstuvwxyz0123456789') {
   return text
 }
 
+function randomTensorData (size, dtype) {
+  if (dtype.startsWith('float')) {
+    return Array(size).fill(0).map(() => Math.random())
+  }
+  const high = { 'int64': 2147483647, 'int32': 2147483647, 'int16': 32767, 'int8': 127, 'uint8': 256 }[dtype]
+  return Array(size).fill(0).map(() => Math.floor(Math.random() * high))
+}
+
+// Returns a tensor with random data which follows the dtype and sparsity of the schema field.
+function randomTensor (name, dim, field) {
+  let size = 1
+  for (let i = 0; i < dim.length; i++) {
+    size *= dim[i]
+  }
+  assert(Number.isInteger(size))
+
+  let dtype = field.dtype || 'float64'
+  if (field.sparse === false) {
+    return new Tensor(name, dim, randomTensorData(size, dtype), dtype)
+  }
+
+  // Pick distinct random positions of the non-zero elements.
+  let nnz = Math.max(1, Math.floor(size * SPARSE_DENSITY))
+  let positions = randomIndices(size).slice(0, nnz).sort((a, b) => a - b)
+  let coords = new BigInt64Array(nnz * dim.length)
+  for (let i = 0; i < nnz; i++) {
+    let position = positions[i]
+    for (let j = dim.length - 1; j >= 0; j--) {
+      coords[i * dim.length + j] = BigInt(position % dim[j])
+      position = Math.floor(position / dim[j])
+    }
+  }
+  let data = new SparseData(coords, randomTensorData(nnz, dtype))
+  return new Tensor(name, dim, data, dtype, 'coo')
+}
+
+// Returns a text, ordinal or datetime file with the given number of random values. For other fields it
+// returns null.
+function randomValueFile (name, field, classes, count) {
+  if (field.fieldType === 'text') {
+    let texts = []
+    for (let i = 0; i < count; i++) {
+      let words = []
+      let numWords = Math.floor(Math.random() * MAX_RANDOM_TEXT_WORDS) + 1
+      for (let j = 0; j < numWords; j++) {
+        words.push(randomString(Math.floor(Math.random() * 8) + 1, 'abcdefghijklmnopqrstuvwxyz'))
+      }
+      texts.push(words.join(' '))
+    }
+    return new Text(name, texts)
+  } else if (field.fieldType === 'ordinal') {
+    let choices = classes[field.categoryClass].categories
+    let values = []
+    for (let i = 0; i < count; i++) {
+      values.push(choices[Math.floor(Math.random() * choices.length)])
+    }
+    return new Ordinal(name, values)
+  } else if (field.fieldType === 'datetime') {
+    let times = []
+    for (let i = 0; i < count; i++) {
+      times.push(new Date(RANDOM_TIME_START + Math.floor(Math.random() * RANDOM_TIME_RANGE) * 1000))
+    }
+    return new Datetime(name, times)
+  }
+  return null
+}
+
 function randomIndices (size) {
   let list = Array.from(Array(size).keys())
 
@@ -546,18 +792,10 @@ function generateFromSchema (root, schema, numSamples = 10, numNodeInstances = 1
 
       if (node.isSingleton) {
         let field = Object.values(node.fields)[0]
-        assert(['tensor', 'category'].indexOf(field.fieldType) >= 0)
 
         // Generate singleton tensor.
         if (field.fieldType === 'tensor') {
-          let size = 1
-          for (let i = 0; i < field.dim.length; i++) {
-            size *= field.dim[i]
-          }
-          assert(Number.isInteger(size))
-
-          let data = Array(size).fill(0).map(() => Math.random())
-          nodes[nodeName] = new Tensor(nodeName, field.dim, data)
+          nodes[nodeName] = randomTensor(nodeName, field.dim, field)
 
           // Generate singleton category.
         } else if (field.fieldType === 'category') {
@@ -565,25 +803,21 @@ function generateFromSchema (root, schema, numSamples = 10, numNodeInstances = 1
           let index = Math.floor(Math.random() * choices.length)
           let categories = [choices[index]]
           nodes[nodeName] = new Category(nodeName, categories)
+
+          // Generate singleton text, ordinal or datetime.
+        } else {
+          nodes[nodeName] = randomValueFile(nodeName, field, classes, 1)
         }
       } else {
         let nodeChildren = {}
 
         for (let fieldName in node.fields) {
           let field = node.fields[fieldName]
-          assert(['tensor', 'category'].indexOf(field.fieldType) >= 0)
 
           // Generate non-singleton tensor.
           if (field.fieldType === 'tensor') {
-            let size = 1
             let dim = [numNodeInstances].concat(field.dim)
-            for (let i = 0; i < dim.length; i++) {
-              size *= dim[i]
-            }
-            assert(Number.isInteger(size))
-
-            let data = Array(size).fill(0).map(() => Math.random())
-            nodeChildren[fieldName] = new Tensor(fieldName, dim, data)
+            nodeChildren[fieldName] = randomTensor(fieldName, dim, field)
 
             // Generate non-singleton category.
           } else if (field.fieldType === 'category') {
@@ -595,6 +829,10 @@ function generateFromSchema (root, schema, numSamples = 10, numNodeInstances = 1
               categories.push(choices[index])
             }
             nodeChildren[fieldName] = new Category(nodeName, categories)
+
+            // Generate non-singleton text, ordinal or datetime.
+          } else {
+            nodeChildren[fieldName] = randomValueFile(fieldName, field, classes, numNodeInstances)
           }
         }
 
@@ -751,19 +989,66 @@ function loadTensor (root, relPath, name, opener, metadataOnly = false, subtype
   let reader = opener(root, filePath, false, true)
 
   if (subtype === 'default') {
-    let npyReader = new jsnpy.NpyReader(reader)
+    let tensor = readNpy(reader, filePath, metadataOnly)
+    return new Tensor(name, tensor.shape, tensor.data, tensor.dtype, subtype)
+  } else if (subtype === 'npz') {
+    let members = null
+    try {
+      members = jsnpz.readArchive(reader)
+    } catch (error) {
+      throw new DatasetException('Tensor file is not a valid NPZ archive.', filePath)
+    } finally {
+      reader.close()
+    }
+    let memberNames = Object.keys(members)
+    if (memberNames.length !== 1 || memberNames[0].endsWith('.npy') === false) {
+      throw new DatasetException('NPZ tensor file must contain exactly one array.', filePath)
+    }
+    let memberReader = new jsnpz.ArrayReaderWriterCloser(members[memberNames[0]])
+    let tensor = readNpy(memberReader, filePath, metadataOnly)
+    return new Tensor(name, tensor.shape, tensor.data, tensor.dtype, subtype)
+  } else if (subtype === 'coo') {
+    // The archive holds the tensor shape, the element coordinates and the element values.
+    let members = null
+    try {
+      members = jsnpz.readArchive(reader)
+    } catch (error) {
+      throw new DatasetException('Tensor file is not a valid NPZ archive.', filePath)
+    } finally {
+      reader.close()
+    }
+    let readMember = function (memberName, metadataOnly) {
+      if ((memberName in members) === false) {
+        throw new DatasetException("Sparse tensor file must contain the '" + memberName + "' array.", filePath)
+      }
+      return readNpy(new jsnpz.ArrayReaderWriterCloser(members[memberName]), filePath, metadataOnly)
+    }
 
-    let data = null
-    if (metadataOnly === false) {
-      data = npyReader.read(false)
+    let shape = readMember('shape.npy', false)
+    if (shape.dtype !== 'int64' || shape.data.length === 0) {
+      throw new DatasetException('Sparse tensor shape must be a non-empty int64 array.', filePath)
     }
-    reader.close()
+    let dimensions = Array.from(shape.data, x => Number(x))
 
-    if (npyReader.dtype !== 'f8') {
-      throw new DatasetException('Tensor datatype must be float64.', filePath)
+    let values = readMember('values.npy', metadataOnly)
+    let coords = readMember('coords.npy', metadataOnly)
+    if (coords.dtype !== 'int64' || coords.shape.length !== 2 || coords.shape[1] !== dimensions.length ||
+        values.shape.length !== 1 || values.shape[0] !== coords.shape[0]) {
+      throw new DatasetException('Sparse tensor coordinates must be an int64 matrix with one row per value.', filePath)
+    }
+
+    let data = null
+    if (metadataOnly === false) {
+      for (let i = 0; i < coords.data.length; i++) {
+        let coord = Number(coords.data[i])
+        if (coord < 0 || coord >= dimensions[i % dimensions.length]) {
+          throw new DatasetException('Sparse tensor coordinate out of bounds.', filePath)
+        }
+      }
+      data = new SparseData(coords.data, values.data)
     }
 
-    return new Tensor(name, npyReader.shape, data)
+    return new Tensor(name, dimensions, data, values.dtype, subtype)
   } else if (subtype === 'csv') {
     let lines = reader.readLines()
     let data = []
@@ -779,19 +1064,58 @@ function loadTensor (root, relPath, name, opener, metadataOnly = false, subtype
     data = new Float64Array(data)
     let shape = lines.length > 1 ? [lines.length, lineLength] : [lineLength]
 
-    return new Tensor(name, shape, data)
+    return new Tensor(name, shape, data, 'float64', subtype)
   } else {
     throw new DatasetException("Unknown tensor subtype '" + subtype + "'.", filePath)
   }
 }
 
+function readNpy (reader, filePath, metadataOnly) {
+  let npyReader = null
+  try {
+    npyReader = new jsnpy.NpyReader(reader)
+  } catch (error) {
+    reader.close()
+    throw new DatasetException('Tensor file is not a valid NPY file.', filePath)
+  }
+
+  let data = null
+  if (metadataOnly === false) {
+    data = npyReader.read(false)
+  }
+  reader.close()
+
+  if ((npyReader.dtype in NPY_DTYPES) === false) {
+    throw new DatasetException("Tensor datatype '" + npyReader.dtype + "' is not supported.", filePath)
+  }
+
+  return { 'shape': npyReader.shape, 'data': data, 'dtype': NPY_DTYPES[npyReader.dtype] }
+}
+
 function dumpTensor (self, root, relPath, name, opener) {
   let filePath = path.join(relPath, name + TYPE_EXTENSIONS['tensor'][self.subtype])
   let writer = opener(root, filePath, false, false)
 
   if (self.subtype === 'default') {
-    let npyWriter = new jsnpy.NpyWriter(writer, self.dimensions, 'f8')
-    npyWriter.write(self.data)
+    writeNpy(self, writer)
+  } else if (self.subtype === 'npz') {
+    let memberWriter = new jsnpz.ArrayReaderWriterCloser()
+    writeNpy(self, memberWriter)
+    jsnpz.writeArchive(writer, { 'arr_0.npy': memberWriter.arrayBuffer() })
+  } else if (self.subtype === 'coo') {
+    let nnz = self.data.values.length
+    let members = {
+      'shape.npy': new Tensor('shape', [self.dimensions.length], new BigInt64Array(self.dimensions.map(x => BigInt(x))), 'int64'),
+      'coords.npy': new Tensor('coords', [nnz, self.dimensions.length], self.data.coords, 'int64'),
+      'values.npy': new Tensor('values', [nnz], self.data.values, self.dtype)
+    }
+    let archive = {}
+    for (let memberName in members) {
+      let memberWriter = new jsnpz.ArrayReaderWriterCloser()
+      writeNpy(members[memberName], memberWriter)
+      archive[memberName] = memberWriter.arrayBuffer()
+    }
+    jsnpz.writeArchive(writer, archive)
   } else if (self.subtype === 'csv') {
     let numLines = self.dimensions.length > 1 ? self.dimensions[0] : 1
     let lineLength = self.dimensions.length > 1 ? self.dimensions[1] : self.dimensions[0]
@@ -812,6 +1136,12 @@ function dumpTensor (self, root, relPath, name, opener) {
   }
 }
 
+function writeNpy (self, writer) {
+  let dtype = Object.keys(NPY_DTYPES).find(key => NPY_DTYPES[key] === self.dtype)
+  let npyWriter = new jsnpy.NpyWriter(writer, self.dimensions, dtype)
+  npyWriter.write(self.data)
+}
+
 function Category (name, categories) {
   File.call(this, name, 'category')
   this.categories = categories
@@ -852,6 +1182,169 @@ Category.prototype.belongsToSet = function (categorySet) {
   return true
 }
 
+// Text holds one string per line. Each line corresponds to one node instance.
+function Text (name, texts) {
+  File.call(this, name, 'text')
+  this.texts = texts
+}
+
+Text.prototype = Object.create(File.prototype)
+Text.prototype.constructor = Text
+
+function loadText (root, relPath, name, opener, metadataOnly = false, subtype = 'default') {
+  let filePath = path.join(relPath, name + TYPE_EXTENSIONS['text'][subtype])
+  let reader = opener(root, filePath, false, true)
+  let texts = readValueLines(reader, filePath, 'text', MAX_TEXT_LENGTH)
+  return new Text(name, texts)
+}
+
+function dumpText (self, root, relPath, name, opener) {
+  let filePath = path.join(relPath, name + TYPE_EXTENSIONS['text'][self.subtype])
+  if (self.texts.some(x => /[\r\n]/.test(x))) {
+    throw new DatasetException('Texts cannot contain line breaks.', filePath)
+  }
+  let writer = opener(root, filePath, false, false)
+  writer.writeLines(self.texts)
+}
+
+// Ordinal holds one ordered category per line. The order of categories is given by the order of lines in
+// the class file.
+function Ordinal (name, values) {
+  File.call(this, name, 'ordinal')
+  this.values = values
+}
+
+Ordinal.prototype = Object.create(File.prototype)
+Ordinal.prototype.constructor = Ordinal
+
+function loadOrdinal (root, relPath, name, opener, metadataOnly = false, subtype = 'default') {
+  let filePath = path.join(relPath, name + TYPE_EXTENSIONS['ordinal'][subtype])
+  let reader = opener(root, filePath, false, true)
+  let values = readValueLines(reader, filePath, 'ordinal', MAX_VALUE_LENGTH).map(x => x.trim())
+  return new Ordinal(name, values)
+}
+
+function dumpOrdinal (self, root, relPath, name, opener) {
+  let filePath = path.join(relPath, name + TYPE_EXTENSIONS['ordinal'][self.subtype])
+  let writer = opener(root, filePath, false, false)
+  writer.writeLines(self.values)
+}
+
+Ordinal.prototype.belongsToSet = function (categorySet) {
+  assert(categorySet instanceof Set)
+  return this.values.every(x => categorySet.has(x))
+}
+
+// Datetime holds one RFC 3339 timestamp per line. The timestamps are kept as dates in UTC so their
+// precision is limited to milliseconds.
+function Datetime (name, times) {
+  File.call(this, name, 'datetime')
+  this.times = times
+}
+
+Datetime.prototype = Object.create(File.prototype)
+Datetime.prototype.constructor = Datetime
+
+function loadDatetime (root, relPath, name, opener, metadataOnly = false, subtype = 'default') {
+  let filePath = path.join(relPath, name + TYPE_EXTENSIONS['datetime'][subtype])
+  let reader = opener(root, filePath, false, true)
+  let lines = readValueLines(reader, filePath, 'datetime', MAX_VALUE_LENGTH)
+
+  let times = []
+  for (let i = 0; i < lines.length; i++) {
+    let value = parseDatetime(lines[i].trim())
+    if (value === null) {
+      throw new DatasetException('Line ' + (i + 1) + ' of the datetime file is not an RFC 3339 timestamp.', filePath)
+    }
+    times.push(value)
+  }
+
+  return new Datetime(name, times)
+}
+
+function dumpDatetime (self, root, relPath, name, opener) {
+  let filePath = path.join(relPath, name + TYPE_EXTENSIONS['datetime'][self.subtype])
+  let writer = opener(root, filePath, false, false)
+  writer.writeLines(self.times.map(formatDatetime))
+}
+
+// Reads the lines of a text, ordinal or datetime file. Only line feeds separate lines and a trailing carriage
+// return is dropped from each line.
+function readValueLines (reader, filePath, fileType, maxLength) {
+  let bytes = new Uint8Array(reader.readAll())
+  reader.close()
+
+  let kind = fileType.charAt(0).toUpperCase() + fileType.slice(1)
+  let decoder = new TextDecoder('utf-8', { 'fatal': true })
+  let lines = []
+  let start = 0
+  while (start < bytes.length) {
+    let end = bytes.indexOf(10, start)
+    if (end < 0) {
+      end = bytes.length
+    }
+    if (end - start > maxLength) {
+      throw new DatasetException(kind + ' file could not be read. Line ' + (lines.length + 1) + ' is too long.', filePath)
+    }
+    try {
+      lines.push(decoder.decode(bytes.subarray(start, end)).replace(/\r+$/, ''))
+    } catch (error) {
+      throw new DatasetException(kind + ' file could not be read. It is not UTF-8 encoded.', filePath)
+    }
+    start = end + 1
+  }
+  return lines
+}
+
+// Parses an RFC 3339 timestamp. Returns null if the value is not valid.
+function parseDatetime (value) {
+  let m = DATETIME_FORMAT.exec(value)
+  if (m === null) {
+    return null
+  }
+  let [year, month, day, hour, minute, second] = m.slice(1, 7).map(Number)
+  let millisecond = m[7] ? Number((m[7].slice(1) + '000').slice(0, 3)) : 0
+
+  let date = new Date(0)
+  date.setUTCFullYear(year, month - 1, day)
+  date.setUTCHours(hour, minute, second, millisecond)
+  if (date.getUTCFullYear() !== year || date.getUTCMonth() !== month - 1 || date.getUTCDate() !== day ||
+      hour > 23 || minute > 59 || second > 59) {
+    return null
+  }
+
+  if (m[8] !== 'Z') {
+    let offset = Number(m[8].slice(1, 3)) * 60 + Number(m[8].slice(4, 6))
+    date.setTime(date.getTime() - (m[8][0] === '-' ? -offset : offset) * 60000)
+  }
+  return date
+}
+
+// Formats a timestamp according to RFC 3339 with trailing zeros of the fractional seconds dropped.
+function formatDatetime (value) {
+  return value.toISOString().replace(/\.(\d*?)0*Z$/, (match, fraction) => (fraction.length > 0 ? '.' + fraction : '') + 'Z')
+}
+
+// Returns the schema field of a text, ordinal or datetime file and the number of values it holds. Ordinals
+// belong to the first class which contains all their values. If no field can be inferred, the returned field
+// is null and the message explains why.
+function inferValueField (file, categoryClassSets) {
+  switch (file.fileType) {
+    case 'text':
+      return [new sch.Text(), file.texts.length, null]
+    case 'datetime':
+      return [new sch.Datetime(), file.times.length, null]
+    case 'ordinal':
+      for (let className in categoryClassSets) {
+        if (file.belongsToSet(categoryClassSets[className])) {
+          return [new sch.Ordinal(className), file.values.length, null]
+        }
+      }
+      return [null, 0, 'Ordinal file does not match any class.']
+  }
+  return [null, 0, "Files of type '" + file.fileType + "' are unexpected."]
+}
+
 function InstanceId (node, index) {
   assert(typeof node === 'string')
   assert(Number.isInteger(index))
@@ -1154,6 +1647,160 @@ function dumpClass (self, root, relPath, name, opener) {
   writer.writeLines(self.categories)
 }
 
+// Images keep their encoded content as data since there is no image decoder available.
+function Image (name, height, width, channels, data = null, subtype = 'png') {
+  File.call(this, name, 'image', subtype)
+  assert([1, 3, 4].indexOf(channels) >= 0)
+  this.height = height
+  this.width = width
+  this.channels = channels
+  this.data = data
+}
+
+Image.prototype = Object.create(File.prototype)
+Image.prototype.constructor = Image
+
+// Returns the shape of the image as (height, width, channels).
+Image.prototype.dimensions = function () {
+  return [this.height, this.width, this.channels]
+}
+
+function loadImage (root, relPath, name, opener, metadataOnly = false, subtype = 'png') {
+  let filePath = path.join(relPath, name + TYPE_EXTENSIONS['image'][subtype])
+  let reader = opener(root, filePath, false, true)
+  let buffer = reader.readAll()
+  reader.close()
+
+  let header = readImageHeader(new DataView(buffer))
+  if (header === null || (header.format === 'png') !== (subtype === 'png')) {
+    throw new DatasetException('Image file is not a valid PNG or JPEG image.', filePath)
+  }
+
+  let data = metadataOnly ? null : buffer
+  return new Image(name, header.height, header.width, header.channels, data, subtype)
+}
+
+function dumpImage (self, root, relPath, name, opener) {
+  let filePath = path.join(relPath, name + TYPE_EXTENSIONS['image'][self.subtype])
+  if (self.data === null) {
+    throw new DatasetException('Cannot write image without data.', filePath)
+  }
+  let writer = opener(root, filePath, false, false)
+  writer.write(self.data, 0, self.data.byteLength, 0)
+  writer.close()
+}
+
+// Returns the format, height, width and number of channels of a PNG or JPEG image or null if the
+// content is not a valid image.
+function readImageHeader (view) {
+  if (view.byteLength >= 2 && view.getUint16(0) === 0xffd8) {
+    return readJpegHeader(view)
+  }
+  const pngSignature = [0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a]
+  if (view.byteLength < 8 || pngSignature.some((x, i) => view.getUint8(i) !== x)) {
+    return null
+  }
+  return readPngHeader(view)
+}
+
+function readPngHeader (view) {
+  let width = null
+  let height = null
+  let colorType = null
+  let transparent = false
+
+  let pos = 8
+  while (true) {
+    if (pos + 8 > view.byteLength) {
+      return null
+    }
+    const length = view.getUint32(pos)
+    const chunkType = String.fromCharCode(view.getUint8(pos + 4), view.getUint8(pos + 5), view.getUint8(pos + 6), view.getUint8(pos + 7))
+    const dataPos = pos + 8
+    if (dataPos + length > view.byteLength) {
+      return null
+    }
+    pos = dataPos + length + 4
+
+    if (chunkType === 'IHDR' && length >= 13) {
+      width = view.getUint32(dataPos)
+      height = view.getUint32(dataPos + 4)
+      colorType = view.getUint8(dataPos + 9)
+      // Only paletted images need the transparency chunk.
+      if (colorType !== 3) {
+        break
+      }
+    } else if (width === null) {
+      return null
+    } else if (chunkType === 'tRNS') {
+      for (let i = 0; i < length; i++) {
+        transparent = transparent || view.getUint8(dataPos + i) !== 255
+      }
+      break
+    } else if (chunkType === 'IDAT' || chunkType === 'IEND') {
+      break
+    }
+  }
+
+  // Images with an alpha channel and paletted images with a transparent color have four channels.
+  const channels = { 0: 1, 2: 3, 3: transparent ? 4 : 3, 4: 4, 6: 4 }[colorType]
+  if (channels === undefined) {
+    return null
+  }
+  return { 'format': 'png', 'height': height, 'width': width, 'channels': channels }
+}
+
+function readJpegHeader (view) {
+  let pos = 2
+  while (true) {
+    if (pos + 2 > view.byteLength || view.getUint8(pos) !== 0xff) {
+      return null
+    }
+    let code = view.getUint8(pos + 1)
+    pos += 2
+    while (code === 0xff) {
+      if (pos >= view.byteLength) {
+        return null
+      }
+      code = view.getUint8(pos)
+      pos++
+    }
+
+    // Markers without a segment.
+    if (code === 0x01 || code === 0xd8 || (code >= 0xd0 && code <= 0xd7)) {
+      continue
+    }
+    // The image data starts before the frame header was found.
+    if (code === 0xd9 || code === 0xda) {
+      return null
+    }
+
+    if (pos + 2 > view.byteLength || view.getUint16(pos) < 2) {
+      return null
+    }
+    const length = view.getUint16(pos) - 2
+    const dataPos = pos + 2
+    if (dataPos + length > view.byteLength) {
+      return null
+    }
+    pos = dataPos + length
+
+    // Only baseline and progressive images are supported.
+    if (code === 0xc0 || code === 0xc1 || code === 0xc2) {
+      if (length < 6) {
+        return null
+      }
+      const components = view.getUint8(dataPos + 5)
+      if ([1, 3, 4].indexOf(components) < 0) {
+        return null
+      }
+      return { 'format': 'jpeg', 'height': view.getUint16(dataPos + 1), 'width': view.getUint16(dataPos + 3), 'channels': components }
+    } else if (code >= 0xc3 && code <= 0xcf && [0xc4, 0xc8, 0xcc].indexOf(code) < 0) {
+      return null
+    }
+  }
+}
+
 export default {
   'FILE_TYPES': FILE_TYPES,
   'TYPE_EXTENSIONS': TYPE_EXTENSIONS,
@@ -1163,11 +1810,16 @@ export default {
   'Dataset': Dataset,
   'Directory': Directory,
   'Tensor': Tensor,
+  'SparseData': SparseData,
   'Category': Category,
+  'Text': Text,
+  'Ordinal': Ordinal,
+  'Datetime': Datetime,
   'InstanceId': InstanceId,
   'Link': Link,
   'Links': Links,
   'Class': Class,
+  'Image': Image,
   'DatasetException': DatasetException,
   'ReaderWriterCloser': ReaderWriterCloser
 }
@@ -73,6 +73,14 @@ ReaderWriterCloser.prototype.readLines = function () {
   return lines
 }
 
+ReaderWriterCloser.prototype.readAll = function () {
+  // Read the whole file into an array buffer.
+  let stats = fs.fstatSync(this.fd)
+  let buffer = new ArrayBuffer(stats.size)
+  let size = fs.readSync(this.fd, Buffer.from(buffer), 0, stats.size, 0)
+  return buffer.slice(0, size)
+}
+
 function readLinesFromDataView (view, size) {
   let data = []
   let lineEnd = false
@@ -110,7 +118,7 @@ ReaderWriterCloser.prototype.write = function (buffer, offset, length, position)
 
 ReaderWriterCloser.prototype.writeLines = function (data) {
   // Returns number of bytes read.
-  return fs.writeSync(this.fd, '\n'.join(data) + '\n')
+  return fs.writeSync(this.fd, data.map(x => x + '\n').join(''))
 }
 
 ReaderWriterCloser.prototype.close = function () {
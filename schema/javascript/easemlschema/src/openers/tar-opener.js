@@ -113,6 +113,10 @@ ReaderWriterCloser.prototype.readLines = function () {
   return lines
 }
 
+ReaderWriterCloser.prototype.readAll = function () {
+  return this.arrayBuffer.slice(0)
+}
+
 function readLinesFromDataView (view, size) {
   let data = []
   let lineEnd = false
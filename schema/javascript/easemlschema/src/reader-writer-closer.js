@@ -11,6 +11,10 @@ ReaderWriterCloser.prototype.readLines = function () {
   throw new Error('Not implemented')
 }
 
+ReaderWriterCloser.prototype.readAll = function () {
+  throw new Error('Not implemented')
+}
+
 ReaderWriterCloser.prototype.write = function (buffer, offset, length, position) {
   throw new Error('Not implemented')
 }
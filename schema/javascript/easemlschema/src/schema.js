@@ -6,6 +6,9 @@ import Permutation from './util/iterative-permutation'
 const NAME_FORMAT = new RegExp('^[a-z_][0-9a-z_]*$')
 const DIM_FORMAT = new RegExp('^[a-z_][0-9a-z_]*[+?*]?$')
 
+// Element types a tensor can declare.
+const TENSOR_DTYPES = ['float64', 'float32', 'float16', 'int64', 'int32', 'int16', 'int8', 'uint8']
+
 function SchemaException (message, path = '') {
   this.message = message
   this.path = path
@@ -81,7 +84,7 @@ function Schema (nodes, categoryClasses, cyclic = false, undirected = false, fan
       }
     }
 
-    // Check category field classes.
+    // Check category and ordinal field classes.
     for (let f in nodes[k].fields) {
       if (nodes[k].fields[f] instanceof Category) {
         if (!(nodes[k].fields[f].categoryClass in categoryClasses)) {
@@ -99,7 +102,7 @@ function Schema (nodes, categoryClasses, cyclic = false, undirected = false, fan
   // Check if there are unreferenced classes.
   if (orphanClasses.size > 0) {
     const iterator1 = orphanClasses[Symbol.iterator]()
-    throw new SchemaException('Every declared class must be referenced in a category.',
+    throw new SchemaException('Every declared class must be referenced in a category or an ordinal.',
       'classes.' + iterator1.next().value)
   }
 
@@ -593,6 +596,9 @@ function matchNode (self, source, dimMap, classNameMap, nodeNameMap, selfClasses
   let dimMapUpdates = {}
   let classNameMapUpdates = {}
 
+  // Categories and ordinals are both matched based on their classes. Text and datetime fields have
+  // no properties so they are matched by type only.
+  let typeCounts = {}
   let selfTensorNames = []
   let selfCategoryNames = []
   for (let k in self.fields) {
@@ -601,6 +607,7 @@ function matchNode (self, source, dimMap, classNameMap, nodeNameMap, selfClasses
     } else if (self.fields[k] instanceof Category) {
       selfCategoryNames.push(k)
     }
+    typeCounts[self.fields[k].fieldType] = (typeCounts[self.fields[k].fieldType] || 0) + 1
   }
 
   let sourceTensorNames = []
@@ -611,11 +618,11 @@ function matchNode (self, source, dimMap, classNameMap, nodeNameMap, selfClasses
     } else if (source.fields[k] instanceof Category) {
       sourceCategoryNames.push(k)
     }
+    typeCounts[source.fields[k].fieldType] = (typeCounts[source.fields[k].fieldType] || 0) - 1
   }
 
   // Simply dismiss in case the counts don't match.
-  if ((selfTensorNames.length !== sourceTensorNames.length) ||
-        (selfCategoryNames.length !== sourceCategoryNames.length) ||
+  if (Object.values(typeCounts).some(count => count !== 0) ||
         (Object.keys(self.links).length !== Object.keys(source.links).length)) {
     if (buildMatching) {
       return [null, {}, {}]
@@ -739,6 +746,15 @@ function matchNode (self, source, dimMap, classNameMap, nodeNameMap, selfClasses
     }
   }
 
+  // Fields without properties are interchangeable so we pair them up by type in the order of their names.
+  for (let fieldType of ['text', 'datetime']) {
+    let selfNames = Object.keys(self.fields).filter(k => self.fields[k].fieldType === fieldType).sort()
+    let sourceNames = Object.keys(source.fields).filter(k => source.fields[k].fieldType === fieldType).sort()
+    for (let i = 0; i < selfNames.length; i++) {
+      fieldNameMap[selfNames[i]] = sourceNames[i]
+    }
+  }
+
   // If a matching was found, build a resulting matching node if needed.
   if (buildMatching) {
     let links = {}
@@ -824,7 +840,7 @@ function matchLink (self, source) {
 }
 
 function Field (fieldType, srcName = null) {
-  assert(['tensor', 'category'].indexOf(fieldType) >= 0)
+  assert(['tensor', 'category', 'ordinal', 'text', 'datetime'].indexOf(fieldType) >= 0)
 
   if (srcName !== null) {
     if (typeof srcName !== 'string') {
@@ -846,6 +862,7 @@ function dumpField (self) {
       result = dumpTensor(self)
       break
     case 'category':
+    case 'ordinal':
       result = dumpCategory(self)
       break
   }
@@ -867,12 +884,18 @@ function loadField (input) {
       return loadTensor(input)
     case 'category':
       return loadCategory(input)
+    case 'ordinal':
+      return loadOrdinal(input)
+    case 'text':
+      return loadText(input)
+    case 'datetime':
+      return loadDatetime(input)
     default:
       throw new SchemaException("Unknown field type '" + fieldType + "'.")
   }
 }
 
-function Tensor (dim, srcName = null, srcDim = null) {
+function Tensor (dim, srcName = null, srcDim = null, dtype = null, sparse = false) {
   Field.call(this, 'tensor', srcName)
 
   // Simple type and value checks.
@@ -882,6 +905,17 @@ function Tensor (dim, srcName = null, srcDim = null) {
   if (dim.length < 1) {
     throw new SchemaException('Tensor must have at least one dimension.')
   }
+  if (dtype !== null) {
+    if (typeof dtype !== 'string') {
+      throw new SchemaException('Tensor dtype must be a string.')
+    }
+    if (TENSOR_DTYPES.indexOf(dtype) < 0) {
+      throw new SchemaException('Tensor dtype must be one of: ' + TENSOR_DTYPES.join(', ') + '.')
+    }
+  }
+  if (typeof sparse !== 'boolean') {
+    throw new SchemaException('Tensor sparse field must be a boolean.')
+  }
 
   // Type and value checks for each dimension.
   for (let i in dim) {
@@ -940,6 +974,8 @@ function Tensor (dim, srcName = null, srcDim = null) {
 
   this.dim = dim
   this.srcDim = srcDim
+  this.dtype = dtype
+  this.sparse = sparse
 }
 
 Tensor.prototype = Object.create(Field.prototype)
@@ -961,6 +997,12 @@ function dumpTensor (self) {
   if (self.srcDim !== null) {
     result['src-dim'] = self.srcDim
   }
+  if (self.dtype !== null) {
+    result['dtype'] = self.dtype
+  }
+  if (self.sparse) {
+    result['sparse'] = true
+  }
   return result
 }
 
@@ -968,18 +1010,30 @@ function loadTensor (input) {
   let dim = 'dim' in input ? input['dim'] : null
   let srcName = 'src-name' in input ? input['src-name'] : null
   let srcDim = 'src-dim' in input ? input['src-dim'] : null
+  let dtype = 'dtype' in input ? input['dtype'] : null
+  let sparse = 'sparse' in input ? input['sparse'] : false
 
   if (dim === null) {
     throw new SchemaException("Tensor must have a 'dim' field.")
   }
 
-  return new Tensor(dim, srcName, srcDim)
+  return new Tensor(dim, srcName, srcDim, dtype, sparse)
 }
 
 function matchTensor (self, source, dimMap) {
   assert(typeof dimMap === 'object')
   assert(source instanceof Tensor)
 
+  // A tensor without a dtype accepts any dtype.
+  if (self.dtype !== null && source.dtype !== null && self.dtype !== source.dtype) {
+    return [false, {}]
+  }
+
+  // A sparse tensor accepts dense tensors as well, but a dense tensor does not accept sparse ones.
+  if (source.sparse && self.sparse === false) {
+    return [false, {}]
+  }
+
   let [match, dimMapUpdate] = matchDimList(self.dim, source.dim, dimMap)
   return [match, dimMapUpdate]
 }
@@ -1090,15 +1144,16 @@ function matchDimList (listA, listB, dimMap = null) {
   return [false, {}]
 }
 
-function Category (categoryClass, srcName = null) {
-  Field.call(this, 'category', srcName)
+function Category (categoryClass, srcName = null, fieldType = 'category') {
+  Field.call(this, fieldType, srcName)
 
   // Simple type and value checks.
+  let kind = fieldType.charAt(0).toUpperCase() + fieldType.slice(1)
   if (typeof categoryClass !== 'string') {
-    throw new SchemaException('Category class must be a string.')
+    throw new SchemaException(kind + ' class must be a string.')
   }
   if (NAME_FORMAT.test(categoryClass) === false) {
-    throw new SchemaException('Category class may contain lowercase letters, numbers and underscores. They must start with a letter.')
+    throw new SchemaException(kind + ' class may contain lowercase letters, numbers and underscores. They must start with a letter.')
   }
 
   this.categoryClass = categoryClass
@@ -1134,6 +1189,11 @@ function matchCategory (self, source, dimMap, classNameMap, selfClasses, sourceC
   assert(typeof classNameMap === 'object')
   assert(source instanceof Category)
 
+  // Categories never match ordinals even if their classes match.
+  if (source.fieldType !== self.fieldType) {
+    return [false, {}, {}]
+  }
+
   // If the class has already been mapped then we simply compare.
   if (self.categoryClass in classNameMap) {
     return [source.categoryClass === classNameMap[self.categoryClass], {}, {}]
@@ -1153,6 +1213,59 @@ function matchCategory (self, source, dimMap, classNameMap, selfClasses, sourceC
   }
 }
 
+// An ordinal is a category whose values are ordered. The order is given by the order of categories in the class.
+function Ordinal (categoryClass, srcName = null) {
+  Category.call(this, categoryClass, srcName, 'ordinal')
+}
+
+Ordinal.prototype = Object.create(Category.prototype)
+Ordinal.prototype.constructor = Ordinal
+
+function loadOrdinal (input) {
+  let categoryClass = 'class' in input ? input['class'] : null
+  let srcName = 'src-name' in input ? input['src-name'] : null
+
+  if (categoryClass === null) {
+    throw new SchemaException("Ordinal must have a 'class' field.")
+  }
+
+  return new Ordinal(categoryClass, srcName)
+}
+
+// A text field holds raw strings of arbitrary length.
+function Text (srcName = null) {
+  Field.call(this, 'text', srcName)
+}
+
+Text.prototype = Object.create(Field.prototype)
+Text.prototype.constructor = Text
+
+Text.prototype.isVariable = function () {
+  return false
+}
+
+function loadText (input) {
+  let srcName = 'src-name' in input ? input['src-name'] : null
+  return new Text(srcName)
+}
+
+// A datetime field holds points in time.
+function Datetime (srcName = null) {
+  Field.call(this, 'datetime', srcName)
+}
+
+Datetime.prototype = Object.create(Field.prototype)
+Datetime.prototype.constructor = Datetime
+
+Datetime.prototype.isVariable = function () {
+  return false
+}
+
+function loadDatetime (input) {
+  let srcName = 'src-name' in input ? input['src-name'] : null
+  return new Datetime(srcName)
+}
+
 function Class (dim, srcName = null) {
   // Simple type and value checks.
   if (Number.isInteger(dim) === false && typeof dim !== 'string') {
@@ -1227,7 +1340,11 @@ export default {
   'Link': Link,
   'Tensor': Tensor,
   'Category': Category,
+  'Ordinal': Ordinal,
+  'Text': Text,
+  'Datetime': Datetime,
   'Class': Class,
   'SchemaException': SchemaException,
+  'TENSOR_DTYPES': TENSOR_DTYPES,
   'load': load
 }
@@ -3,14 +3,16 @@
 import assert from 'assert'
 import ReaderWriterCloser from '../reader-writer-closer'
 
-const DATA_TYPES = ['f8', 'f4', 'i8', 'i4', 'i2', 'i1']
+const DATA_TYPES = ['f8', 'f4', 'f2', 'i8', 'i4', 'i2', 'i1', 'u1']
 const BYTES_PER_ELEMENT = {
   'f8': 8,
   'f4': 4,
+  'f2': 2,
   'i8': 8,
   'i4': 4,
   'i2': 2,
-  'i1': 1
+  'i1': 1,
+  'u1': 1
 }
 
 function NpyWriter (writer, shape, dtype, columnMajor = false, bigEndian = false, version = 1) {
@@ -118,10 +120,9 @@ function readHeader (reader) {
   const lengthView = new DataView(lengthBuffer)
   const headerLength = (versionMajor === 1) ? lengthView.getUint16(0, true) : lengthView.getUint32(0, true)
 
-  // Read the header.
-  const headerDictLength = headerLength - lengthBytes - 8
-  const headerBuffer = new ArrayBuffer(headerDictLength)
-  reader.read(headerBuffer, 0, headerDictLength, lengthBytes + 8)
+  // Read the header. The header length does not include the magic string, version and length fields.
+  const headerBuffer = new ArrayBuffer(headerLength)
+  reader.read(headerBuffer, 0, headerLength, lengthBytes + 8)
   const headerView = new DataView(headerBuffer)
   const headerString = readDataViewAsString(headerView)
 
@@ -207,9 +208,15 @@ NpyWriter.prototype.write = function (data, close = true) {
         pos += ELEM_BYTES
       }
       break
+    case 'f2':
+      for (let i = 0; i < data.length; i++) {
+        view.setUint16(pos, float16Bits(data[i]), !this.bigEndian)
+        pos += ELEM_BYTES
+      }
+      break
     case 'i8':
       for (let i = 0; i < data.length; i++) {
-        view.setInt64(pos, data[i], !this.bigEndian)
+        view.setBigInt64(pos, BigInt(data[i]), !this.bigEndian)
         pos += ELEM_BYTES
       }
 
@@ -231,15 +238,16 @@ NpyWriter.prototype.write = function (data, close = true) {
 
     case 'i1':
       for (let i = 0; i < data.length; i++) {
-        view.setInt8(pos, data[i], !this.bigEndian)
+        view.setInt8(pos, data[i])
+        pos += ELEM_BYTES
+      }
+      break
+    case 'u1':
+      for (let i = 0; i < data.length; i++) {
+        view.setUint8(pos, data[i])
         pos += ELEM_BYTES
       }
       break
-  }
-
-  // Close the writer if specified.
-  if (close) {
-    this.writer.close()
   }
 
   // Write the buffer to the file.
@@ -247,6 +255,11 @@ NpyWriter.prototype.write = function (data, close = true) {
 
   // Shift the position by the amount of data we've just written.
   this.pos += BUFFER_SIZE
+
+  // Close the writer if specified.
+  if (close) {
+    this.writer.close()
+  }
 }
 
 NpyReader.prototype.read = function (close = true) {
@@ -271,9 +284,18 @@ NpyReader.prototype.read = function (close = true) {
     case 'f4':
       return new Float32Array(BUFFER)
 
+    case 'f2': {
+      // Half precision floats are widened since there is no typed array for them.
+      const view = new DataView(BUFFER)
+      const result = new Float32Array(dataLength)
+      for (let i = 0; i < dataLength; i++) {
+        result[i] = float16Value(view.getUint16(i * ELEM_BYTES, !this.bigEndian))
+      }
+      return result
+    }
+
     case 'i8':
-      // eslint-disable-next-line no-undef
-      return new Int64Array(BUFFER)
+      return new BigInt64Array(BUFFER)
 
     case 'i4':
       return new Int32Array(BUFFER)
@@ -283,7 +305,41 @@ NpyReader.prototype.read = function (close = true) {
 
     case 'i1':
       return new Int8Array(BUFFER)
+
+    case 'u1':
+      return new Uint8Array(BUFFER)
+  }
+}
+
+function float16Value (bits) {
+  const sign = (bits & 0x8000) ? -1 : 1
+  const exponent = (bits >> 10) & 0x1f
+  const fraction = bits & 0x3ff
+  if (exponent === 0) {
+    return sign * Math.pow(2, -14) * (fraction / 1024)
+  } else if (exponent === 0x1f) {
+    return fraction ? NaN : sign * Infinity
+  }
+  return sign * Math.pow(2, exponent - 15) * (1 + fraction / 1024)
+}
+
+function float16Bits (value) {
+  const sign = (value < 0 || Object.is(value, -0)) ? 0x8000 : 0
+  value = Math.abs(value)
+  if (isNaN(value)) {
+    return 0x7e00
+  } else if (value >= 65520) {
+    return sign | 0x7c00
+  } else if (value < Math.pow(2, -14)) {
+    return sign | Math.round(value / Math.pow(2, -24))
+  }
+  let exponent = Math.floor(Math.log2(value))
+  let fraction = Math.round((value / Math.pow(2, exponent) - 1) * 1024)
+  if (fraction === 1024) {
+    exponent++
+    fraction = 0
   }
+  return sign | ((exponent + 15) << 10) | fraction
 }
 
 export default {
@@ -0,0 +1,209 @@
+'use strict'
+
+import assert from 'assert'
+import zlib from 'zlib'
+import ReaderWriterCloser from '../reader-writer-closer'
+
+const LOCAL_HEADER_SIGNATURE = 0x04034b50
+const CENTRAL_HEADER_SIGNATURE = 0x02014b50
+const END_OF_CENTRAL_DIRECTORY_SIGNATURE = 0x06054b50
+const END_OF_CENTRAL_DIRECTORY_LENGTH = 22
+
+const METHOD_STORED = 0
+const METHOD_DEFLATE = 8
+
+// Reads all members of a zip archive into a map from member names to array buffers.
+function readArchive (reader) {
+  assert(reader instanceof ReaderWriterCloser)
+
+  const buffer = reader.readAll()
+  const view = new DataView(buffer)
+
+  // The end of central directory record is followed by a comment of variable length.
+  let end = -1
+  for (let pos = buffer.byteLength - END_OF_CENTRAL_DIRECTORY_LENGTH; pos >= 0; pos--) {
+    if (view.getUint32(pos, true) === END_OF_CENTRAL_DIRECTORY_SIGNATURE) {
+      end = pos
+      break
+    }
+  }
+  if (end < 0) {
+    throw new Error('The given file is not a valid zip archive.')
+  }
+  const numMembers = view.getUint16(end + 10, true)
+  let pos = view.getUint32(end + 16, true)
+
+  let result = {}
+  for (let i = 0; i < numMembers; i++) {
+    if (pos + 46 > buffer.byteLength || view.getUint32(pos, true) !== CENTRAL_HEADER_SIGNATURE) {
+      throw new Error('The given file is not a valid zip archive.')
+    }
+    const method = view.getUint16(pos + 10, true)
+    const compressedSize = view.getUint32(pos + 20, true)
+    const nameLength = view.getUint16(pos + 28, true)
+    const extraLength = view.getUint16(pos + 30, true)
+    const commentLength = view.getUint16(pos + 32, true)
+    const localOffset = view.getUint32(pos + 42, true)
+    const name = readString(buffer, pos + 46, nameLength)
+    pos += 46 + nameLength + extraLength + commentLength
+
+    // The local header repeats the name and may have a different extra field.
+    if (localOffset + 30 > buffer.byteLength || view.getUint32(localOffset, true) !== LOCAL_HEADER_SIGNATURE) {
+      throw new Error('The given file is not a valid zip archive.')
+    }
+    const dataOffset = localOffset + 30 + view.getUint16(localOffset + 26, true) + view.getUint16(localOffset + 28, true)
+    if (dataOffset + compressedSize > buffer.byteLength) {
+      throw new Error('The given file is not a valid zip archive.')
+    }
+    const data = buffer.slice(dataOffset, dataOffset + compressedSize)
+
+    if (method === METHOD_STORED) {
+      result[name] = data
+    } else if (method === METHOD_DEFLATE) {
+      const inflated = zlib.inflateRawSync(Buffer.from(data))
+      result[name] = inflated.buffer.slice(inflated.byteOffset, inflated.byteOffset + inflated.byteLength)
+    } else {
+      throw new Error('Unsupported zip compression method ' + method + '.')
+    }
+  }
+
+  return result
+}
+
+// Writes the given map from member names to array buffers as a zip archive with uncompressed members.
+function writeArchive (writer, members) {
+  assert(writer instanceof ReaderWriterCloser)
+
+  let localParts = []
+  let centralParts = []
+  let offset = 0
+  for (let name in members) {
+    const data = new Uint8Array(members[name])
+    const crc = crc32(data)
+
+    const local = new DataView(new ArrayBuffer(30 + name.length))
+    local.setUint32(0, LOCAL_HEADER_SIGNATURE, true)
+    local.setUint16(4, 20, true)
+    local.setUint16(8, METHOD_STORED, true)
+    local.setUint32(14, crc, true)
+    local.setUint32(18, data.length, true)
+    local.setUint32(22, data.length, true)
+    local.setUint16(26, name.length, true)
+    writeString(local, 30, name)
+    localParts.push(local.buffer, data.buffer.slice(data.byteOffset, data.byteOffset + data.byteLength))
+
+    const central = new DataView(new ArrayBuffer(46 + name.length))
+    central.setUint32(0, CENTRAL_HEADER_SIGNATURE, true)
+    central.setUint16(4, 20, true)
+    central.setUint16(6, 20, true)
+    central.setUint16(10, METHOD_STORED, true)
+    central.setUint32(16, crc, true)
+    central.setUint32(20, data.length, true)
+    central.setUint32(24, data.length, true)
+    central.setUint16(28, name.length, true)
+    central.setUint32(42, offset, true)
+    writeString(central, 46, name)
+    centralParts.push(central.buffer)
+
+    offset += local.byteLength + data.length
+  }
+
+  const centralSize = centralParts.reduce((a, b) => a + b.byteLength, 0)
+  const end = new DataView(new ArrayBuffer(END_OF_CENTRAL_DIRECTORY_LENGTH))
+  end.setUint32(0, END_OF_CENTRAL_DIRECTORY_SIGNATURE, true)
+  end.setUint16(8, centralParts.length, true)
+  end.setUint16(10, centralParts.length, true)
+  end.setUint32(12, centralSize, true)
+  end.setUint32(16, offset, true)
+
+  const parts = localParts.concat(centralParts, [end.buffer])
+  const result = new Uint8Array(offset + centralSize + END_OF_CENTRAL_DIRECTORY_LENGTH)
+  let pos = 0
+  for (let i = 0; i < parts.length; i++) {
+    result.set(new Uint8Array(parts[i]), pos)
+    pos += parts[i].byteLength
+  }
+
+  writer.write(result.buffer, 0, result.length, 0)
+  writer.close()
+}
+
+// ArrayReaderWriterCloser reads from and writes to an array buffer in memory. It is used to access the
+// arrays stored in an archive with the NPY reader and writer.
+function ArrayReaderWriterCloser (arrayBuffer = null) {
+  ReaderWriterCloser.call(this)
+  this.bytes = new Uint8Array(arrayBuffer || new ArrayBuffer(0))
+  this.size = this.bytes.length
+}
+ArrayReaderWriterCloser.prototype = Object.create(ReaderWriterCloser.prototype)
+ArrayReaderWriterCloser.prototype.constructor = ArrayReaderWriterCloser
+
+ArrayReaderWriterCloser.prototype.read = function (buffer, offset, length, position) {
+  length = Math.max(0, Math.min(length, this.size - position))
+  new Uint8Array(buffer).set(this.bytes.subarray(position, position + length), offset)
+
+  // Returns number of bytes read.
+  return length
+}
+
+ArrayReaderWriterCloser.prototype.readAll = function () {
+  return this.arrayBuffer()
+}
+
+ArrayReaderWriterCloser.prototype.write = function (buffer, offset, length, position) {
+  if (position + length > this.bytes.length) {
+    const bytes = new Uint8Array(Math.max(position + length, 2 * this.bytes.length))
+    bytes.set(this.bytes)
+    this.bytes = bytes
+  }
+  this.bytes.set(new Uint8Array(buffer, offset, length), position)
+  this.size = Math.max(this.size, position + length)
+
+  // Returns number of bytes written.
+  return length
+}
+
+ArrayReaderWriterCloser.prototype.close = function () {
+}
+
+// Returns the written content.
+ArrayReaderWriterCloser.prototype.arrayBuffer = function () {
+  return this.bytes.buffer.slice(0, this.size)
+}
+
+function readString (buffer, pos, length) {
+  return String.fromCharCode.apply(null, new Uint8Array(buffer, pos, length))
+}
+
+function writeString (view, pos, str) {
+  for (let i = 0; i < str.length; i++) {
+    view.setUint8(pos + i, str.charCodeAt(i))
+  }
+}
+
+let crcTable = null
+
+function crc32 (data) {
+  if (crcTable === null) {
+    crcTable = new Uint32Array(256)
+    for (let i = 0; i < 256; i++) {
+      let c = i
+      for (let k = 0; k < 8; k++) {
+        c = (c & 1) ? (0xedb88320 ^ (c >>> 1)) : (c >>> 1)
+      }
+      crcTable[i] = c >>> 0
+    }
+  }
+
+  let crc = 0xffffffff
+  for (let i = 0; i < data.length; i++) {
+    crc = crcTable[(crc ^ data[i]) & 0xff] ^ (crc >>> 8)
+  }
+  return (crc ^ 0xffffffff) >>> 0
+}
+
+export default {
+  'readArchive': readArchive,
+  'writeArchive': writeArchive,
+  'ArrayReaderWriterCloser': ArrayReaderWriterCloser
+}
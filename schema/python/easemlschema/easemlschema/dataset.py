@@ -6,7 +6,12 @@ import os
 import random
 import re
 import string
+import struct
 import sys
+import zipfile
+
+from datetime import datetime, timedelta, timezone
+from PIL import Image as PILImage
 
 import easemlschema.schema as sch
 
@@ -42,6 +47,210 @@ def random_string(size, chars=string.ascii_lowercase + string.digits):
     return "".join(random.choice(chars) for _ in range(size))
 
 
+def random_tensor_data(dim, dtype):
+    if dtype.startswith("float"):
+        return np.random.rand(*dim).astype(dtype)
+    high = min(np.iinfo(dtype).max, np.iinfo(np.int32).max)
+    return np.random.randint(0, high, size=dim, dtype=dtype)
+
+
+def random_tensor(name, dim, field):
+    # Returns a tensor with random data which follows the dtype and sparsity of
+    # the schema field.
+    dtype = field.dtype or "float64"
+    if not field.sparse:
+        return Tensor(name, dim, random_tensor_data(dim, dtype), dtype=dtype)
+
+    # Pick distinct random positions of the non-zero elements.
+    size = int(np.prod(dim))
+    nnz = max(1, int(size * SPARSE_DENSITY))
+    positions = np.sort(np.random.choice(size, nnz, replace=False))
+    coords = np.stack(np.unravel_index(positions, dim), axis=1).astype("int64")
+    data = SparseData(coords, random_tensor_data([nnz], dtype))
+    return Tensor(name, dim, data, "coo", dtype)
+
+
+def random_value_file(name, field, classes, count):
+    # Returns a text, ordinal or datetime file with the given number of random
+    # values. For other fields it returns None.
+    if field.field_type == "text":
+        texts = [" ".join(random_string(random.randint(1, 8), string.ascii_lowercase)
+                          for _ in range(random.randint(1, MAX_RANDOM_TEXT_WORDS)))
+                 for _ in range(count)]
+        return Text(name, texts)
+
+    elif field.field_type == "ordinal":
+        values = [random.choice(classes[field.category_class].categories)
+                  for _ in range(count)]
+        return Ordinal(name, values)
+
+    elif field.field_type == "datetime":
+        times = [RANDOM_TIME_START + timedelta(seconds=random.randrange(RANDOM_TIME_RANGE))
+                 for _ in range(count)]
+        return Datetime(name, times)
+
+    return None
+
+
+def infer_value_field(file, category_class_sets):
+    # Returns the schema field of a text, ordinal or datetime file and the
+    # number of values it holds. Ordinals belong to the first class which
+    # contains all their values. If no field can be inferred, the returned
+    # field is None and the message explains why.
+    if file.file_type == "text":
+        return sch.Text(), len(file.texts), None
+    elif file.file_type == "datetime":
+        return sch.Datetime(), len(file.times), None
+    elif file.file_type == "ordinal":
+        for class_name, category_set in category_class_sets.items():
+            if file.belongs_to_set(category_set):
+                return sch.Ordinal(class_name), len(file.values), None
+        return None, 0, "Ordinal file does not match any class."
+    return None, 0, "Files of type '%s' are unexpected." % file.file_type
+
+
+def read_value_lines(f, path, file_type, max_length):
+    # Reads the lines of a text, ordinal or datetime file. Only line feeds
+    # separate lines and a trailing carriage return is dropped from each line.
+    content = f.read()
+    lines = content.split(b"\n")
+    if content.endswith(b"\n") or len(content) == 0:
+        lines.pop()
+    for i, line in enumerate(lines):
+        if len(line) > max_length:
+            raise DatasetException("%s file could not be read. Line %d is too long." %
+                                   (file_type.capitalize(), i + 1), path)
+    try:
+        return [line.decode("utf-8").rstrip("\r") for line in lines]
+    except UnicodeDecodeError:
+        raise DatasetException("%s file could not be read. It is not UTF-8 encoded." %
+                               file_type.capitalize(), path)
+
+
+def parse_datetime(value):
+    # Parses an RFC 3339 timestamp. Returns None if the value is not valid.
+    m = DATETIME_FORMAT.match(value)
+    if m is None:
+        return None
+    year, month, day, hour, minute, second, fraction, zone = m.groups()
+    if zone == "Z":
+        tz = timezone.utc
+    else:
+        offset = timedelta(hours=int(zone[1:3]), minutes=int(zone[4:6]))
+        tz = timezone(-offset if zone[0] == "-" else offset)
+    microsecond = int((fraction[1:] + "000000")[:6]) if fraction else 0
+    try:
+        return datetime(int(year), int(month), int(day), int(hour), int(minute),
+                        int(second), microsecond, tz)
+    except ValueError:
+        return None
+
+
+def format_datetime(value):
+    # Formats a timestamp according to RFC 3339 with trailing zeros of the
+    # fractional seconds dropped.
+    result = value.strftime("%Y-%m-%dT%H:%M:%S")
+    if value.microsecond != 0:
+        result += ("." + "%06d" % value.microsecond).rstrip("0")
+    offset = value.utcoffset()
+    if not offset:
+        return result + "Z"
+    minutes = int(offset.total_seconds()) // 60
+    sign = "-" if minutes < 0 else "+"
+    return result + "%s%02d:%02d" % (sign, abs(minutes) // 60, abs(minutes) % 60)
+
+
+def image_dim_name(node_name, index):
+    # Returns the name of the variable height or width dimension of an image
+    # node.
+    name = re.sub(r"[^a-z0-9]", "_", node_name.lower())
+    if len(name) == 0 or name[0].isdigit():
+        name = "img_" + name
+    return name + ("_height" if index == 0 else "_width")
+
+
+def read_image_header(f):
+    # Returns the format, height, width and number of channels of a PNG or
+    # JPEG image or None if the file is not a valid image.
+    signature = f.read(2)
+    if signature == b"\xff\xd8":
+        return read_jpeg_header(f)
+    elif signature + f.read(6) == b"\x89PNG\r\n\x1a\n":
+        return read_png_header(f)
+    return None
+
+
+def read_png_header(f):
+    width, height, color_type, transparent = None, None, None, False
+    while True:
+        chunk = f.read(8)
+        if len(chunk) < 8:
+            return None
+        length, chunk_type = struct.unpack(">I4s", chunk)
+        if width is not None and chunk_type in [b"IDAT", b"IEND"]:
+            break
+        data = f.read(length + 4)[:length]
+        if len(data) < length:
+            return None
+
+        if chunk_type == b"IHDR" and length >= 13:
+            width, height, _, color_type = struct.unpack(">IIBB", data[:10])
+            # Only paletted images need the transparency chunk.
+            if color_type != 3:
+                break
+        elif width is None:
+            return None
+        elif chunk_type == b"tRNS":
+            transparent = any([x != 255 for x in bytearray(data)])
+            break
+
+    # Images with an alpha channel and paletted images with a transparent
+    # color have four channels.
+    channels = {0: 1, 2: 3, 3: 4 if transparent else 3, 4: 4, 6: 4}.get(color_type)
+    if channels is None:
+        return None
+    return "png", height, width, channels
+
+
+def read_jpeg_header(f):
+    while True:
+        marker = bytearray(f.read(2))
+        if len(marker) < 2 or marker[0] != 0xff:
+            return None
+        code = marker[1]
+        while code == 0xff:
+            fill = bytearray(f.read(1))
+            if len(fill) == 0:
+                return None
+            code = fill[0]
+
+        # Markers without a segment.
+        if code in [0x01, 0xd8] or 0xd0 <= code <= 0xd7:
+            continue
+        # The image data starts before the frame header was found.
+        if code in [0xd9, 0xda]:
+            return None
+
+        length = f.read(2)
+        if len(length) < 2 or struct.unpack(">H", length)[0] < 2:
+            return None
+        length = struct.unpack(">H", length)[0] - 2
+        data = f.read(length)
+        if len(data) < length:
+            return None
+
+        # Only baseline and progressive images are supported.
+        if code in [0xc0, 0xc1, 0xc2]:
+            if length < 6:
+                return None
+            _, height, width, components = struct.unpack(">BHHB", data[:6])
+            if components not in [1, 3, 4]:
+                return None
+            return "jpeg", height, width, components
+        elif 0xc3 <= code <= 0xcf and code not in [0xc4, 0xc8, 0xcc]:
+            return None
+
+
 class File:
 
     def __init__(self, name, file_type, subtype="default"):
@@ -50,7 +259,7 @@ class File:
 
         self.name = name
         self.file_type = file_type
-        self.subtype = "default"
+        self.subtype = subtype
 
 
 class Directory(File):
@@ -165,6 +374,10 @@ class Dataset(Directory):
         sch_fanin = False
         sch_undirected = True
 
+        # Images are inferred as tensors. Their height and width may vary
+        # between samples.
+        image_nodes = set()
+
         # Go through all data samples.
         for sample_name, sample in samples.items():
 
@@ -199,10 +412,18 @@ class Dataset(Directory):
                     raise DatasetException(
                         "Item found but not expected.", "/".join(["", sample_name, child_name]))
 
+            sample_tensors = dict(sample_children["tensor"])
+            for child_name, child in sample_children["image"].items():
+                sample_tensors[child_name] = Tensor(
+                    child_name, child.dimensions, dtype="uint8")
+                if first_sample:
+                    image_nodes.add(child_name)
+
             # Handle tensor singleton nodes.
-            for child_name, child in sample_children["tensor"].items():
+            for child_name, child in sample_tensors.items():
                 if first_sample:
-                    field = sch.Tensor(child.dimensions)
+                    field = sch.Tensor(list(child.dimensions), dtype=child.dtype,
+                                       sparse=child.is_sparse())
                     sch_nodes[child_name] = sch.Node(
                         is_singleton=True, fields={"field": field})
                 else:
@@ -212,9 +433,16 @@ class Dataset(Directory):
                             node.fields) > 1 or node.fields["field"].field_type != "tensor":
                         raise DatasetException(
                             "Node '%s' not the same type in all samples." % child_name, "/".join(["", sample_name]))
-                    elif node.fields["field"].dim != child.dimensions:
+                    elif not Dataset._match_tensor_dim(node.fields["field"], child.dimensions,
+                                                       child_name if child_name in image_nodes else None):
                         raise DatasetException(
                             "Tensor dimensions mismatch.", "/".join(["", sample_name, child_name]))
+                    elif node.fields["field"].dtype != child.dtype:
+                        raise DatasetException(
+                            "Tensor dtype mismatch.", "/".join(["", sample_name, child_name]))
+                    elif node.fields["field"].sparse != child.is_sparse():
+                        raise DatasetException(
+                            "Tensor sparsity mismatch.", "/".join(["", sample_name, child_name]))
 
             # Handle category singleton nodes.
             for child_name, child in sample_children["category"].items():
@@ -244,6 +472,32 @@ class Dataset(Directory):
                         raise DatasetException(
                             "Category class mismatch.", "/".join(["", sample_name, child_name]))
 
+            # Handle text, ordinal and datetime singleton nodes.
+            sample_values = {}
+            for file_type in VALUE_FILE_TYPES:
+                sample_values.update(sample_children[file_type])
+            for child_name, child in sample_values.items():
+                field, _, message = infer_value_field(
+                    child, category_class_sets)
+                if field is None:
+                    raise DatasetException(
+                        message, "/".join(["", sample_name, child_name]))
+
+                if first_sample:
+                    sch_nodes[child_name] = sch.Node(
+                        is_singleton=True, fields={"field": field})
+                else:
+                    # Verify that the node is the same.
+                    node = sch_nodes[child_name]
+                    if node.is_singleton is False or len(
+                            node.fields) > 1 or node.fields["field"].field_type != field.field_type:
+                        raise DatasetException(
+                            "Node '%s' not the same type in all samples." % child_name, "/".join(["", sample_name]))
+                    elif field.field_type == "ordinal" and \
+                            node.fields["field"].category_class != field.category_class:
+                        raise DatasetException(
+                            "Ordinal class mismatch.", "/".join(["", sample_name, child_name]))
+
             # This counts how many instances each node has. It is used to
             # validate link targets.
             node_instance_count = {}
@@ -287,7 +541,8 @@ class Dataset(Directory):
 
                         if first_sample:
                             fields[node_child_name] = sch.Tensor(
-                                node_child.dimensions[1:])
+                                node_child.dimensions[1:], dtype=node_child.dtype,
+                                sparse=node_child.is_sparse())
                         else:
                             # Verify that the node is the same.
                             field = fields[node_child_name]
@@ -297,6 +552,12 @@ class Dataset(Directory):
                             if field.dim != node_child.dimensions[1:]:
                                 raise DatasetException("Tensor dimensions mismatch.",
                                                        "/".join(["", sample_name, child_name, node_child_name]))
+                            if field.dtype != node_child.dtype:
+                                raise DatasetException("Tensor dtype mismatch.",
+                                                       "/".join(["", sample_name, child_name, node_child_name]))
+                            if field.sparse != node_child.is_sparse():
+                                raise DatasetException("Tensor sparsity mismatch.",
+                                                       "/".join(["", sample_name, child_name, node_child_name]))
 
                     elif node_child.file_type == "category":
 
@@ -332,6 +593,37 @@ class Dataset(Directory):
                                 raise DatasetException("Category class mismatch.",
                                                        "/".join(["", sample_name, child_name, node_child_name]))
 
+                    elif node_child.file_type in VALUE_FILE_TYPES:
+
+                        field, count, message = infer_value_field(
+                            node_child, category_class_sets)
+                        if field is None:
+                            raise DatasetException(message, "/".join(
+                                ["", sample_name, child_name, node_child_name]))
+
+                        # Verify that all node fields have the same number of
+                        # instances.
+                        if node_instance_count.setdefault(
+                                child_name, count) != count:
+                            raise DatasetException("%s instance count mismatch." % field.field_type.capitalize(),
+                                                   "/".join(["", sample_name, child_name, node_child_name]))
+
+                        if first_sample:
+                            fields[node_child_name] = field
+                        else:
+                            # Verify that the node is the same.
+                            existing = fields[node_child_name]
+                            if existing.field_type != field.field_type:
+                                raise DatasetException("Node '%s' not the same type in all samples." %
+                                                       child_name, "/".join(["", sample_name, child_name, node_child_name]))
+                            if field.field_type == "ordinal" and existing.category_class != field.category_class:
+                                raise DatasetException("Ordinal class mismatch.",
+                                                       "/".join(["", sample_name, child_name, node_child_name]))
+
+                    elif node_child.file_type == "image":
+                        raise DatasetException("Images are only supported as singleton nodes.",
+                                               "/".join(["", sample_name, child_name, node_child_name]))
+
                     else:
                         # We forbid any other file type in the node directory.
                         # Maybe we should just ignore.
@@ -422,6 +714,21 @@ class Dataset(Directory):
         return sch.Schema(sch_nodes, sch_category_classes,
                           sch_cyclic, sch_undirected, sch_fanin)
 
+    @staticmethod
+    def _match_tensor_dim(field, dimensions, image_name=None):
+        if len(field.dim) != len(dimensions):
+            return False
+        for i, d in enumerate(dimensions):
+            if not isinstance(field.dim[i], int):
+                # Only image height and width can be variable.
+                continue
+            if field.dim[i] != d:
+                if image_name is not None and i < 2:
+                    field.dim[i] = image_dim_name(image_name, i)
+                    continue
+                return False
+        return True
+
     @staticmethod
     def generate_from_schema(
             root, schema, num_samples=10, num_node_instances=10):
@@ -447,13 +754,12 @@ class Dataset(Directory):
                 if node.is_singleton:
 
                     field = next(iter(node.fields.values()))
-                    assert(field.field_type in ["tensor", "category"])
 
                     # Generate singleton tensor.
                     if field.field_type == "tensor":
                         assert(all([isinstance(x, int) for x in field.dim]))
-                        data = np.random.rand(*field.dim)
-                        nodes[node_name] = Tensor(node_name, field.dim, data)
+                        nodes[node_name] = random_tensor(
+                            node_name, field.dim, field)
 
                     # Generate singleton category.
                     elif field.field_type == "category":
@@ -461,21 +767,24 @@ class Dataset(Directory):
                             classes[field.category_class].categories)]
                         nodes[node_name] = Category(node_name, categories)
 
+                    # Generate singleton text, ordinal or datetime.
+                    else:
+                        nodes[node_name] = random_value_file(
+                            node_name, field, classes, 1)
+
                 else:
 
                     node_children = {}
 
                     for field_name, field in node.fields.items():
-                        assert(field.field_type in ["tensor", "category"])
 
                         # Generate non-singleton tensor.
                         if field.field_type == "tensor":
                             assert(all([isinstance(x, int)
                                         for x in field.dim]))
                             dim = [num_node_instances] + field.dim
-                            data = np.random.rand(*dim)
-                            node_children[field_name] = Tensor(
-                                field_name, dim, data)
+                            node_children[field_name] = random_tensor(
+                                field_name, dim, field)
 
                         # Generate non-singleton category.
                         elif field.field_type == "category":
@@ -484,6 +793,11 @@ class Dataset(Directory):
                             node_children[field_name] = Category(
                                 field_name, categories)
 
+                        # Generate non-singleton text, ordinal or datetime.
+                        else:
+                            node_children[field_name] = random_value_file(
+                                field_name, field, classes, num_node_instances)
+
                     # Generate the actual node directory.
                     nodes[node_name] = Directory(node_name, node_children)
 
@@ -589,12 +903,18 @@ class Dataset(Directory):
 
 class Tensor(File):
 
-    def __init__(self, name, dimensions, data=None, subtype="default"):
-        assert(subtype in ["default", "csv"])
+    def __init__(self, name, dimensions, data=None,
+                 subtype="default", dtype="float64"):
+        assert(subtype in ["default", "npz", "coo", "csv"])
         super(Tensor, self).__init__(name, "tensor", subtype)
         assert(isinstance(dimensions, list) or isinstance(dimensions, tuple))
+        assert(dtype in sch.TENSOR_DTYPES)
         self.dimensions = list(dimensions)
         self.data = data
+        self.dtype = dtype
+
+    def is_sparse(self):
+        return self.subtype == "coo"
 
     @staticmethod
     def _load(root, rel_path, name, opener,
@@ -603,28 +923,93 @@ class Tensor(File):
         with opener(root, path + TYPE_EXTENSIONS["tensor"][subtype], "tensor", read_only=True, binary=True) as f:
 
             if subtype == "default":
-                if metadata_only:
-                    major, minor = np.lib.format.read_magic(f)
-                    read_header = getattr(
-                        np.lib.format, "read_array_header_%d_%d" %
-                        (major, minor))
-                    shape, _, dtype = read_header(f)
-                    data = None
+                data, shape, dtype = Tensor._read_npy(f, metadata_only)
 
-                else:
-                    data = np.load(f)
-                    shape = data.shape
-                    dtype = data.dtype
+            elif subtype == "npz":
+                try:
+                    archive = zipfile.ZipFile(f)
+                except zipfile.BadZipFile:
+                    raise DatasetException(
+                        "Tensor file is not a valid NPZ archive.", path)
+                with archive:
+                    members = archive.namelist()
+                    if len(members) != 1 or not members[0].endswith(".npy"):
+                        raise DatasetException(
+                            "NPZ tensor file must contain exactly one array.", path)
+                    with archive.open(members[0]) as member:
+                        data, shape, dtype = Tensor._read_npy(
+                            member, metadata_only)
+
+            elif subtype == "coo":
+                data, shape, dtype = Tensor._read_coo(f, metadata_only, path)
 
             elif subtype == "csv":
                 data = np.loadtxt(f, delimiter=",")
                 shape = data.shape
                 dtype = data.dtype
 
-        if dtype != np.dtype("float_"):
-            raise DatasetException("Tensor datatype must be float64.", path)
+        dtype = np.dtype(dtype).name
+        if dtype not in sch.TENSOR_DTYPES:
+            raise DatasetException(
+                "Tensor datatype '%s' is not supported." % dtype, path)
+
+        return Tensor(name, shape, data, subtype, dtype)
+
+    @staticmethod
+    def _read_npy(f, metadata_only):
+        if metadata_only:
+            major, minor = np.lib.format.read_magic(f)
+            read_header = getattr(
+                np.lib.format, "read_array_header_%d_%d" %
+                (major, minor))
+            shape, _, dtype = read_header(f)
+            return None, shape, dtype
+
+        data = np.load(f, allow_pickle=False)
+        return data, data.shape, data.dtype
+
+    @staticmethod
+    def _read_coo(f, metadata_only, path):
+        # The archive holds the tensor shape, the element coordinates and the
+        # element values.
+        try:
+            archive = zipfile.ZipFile(f)
+        except zipfile.BadZipFile:
+            raise DatasetException(
+                "Tensor file is not a valid NPZ archive.", path)
+
+        with archive:
+            members = archive.namelist()
+
+            def read_member(member_name, metadata_only):
+                if member_name not in members:
+                    raise DatasetException(
+                        "Sparse tensor file must contain the '%s' array." % member_name, path)
+                with archive.open(member_name) as member:
+                    return Tensor._read_npy(member, metadata_only)
+
+            shape, _, shape_dtype = read_member("shape.npy", False)
+            if np.dtype(shape_dtype).name != "int64" or np.size(shape) == 0:
+                raise DatasetException(
+                    "Sparse tensor shape must be a non-empty int64 array.", path)
+            dimensions = [int(x) for x in np.ravel(shape)]
+
+            values, values_shape, dtype = read_member("values.npy", metadata_only)
+            coords, coords_shape, coords_dtype = read_member("coords.npy", metadata_only)
+            if np.dtype(coords_dtype).name != "int64" or len(coords_shape) != 2 or \
+                    coords_shape[1] != len(dimensions) or len(values_shape) != 1 or \
+                    values_shape[0] != coords_shape[0]:
+                raise DatasetException(
+                    "Sparse tensor coordinates must be an int64 matrix with one row per value.", path)
+
+        data = None
+        if not metadata_only:
+            if np.any(coords < 0) or np.any(coords >= np.array(dimensions)):
+                raise DatasetException(
+                    "Sparse tensor coordinate out of bounds.", path)
+            data = SparseData(coords, values)
 
-        return Tensor(name, shape, data, subtype)
+        return data, dimensions, dtype
 
     def _dump(self, root, rel_path, name, opener):
 
@@ -635,10 +1020,25 @@ class Tensor(File):
         with opener(root, path + TYPE_EXTENSIONS["tensor"][self.subtype], "tensor", read_only=False, binary=True) as f:
             if self.subtype == "default":
                 np.save(f, self.data, allow_pickle=False)
+            elif self.subtype == "npz":
+                np.savez_compressed(f, self.data)
+            elif self.subtype == "coo":
+                np.savez_compressed(f, shape=np.array(self.dimensions, dtype="int64"),
+                                    coords=self.data.coords, values=self.data.values)
             elif self.subtype == "csv":
                 np.savetxt(f, self.data, delimiter=",")
 
 
+class SparseData(object):
+    # Holds the non-zero elements of a sparse tensor in coordinate (COO)
+    # format. The coords are a matrix with one row of indices per element and
+    # the values hold the element values.
+
+    def __init__(self, coords, values):
+        self.coords = coords
+        self.values = values
+
+
 class Category(File):
 
     def __init__(self, name, categories):
@@ -673,6 +1073,92 @@ class Category(File):
         return True
 
 
+class Text(File):
+    # Holds one string per line. Each line corresponds to one node instance.
+
+    def __init__(self, name, texts):
+        super(Text, self).__init__(name, "text")
+        assert(isinstance(texts, list))
+        self.texts = texts
+
+    @staticmethod
+    def _load(root, rel_path, name, opener,
+              metadata_only=False, subtype="default"):
+        path = os.path.join(rel_path, name)
+        with opener(root, path + TYPE_EXTENSIONS["text"][subtype], "text", read_only=True, binary=True) as f:
+            texts = read_value_lines(f, path, "text", MAX_TEXT_LENGTH)
+
+        return Text(name, texts)
+
+    def _dump(self, root, rel_path, name, opener):
+        path = os.path.join(rel_path, name)
+        if any("\r" in x or "\n" in x for x in self.texts):
+            raise DatasetException("Texts cannot contain line breaks.", path)
+        with opener(root, path + TYPE_EXTENSIONS["text"][self.subtype], "text", read_only=False, binary=True) as f:
+            f.writelines((x + "\n").encode("utf-8") for x in self.texts)
+
+
+class Ordinal(File):
+    # Holds one ordered category per line. The order of categories is given by
+    # the order of lines in the class file.
+
+    def __init__(self, name, values):
+        super(Ordinal, self).__init__(name, "ordinal")
+        assert(isinstance(values, list))
+        self.values = values
+
+    @staticmethod
+    def _load(root, rel_path, name, opener,
+              metadata_only=False, subtype="default"):
+        path = os.path.join(rel_path, name)
+        with opener(root, path + TYPE_EXTENSIONS["ordinal"][subtype], "ordinal", read_only=True, binary=True) as f:
+            values = [x.strip() for x in read_value_lines(
+                f, path, "ordinal", MAX_VALUE_LENGTH)]
+
+        return Ordinal(name, values)
+
+    def _dump(self, root, rel_path, name, opener):
+        path = os.path.join(rel_path, name)
+        with opener(root, path + TYPE_EXTENSIONS["ordinal"][self.subtype], "ordinal", read_only=False, binary=True) as f:
+            f.writelines((x + "\n").encode("utf-8") for x in self.values)
+
+    def belongs_to_set(self, category_set):
+        assert(isinstance(category_set, set))
+        return all(value in category_set for value in self.values)
+
+
+class Datetime(File):
+    # Holds one RFC 3339 timestamp per line.
+
+    def __init__(self, name, times):
+        super(Datetime, self).__init__(name, "datetime")
+        assert(isinstance(times, list))
+        self.times = times
+
+    @staticmethod
+    def _load(root, rel_path, name, opener,
+              metadata_only=False, subtype="default"):
+        path = os.path.join(rel_path, name)
+        with opener(root, path + TYPE_EXTENSIONS["datetime"][subtype], "datetime", read_only=True, binary=True) as f:
+            lines = read_value_lines(f, path, "datetime", MAX_VALUE_LENGTH)
+
+        times = []
+        for i, line in enumerate(lines):
+            value = parse_datetime(line.strip())
+            if value is None:
+                raise DatasetException(
+                    "Line %d of the datetime file is not an RFC 3339 timestamp." % (i + 1), path)
+            times.append(value)
+
+        return Datetime(name, times)
+
+    def _dump(self, root, rel_path, name, opener):
+        path = os.path.join(rel_path, name)
+        with opener(root, path + TYPE_EXTENSIONS["datetime"][self.subtype], "datetime", read_only=False, binary=True) as f:
+            f.writelines((format_datetime(x) + "\n").encode("utf-8")
+                         for x in self.times)
+
+
 class Links(File):
 
     def __init__(self, name, links):
@@ -939,26 +1425,120 @@ class Class(File):
             f.writelines(lines)
 
 
+class Image(File):
+
+    def __init__(self, name, height, width, channels,
+                 data=None, subtype="png"):
+        assert(subtype in ["png", "jpeg", "jpg"])
+        super(Image, self).__init__(name, "image", subtype)
+        assert(channels in [1, 3, 4])
+        self.height = height
+        self.width = width
+        self.channels = channels
+        self.data = data
+
+    @property
+    def dimensions(self):
+        # The shape of the image as (height, width, channels).
+        return [self.height, self.width, self.channels]
+
+    @staticmethod
+    def _load(root, rel_path, name, opener,
+              metadata_only=False, subtype="png"):
+        path = os.path.join(rel_path, name)
+        with opener(root, path + TYPE_EXTENSIONS["image"][subtype], "image", read_only=True, binary=True) as f:
+
+            header = read_image_header(f)
+            if header is None or (header[0] == "png") != (subtype == "png"):
+                raise DatasetException(
+                    "Image file is not a valid PNG or JPEG image.", path)
+            _, height, width, channels = header
+
+            # The pixels are kept as an array of shape (height, width,
+            # channels).
+            data = None
+            if not metadata_only:
+                f.seek(0)
+                try:
+                    image = PILImage.open(f)
+                    if image.mode != "CMYK":
+                        image = image.convert(
+                            {1: "L", 3: "RGB", 4: "RGBA"}[channels])
+                    data = np.asarray(image).reshape(
+                        (height, width, channels))
+                except (OSError, SyntaxError, ValueError):
+                    raise DatasetException(
+                        "Image file is not a valid PNG or JPEG image.", path)
+
+        return Image(name, height, width, channels, data, subtype)
+
+    def _dump(self, root, rel_path, name, opener):
+        path = os.path.join(rel_path, name)
+        if self.data is None:
+            raise DatasetException("Cannot write image without data.", path)
+
+        data = np.asarray(self.data, dtype=np.uint8)
+        if self.channels == 1:
+            data = data.reshape((self.height, self.width))
+        image_format = "PNG" if self.subtype == "png" else "JPEG"
+
+        with opener(root, path + TYPE_EXTENSIONS["image"][self.subtype], "image", read_only=False, binary=True) as f:
+            PILImage.fromarray(data).save(f, format=image_format)
+
+
 FILE_TYPES = {
     "directory": Directory,
     "tensor": Tensor,
     "category": Category,
     "links": Links,
-    "class": Class
+    "class": Class,
+    "image": Image,
+    "text": Text,
+    "ordinal": Ordinal,
+    "datetime": Datetime
 }
 
+# File types which hold one value per node instance and are inferred as
+# text, ordinal or datetime fields.
+VALUE_FILE_TYPES = ["text", "ordinal", "datetime"]
+
 
 TYPE_EXTENSIONS = {
-    "tensor": {"default": ".ten.npy", "csv": ".ten.csv"},
+    "tensor": {"default": ".ten.npy", "npz": ".ten.npz", "coo": ".ten.coo.npz", "csv": ".ten.csv"},
     "category": {"default": ".cat.txt"},
     "class": {"default": ".class.txt"},
-    "links": {"default": ".links.csv"}
+    "links": {"default": ".links.csv"},
+    "image": {"png": ".png", "jpeg": ".jpeg", "jpg": ".jpg"},
+    "text": {"default": ".text.txt"},
+    "ordinal": {"default": ".ord.txt"},
+    "datetime": {"default": ".time.txt"}
 }
 
 
 LINK_FORMAT = re.compile(
     r"^\s*[a-z_]*[0-9a-z_]*(/[0-9]+)?\s+[a-z_]*[0-9a-z_]*(/[0-9]+)?\s*\Z",
     re.ASCII)
+# The fraction of non-zero elements in generated sparse tensors.
+SPARSE_DENSITY = 0.1
+
+# The maximum length of a single text and of a line in an ordinal or datetime
+# file in bytes.
+MAX_TEXT_LENGTH = 16 * 1024 * 1024
+MAX_VALUE_LENGTH = 64 * 1024
+
+DATETIME_FORMAT = re.compile(
+    r"^(\d{4})-(\d{2})-(\d{2})T(\d{2}):(\d{2}):(\d{2})(\.\d+)?(Z|[+-]\d{2}:\d{2})\Z",
+    re.ASCII)
+
+# The maximum number of words in generated texts.
+MAX_RANDOM_TEXT_WORDS = 20
+
+# Generated timestamps are picked uniformly at random from a ten year range.
+# They are whole seconds to survive the round trip through the RFC 3339
+# format.
+RANDOM_TIME_START = datetime(2010, 1, 1, tzinfo=timezone.utc)
+RANDOM_TIME_RANGE = 10 * 365 * 24 * 60 * 60
+
 NODE_SOURCE = "SOURCE"
 NODE_SINK = "SINK"
 
@@ -1,4 +1,5 @@
 import argparse
+import collections
 import copy
 import itertools
 import json
@@ -9,6 +10,10 @@ import sys
 NAME_FORMAT = re.compile(r"^[a-z_][0-9a-z_]*\Z", re.ASCII)
 DIM_FORMAT = re.compile(r"^[a-z_][0-9a-z_]*[+?*]?\Z", re.ASCII)
 
+# Element types a tensor can declare.
+TENSOR_DTYPES = ["float64", "float32", "float16",
+                 "int64", "int32", "int16", "int8", "uint8"]
+
 
 class SchemaException(Exception):
 
@@ -90,7 +95,7 @@ class Schema:
                             "nodes." + k + ".links." + l)
                     link_count += nodes[k].links[l].dim[1]
 
-            # Check category field classes.
+            # Check category and ordinal field classes.
             for f in nodes[k].fields:
                 if isinstance(nodes[k].fields[f], Category):
                     if nodes[k].fields[f].category_class not in category_classes:
@@ -107,7 +112,7 @@ class Schema:
         # Check if there are unreferenced classes.
         if len(orphan_classes) > 0:
             raise SchemaException(
-                "Every declared class must be referenced in a category.",
+                "Every declared class must be referenced in a category or an ordinal.",
                 "classes." + orphan_classes.pop())
 
         # Source dimensions check.
@@ -502,6 +507,9 @@ class Node:
         dim_map_updates = {}
         class_name_map_updates = {}
 
+        # Categories and ordinals are both matched based on their classes.
+        # Text and datetime fields have no properties so they are matched by
+        # type only.
         self_tensor_names = [
             name for name in self.fields if isinstance(
                 self.fields[name], Tensor)]
@@ -516,8 +524,11 @@ class Node:
                 source.fields[name], Category)]
 
         # Simply dismiss in case the counts don't match.
-        if len(self_tensor_names) != len(source_tensor_names) or \
-                len(self_category_names) != len(source_category_names) or \
+        self_type_counts = collections.Counter(
+            field.field_type for field in self.fields.values())
+        source_type_counts = collections.Counter(
+            field.field_type for field in source.fields.values())
+        if self_type_counts != source_type_counts or \
                 len(self.links) != len(source.links):
             return (None, {}, {}) if build_matching else (False, {}, {})
 
@@ -602,6 +613,15 @@ class Node:
         if not match:
             return (None, {}, {}) if build_matching else (False, {}, {})
 
+        # Fields without properties are interchangeable so we pair them up by
+        # type in the order of their names.
+        for field_type in ["text", "datetime"]:
+            self_names = sorted(name for name, field in self.fields.items()
+                                if field.field_type == field_type)
+            source_names = sorted(name for name, field in source.fields.items()
+                                  if field.field_type == field_type)
+            field_name_map.update(dict(zip(self_names, source_names)))
+
         # If a matching was found, build a resulting matching node if needed.
         if build_matching:
             links = copy.deepcopy(self.links)
@@ -689,7 +709,8 @@ class Link:
 class Field:
 
     def __init__(self, field_type, src_name=None):
-        assert(field_type in ["tensor", "category"])
+        assert(field_type in ["tensor", "category",
+                              "ordinal", "text", "datetime"])
         assert(src_name is None or isinstance(src_name, str))
 
         self.field_type = field_type
@@ -713,13 +734,20 @@ class Field:
             return Tensor._load(input)
         elif field_type == "category":
             return Category._load(input)
+        elif field_type == "ordinal":
+            return Ordinal._load(input)
+        elif field_type == "text":
+            return Text._load(input)
+        elif field_type == "datetime":
+            return Datetime._load(input)
         else:
             raise SchemaException("Unknown field type '%s'." % field_type)
 
 
 class Tensor(Field):
 
-    def __init__(self, dim, src_name=None, src_dim=None):
+    def __init__(self, dim, src_name=None, src_dim=None, dtype=None,
+                 sparse=False):
 
         # Simple type and value checks.
         if not isinstance(dim, list):
@@ -733,6 +761,14 @@ class Tensor(Field):
             elif re.match(NAME_FORMAT, src_name) is None:
                 raise SchemaException(
                     "Source name may contain lowercase letters, numbers and underscores. They must start with a letter.")
+        if dtype is not None:
+            if not isinstance(dtype, str):
+                raise SchemaException("Tensor dtype must be a string.")
+            elif dtype not in TENSOR_DTYPES:
+                raise SchemaException(
+                    "Tensor dtype must be one of: %s." % ", ".join(TENSOR_DTYPES))
+        if not isinstance(sparse, bool):
+            raise SchemaException("Tensor sparse field must be a boolean.")
 
         # Type and value checks for each dimension.
         for d in dim:
@@ -783,12 +819,18 @@ class Tensor(Field):
         super(Tensor, self).__init__("tensor", src_name)
         self.dim = dim
         self.src_dim = src_dim
+        self.dtype = dtype
+        self.sparse = sparse
 
     def _dump(self):
         result = super(Tensor, self)._dump()
         result["dim"] = self.dim
         if self.src_dim is not None:
             result["src-dim"] = self.src_dim
+        if self.dtype is not None:
+            result["dtype"] = self.dtype
+        if self.sparse:
+            result["sparse"] = True
         return result
 
     @staticmethod
@@ -796,16 +838,27 @@ class Tensor(Field):
         dim = input.get("dim", None)
         src_name = input.get("src-name", None)
         src_dim = input.get("src-dim", None)
+        dtype = input.get("dtype", None)
+        sparse = input.get("sparse", False)
 
         if dim is None:
             raise SchemaException("Tensor must have a 'dim' field.")
 
-        return Tensor(dim, src_name, src_dim)
+        return Tensor(dim, src_name, src_dim, dtype, sparse)
 
     def _match(self, source, dim_map):
         assert(isinstance(dim_map, dict))
         assert(isinstance(source, Tensor))
 
+        # A tensor without a dtype accepts any dtype.
+        if self.dtype is not None and source.dtype is not None and self.dtype != source.dtype:
+            return False, {}
+
+        # A sparse tensor accepts dense tensors as well, but a dense tensor
+        # does not accept sparse ones.
+        if source.sparse and not self.sparse:
+            return False, {}
+
         match, dim_map_update = match_dim_list(self.dim, source.dim, dim_map)
         return match, dim_map_update
 
@@ -907,14 +960,15 @@ def match_dim_list(list_a, list_b, dim_map=None):
 
 class Category(Field):
 
-    def __init__(self, category_class, src_name=None):
+    def __init__(self, category_class, src_name=None, field_type="category"):
 
         # Simple type and value checks.
+        kind = field_type.capitalize()
         if not isinstance(category_class, str):
-            raise SchemaException("Category class must be a string.")
+            raise SchemaException("%s class must be a string." % kind)
         elif re.match(NAME_FORMAT, category_class) is None:
             raise SchemaException(
-                "Category class may contain lowercase letters, numbers and underscores. They must start with a letter.")
+                "%s class may contain lowercase letters, numbers and underscores. They must start with a letter." % kind)
         if src_name is not None:
             if not isinstance(src_name, str):
                 raise SchemaException("Source name must be a string.")
@@ -922,7 +976,7 @@ class Category(Field):
                 raise SchemaException(
                     "Source name may contain lowercase letters, numbers and underscores. They must start with a letter.")
 
-        super(Category, self).__init__("category", src_name)
+        super(Category, self).__init__(field_type, src_name)
         self.category_class = category_class
 
     def _dump(self):
@@ -951,6 +1005,10 @@ class Category(Field):
         assert(isinstance(class_name_map, dict))
         assert(isinstance(source, Category))
 
+        # Categories never match ordinals even if their classes match.
+        if source.field_type != self.field_type:
+            return False, {}, {}
+
         # If the class has already been mapped then we simply compare.
         if self.category_class in class_name_map:
             return source.category_class == class_name_map[self.category_class], {
@@ -968,6 +1026,64 @@ class Category(Field):
                 return False, {}, {}
 
 
+class Ordinal(Category):
+    # An ordinal is a category whose values are ordered. The order is given by
+    # the order of categories in the class.
+
+    def __init__(self, category_class, src_name=None):
+        super(Ordinal, self).__init__(category_class, src_name, "ordinal")
+
+    @staticmethod
+    def _load(input):
+        category_class = input.get("class", None)
+        src_name = input.get("src-name", None)
+
+        if category_class is None:
+            raise SchemaException("Ordinal must have a 'class' field.")
+
+        return Ordinal(category_class, src_name)
+
+
+class Text(Field):
+    # A text field holds raw strings of arbitrary length.
+
+    def __init__(self, src_name=None):
+
+        # Simple type and value checks.
+        if src_name is not None:
+            if not isinstance(src_name, str):
+                raise SchemaException("Source name must be a string.")
+            elif re.match(NAME_FORMAT, src_name) is None:
+                raise SchemaException(
+                    "Source name may contain lowercase letters, numbers and underscores. They must start with a letter.")
+
+        super(Text, self).__init__("text", src_name)
+
+    @staticmethod
+    def _load(input):
+        return Text(input.get("src-name", None))
+
+
+class Datetime(Field):
+    # A datetime field holds points in time.
+
+    def __init__(self, src_name=None):
+
+        # Simple type and value checks.
+        if src_name is not None:
+            if not isinstance(src_name, str):
+                raise SchemaException("Source name must be a string.")
+            elif re.match(NAME_FORMAT, src_name) is None:
+                raise SchemaException(
+                    "Source name may contain lowercase letters, numbers and underscores. They must start with a letter.")
+
+        super(Datetime, self).__init__("datetime", src_name)
+
+    @staticmethod
+    def _load(input):
+        return Datetime(input.get("src-name", None))
+
+
 class Class:
 
     def __init__(self, dim, src_name=None):